@@ -13,6 +13,7 @@ import (
 
 	"github.com/bluele/gcache"
 	kiratools "github.com/kira1928/remotetools/pkg/tools"
+	"github.com/sirupsen/logrus"
 
 	_ "github.com/bililive-go/bililive-go/src/cmd/bililive/internal"
 	"github.com/bililive-go/bililive-go/src/cmd/bililive/internal/flag"
@@ -21,11 +22,14 @@ import (
 	"github.com/bililive-go/bililive-go/src/instance"
 	"github.com/bililive-go/bililive-go/src/listeners"
 	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/live/ingest"
 	"github.com/bililive-go/bililive-go/src/livestate"
 	"github.com/bililive-go/bililive-go/src/log"
 	"github.com/bililive-go/bililive-go/src/metrics"
+	"github.com/bililive-go/bililive-go/src/notify"
 	"github.com/bililive-go/bililive-go/src/pipeline"
 	"github.com/bililive-go/bililive-go/src/pipeline/stages"
+	"github.com/bililive-go/bililive-go/src/pkg/cookiemonitor"
 	"github.com/bililive-go/bililive-go/src/pkg/events"
 	"github.com/bililive-go/bililive-go/src/pkg/iostats"
 	"github.com/bililive-go/bililive-go/src/pkg/kliveproxy"
@@ -378,6 +382,8 @@ func main() {
 		if err := liveStateManager.Start(); err != nil {
 			logger.WithError(err).Warn("启动直播间状态管理器失败")
 		}
+		// 根据历史开播时间自适应调整检测间隔（通过回调避免循环依赖）
+		live.SetPollingAdvisor(liveStateManager.PollingAdvice)
 	}
 
 	// 先初始化 manager（不启动），因为 server 依赖它们
@@ -439,6 +445,34 @@ func main() {
 		logger.Fatalf("failed to init pipeline manager, error: %s", err)
 	}
 
+	// 平台被风控拦截时自动冷却，冷却状态变化时发送通知、推送到前端并记录冷却期
+	// 事件由单个 goroutine 按顺序处理，避免恢复事件先于冷却事件写入冷却记录
+	cooldownEvents := make(chan ratelimit.CooldownEvent, 64)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e := <-cooldownEvents:
+				handlePlatformCooldown(e)
+			}
+		}
+	}()
+	ratelimit.GetGlobalRateLimiter().SetCooldownCallback(func(e ratelimit.CooldownEvent) {
+		select {
+		case cooldownEvents <- e:
+		case <-ctx.Done():
+		}
+	})
+
+	// 启动 Cookie 健康检查（是否检查由 cookie_monitor.enable 在每轮检查时决定）
+	cookiemonitor.GetMonitor().Start(ctx)
+
+	// 启动内置 RTMP 推流服务器（是否启动由 ingest.enable 决定）
+	if err = ingest.Start(ctx); err != nil {
+		logger.WithError(err).Error("启动 RTMP 推流服务器失败")
+	}
+
 	if err = metrics.NewCollector(ctx).Start(ctx); err != nil {
 		logger.Fatalf("failed to init metrics collector, error: %s", err)
 	}
@@ -505,18 +539,16 @@ func main() {
 		})
 	}
 
+	// 为推流密钥补充虚拟直播间
+	if _, err := configs.EnsureIngestLiveRooms(); err != nil {
+		logger.WithError(err).Warn("添加推流虚拟直播间失败")
+	}
+
 	// 初始化 live rooms
 	// 第一步：立即为所有配置的直播间创建 InitializingLive，让前端可以看到
 	cfg := configs.GetCurrentConfig()
 
-	// 确保所有平台都有最小访问限制（用于控制并行初始化时的请求速度）
-	for _, room := range cfg.LiveRooms {
-		platformKey := configs.GetPlatformKeyFromUrl(room.Url)
-		if platformKey != "" {
-			minInterval := cfg.GetPlatformMinAccessInterval(platformKey)
-			ratelimit.GetGlobalRateLimiter().SetPlatformLimit(platformKey, minInterval)
-		}
-	}
+	// 所有平台都有默认的令牌桶限制（加载配置时同步），用于控制并行初始化时的请求速度
 
 	// 分两批处理：监听中的直播间和非监听的直播间
 	var listeningRooms []live.Live
@@ -734,3 +766,48 @@ func main() {
 
 	logger.Info("Bye~")
 }
+
+// handlePlatformCooldown 处理平台风控冷却状态变化
+func handlePlatformCooldown(e ratelimit.CooldownEvent) {
+	logger := logrus.WithFields(logrus.Fields{
+		"platform": e.Platform,
+		"level":    e.Level,
+		"until":    e.Until.Format(time.DateTime),
+	})
+	switch e.Type {
+	case ratelimit.CooldownStarted:
+		logger.WithField("reason", e.Reason).Warn("请求被平台风控拦截，暂停访问该平台")
+	case ratelimit.CooldownRecovering:
+		logger.Info("平台冷却结束，逐步恢复请求频率")
+	case ratelimit.CooldownRecovered:
+		logger.Info("平台已恢复正常请求频率")
+	}
+
+	servers.GetSSEHub().BroadcastRiskControl(map[string]interface{}{
+		"event_type": string(e.Type),
+		"cooldown":   e.CooldownState,
+		"timestamp":  time.Now().Unix(),
+	})
+
+	if e.Type != ratelimit.CooldownRecovering {
+		iostats.TrackCooldown(&iostats.PlatformCooldown{
+			Platform:  e.Name,
+			StartTime: e.StartedAt.UnixMilli(),
+			EndTime:   e.Until.UnixMilli(),
+			MaxLevel:  e.MaxLevel,
+			Reason:    e.Reason,
+		})
+	}
+
+	// 每次冷却期只在首次触发时通知，避免升级冷却时重复打扰
+	if e.Type == ratelimit.CooldownStarted && e.Level == 1 {
+		if err := notify.Send(context.Background(), nil, &notify.Message{
+			Event:    notify.EventRiskControl,
+			HostName: e.Name,
+			Platform: e.Name,
+			Detail:   fmt.Sprintf("%s，暂停访问至 %s，之后逐步恢复请求频率", e.Reason, e.Until.Format(time.DateTime)),
+		}); err != nil {
+			logger.WithError(err).Warn("failed to send risk control notification")
+		}
+	}
+}
@@ -3,17 +3,22 @@ package internal
 import (
 	// import all lives
 	_ "github.com/bililive-go/bililive-go/src/live/acfun"
+	_ "github.com/bililive-go/bililive-go/src/live/apiadapter"
 	_ "github.com/bililive-go/bililive-go/src/live/bilibili"
 	_ "github.com/bililive-go/bililive-go/src/live/cc"
+	_ "github.com/bililive-go/bililive-go/src/live/direct"
 	_ "github.com/bililive-go/bililive-go/src/live/douyin"
 	_ "github.com/bililive-go/bililive-go/src/live/douyu"
+	_ "github.com/bililive-go/bililive-go/src/live/file"
 	_ "github.com/bililive-go/bililive-go/src/live/hongdoufm"
 	_ "github.com/bililive-go/bililive-go/src/live/huajiao"
 	_ "github.com/bililive-go/bililive-go/src/live/huya"
+	_ "github.com/bililive-go/bililive-go/src/live/ingest"
 	_ "github.com/bililive-go/bililive-go/src/live/kuaishou"
 	_ "github.com/bililive-go/bililive-go/src/live/lang"
 	_ "github.com/bililive-go/bililive-go/src/live/missevan"
 	_ "github.com/bililive-go/bililive-go/src/live/openrec"
+	_ "github.com/bililive-go/bililive-go/src/live/plugin"
 	_ "github.com/bililive-go/bililive-go/src/live/qq"
 	_ "github.com/bililive-go/bililive-go/src/live/system"
 	_ "github.com/bililive-go/bililive-go/src/live/twitch"
@@ -22,4 +27,17 @@ import (
 	_ "github.com/bililive-go/bililive-go/src/live/yizhibo"
 	_ "github.com/bililive-go/bililive-go/src/live/yy"
 	_ "github.com/bililive-go/bililive-go/src/live/zhanqi"
+
+	// import all notify channels
+	_ "github.com/bililive-go/bililive-go/src/notify/bark"
+	_ "github.com/bililive-go/bililive-go/src/notify/dingtalk"
+	_ "github.com/bililive-go/bililive-go/src/notify/discord"
+	_ "github.com/bililive-go/bililive-go/src/notify/email"
+	_ "github.com/bililive-go/bililive-go/src/notify/feishu"
+	_ "github.com/bililive-go/bililive-go/src/notify/gotify"
+	_ "github.com/bililive-go/bililive-go/src/notify/ntfy"
+	_ "github.com/bililive-go/bililive-go/src/notify/pushover"
+	_ "github.com/bililive-go/bililive-go/src/notify/serverchan"
+	_ "github.com/bililive-go/bililive-go/src/notify/telegram"
+	_ "github.com/bililive-go/bililive-go/src/notify/wecom"
 )
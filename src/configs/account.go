@@ -0,0 +1,103 @@
+package configs
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Account 命名的平台登录账号
+// 同一平台可以配置多个账号，直播间通过 account 字段选择使用哪个账号，
+// 未指定时使用 cookies 中该平台的 Cookie
+type Account struct {
+	Host         string `yaml:"host" json:"host"`                                       // 账号所属平台的 host，如 live.bilibili.com
+	Cookie       string `yaml:"cookie" json:"cookie"`                                   // 登录 Cookie
+	RefreshToken string `yaml:"refresh_token,omitempty" json:"refresh_token,omitempty"` // 用于刷新 Cookie 的令牌（B站扫码登录时自动获取）
+	UserName     string `yaml:"user_name,omitempty" json:"user_name,omitempty"`         // 账号昵称
+	UID          string `yaml:"uid,omitempty" json:"uid,omitempty"`                     // 账号 UID
+	Note         string `yaml:"note,omitempty" json:"note,omitempty"`                   // 备注
+	Disabled     bool   `yaml:"disabled,omitempty" json:"disabled,omitempty"`           // 是否停用
+}
+
+// AccountsForHost 返回指定 host 下所有启用的账号名称（按名称排序）
+func (c *Config) AccountsForHost(host string) []string {
+	names := make([]string, 0)
+	for name, account := range c.Accounts {
+		if account.Host == host && !account.Disabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetAccountUsage 返回引用了指定账号的直播间 URL 列表
+func (c *Config) GetAccountUsage(name string) []string {
+	rooms := []string{}
+	for _, room := range c.LiveRooms {
+		if room.Account == name {
+			rooms = append(rooms, room.Url)
+		}
+	}
+	return rooms
+}
+
+// IsAccountRotationEnabled 判断指定平台是否允许在账号触发风控时轮换账号
+func (c *Config) IsAccountRotationEnabled(platformKey string) bool {
+	if platformConfig, ok := c.PlatformConfigs[platformKey]; ok {
+		return platformConfig.AccountRotation
+	}
+	return false
+}
+
+// ValidateAccounts 验证账号本身及直播间对账号的引用是否有效
+func (c *Config) ValidateAccounts() error {
+	for name, account := range c.Accounts {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("账号名称不能为空")
+		}
+		if account.Host == "" {
+			return fmt.Errorf("账号 '%s': host 不能为空", name)
+		}
+	}
+	for _, room := range c.LiveRooms {
+		if room.Account == "" {
+			continue
+		}
+		account, ok := c.Accounts[room.Account]
+		if !ok {
+			return fmt.Errorf("直播间 '%s': 引用的账号 '%s' 不存在", room.Url, room.Account)
+		}
+		if u, err := url.Parse(room.Url); err == nil && u.Host != "" && u.Host != account.Host {
+			return fmt.Errorf("直播间 '%s': 账号 '%s' 属于 %s，与直播间平台不一致", room.Url, room.Account, account.Host)
+		}
+	}
+	return nil
+}
+
+// SetAccount 创建或替换一个命名账号。
+func SetAccount(name string, account Account) (*Config, error) {
+	return UpdateWithRetry(func(c *Config) error {
+		if c.Accounts == nil {
+			c.Accounts = make(map[string]Account)
+		}
+		c.Accounts[name] = account
+		return c.ValidateAccounts()
+	}, 3, 10*time.Millisecond)
+}
+
+// SetAccountCookie 更新命名账号的 Cookie 和刷新令牌（用于 Cookie 自动刷新），账号不存在时返回错误。
+func SetAccountCookie(name, cookie, refreshToken string) (*Config, error) {
+	return UpdateWithRetry(func(c *Config) error {
+		account, ok := c.Accounts[name]
+		if !ok {
+			return fmt.Errorf("账号 '%s' 不存在", name)
+		}
+		account.Cookie = cookie
+		account.RefreshToken = refreshToken
+		c.Accounts[name] = account
+		return nil
+	}, 3, 10*time.Millisecond)
+}
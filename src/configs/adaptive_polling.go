@@ -0,0 +1,104 @@
+package configs
+
+import (
+	"fmt"
+	"time"
+)
+
+// AdaptivePolling 自适应轮询配置
+// 根据直播间的历史开播时间学习主播的开播规律，在常开播的时段加快检测，在不常开播的时段放慢检测
+type AdaptivePolling struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// MinIntervalSec 常开播时段的检测间隔（秒，默认 10），不会低于平台的 min_access_interval_sec
+	MinIntervalSec int `yaml:"min_interval_sec" json:"min_interval_sec"`
+	// MaxIntervalSec 不常开播时段的最大检测间隔（秒，默认 300）
+	MaxIntervalSec int `yaml:"max_interval_sec" json:"max_interval_sec"`
+	// WindowMinutes 预测开播时间前后视为开播时段的范围（分钟，默认 30）
+	WindowMinutes int `yaml:"window_minutes" json:"window_minutes"`
+	// MinSessions 开始学习所需的最少历史场次（默认 3），不足时使用配置的 interval
+	MinSessions int `yaml:"min_sessions" json:"min_sessions"`
+	// HistoryDays 参与学习的历史天数（默认 60）
+	HistoryDays int `yaml:"history_days" json:"history_days"`
+}
+
+var defaultAdaptivePolling = AdaptivePolling{
+	Enable:         false,
+	MinIntervalSec: 10,
+	MaxIntervalSec: 300,
+	WindowMinutes:  30,
+	MinSessions:    3,
+	HistoryDays:    60,
+}
+
+// GetWindow 返回开播时段的范围
+func (a AdaptivePolling) GetWindow() time.Duration {
+	if a.WindowMinutes <= 0 {
+		return time.Duration(defaultAdaptivePolling.WindowMinutes) * time.Minute
+	}
+	return time.Duration(a.WindowMinutes) * time.Minute
+}
+
+// GetMinSessions 返回开始学习所需的最少历史场次
+func (a AdaptivePolling) GetMinSessions() int {
+	if a.MinSessions <= 0 {
+		return defaultAdaptivePolling.MinSessions
+	}
+	return a.MinSessions
+}
+
+// GetHistoryDays 返回参与学习的历史天数
+func (a AdaptivePolling) GetHistoryDays() int {
+	if a.HistoryDays <= 0 {
+		return defaultAdaptivePolling.HistoryDays
+	}
+	return a.HistoryDays
+}
+
+// GetAdaptivePollingBounds 返回平台的自适应检测间隔上下限
+// 平台配置的 adaptive_min_interval_sec / adaptive_max_interval_sec 优先，下限不低于平台最小访问间隔
+func (c *Config) GetAdaptivePollingBounds(platformKey string) (min, max time.Duration) {
+	minSec := c.AdaptivePolling.MinIntervalSec
+	if minSec <= 0 {
+		minSec = defaultAdaptivePolling.MinIntervalSec
+	}
+	maxSec := c.AdaptivePolling.MaxIntervalSec
+	if maxSec <= 0 {
+		maxSec = defaultAdaptivePolling.MaxIntervalSec
+	}
+	if platformConfig, ok := c.PlatformConfigs[platformKey]; ok {
+		if platformConfig.AdaptiveMinIntervalSec > 0 {
+			minSec = platformConfig.AdaptiveMinIntervalSec
+		}
+		if platformConfig.AdaptiveMaxIntervalSec > 0 {
+			maxSec = platformConfig.AdaptiveMaxIntervalSec
+		}
+	}
+	if access := c.GetPlatformMinAccessInterval(platformKey); minSec < access {
+		minSec = access
+	}
+	if maxSec < minSec {
+		maxSec = minSec
+	}
+	return time.Duration(minSec) * time.Second, time.Duration(maxSec) * time.Second
+}
+
+// ValidateAdaptivePolling 验证自适应轮询配置
+func (c *Config) ValidateAdaptivePolling() error {
+	a := c.AdaptivePolling
+	if a.MinIntervalSec < 0 || a.MaxIntervalSec < 0 {
+		return fmt.Errorf("自适应轮询的检测间隔不能为负数")
+	}
+	if a.MinIntervalSec > 0 && a.MaxIntervalSec > 0 && a.MaxIntervalSec < a.MinIntervalSec {
+		return fmt.Errorf("自适应轮询的最大检测间隔 (%d) 不能小于最小检测间隔 (%d)", a.MaxIntervalSec, a.MinIntervalSec)
+	}
+	for platformKey, platformConfig := range c.PlatformConfigs {
+		minSec, maxSec := platformConfig.AdaptiveMinIntervalSec, platformConfig.AdaptiveMaxIntervalSec
+		if minSec < 0 || maxSec < 0 {
+			return fmt.Errorf("平台 '%s': 自适应检测间隔不能为负数", platformKey)
+		}
+		if minSec > 0 && maxSec > 0 && maxSec < minSec {
+			return fmt.Errorf("平台 '%s': 自适应最大检测间隔 (%d) 不能小于最小检测间隔 (%d)", platformKey, maxSec, minSec)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package configs
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// ApiAdapterConfig 声明式 JSON API 平台适配器
+// 适用于只需要“请求某个 JSON 接口，从固定路径读取开播状态、标题、主播和流地址”的小平台，
+// 无需编写代码即可支持新站点
+type ApiAdapterConfig struct {
+	Name   string   `yaml:"name" json:"name"`                           // 适配器名称，同时作为平台标识（platform_configs 的键）
+	CNName string   `yaml:"cn_name,omitempty" json:"cn_name,omitempty"` // 平台显示名称，为空时使用 name
+	Hosts  []string `yaml:"hosts" json:"hosts"`                         // 匹配的直播间 host，支持通配符，如 *.example.com
+	// RoomIDPattern 从直播间 URL 中提取房间号的正则，使用名为 room_id 的分组，没有时使用第一个分组
+	RoomIDPattern string `yaml:"room_id_pattern" json:"room_id_pattern"`
+
+	// Info 获取直播间信息的请求
+	Info ApiAdapterRequest `yaml:"info" json:"info"`
+	// Streams 获取流地址的请求，为空时从 Info 的响应中读取流地址
+	Streams *ApiAdapterRequest `yaml:"streams,omitempty" json:"streams,omitempty"`
+
+	InfoFields   ApiAdapterInfoFields   `yaml:"info_fields" json:"info_fields"`
+	StreamFields ApiAdapterStreamFields `yaml:"stream_fields" json:"stream_fields"`
+
+	// StreamHeaders 下载流时附带的请求头，值支持模板
+	StreamHeaders map[string]string `yaml:"stream_headers,omitempty" json:"stream_headers,omitempty"`
+	Disabled      bool              `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+// ApiAdapterRequest 请求模板
+// Url、Headers 的值和 Body 均为 Go 模板，可用字段：RoomID、Url（直播间 URL）、Cookies、Quality
+type ApiAdapterRequest struct {
+	Url         string            `yaml:"url" json:"url"`
+	Method      string            `yaml:"method,omitempty" json:"method,omitempty"` // 默认 GET
+	Headers     map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Body        string            `yaml:"body,omitempty" json:"body,omitempty"`
+	SendCookies bool              `yaml:"send_cookies,omitempty" json:"send_cookies,omitempty"` // 是否附带该直播间使用的 Cookie
+	// OkPath/OkValue 校验响应是否成功，例如 code == 0；OkPath 为空时不校验
+	OkPath  string `yaml:"ok_path,omitempty" json:"ok_path,omitempty"`
+	OkValue string `yaml:"ok_value,omitempty" json:"ok_value,omitempty"`
+}
+
+// ApiAdapterInfoFields 直播间信息在响应中的 gjson 路径
+type ApiAdapterInfoFields struct {
+	Live string `yaml:"live" json:"live"` // 开播状态
+	// LiveValues 表示开播的取值，为空时按布尔值解析（true / 非零数字）
+	LiveValues   []string `yaml:"live_values,omitempty" json:"live_values,omitempty"`
+	RoomName     string   `yaml:"room_name,omitempty" json:"room_name,omitempty"`
+	HostName     string   `yaml:"host_name,omitempty" json:"host_name,omitempty"`
+	Cover        string   `yaml:"cover,omitempty" json:"cover,omitempty"`
+	CustomLiveId string   `yaml:"custom_live_id,omitempty" json:"custom_live_id,omitempty"`
+}
+
+// ApiAdapterStreamFields 流地址在响应中的 gjson 路径
+// List 为空时响应中只有一路流，其余路径相对于响应根；否则遍历 List 指向的数组，其余路径相对于数组元素
+type ApiAdapterStreamFields struct {
+	List      string `yaml:"list,omitempty" json:"list,omitempty"`
+	Url       string `yaml:"url" json:"url"`
+	Quality   string `yaml:"quality,omitempty" json:"quality,omitempty"`
+	Format    string `yaml:"format,omitempty" json:"format,omitempty"`
+	Codec     string `yaml:"codec,omitempty" json:"codec,omitempty"`
+	Width     string `yaml:"width,omitempty" json:"width,omitempty"`
+	Height    string `yaml:"height,omitempty" json:"height,omitempty"`
+	Bitrate   string `yaml:"bitrate,omitempty" json:"bitrate,omitempty"`
+	FrameRate string `yaml:"frame_rate,omitempty" json:"frame_rate,omitempty"`
+}
+
+// GetCNName 返回平台显示名称
+func (a ApiAdapterConfig) GetCNName() string {
+	if a.CNName != "" {
+		return a.CNName
+	}
+	return a.Name
+}
+
+// MatchHost 判断 host 是否匹配适配器声明的任一 host 模式
+func (a ApiAdapterConfig) MatchHost(host string) bool {
+	for _, pattern := range a.Hosts {
+		if MatchHostPattern(pattern, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindApiAdapterForHost 返回第一个匹配 host 且启用的适配器
+func (c *Config) FindApiAdapterForHost(host string) (ApiAdapterConfig, bool) {
+	for _, adapter := range c.ApiAdapters {
+		if !adapter.Disabled && adapter.MatchHost(host) {
+			return adapter, true
+		}
+	}
+	return ApiAdapterConfig{}, false
+}
+
+// GetApiAdapter 按名称查找适配器
+func (c *Config) GetApiAdapter(name string) (ApiAdapterConfig, bool) {
+	for _, adapter := range c.ApiAdapters {
+		if adapter.Name == name {
+			return adapter, true
+		}
+	}
+	return ApiAdapterConfig{}, false
+}
+
+// ValidateApiAdapters 验证声明式适配器配置
+func (c *Config) ValidateApiAdapters() error {
+	names := make(map[string]struct{}, len(c.ApiAdapters))
+	for _, plugin := range c.Plugins {
+		names[plugin.Name] = struct{}{}
+	}
+	for i, adapter := range c.ApiAdapters {
+		if strings.TrimSpace(adapter.Name) == "" {
+			return fmt.Errorf("第 %d 个 API 适配器: 名称不能为空", i+1)
+		}
+		if _, ok := names[adapter.Name]; ok {
+			return fmt.Errorf("API 适配器 '%s': 名称与其他适配器或插件重复", adapter.Name)
+		}
+		names[adapter.Name] = struct{}{}
+		if len(adapter.Hosts) == 0 {
+			return fmt.Errorf("API 适配器 '%s': 至少需要声明一个 host", adapter.Name)
+		}
+		for _, pattern := range adapter.Hosts {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("API 适配器 '%s': 无效的 host 模式 '%s'", adapter.Name, pattern)
+			}
+		}
+		if adapter.RoomIDPattern != "" {
+			re, err := regexp.Compile(adapter.RoomIDPattern)
+			if err != nil {
+				return fmt.Errorf("API 适配器 '%s': 无效的 room_id_pattern: %w", adapter.Name, err)
+			}
+			if re.NumSubexp() == 0 {
+				return fmt.Errorf("API 适配器 '%s': room_id_pattern 需要包含一个分组", adapter.Name)
+			}
+		}
+		requests := []*ApiAdapterRequest{&adapter.Info}
+		if adapter.Streams != nil {
+			requests = append(requests, adapter.Streams)
+		}
+		for _, req := range requests {
+			if req.Url == "" {
+				return fmt.Errorf("API 适配器 '%s': 请求 url 不能为空", adapter.Name)
+			}
+			for _, text := range append([]string{req.Url, req.Body}, mapValues(req.Headers)...) {
+				if _, err := template.New("").Parse(text); err != nil {
+					return fmt.Errorf("API 适配器 '%s': 无效的请求模板: %w", adapter.Name, err)
+				}
+			}
+		}
+		if adapter.InfoFields.Live == "" {
+			return fmt.Errorf("API 适配器 '%s': info_fields.live 不能为空", adapter.Name)
+		}
+		if adapter.StreamFields.Url == "" {
+			return fmt.Errorf("API 适配器 '%s': stream_fields.url 不能为空", adapter.Name)
+		}
+	}
+	return nil
+}
+
+func mapValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
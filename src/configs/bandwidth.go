@@ -0,0 +1,78 @@
+package configs
+
+import (
+	"fmt"
+
+	"github.com/bililive-go/bililive-go/src/pkg/bandwidth"
+)
+
+// Bandwidth 下载与上传带宽限制，单位均为 Mbps，0 表示不限制
+// 限制作用于原生 FLV 下载器、流探测代理（FFmpeg 和录播姬下载 FLV 流时经过）和 OpenList 上传；
+// FFmpeg 直接下载的 HLS 流不受限制，也不计入全局上限，会占用 reserved_mbps 保留的带宽，IO 统计中的 unlimited 列出这些下载
+type Bandwidth struct {
+	// GlobalLimitMbps 全局带宽上限，下载和上传共享
+	GlobalLimitMbps float64 `yaml:"global_limit_mbps" json:"global_limit_mbps"`
+	// ReservedMbps 为接口轮询等控制流量保留的带宽，从全局上限中扣除
+	ReservedMbps float64 `yaml:"reserved_mbps" json:"reserved_mbps"`
+	// RoomLimitMbps 单个直播间的默认下载上限，可通过 bandwidth_limit_mbps 在平台、直播间或配置模板中覆盖
+	RoomLimitMbps float64 `yaml:"room_limit_mbps" json:"room_limit_mbps"`
+	// UploadLimitMbps 上传的总带宽上限
+	UploadLimitMbps float64 `yaml:"upload_limit_mbps" json:"upload_limit_mbps"`
+}
+
+var defaultBandwidth = Bandwidth{}
+
+// mbpsToBytes 将 Mbps 转换为 bytes/s
+func mbpsToBytes(mbps float64) int64 {
+	return int64(mbps * 1000 * 1000 / 8)
+}
+
+// ValidateBandwidth 验证带宽限制
+func (c *Config) ValidateBandwidth() error {
+	b := c.Bandwidth
+	if b.GlobalLimitMbps < 0 || b.ReservedMbps < 0 || b.RoomLimitMbps < 0 || b.UploadLimitMbps < 0 {
+		return fmt.Errorf("带宽限制不能为负数")
+	}
+	if b.ReservedMbps > 0 {
+		if b.GlobalLimitMbps <= 0 {
+			return fmt.Errorf("保留带宽需要同时设置全局带宽上限")
+		}
+		if b.ReservedMbps >= b.GlobalLimitMbps {
+			return fmt.Errorf("保留带宽 (%g Mbps) 必须小于全局带宽上限 (%g Mbps)", b.ReservedMbps, b.GlobalLimitMbps)
+		}
+	}
+	for platformKey, pc := range c.PlatformConfigs {
+		if pc.BandwidthLimitMbps != nil && *pc.BandwidthLimitMbps < 0 {
+			return fmt.Errorf("平台 '%s' 的带宽限制不能为负数", platformKey)
+		}
+	}
+	for _, room := range c.LiveRooms {
+		if room.BandwidthLimitMbps != nil && *room.BandwidthLimitMbps < 0 {
+			return fmt.Errorf("直播间 '%s' 的带宽限制不能为负数", room.Url)
+		}
+	}
+	return nil
+}
+
+// syncBandwidth 同步带宽限制到全局带宽管理器
+// 直播间上限按直播间 ID 下发，尚未获取到 ID 的直播间使用默认上限
+func (c *Config) syncBandwidth() {
+	limits := bandwidth.Limits{
+		Global:      mbpsToBytes(c.Bandwidth.GlobalLimitMbps),
+		Reserved:    mbpsToBytes(c.Bandwidth.ReservedMbps),
+		Upload:      mbpsToBytes(c.Bandwidth.UploadLimitMbps),
+		RoomDefault: mbpsToBytes(c.Bandwidth.RoomLimitMbps),
+		Rooms:       make(map[string]int64),
+	}
+	for i := range c.LiveRooms {
+		room := &c.LiveRooms[i]
+		if room.LiveId == "" {
+			continue
+		}
+		resolved := c.ResolveConfigForRoom(room, GetPlatformKeyFromUrl(room.Url))
+		if resolved.SourceOf("bandwidth_limit_mbps") != ConfigSourceGlobal {
+			limits.Rooms[string(room.LiveId)] = mbpsToBytes(resolved.BandwidthLimitMbps)
+		}
+	}
+	bandwidth.GetManager().SetLimits(limits)
+}
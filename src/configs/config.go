@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"maps"
 	"net"
 	"net/url"
 	"os"
@@ -13,7 +14,6 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/bililive-go/bililive-go/src/pkg/ratelimit"
 	"github.com/bililive-go/bililive-go/src/types"
 	"gopkg.in/yaml.v3"
 )
@@ -24,6 +24,9 @@ type RPC struct {
 	Bind   string `yaml:"bind" json:"bind"`
 	// SSE 配置
 	SSEListThreshold int `yaml:"sse_list_threshold" json:"sse_list_threshold"` // 监控列表超过此阈值时仅为详情页启用SSE
+	// TrustProxyHeaders 是否信任反向代理设置的 X-Forwarded-Proto / X-Forwarded-Host（用于生成订阅源中的链接），
+	// 只应在通过反向代理访问时开启
+	TrustProxyHeaders bool `yaml:"trust_proxy_headers,omitempty" json:"trust_proxy_headers"`
 }
 
 var defaultRPC = RPC{
@@ -60,6 +63,13 @@ type Feature struct {
 	// 当检测到视频编码参数变化（新的 SPS/PPS）时，会主动断开连接触发 FFmpeg 分段
 	// 这可以避免因编码参数变化导致的花屏问题
 	EnableFlvProxySegment bool `yaml:"enable_flv_proxy_segment,omitempty" json:"enable_flv_proxy_segment,omitempty"`
+
+	// DownloaderFallback 下载器连续快速失败后依次切换的备用下载器，按顺序排列，用完后回到 DownloaderType
+	DownloaderFallback []DownloaderType `yaml:"downloader_fallback,omitempty" json:"downloader_fallback,omitempty"`
+	// DownloaderFallbackFailures 连续快速失败多少次后切换到下一个下载器，0 表示使用默认值
+	DownloaderFallbackFailures int `yaml:"downloader_fallback_failures,omitempty" json:"downloader_fallback_failures,omitempty"`
+	// DownloaderFastFailureSec 下载器启动后多少秒内退出视为快速失败（没有写入数据也视为快速失败），0 表示使用默认值
+	DownloaderFastFailureSec int `yaml:"downloader_fast_failure_sec,omitempty" json:"downloader_fast_failure_sec,omitempty"`
 }
 
 // GetEffectiveDownloaderType 获取实际生效的下载器类型
@@ -101,6 +111,21 @@ type CloudUpload struct {
 	AdditionalStorages []string `yaml:"additional_storages,omitempty" json:"additional_storages,omitempty"` // 额外存储（支持多目标上传）
 }
 
+// MediaLibrary 媒体服务器（Jellyfin/Emby/Plex）资料库导出
+type MediaLibrary struct {
+	Enable   bool   `yaml:"enable" json:"enable"`       // 是否为录制文件生成 nfo 和海报
+	Path     string `yaml:"path" json:"path"`           // 资料库根目录（开启时必填），按 节目/Season 年份/剧集 组织，不在录制输出目录中写入 nfo 和图片
+	LinkMode string `yaml:"link_mode" json:"link_mode"` // 放入资料库的方式：symlink（默认）/ hardlink / copy / move，只有 move 会改动原输出目录
+}
+
+// 资料库文件放置方式
+const (
+	LinkModeSymlink  = "symlink"
+	LinkModeHardlink = "hardlink"
+	LinkModeCopy     = "copy"
+	LinkModeMove     = "move"
+)
+
 // On record finished actions.
 type OnRecordFinished struct {
 	ConvertToMp4          bool         `yaml:"convert_to_mp4" json:"convert_to_mp4"`
@@ -110,8 +135,20 @@ type OnRecordFinished struct {
 	SaveCover             bool         `yaml:"save_cover" json:"save_cover"`       // 保存视频第一帧作为封面图（.jpg）
 	CloudUpload           CloudUpload  `yaml:"cloud_upload" json:"cloud_upload"`   // 云上传配置
 	UploadTiming          UploadTiming `yaml:"upload_timing" json:"upload_timing"` // 上传时机
+	// AudioFormat 音频录制（.aac）转换的格式，可选 m4a / mp3，为空时不转换
+	AudioFormat string `yaml:"audio_format" json:"audio_format"`
+	// GenerateStoryboard 生成故事板雪碧图、WebVTT 缩略图轨道和音频波形，用于网页播放器拖动预览
+	GenerateStoryboard bool `yaml:"generate_storyboard" json:"generate_storyboard"`
+	// MediaLibrary 为 Jellyfin/Emby/Plex 生成 nfo、海报并整理资料库目录
+	MediaLibrary MediaLibrary `yaml:"media_library" json:"media_library"`
 }
 
+// 音频转换格式
+const (
+	AudioFormatM4a = "m4a"
+	AudioFormatMp3 = "mp3"
+)
+
 type Log struct {
 	OutPutFolder string `yaml:"out_put_folder" json:"out_put_folder"`
 	SaveLastLog  bool   `yaml:"save_last_log" json:"save_last_log"`
@@ -122,9 +159,22 @@ type Log struct {
 
 // 通知服务所需配置
 type Notify struct {
-	Telegram Telegram `yaml:"telegram" json:"telegram"`
-	Email    Email    `yaml:"email" json:"email"`
-	Ntfy     Ntfy     `yaml:"ntfy" json:"ntfy"`
+	Telegram   Telegram   `yaml:"telegram" json:"telegram"`
+	Email      Email      `yaml:"email" json:"email"`
+	Ntfy       Ntfy       `yaml:"ntfy" json:"ntfy"`
+	Bark       Bark       `yaml:"bark" json:"bark"`
+	ServerChan ServerChan `yaml:"serverchan" json:"serverchan"`
+	DingTalk   DingTalk   `yaml:"dingtalk" json:"dingtalk"`
+	Feishu     Feishu     `yaml:"feishu" json:"feishu"`
+	WeCom      WeCom      `yaml:"wecom" json:"wecom"`
+	Discord    Discord    `yaml:"discord" json:"discord"`
+	Gotify     Gotify     `yaml:"gotify" json:"gotify"`
+	Pushover   Pushover   `yaml:"pushover" json:"pushover"`
+
+	// Rules 全局通知规则，可被平台、标签、配置模板、直播间级别的 notify_rules 覆盖
+	Rules NotifyRules `yaml:"rules,omitempty" json:"rules"`
+	// TagRules 按直播间标签配置的通知规则
+	TagRules map[string]NotifyRules `yaml:"tag_rules,omitempty" json:"tag_rules,omitempty"`
 }
 
 type Telegram struct {
@@ -213,6 +263,33 @@ var defaultUpdateConfig = UpdateConfig{
 	IncludePrerelease:  false,
 }
 
+// CookieMonitor Cookie 健康检查配置
+type CookieMonitor struct {
+	// Enable 是否定期检查已配置 Cookie 的登录状态（默认 true）
+	Enable bool `yaml:"enable" json:"enable"`
+	// CheckIntervalMinutes 检查间隔（分钟，默认 120）
+	CheckIntervalMinutes int `yaml:"check_interval_minutes" json:"check_interval_minutes"`
+	// AutoRefresh 平台支持时是否自动刷新即将过期的 Cookie（默认 true，目前仅 B站）
+	AutoRefresh bool `yaml:"auto_refresh" json:"auto_refresh"`
+	// RefreshTokens 各 host 用于刷新 Cookie 的令牌，刷新成功后自动更新
+	// B站为登录后浏览器 localStorage 中的 ac_time_value
+	RefreshTokens map[string]string `yaml:"refresh_tokens,omitempty" json:"refresh_tokens,omitempty"`
+}
+
+var defaultCookieMonitor = CookieMonitor{
+	Enable:               true,
+	CheckIntervalMinutes: 120,
+	AutoRefresh:          true,
+}
+
+// GetCheckInterval 返回检查间隔，最小 1 分钟
+func (c CookieMonitor) GetCheckInterval() time.Duration {
+	if c.CheckIntervalMinutes < 1 {
+		return time.Minute
+	}
+	return time.Duration(c.CheckIntervalMinutes) * time.Minute
+}
+
 // StreamPreference 流偏好配置
 // 采用指针模式以区分"未设置"和"设置为零值"
 type StreamPreference struct {
@@ -237,13 +314,26 @@ type OverridableConfig struct {
 	OnRecordFinished     *OnRecordFinished     `yaml:"on_record_finished,omitempty" json:"on_record_finished,omitempty"`         // 录制完成后的动作
 	TimeoutInUs          *int                  `yaml:"timeout_in_us,omitempty" json:"timeout_in_us,omitempty"`                   // 超时设置(微秒)
 	StreamPreference     *StreamPreference     `yaml:"stream_preference,omitempty" json:"stream_preference,omitempty"`           // 流偏好配置
+	NotifyRules          *NotifyRules          `yaml:"notify_rules,omitempty" json:"notify_rules,omitempty"`                     // 通知规则
+	RecordingPriority    *int                  `yaml:"recording_priority,omitempty" json:"recording_priority,omitempty"`         // 录制优先级，达到同时录制上限时使用
+	BandwidthLimitMbps   *float64              `yaml:"bandwidth_limit_mbps,omitempty" json:"bandwidth_limit_mbps,omitempty"`     // 直播间下载带宽上限(Mbps)，0 表示不限制
+	RateLimitWeight      *int                  `yaml:"rate_limit_weight,omitempty" json:"rate_limit_weight,omitempty"`           // 访问频率限制的排队权重，权重越大分到的请求份额越多，默认 1
 }
 
 // PlatformConfig 包含平台特定的设置
 type PlatformConfig struct {
 	OverridableConfig    `yaml:",inline" json:",inline"`
 	Name                 string `yaml:"name" json:"name"`                                                           // 平台中文名称
-	MinAccessIntervalSec int    `yaml:"min_access_interval_sec,omitempty" json:"min_access_interval_sec,omitempty"` // 平台访问最小间隔(秒)，用于防风控
+	MinAccessIntervalSec int    `yaml:"min_access_interval_sec,omitempty" json:"min_access_interval_sec,omitempty"` // 已废弃，加载配置时迁移到 rate_limits.info
+	// RateLimits 按接口类别（info / stream / cookie）覆盖全局的令牌桶配置，用于防风控
+	RateLimits map[string]RateLimitBucket `yaml:"rate_limits,omitempty" json:"rate_limits,omitempty"`
+	// Profiles 引用的配置模板名称，按顺序应用，优先级低于平台自身的覆盖配置
+	Profiles []string `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+	// AccountRotation 账号触发风控时是否自动轮换到该平台的其他账号
+	AccountRotation bool `yaml:"account_rotation,omitempty" json:"account_rotation,omitempty"`
+	// AdaptiveMinIntervalSec / AdaptiveMaxIntervalSec 覆盖全局自适应轮询的检测间隔上下限(秒)
+	AdaptiveMinIntervalSec int `yaml:"adaptive_min_interval_sec,omitempty" json:"adaptive_min_interval_sec,omitempty"`
+	AdaptiveMaxIntervalSec int `yaml:"adaptive_max_interval_sec,omitempty" json:"adaptive_max_interval_sec,omitempty"`
 }
 
 type Ntfy struct {
@@ -253,6 +343,68 @@ type Ntfy struct {
 	Tag    string `yaml:"tag"`
 }
 
+// Bark iOS 推送
+type Bark struct {
+	Enable    bool   `yaml:"enable" json:"enable"`
+	ServerURL string `yaml:"server_url" json:"server_url"` // Bark 服务地址，默认 https://api.day.app
+	DeviceKey string `yaml:"device_key" json:"device_key"`
+	Group     string `yaml:"group,omitempty" json:"group,omitempty"`
+	Sound     string `yaml:"sound,omitempty" json:"sound,omitempty"`
+	Level     string `yaml:"level,omitempty" json:"level,omitempty"` // active / timeSensitive / passive
+}
+
+// ServerChan Server酱推送
+type ServerChan struct {
+	Enable  bool   `yaml:"enable" json:"enable"`
+	SendKey string `yaml:"send_key" json:"send_key"`
+	APIURL  string `yaml:"api_url,omitempty" json:"api_url,omitempty"` // 自定义接口地址，留空时根据 SendKey 自动推断
+}
+
+// DingTalk 钉钉群机器人
+type DingTalk struct {
+	Enable  bool   `yaml:"enable" json:"enable"`
+	Webhook string `yaml:"webhook" json:"webhook"`
+	Secret  string `yaml:"secret,omitempty" json:"secret,omitempty"` // 加签密钥（可选）
+}
+
+// Feishu 飞书/Lark 群机器人
+type Feishu struct {
+	Enable  bool   `yaml:"enable" json:"enable"`
+	Webhook string `yaml:"webhook" json:"webhook"`
+	Secret  string `yaml:"secret,omitempty" json:"secret,omitempty"` // 签名校验密钥（可选）
+}
+
+// WeCom 企业微信群机器人
+type WeCom struct {
+	Enable  bool   `yaml:"enable" json:"enable"`
+	Webhook string `yaml:"webhook" json:"webhook"`
+}
+
+// Discord Webhook
+type Discord struct {
+	Enable   bool   `yaml:"enable" json:"enable"`
+	Webhook  string `yaml:"webhook" json:"webhook"`
+	Username string `yaml:"username,omitempty" json:"username,omitempty"`
+}
+
+// Gotify 自建推送服务
+type Gotify struct {
+	Enable    bool   `yaml:"enable" json:"enable"`
+	ServerURL string `yaml:"server_url" json:"server_url"`
+	Token     string `yaml:"token" json:"token"` // 应用 Token
+	Priority  int    `yaml:"priority,omitempty" json:"priority,omitempty"`
+}
+
+// Pushover 推送
+type Pushover struct {
+	Enable   bool   `yaml:"enable" json:"enable"`
+	Token    string `yaml:"token" json:"token"`       // 应用 API Token
+	UserKey  string `yaml:"user_key" json:"user_key"` // 用户或群组 Key
+	Device   string `yaml:"device,omitempty" json:"device,omitempty"`
+	Priority int    `yaml:"priority,omitempty" json:"priority,omitempty"`
+	APIURL   string `yaml:"api_url,omitempty" json:"api_url,omitempty"` // 自定义接口地址，默认官方接口
+}
+
 // Config content all config info.
 type Config struct {
 	// 核心配置
@@ -281,6 +433,9 @@ type Config struct {
 	// Cookies 配置
 	Cookies map[string]string `yaml:"cookies" json:"cookies"`
 
+	// 命名账号，可被直播间通过 account 字段引用
+	Accounts map[string]Account `yaml:"accounts,omitempty" json:"accounts,omitempty"`
+
 	// 通知服务配置
 	Notify Notify `yaml:"notify" json:"notify"`
 
@@ -301,9 +456,41 @@ type Config struct {
 	// 自动更新配置
 	Update UpdateConfig `yaml:"update" json:"update"`
 
+	// Cookie 健康检查配置
+	CookieMonitor CookieMonitor `yaml:"cookie_monitor" json:"cookie_monitor"`
+
+	// 根据历史开播时间自适应调整检测间隔
+	AdaptivePolling AdaptivePolling `yaml:"adaptive_polling" json:"adaptive_polling"`
+
+	// 通过平台推送通道实时检测开播
+	PushDetection PushDetection `yaml:"push_detection" json:"push_detection"`
+
+	// 下播后的宽限期，宽限期内恢复的直播合并为同一个会话
+	SessionGrace SessionGrace `yaml:"session_grace" json:"session_grace"`
+
+	// 同时录制数量限制
+	RecordingLimit RecordingLimit `yaml:"recording_limit" json:"recording_limit"`
+
+	// 下载与上传带宽限制
+	Bandwidth Bandwidth `yaml:"bandwidth" json:"bandwidth"`
+	// RateLimits 各接口类别访问平台的令牌桶配置，可在 platform_configs 中按平台覆盖，没有配置的接口类别不限制访问频率
+	RateLimits map[string]RateLimitBucket `yaml:"rate_limits" json:"rate_limits"`
+
 	// 平台特定配置（层级覆盖，使用 OverridableConfig 中的指针模式）
 	PlatformConfigs map[string]PlatformConfig `yaml:"platform_configs,omitempty" json:"platform_configs,omitempty"`
 
+	// 命名配置模板，可被平台和直播间通过 profiles 字段引用
+	Profiles map[string]ConfigProfile `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+
+	// 外部解析插件，用于支持未内置的直播平台
+	Plugins []PluginConfig `yaml:"plugins,omitempty" json:"plugins,omitempty"`
+
+	// 声明式 JSON API 平台适配器
+	ApiAdapters []ApiAdapterConfig `yaml:"api_adapters,omitempty" json:"api_adapters,omitempty"`
+
+	// 内置 RTMP 推流服务器
+	Ingest Ingest `yaml:"ingest" json:"ingest"`
+
 	// 内部缓存
 	liveRoomIndexCache map[string]int `json:"-"`
 }
@@ -331,6 +518,8 @@ func SetCurrentConfig(cfg *Config) {
 	currentDebug.Store(cfg.Debug)
 	// 配置更新时同步平台访问频率限制器
 	cfg.syncPlatformRateLimits()
+	cfg.syncPushDetection()
+	cfg.syncBandwidth()
 }
 
 func GetCurrentConfig() *Config {
@@ -493,6 +682,25 @@ func SetCookie(host, cookie string) (*Config, error) {
 	}, 3, 10*time.Millisecond)
 }
 
+// SetCookieWithRefreshToken 同时设置某个 host 的 Cookie 和刷新令牌（用于 Cookie 自动刷新）。
+func SetCookieWithRefreshToken(host, cookie, refreshToken string) (*Config, error) {
+	return UpdateWithRetry(func(c *Config) error {
+		if c.Cookies == nil {
+			c.Cookies = make(map[string]string)
+		}
+		c.Cookies[host] = cookie
+		if c.CookieMonitor.RefreshTokens == nil {
+			c.CookieMonitor.RefreshTokens = make(map[string]string)
+		}
+		if refreshToken == "" {
+			delete(c.CookieMonitor.RefreshTokens, host)
+		} else {
+			c.CookieMonitor.RefreshTokens[host] = refreshToken
+		}
+		return nil
+	}, 3, 10*time.Millisecond)
+}
+
 // AppendLiveRoom 追加一个 LiveRoom。
 func AppendLiveRoom(room LiveRoom) (*Config, error) {
 	return UpdateWithRetry(func(c *Config) error {
@@ -548,6 +756,15 @@ type LiveRoom struct {
 	NickName    string       `yaml:"nick_name,omitempty" json:"nick_name,omitempty"`
 	SchemeUrl   string       `yaml:"scheme" json:"scheme,omitempty"`
 
+	// Profiles 引用的配置模板名称，按顺序应用，优先级低于房间自身的覆盖配置
+	Profiles []string `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+	// Tags 直播间标签，用于匹配 notify.tag_rules 中的通知规则
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	// Account 使用的账号名称，为空时使用 cookies 中该平台的 Cookie
+	Account string `yaml:"account,omitempty" json:"account,omitempty"`
+	// Restream 转推目标，录制 FLV / RTMP 流时同时推送到这些 RTMP 服务器
+	Restream []RestreamTarget `yaml:"restream,omitempty" json:"restream,omitempty"`
+
 	// 房间级可覆盖配置
 	OverridableConfig `yaml:",inline" json:",inline"` // 房间级配置覆盖
 }
@@ -641,6 +858,10 @@ var defaultConfig = Config{
 			Token:  "",
 			Tag:    "",
 		},
+		Bark: Bark{
+			Enable:    false,
+			ServerURL: "https://api.day.app",
+		},
 	},
 	AppDataPath:        "",
 	ReadOnlyToolFolder: "",
@@ -650,6 +871,13 @@ var defaultConfig = Config{
 	Proxy:           defaultProxy,
 	OpenList:        defaultOpenListConfig,
 	Update:          defaultUpdateConfig,
+	CookieMonitor:   defaultCookieMonitor,
+	AdaptivePolling: defaultAdaptivePolling,
+	PushDetection:   defaultPushDetection,
+	SessionGrace:    defaultSessionGrace,
+	RecordingLimit:  defaultRecordingLimit,
+	Bandwidth:       defaultBandwidth,
+	Ingest:          defaultIngest,
 	PlatformConfigs: map[string]PlatformConfig{},
 }
 
@@ -657,6 +885,9 @@ func NewConfig() *Config {
 	config := defaultConfig
 	config.liveRoomIndexCache = map[string]int{}
 	config.PlatformConfigs = map[string]PlatformConfig{}
+	config.Profiles = map[string]ConfigProfile{}
+	config.Accounts = map[string]Account{}
+	config.RateLimits = map[string]RateLimitBucket{}
 	newConfigPostProcess(&config)
 	return &config
 }
@@ -691,12 +922,91 @@ func (c *Config) Verify() error {
 	if !c.RPC.Enable && len(c.LiveRooms) == 0 {
 		return fmt.Errorf("RPC 服务已禁用且未配置直播间，程序无任务可执行")
 	}
+	switch c.OnRecordFinished.AudioFormat {
+	case "", AudioFormatM4a, AudioFormatMp3:
+	default:
+		return fmt.Errorf("不支持的音频转换格式 '%s'，可选 m4a 或 mp3", c.OnRecordFinished.AudioFormat)
+	}
+	switch c.OnRecordFinished.MediaLibrary.LinkMode {
+	case "", LinkModeSymlink, LinkModeHardlink, LinkModeCopy, LinkModeMove:
+	default:
+		return fmt.Errorf("不支持的资料库放置方式 '%s'，可选 symlink、hardlink、copy 或 move", c.OnRecordFinished.MediaLibrary.LinkMode)
+	}
+	if c.OnRecordFinished.MediaLibrary.Enable && strings.TrimSpace(c.OnRecordFinished.MediaLibrary.Path) == "" {
+		return fmt.Errorf("开启媒体资料库导出时必须设置资料库目录 path")
+	}
 
 	// 验证平台配置
 	if err := c.ValidatePlatformConfigs(); err != nil {
 		return err
 	}
 
+	// 验证配置模板
+	if err := c.ValidateProfiles(); err != nil {
+		return err
+	}
+
+	// 验证通知规则
+	if err := c.ValidateNotifyRules(); err != nil {
+		return err
+	}
+
+	// 验证账号
+	if err := c.ValidateAccounts(); err != nil {
+		return err
+	}
+
+	// 验证外部解析插件
+	if err := c.ValidatePlugins(); err != nil {
+		return err
+	}
+
+	// 验证声明式 API 适配器
+	if err := c.ValidateApiAdapters(); err != nil {
+		return err
+	}
+
+	// 验证自适应轮询
+	if err := c.ValidateAdaptivePolling(); err != nil {
+		return err
+	}
+
+	// 验证推送开播检测
+	if err := c.ValidatePushDetection(); err != nil {
+		return err
+	}
+
+	// 验证直播会话宽限期
+	if err := c.ValidateSessionGrace(); err != nil {
+		return err
+	}
+
+	// 验证同时录制数量限制
+	if err := c.ValidateRecordingLimit(); err != nil {
+		return err
+	}
+
+	// 验证带宽限制
+	if err := c.ValidateBandwidth(); err != nil {
+		return err
+	}
+
+	// 验证下载器回退配置
+	if err := c.ValidateDownloaderFallback(); err != nil {
+		return err
+	}
+	if err := c.ValidateRateLimits(); err != nil {
+		return err
+	}
+
+	// 验证推流服务器
+	if err := c.ValidateIngest(); err != nil {
+		return err
+	}
+	if err := c.ValidateRestream(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -749,7 +1059,14 @@ func NewConfigWithBytes(b []byte) (*Config, error) {
 	if config.PlatformConfigs == nil {
 		config.PlatformConfigs = map[string]PlatformConfig{}
 	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]ConfigProfile{}
+	}
+	if config.Accounts == nil {
+		config.Accounts = map[string]Account{}
+	}
 
+	config.migrateRateLimits()
 	config.RefreshLiveRoomIndexCache()
 	newConfigPostProcess(&config)
 	// 在配置加载时同步平台访问频率限制器
@@ -833,6 +1150,22 @@ func CloneConfigShallow(src *Config) *Config {
 		cp.LiveRooms = make([]LiveRoom, len(src.LiveRooms))
 		copy(cp.LiveRooms, src.LiveRooms)
 	}
+	if src.Plugins != nil {
+		cp.Plugins = make([]PluginConfig, len(src.Plugins))
+		copy(cp.Plugins, src.Plugins)
+	}
+	if src.ApiAdapters != nil {
+		cp.ApiAdapters = make([]ApiAdapterConfig, len(src.ApiAdapters))
+		copy(cp.ApiAdapters, src.ApiAdapters)
+	}
+	if src.Ingest.Keys != nil {
+		cp.Ingest.Keys = make([]IngestKey, len(src.Ingest.Keys))
+		copy(cp.Ingest.Keys, src.Ingest.Keys)
+	}
+	if src.Feature.DownloaderFallback != nil {
+		cp.Feature.DownloaderFallback = make([]DownloaderType, len(src.Feature.DownloaderFallback))
+		copy(cp.Feature.DownloaderFallback, src.Feature.DownloaderFallback)
+	}
 	// map 拷贝
 	if src.Cookies != nil {
 		cp.Cookies = make(map[string]string, len(src.Cookies))
@@ -847,6 +1180,38 @@ func CloneConfigShallow(src *Config) *Config {
 			cp.PlatformConfigs[k] = v
 		}
 	}
+	// RateLimits 拷贝
+	if src.RateLimits != nil {
+		cp.RateLimits = maps.Clone(src.RateLimits)
+	}
+	// Profiles 拷贝
+	if src.Profiles != nil {
+		cp.Profiles = make(map[string]ConfigProfile, len(src.Profiles))
+		for k, v := range src.Profiles {
+			cp.Profiles[k] = v
+		}
+	}
+	// Accounts 拷贝
+	if src.Accounts != nil {
+		cp.Accounts = make(map[string]Account, len(src.Accounts))
+		for k, v := range src.Accounts {
+			cp.Accounts[k] = v
+		}
+	}
+	// RefreshTokens 拷贝
+	if src.CookieMonitor.RefreshTokens != nil {
+		cp.CookieMonitor.RefreshTokens = make(map[string]string, len(src.CookieMonitor.RefreshTokens))
+		for k, v := range src.CookieMonitor.RefreshTokens {
+			cp.CookieMonitor.RefreshTokens[k] = v
+		}
+	}
+	// TagRules 拷贝
+	if src.Notify.TagRules != nil {
+		cp.Notify.TagRules = make(map[string]NotifyRules, len(src.Notify.TagRules))
+		for k, v := range src.Notify.TagRules {
+			cp.Notify.TagRules[k] = v
+		}
+	}
 	// liveRoomIndexCache 拷贝，避免刷新索引时影响旧快照
 	if src.liveRoomIndexCache != nil {
 		cp.liveRoomIndexCache = make(map[string]int, len(src.liveRoomIndexCache))
@@ -860,7 +1225,9 @@ func CloneConfigShallow(src *Config) *Config {
 }
 
 // ResolveConfigForRoom 为指定房间解析最终的配置值
-// 通过合并 全局 -> 平台 -> 房间 级别的配置
+// 通过合并 全局 -> 平台模板 -> 平台 -> 房间模板 -> 房间 级别的配置
+// 通知规则还会在平台之后、房间模板之前按房间标签合并 notify.tag_rules
+// 每个值的来源记录在 ResolvedConfig.Sources 中
 func (c *Config) ResolveConfigForRoom(room *LiveRoom, platformName string) ResolvedConfig {
 	resolved := ResolvedConfig{
 		Interval:             c.Interval,
@@ -872,53 +1239,28 @@ func (c *Config) ResolveConfigForRoom(room *LiveRoom, platformName string) Resol
 		VideoSplitStrategies: c.VideoSplitStrategies,
 		OnRecordFinished:     c.OnRecordFinished,
 		TimeoutInUs:          c.TimeoutInUs,
+		NotifyRules:          *MergeNotifyRules(nil, &c.Notify.Rules),
+		BandwidthLimitMbps:   c.Bandwidth.RoomLimitMbps,
+		RateLimitWeight:      1,
+		Sources:              map[string]string{},
 	}
 
 	// 应用平台级覆盖
 	if platformConfig, exists := c.PlatformConfigs[platformName]; exists {
-		resolved.applyOverrides(&platformConfig.OverridableConfig)
+		resolved.applyProfiles(c.Profiles, platformConfig.Profiles)
+		resolved.applyOverridesFrom(&platformConfig.OverridableConfig, ConfigSourcePlatform)
 	}
 
+	// 应用标签级通知规则
+	resolved.applyTagNotifyRules(c.Notify.TagRules, room.Tags)
+
 	// 应用房间级覆盖
-	resolved.applyOverrides(&room.OverridableConfig)
+	resolved.applyProfiles(c.Profiles, room.Profiles)
+	resolved.applyOverridesFrom(&room.OverridableConfig, ConfigSourceRoom)
 
 	return resolved
 }
 
-// GetPlatformMinAccessInterval 返回指定平台的最小访问间隔
-// 强制最小值为 1 秒，不允许无限制高频访问
-func (c *Config) GetPlatformMinAccessInterval(platformName string) int {
-	minInterval := 1 // 默认最小间隔为 1 秒
-	if platformConfig, exists := c.PlatformConfigs[platformName]; exists {
-		if platformConfig.MinAccessIntervalSec >= 1 {
-			return platformConfig.MinAccessIntervalSec
-		}
-	}
-	return minInterval
-}
-
-// syncPlatformRateLimits 同步平台访问频率限制到全局限制器
-func (c *Config) syncPlatformRateLimits() {
-	rateLimiter := ratelimit.GetGlobalRateLimiter()
-
-	// 清除已有限制
-	currentLimits := rateLimiter.GetAllPlatformLimits()
-
-	// 设置新的平台限制
-	for platformKey, platformConfig := range c.PlatformConfigs {
-		if platformConfig.MinAccessIntervalSec > 0 {
-			rateLimiter.SetPlatformLimit(platformKey, platformConfig.MinAccessIntervalSec)
-		}
-		// 从当前限制列表中移除此平台（标记为已处理）
-		delete(currentLimits, platformKey)
-	}
-
-	// 清除配置中不再存在的平台限制
-	for platformKey := range currentLimits {
-		rateLimiter.RemovePlatformLimit(platformKey)
-	}
-}
-
 // ResolvedConfig 包含房间的最终解析配置值
 type ResolvedConfig struct {
 	Interval             int                  `json:"interval"`
@@ -931,39 +1273,84 @@ type ResolvedConfig struct {
 	OnRecordFinished     OnRecordFinished     `json:"on_record_finished"`
 	TimeoutInUs          int                  `json:"timeout_in_us"`
 	StreamPreference     StreamPreference     `json:"stream_preference"`
+	NotifyRules          NotifyRules          `json:"notify_rules"`
+	RecordingPriority    int                  `json:"recording_priority"`
+	BandwidthLimitMbps   float64              `json:"bandwidth_limit_mbps"`
+	RateLimitWeight      int                  `json:"rate_limit_weight"`
+
+	// Sources 记录各配置项的来源（global / platform / room / profile:<模板名> / tag:<标签名>）
+	// 未出现在此处的配置项来自全局配置
+	Sources map[string]string `json:"sources"`
+}
+
+// SourceOf 返回指定配置项（yaml 键名）的来源
+func (r *ResolvedConfig) SourceOf(key string) string {
+	if source, ok := r.Sources[key]; ok {
+		return source
+	}
+	return ConfigSourceGlobal
 }
 
-// applyOverrides 将可覆盖配置中的非空值应用到解析配置中
-func (r *ResolvedConfig) applyOverrides(override *OverridableConfig) {
+// applyOverridesFrom 将可覆盖配置中的非空值应用到解析配置中，并记录来源
+func (r *ResolvedConfig) applyOverridesFrom(override *OverridableConfig, source string) {
+	if r.Sources == nil {
+		r.Sources = map[string]string{}
+	}
 	if override.Interval != nil {
 		r.Interval = *override.Interval
+		r.Sources["interval"] = source
 	}
 	if override.OutPutPath != nil {
 		r.OutPutPath = *override.OutPutPath
+		r.Sources["out_put_path"] = source
 	}
 	if override.FfmpegPath != nil {
 		r.FfmpegPath = *override.FfmpegPath
+		r.Sources["ffmpeg_path"] = source
 	}
 	if override.Log != nil {
 		r.Log = *override.Log
+		r.Sources["log"] = source
 	}
 	if override.Feature != nil {
 		r.Feature = *override.Feature
+		r.Sources["feature"] = source
 	}
 	if override.OutputTmpl != nil {
 		r.OutputTmpl = *override.OutputTmpl
+		r.Sources["out_put_tmpl"] = source
 	}
 	if override.VideoSplitStrategies != nil {
 		r.VideoSplitStrategies = *override.VideoSplitStrategies
+		r.Sources["video_split_strategies"] = source
 	}
 	if override.OnRecordFinished != nil {
 		r.OnRecordFinished = *override.OnRecordFinished
+		r.Sources["on_record_finished"] = source
 	}
 	if override.TimeoutInUs != nil {
 		r.TimeoutInUs = *override.TimeoutInUs
+		r.Sources["timeout_in_us"] = source
 	}
 	if override.StreamPreference != nil {
 		r.StreamPreference = *MergeStreamPreference(&r.StreamPreference, override.StreamPreference)
+		r.Sources["stream_preference"] = source
+	}
+	if override.NotifyRules != nil {
+		r.NotifyRules = *MergeNotifyRules(&r.NotifyRules, override.NotifyRules)
+		r.Sources["notify_rules"] = source
+	}
+	if override.RecordingPriority != nil {
+		r.RecordingPriority = *override.RecordingPriority
+		r.Sources["recording_priority"] = source
+	}
+	if override.BandwidthLimitMbps != nil {
+		r.BandwidthLimitMbps = *override.BandwidthLimitMbps
+		r.Sources["bandwidth_limit_mbps"] = source
+	}
+	if override.RateLimitWeight != nil {
+		r.RateLimitWeight = *override.RateLimitWeight
+		r.Sources["rate_limit_weight"] = source
 	}
 }
 
@@ -1005,10 +1392,49 @@ func GetPlatformKeyFromUrl(urlStr string) string {
 		return platform
 	}
 
+	// 外部解析插件和声明式 API 适配器的直播间使用其名称作为平台标识
+	if cfg := GetCurrentConfig(); cfg != nil {
+		if plugin, ok := cfg.FindPluginForHost(u.Host); ok {
+			return plugin.Name
+		}
+		if adapter, ok := cfg.FindApiAdapterForHost(u.Host); ok {
+			return adapter.Name
+		}
+	}
+
+	// 伪平台：本地文件、内置推流服务器和直接录制的流地址
+	if u.Scheme == "file" {
+		return PlatformKeyFile
+	}
+	if u.Scheme == PlatformKeyIngest {
+		return PlatformKeyIngest
+	}
+	if IsDirectStreamUrl(u) {
+		return PlatformKeyDirect
+	}
+
 	// 备用方案：使用主机名
 	return u.Host
 }
 
+// 伪平台标识
+const (
+	PlatformKeyDirect = "direct" // 直接录制 rtmp / .flv / .m3u8 流地址
+	PlatformKeyFile   = "file"   // 以本地媒体文件模拟直播间
+)
+
+// IsDirectStreamUrl 判断 URL 是否为可直接录制的流地址（rtmp 地址或 .flv / .m3u8 链接）
+func IsDirectStreamUrl(u *url.URL) bool {
+	switch strings.ToLower(u.Scheme) {
+	case "rtmp", "rtmps":
+		return true
+	case "http", "https":
+		path := strings.ToLower(u.Path)
+		return strings.HasSuffix(path, ".flv") || strings.HasSuffix(path, ".m3u8")
+	}
+	return false
+}
+
 // GetEffectiveConfigForRoom 返回房间的有效配置
 func (c *Config) GetEffectiveConfigForRoom(roomUrl string) ResolvedConfig {
 	platformKey := GetPlatformKeyFromUrl(roomUrl)
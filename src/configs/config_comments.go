@@ -40,6 +40,16 @@ func DecorateConfigNode(node *yaml.Node) {
 #  来判断是否需要删除原始 flv 文件。
 #  以下是一个在录制结束后将 flv 视频转换为同名 mp4 视频的示例：
 #  custom_commandline: '{{ .Ffmpeg }} -hide_banner -i "{{ .FileName }}" -c copy "{{ .FileName | trimSuffix (.FileName | ext)}}.mp4"'`, "")
+		setFieldComment(finishNode, "audio_format",
+			`#  音频直播间（猫耳FM、红豆FM 或 audio_only）录制的 .aac 文件转换格式：m4a 或 mp3，为空时不转换
+#  转换时会写入主播名、标题、日期和直播间封面等标签`, "")
+		setFieldComment(finishNode, "generate_storyboard",
+			`#  录制完成后生成故事板：按间隔截图拼接的雪碧图、WebVTT 缩略图轨道和音频波形
+#  保存在录制文件同目录的 .storyboard 文件夹中，网页播放器拖动进度条时显示预览`, "")
+		setFieldComment(finishNode, "media_library",
+			`#  媒体服务器（Jellyfin/Emby/Plex）资料库导出：为录制文件写入 .nfo、海报（poster）和背景图（fanart）
+#  path 为资料库目录（开启时必填），按 主播/Season 年份/剧集 组织，nfo 和图片只写入该目录，不会写入录制输出目录
+#  link_mode 为放入资料库的方式：symlink（默认）、hardlink、copy 或 move，除 move 外不会改动原输出目录`, "")
 	}
 
 	setFieldHeadComment(root, "notify", "# 通知服务配置")
@@ -61,6 +71,16 @@ func DecorateConfigNode(node *yaml.Node) {
 			setFieldComment(email, "senderPassword", "# 发送者邮箱授权码或应用专用密码", "")
 			setFieldComment(email, "recipientEmail", "# 接收者邮箱地址 ", "")
 		}
+		setFieldComment(notifyNode, "rules",
+			`# 全局通知规则，平台 / 配置模板 / 直播间可通过 notify_rules 覆盖
+# events: 需要发送的事件 live_start, live_end, live_resumed, recording_failed, pipeline_done, pipeline_failed, cookie_expired, risk_control
+# channels: 发送渠道，为空时发送到所有已启用渠道；event_channels 可按事件指定渠道
+# quiet_hours: 免打扰时段，例如 {start: "23:00", end: "08:00", allow_events: [recording_failed]}
+# templates: 按事件自定义标题和正文（Go 模板），例如 live_start: {title: "{{ .HostName }} 开播了"}
+# flap_window_sec: 下播后在此时间内重新开播，不再单独通知，而是合并为一条“中断后恢复”通知（开启 session_grace 时由宽限期合并，此项不生效）`, "")
+		setFieldComment(notifyNode, "tag_rules",
+			`# 按直播间标签（live_rooms[].tags）配置的通知规则
+# 生效顺序：全局 -> 平台 -> 标签 -> 直播间引用的模板 -> 直播间`, "")
 	}
 
 	// 特殊处理 live_rooms
@@ -71,9 +91,88 @@ func DecorateConfigNode(node *yaml.Node) {
 		firstItem.HeadComment = `# quality参数目前仅B站启用，默认为0
 # (B站)0代表原画PRO(HEVC)优先, 其他数值为原画(AVC)
 # 原画PRO会保存为.ts文件, 原画为.flv
-# HEVC相比AVC体积更小, 减少35%体积, 画质相当, 但是B站转码有时候会崩`
+# HEVC相比AVC体积更小, 减少35%体积, 画质相当, 但是B站转码有时候会崩
+# url 也可以直接填写流地址（rtmp:// 或以 .flv / .m3u8 结尾的链接），通过探测流判断是否开播
+# 或填写本地文件 file:///path/to/video.flv?loop=true，以本地文件模拟直播间，用于测试录制和后处理（只能在配置文件中添加）
+# restream: [{name: backup, url: rtmp://host/app/key}] 录制 FLV / RTMP 流时同时转推到这些地址，断开后自动重连`
 	}
 
+	// Profiles 配置模板注释
+	setFieldHeadComment(root, "profiles",
+		`# 命名配置模板，可在 platform_configs 或 live_rooms 中通过 profiles: [模板名] 引用
+# 生效顺序：全局 -> 平台引用的模板 -> 平台 -> 直播间引用的模板 -> 直播间
+# 修改模板后所有引用它的直播间立即生效`)
+
+	// Plugins 外部解析插件注释
+	setFieldHeadComment(root, "plugins",
+		`# 外部解析插件：通过可执行文件支持未内置的直播平台，协议说明见 src/live/plugin/README.md
+# 例如 - {name: example, command: /path/to/resolver, hosts: ["*.example.com"]}
+# 插件名称同时作为平台标识，可在 platform_configs 中为其配置访问间隔等`)
+
+	// ApiAdapters 声明式 API 适配器注释
+	setFieldHeadComment(root, "api_adapters",
+		`# 声明式 JSON API 平台适配器：通过配置接口地址和 gjson 路径支持新站点，无需编写代码
+# 说明和示例见 src/live/apiadapter/README.md`)
+
+	// Ingest 内置推流服务器注释
+	setFieldHeadComment(root, "ingest",
+		`# 内置 RTMP 推流服务器：OBS 等推流到 rtmp://服务器地址:1935/live/推流密钥?secret=密码
+# 每个推流密钥自动对应一个虚拟直播间 ingest://推流密钥，有推流时视为开播并按普通直播间录制
+# 可在 platform_configs.ingest 中调小 interval 以更快发现推流，修改 enable 或 rtmp_listen 需要重启`)
+
+	// Accounts 命名账号注释
+	setFieldHeadComment(root, "accounts",
+		`# 命名账号，同一平台可配置多个账号，直播间通过 account: 账号名 选择使用的账号
+# 未指定账号的直播间使用 cookies 中该平台的 Cookie
+# platform_configs 中开启 account_rotation 后，账号触发风控时会临时轮换到同平台的其他账号`)
+
+	// CookieMonitor Cookie 健康检查注释
+	setFieldHeadComment(root, "cookie_monitor",
+		`# Cookie 健康检查：定期检查 cookies 中各平台的登录状态，失效时发送 cookie_expired 通知
+# auto_refresh 开启且在 refresh_tokens 中配置了刷新令牌时，会在平台要求刷新时自动更新 Cookie
+# B站的刷新令牌为登录后浏览器 localStorage 中的 ac_time_value`)
+
+	// AdaptivePolling 自适应轮询注释
+	setFieldHeadComment(root, "adaptive_polling",
+		`# 自适应轮询：根据历史开播时间学习主播的开播规律，常开播的时段按 min_interval_sec 检测，
+# 其他时段随距离预测开播时间的远近逐步放慢，最慢 max_interval_sec；历史不足 min_sessions 场时使用 interval
+# 可在 platform_configs 中通过 adaptive_min_interval_sec / adaptive_max_interval_sec 为平台单独设置上下限`)
+
+	// PushDetection 推送开播检测注释
+	setFieldHeadComment(root, "push_detection",
+		`# 推送开播检测：订阅平台的直播间推送通道（目前支持 B站），开播/下播时立即处理，不必等待下一次轮询
+# 推送通道连接正常时按 poll_interval_sec 轮询作为补充，连接断开时恢复按 interval 轮询
+# max_connections 限制推送通道的连接总数，超出的直播间继续轮询
+# B站每个连接只能订阅一个直播间，监控的 B站直播间多于 max_connections 时需要相应调大`)
+
+	// SessionGrace 直播会话宽限期注释
+	setFieldHeadComment(root, "session_grace",
+		`# 直播会话宽限期：检测到下播后等待 period_sec 秒（默认 0 表示关闭，开启后下播通知和录制收尾相应延后），期间录制器继续重试，并按 poll_interval_sec 加快检测
+# 宽限期内重新开播视为同一场直播：继续同一个会话，只发送一次开播和下播通知，中断次数记录在会话中`)
+
+	// RecordingLimit 同时录制数量限制注释
+	setFieldHeadComment(root, "recording_limit",
+		`# 同时录制数量限制：max_concurrent 为最多同时录制的直播间数量（0 表示不限制）
+# 达到上限后开播的直播间按 recording_priority（数值越大越优先，可在直播间、平台或配置模板中设置，默认 0）排队，有空位时自动开始录制
+# preempt 为 true 时，优先级更高的直播间会抢占优先级最低的录制，被抢占的直播间重新排队`)
+
+	// Bandwidth 带宽限制注释
+	setFieldHeadComment(root, "bandwidth",
+		`# 带宽限制（单位 Mbps，0 表示不限制）：作用于原生 FLV 下载器、流探测代理（FFmpeg 和录播姬下载 FLV 流时经过）和 OpenList 上传
+# global_limit_mbps 为下载和上传共享的全局上限，其中 reserved_mbps 保留给接口轮询等控制流量
+# room_limit_mbps 为单个直播间的下载上限，可在平台、直播间或配置模板中通过 bandwidth_limit_mbps 覆盖；upload_limit_mbps 为上传总上限
+# HLS 流由 FFmpeg 直接下载，不受带宽限制也不计入全局上限（会挤占 reserved_mbps），IO 统计的 unlimited 中列出这些直播间
+# 各使用方的实时带宽可在 IO 统计中查看`)
+
+	// RateLimits 访问频率限制注释
+	setFieldHeadComment(root, "rate_limits",
+		`# 访问频率限制：每个平台按接口类别分别使用令牌桶，令牌每 interval_sec 秒补充一个，最多积攒 burst 个
+# info: 开播状态检测，stream: 获取直播流地址（刚开播的直播间优先），cookie: Cookie 登录状态检查
+# 没有配置的接口类别不限制访问频率（触发风控后仍会冷却），例如 info: {interval_sec: 1, burst: 1}
+# 排队的请求在直播间之间公平分配，可在直播间、平台或配置模板中通过 rate_limit_weight 设置权重（默认 1，权重为 2 的直播间分到两倍份额）
+# 可在 platform_configs 中通过 rate_limits 为平台单独设置
+# 旧的 min_access_interval_sec 会在加载时迁移为 rate_limits.info`)
+
 	// Proxy 代理配置注释
 	setFieldHeadComment(root, "proxy", "# 代理配置（支持 HTTP 和 SOCKS5 代理）")
 	proxyNode := findNode(root, "proxy")
@@ -105,8 +204,12 @@ func DecorateConfigNode(node *yaml.Node) {
 		setFieldComment(featureNode, "downloader_type",
 			`# 下载器类型：ffmpeg（默认）、native（内置 FLV 解析器）、bililive-recorder
 # ffmpeg: 使用 FFmpeg 录制，支持所有流格式，需要安装 FFmpeg
-# native: 使用内置 FLV 解析器，仅支持 FLV 流，无需额外依赖
+# native: 使用内置 FLV 解析器，支持 HTTP-FLV 和 RTMP 流，无需额外依赖
 # bililive-recorder: 使用 BililiveRecorder CLI，仅支持 FLV 流`, "")
+		setFieldComment(featureNode, "downloader_fallback",
+			`# 备用下载器列表（可选），例如 [native, bililive-recorder]
+# 当前下载器连续 downloader_fallback_failures 次（默认 3）快速失败时切换到列表中的下一个，用完后回到 downloader_type
+# 启动后 downloader_fast_failure_sec 秒内（默认 10）退出或没有写入数据视为快速失败；直播会话结束后重新从 downloader_type 开始`, "")
 		setFieldComment(featureNode, "enable_flv_proxy_segment",
 			`# FLV 代理分段功能（仅对 FFmpeg 下载器生效）
 # 当检测到视频编码参数变化（新的 SPS/PPS）时，会主动断开连接触发 FFmpeg 分段
@@ -1,10 +1,16 @@
 package configs
 
 import (
+	"context"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bililive-go/bililive-go/src/pkg/ratelimit"
 )
 
 func TestNewConfig(t *testing.T) {
@@ -39,6 +45,10 @@ func TestConfig_Verify(t *testing.T) {
 	cfg.OutPutPath = "foobar"
 	assert.Error(t, cfg.Verify())
 	cfg.OutPutPath = os.TempDir()
+	cfg.OnRecordFinished.MediaLibrary.Enable = true
+	assert.Error(t, cfg.Verify())
+	cfg.OnRecordFinished.MediaLibrary.Path = os.TempDir()
+	assert.NoError(t, cfg.Verify())
 	cfg.RPC.Enable = false
 	assert.Error(t, cfg.Verify())
 }
@@ -94,6 +104,30 @@ func TestGetPlatformMinAccessInterval(t *testing.T) {
 	assert.Equal(t, 1, interval) // 默认最小间隔为 1 秒，防止无限制高频访问
 }
 
+func TestGetPlatformRateLimit(t *testing.T) {
+	cfg, err := NewConfigWithBytes([]byte(`
+rate_limits:
+  stream: {interval_sec: 2, burst: 3}
+platform_configs:
+  douyin:
+    min_access_interval_sec: 5
+`))
+	require.NoError(t, err)
+
+	// 没有配置的接口类别不限制访问频率
+	_, ok := cfg.GetPlatformRateLimit("bilibili", ratelimit.EndpointInfo)
+	assert.False(t, ok)
+	assert.NotContains(t, cfg.rateLimitBuckets("bilibili"), ratelimit.EndpointInfo)
+
+	bucket, ok := cfg.GetPlatformRateLimit("bilibili", ratelimit.EndpointStream)
+	assert.True(t, ok)
+	assert.Equal(t, RateLimitBucket{IntervalSec: 2, Burst: 3}, bucket)
+
+	bucket, ok = cfg.GetPlatformRateLimit("douyin", ratelimit.EndpointInfo)
+	assert.True(t, ok)
+	assert.Equal(t, RateLimitBucket{IntervalSec: 5, Burst: 1}, bucket)
+}
+
 func TestBackwardsCompatibility(t *testing.T) {
 	// Test that old config files still work
 	oldConfigYaml := `
@@ -188,6 +222,218 @@ platform_configs:
 	assert.Equal(t, 20, resolvedUnknown.Interval) // 使用全局默认值
 }
 
+func TestResolveConfigForRoomWithProfiles(t *testing.T) {
+	profileYaml := `
+rpc:
+  enable: true
+  bind: :8080
+interval: 20
+out_put_path: ./
+profiles:
+  archive:
+    description: "归档直播间"
+    out_put_tmpl: "archive/{{ .HostName }}.flv"
+    out_put_path: /archive
+  fast:
+    interval: 5
+platform_configs:
+  bilibili:
+    profiles: [fast]
+    out_put_path: /bilibili
+live_rooms:
+- url: https://live.bilibili.com/1
+  profiles: [archive]
+- url: https://live.bilibili.com/2
+  profiles: [archive]
+  out_put_path: /room2
+`
+	cfg, err := NewConfigWithBytes([]byte(profileYaml))
+	assert.NoError(t, err)
+	assert.NoError(t, cfg.ValidateProfiles())
+	assert.Len(t, cfg.Profiles, 2)
+
+	// 平台模板 -> 平台 -> 房间模板 -> 房间
+	resolved := cfg.ResolveConfigForRoom(&cfg.LiveRooms[0], "bilibili")
+	assert.Equal(t, 5, resolved.Interval)
+	assert.Equal(t, "profile:fast", resolved.SourceOf("interval"))
+	assert.Equal(t, "/archive", resolved.OutPutPath)
+	assert.Equal(t, "profile:archive", resolved.SourceOf("out_put_path"))
+	assert.Equal(t, "archive/{{ .HostName }}.flv", resolved.OutputTmpl)
+	assert.Equal(t, ConfigSourceGlobal, resolved.SourceOf("ffmpeg_path"))
+
+	resolved = cfg.ResolveConfigForRoom(&cfg.LiveRooms[1], "bilibili")
+	assert.Equal(t, "/room2", resolved.OutPutPath)
+	assert.Equal(t, ConfigSourceRoom, resolved.SourceOf("out_put_path"))
+
+	// 修改模板后，所有引用它的房间立即使用新值
+	profile := cfg.Profiles["archive"]
+	profile.OutputTmpl = stringPtr("new/{{ .HostName }}.flv")
+	cfg.Profiles["archive"] = profile
+	for i := range cfg.LiveRooms {
+		resolved = cfg.ResolveConfigForRoom(&cfg.LiveRooms[i], "bilibili")
+		assert.Equal(t, "new/{{ .HostName }}.flv", resolved.OutputTmpl)
+	}
+
+	usage := cfg.GetProfileUsage("archive")
+	assert.Len(t, usage.Rooms, 2)
+	assert.Empty(t, usage.Platforms)
+	usage = cfg.GetProfileUsage("fast")
+	assert.Equal(t, []string{"bilibili"}, usage.Platforms)
+
+	// 引用不存在的模板应报错
+	cfg.LiveRooms[0].Profiles = []string{"missing"}
+	assert.Error(t, cfg.ValidateProfiles())
+}
+
+func TestValidateAccounts(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Accounts["main"] = Account{Host: "live.bilibili.com", Cookie: "a=b"}
+	cfg.LiveRooms = []LiveRoom{{Url: "https://live.bilibili.com/1", Account: "main"}}
+	assert.NoError(t, cfg.ValidateAccounts())
+	assert.Equal(t, []string{"https://live.bilibili.com/1"}, cfg.GetAccountUsage("main"))
+
+	// 账号与直播间平台不一致
+	cfg.LiveRooms[0].Url = "https://www.douyu.com/1"
+	assert.Error(t, cfg.ValidateAccounts())
+
+	// 引用不存在的账号
+	cfg.LiveRooms[0] = LiveRoom{Url: "https://live.bilibili.com/1", Account: "missing"}
+	assert.Error(t, cfg.ValidateAccounts())
+
+	// 克隆后修改账号不影响原配置
+	cfg.LiveRooms[0].Account = "main"
+	cp := CloneConfigShallow(cfg)
+	cp.Accounts["backup"] = Account{Host: "live.bilibili.com"}
+	assert.Len(t, cfg.Accounts, 1)
+}
+
+func TestIngest(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Ingest.Enable = true
+	cfg.Ingest.Keys = []IngestKey{{Key: "a"}, {Key: "b"}, {Key: "c", Disabled: true}}
+	cfg.LiveRooms = []LiveRoom{{Url: IngestRoomUrl("a")}}
+	assert.NoError(t, cfg.ValidateIngest())
+	assert.Equal(t, PlatformKeyIngest, GetPlatformKeyFromUrl(IngestRoomUrl("a")))
+	SetCurrentConfig(cfg)
+
+	// 为启用且没有直播间的密钥补充虚拟直播间
+	newCfg, err := EnsureIngestLiveRooms()
+	assert.NoError(t, err)
+	var urls []string
+	for _, room := range newCfg.LiveRooms {
+		urls = append(urls, room.Url)
+	}
+	assert.Equal(t, []string{"ingest://a", "ingest://b"}, urls)
+	assert.True(t, newCfg.LiveRooms[1].IsListening)
+
+	// 密钥重复或包含非法字符
+	cfg.Ingest.Keys = []IngestKey{{Key: "a"}, {Key: "a"}}
+	assert.Error(t, cfg.ValidateIngest())
+	cfg.Ingest.Keys = []IngestKey{{Key: "a/b"}}
+	assert.Error(t, cfg.ValidateIngest())
+	cfg.Ingest.Keys = nil
+	cfg.Ingest.RtmpListen = "1935"
+	assert.Error(t, cfg.ValidateIngest())
+}
+
+func TestValidateRestream(t *testing.T) {
+	cfg := NewConfig()
+	cfg.LiveRooms = []LiveRoom{{Url: "https://live.bilibili.com/1", Restream: []RestreamTarget{
+		{Url: "rtmp://a.com/live/key"},
+		{Name: "b", Url: "rtmps://b.com/live/key", Disabled: true},
+	}}}
+	assert.NoError(t, cfg.ValidateRestream())
+	assert.Equal(t, "a.com", cfg.LiveRooms[0].Restream[0].GetName())
+	assert.Len(t, cfg.LiveRooms[0].EnabledRestreamTargets(), 1)
+
+	// 只支持 RTMP 地址，名称不能重复
+	cfg.LiveRooms[0].Restream[1] = RestreamTarget{Url: "https://a.com/live/key"}
+	assert.Error(t, cfg.ValidateRestream())
+	cfg.LiveRooms[0].Restream[1] = RestreamTarget{Url: "rtmp://a.com/live/other"}
+	assert.Error(t, cfg.ValidateRestream())
+}
+
+func TestAdaptivePollingBounds(t *testing.T) {
+	cfg := NewConfig()
+	min, max := cfg.GetAdaptivePollingBounds("bilibili")
+	assert.Equal(t, 10*time.Second, min)
+	assert.Equal(t, 300*time.Second, max)
+
+	// 平台覆盖上下限，下限不低于平台最小访问间隔
+	cfg.PlatformConfigs["bilibili"] = PlatformConfig{MinAccessIntervalSec: 20, AdaptiveMaxIntervalSec: 120}
+	min, max = cfg.GetAdaptivePollingBounds("bilibili")
+	assert.Equal(t, 20*time.Second, min)
+	assert.Equal(t, 120*time.Second, max)
+	assert.NoError(t, cfg.ValidateAdaptivePolling())
+
+	cfg.PlatformConfigs["bilibili"] = PlatformConfig{AdaptiveMinIntervalSec: 60, AdaptiveMaxIntervalSec: 30}
+	assert.Error(t, cfg.ValidateAdaptivePolling())
+}
+
+func TestBandwidth(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Bandwidth = Bandwidth{GlobalLimitMbps: 100, ReservedMbps: 10, RoomLimitMbps: 20}
+	limit := 0.0
+	cfg.LiveRooms = []LiveRoom{
+		{Url: "https://live.bilibili.com/1", LiveId: "a"},
+		{Url: "https://live.bilibili.com/2", LiveId: "b", OverridableConfig: OverridableConfig{BandwidthLimitMbps: &limit}},
+	}
+	assert.NoError(t, cfg.ValidateBandwidth())
+	assert.Equal(t, 20.0, cfg.ResolveConfigForRoom(&cfg.LiveRooms[0], "bilibili").BandwidthLimitMbps)
+	assert.Equal(t, 0.0, cfg.ResolveConfigForRoom(&cfg.LiveRooms[1], "bilibili").BandwidthLimitMbps)
+	assert.Equal(t, int64(12500000), mbpsToBytes(100))
+
+	// 保留带宽必须小于全局上限
+	cfg.Bandwidth.ReservedMbps = 100
+	assert.Error(t, cfg.ValidateBandwidth())
+	cfg.Bandwidth = Bandwidth{ReservedMbps: 10}
+	assert.Error(t, cfg.ValidateBandwidth())
+	cfg.Bandwidth = Bandwidth{}
+	limit = -1
+	assert.Error(t, cfg.ValidateBandwidth())
+}
+
+func TestDownloaderChain(t *testing.T) {
+	f := Feature{DownloaderType: DownloaderFFmpeg}
+	assert.Equal(t, []DownloaderType{DownloaderFFmpeg}, f.GetDownloaderChain())
+	assert.Equal(t, 3, f.GetDownloaderFallbackFailures())
+	assert.Equal(t, 10*time.Second, f.GetDownloaderFastFailureWindow())
+
+	// 首选下载器在前，备用下载器去重
+	f.DownloaderFallback = []DownloaderType{DownloaderNative, DownloaderFFmpeg, DownloaderBililiveRecorder, DownloaderNative}
+	assert.Equal(t, []DownloaderType{DownloaderFFmpeg, DownloaderNative, DownloaderBililiveRecorder}, f.GetDownloaderChain())
+
+	cfg := NewConfig()
+	cfg.Feature = f
+	assert.NoError(t, cfg.ValidateDownloaderFallback())
+	cfg.LiveRooms = []LiveRoom{{Url: "https://live.bilibili.com/1", OverridableConfig: OverridableConfig{
+		Feature: &Feature{DownloaderFallback: []DownloaderType{"unknown"}},
+	}}}
+	assert.Error(t, cfg.ValidateDownloaderFallback())
+}
+
+func TestValidateNotifyRules(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Notify.Rules = NotifyRules{
+		Events:        []string{"live_start", "live_end"},
+		EventChannels: map[string][]string{"live_start": {"telegram"}},
+		FlapWindowSec: intPtr(60),
+	}
+	assert.NoError(t, cfg.ValidateNotifyRules())
+
+	// 未知事件名称和负数防抖窗口
+	cfg.Notify.Rules.Events = []string{"live_begin"}
+	assert.Error(t, cfg.ValidateNotifyRules())
+	cfg.Notify.Rules.Events = nil
+	cfg.Notify.TagRules = map[string]NotifyRules{"vip": {Templates: map[string]NotifyTemplate{"unknown": {}}}}
+	assert.Error(t, cfg.ValidateNotifyRules())
+	cfg.Notify.TagRules = nil
+	cfg.LiveRooms = []LiveRoom{{Url: "https://live.bilibili.com/1", OverridableConfig: OverridableConfig{
+		NotifyRules: &NotifyRules{FlapWindowSec: intPtr(-1)},
+	}}}
+	assert.Error(t, cfg.ValidateNotifyRules())
+}
+
 // Helper functions for pointer conversion
 func intPtr(i int) *int {
 	return &i
@@ -196,3 +442,58 @@ func intPtr(i int) *int {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestRateLimitWeight(t *testing.T) {
+	weightYaml := `
+rpc:
+  enable: true
+  bind: :8080
+interval: 20
+out_put_path: ./
+live_rooms:
+- url: https://live.bilibili.com/1
+- url: https://live.bilibili.com/2
+  rate_limit_weight: 2
+`
+	cfg, err := NewConfigWithBytes([]byte(weightYaml))
+	require.NoError(t, err)
+	require.NoError(t, cfg.ValidateRateLimits())
+
+	weights := make([]int, len(cfg.LiveRooms))
+	for i := range cfg.LiveRooms {
+		weights[i] = cfg.ResolveConfigForRoom(&cfg.LiveRooms[i], "bilibili").RateLimitWeight
+	}
+	assert.Equal(t, []int{1, 2}, weights)
+
+	// 按解析出的权重排队，权重为 2 的直播间分到两倍份额
+	prl := ratelimit.NewPlatformRateLimiter()
+	prl.SetDefaultBuckets(map[ratelimit.Endpoint]ratelimit.BucketConfig{
+		ratelimit.EndpointInfo: {Interval: 20 * time.Millisecond, Burst: 1},
+	})
+	require.True(t, prl.Wait(context.Background(), ratelimit.Request{Platform: "bilibili"}))
+	rooms := []int{0, 0, 1, 1}
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i, room := range rooms {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := ratelimit.Request{Platform: "bilibili", Room: cfg.LiveRooms[room].Url, Weight: weights[room]}
+			if prl.Wait(context.Background(), req) {
+				mu.Lock()
+				order = append(order, i)
+				mu.Unlock()
+			}
+		}()
+		require.Eventually(t, func() bool {
+			return prl.GetWaitInfo("bilibili", ratelimit.EndpointInfo).Waiting == i+1
+		}, time.Second, time.Millisecond)
+	}
+	wg.Wait()
+	assert.Equal(t, []int{2, 0, 3, 1}, order)
+
+	zero := 0
+	cfg.LiveRooms[1].RateLimitWeight = &zero
+	assert.Error(t, cfg.ValidateRateLimits())
+}
@@ -1,5 +1,10 @@
 package configs
 
+import (
+	"fmt"
+	"time"
+)
+
 // DownloaderType 表示下载器类型
 type DownloaderType string
 
@@ -63,3 +68,84 @@ func ParseDownloaderType(s string) DownloaderType {
 		return DownloaderFFmpeg
 	}
 }
+
+const (
+	// defaultDownloaderFallbackFailures 默认连续快速失败多少次后切换下载器
+	defaultDownloaderFallbackFailures = 3
+	// defaultDownloaderFastFailureSec 默认下载器启动后多少秒内退出视为快速失败
+	defaultDownloaderFastFailureSec = 10
+)
+
+// GetDownloaderChain 返回下载器回退链：首选下载器在前，之后是去重后的备用下载器
+func (f *Feature) GetDownloaderChain() []DownloaderType {
+	chain := []DownloaderType{f.GetEffectiveDownloaderType()}
+	for _, d := range f.DownloaderFallback {
+		if !d.IsValid() {
+			continue
+		}
+		duplicate := false
+		for _, existing := range chain {
+			if existing == d {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			chain = append(chain, d)
+		}
+	}
+	return chain
+}
+
+// GetDownloaderFallbackFailures 返回切换下载器前允许的连续快速失败次数
+func (f *Feature) GetDownloaderFallbackFailures() int {
+	if f.DownloaderFallbackFailures > 0 {
+		return f.DownloaderFallbackFailures
+	}
+	return defaultDownloaderFallbackFailures
+}
+
+// GetDownloaderFastFailureWindow 返回判定快速失败的时长
+func (f *Feature) GetDownloaderFastFailureWindow() time.Duration {
+	if f.DownloaderFastFailureSec > 0 {
+		return time.Duration(f.DownloaderFastFailureSec) * time.Second
+	}
+	return defaultDownloaderFastFailureSec * time.Second
+}
+
+// validateDownloaderFallback 验证下载器回退配置
+func validateDownloaderFallback(f *Feature) error {
+	for _, d := range f.DownloaderFallback {
+		if !d.IsValid() {
+			return fmt.Errorf("无效的备用下载器 '%s'", d)
+		}
+	}
+	if f.DownloaderFallbackFailures < 0 || f.DownloaderFastFailureSec < 0 {
+		return fmt.Errorf("下载器回退的失败次数和快速失败时长不能为负数")
+	}
+	return nil
+}
+
+// ValidateDownloaderFallback 验证全局、平台和直播间的下载器回退配置
+func (c *Config) ValidateDownloaderFallback() error {
+	if err := validateDownloaderFallback(&c.Feature); err != nil {
+		return err
+	}
+	for platformKey, pc := range c.PlatformConfigs {
+		if pc.Feature == nil {
+			continue
+		}
+		if err := validateDownloaderFallback(pc.Feature); err != nil {
+			return fmt.Errorf("平台 '%s': %w", platformKey, err)
+		}
+	}
+	for _, room := range c.LiveRooms {
+		if room.Feature == nil {
+			continue
+		}
+		if err := validateDownloaderFallback(room.Feature); err != nil {
+			return fmt.Errorf("直播间 '%s': %w", room.Url, err)
+		}
+	}
+	return nil
+}
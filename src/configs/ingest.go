@@ -0,0 +1,121 @@
+package configs
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// PlatformKeyIngest 内置推流服务器虚拟直播间的平台标识
+const PlatformKeyIngest = "ingest"
+
+// Ingest 内置 RTMP 推流服务器配置
+// 每个推流密钥对应一个虚拟直播间（URL 为 ingest://密钥），有推流端连接时视为开播
+type Ingest struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// RtmpListen RTMP 监听地址，默认 :1935
+	RtmpListen string `yaml:"rtmp_listen" json:"rtmp_listen"`
+	// App 推流地址中的应用名，默认 live，推流地址为 rtmp://服务器地址/live/密钥
+	App  string      `yaml:"app" json:"app"`
+	Keys []IngestKey `yaml:"keys,omitempty" json:"keys,omitempty"`
+}
+
+// IngestKey 推流密钥
+type IngestKey struct {
+	Key string `yaml:"key" json:"key"`
+	// Secret 推流鉴权密码，不为空时推流地址需要带上 ?secret=密码
+	Secret   string `yaml:"secret,omitempty" json:"secret,omitempty"`
+	HostName string `yaml:"host_name,omitempty" json:"host_name,omitempty"` // 虚拟直播间显示的主播名，默认使用密钥
+	RoomName string `yaml:"room_name,omitempty" json:"room_name,omitempty"` // 虚拟直播间显示的标题
+	Disabled bool   `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+var defaultIngest = Ingest{
+	Enable:     false,
+	RtmpListen: ":1935",
+	App:        "live",
+}
+
+// GetApp 返回推流应用名
+func (i Ingest) GetApp() string {
+	if i.App == "" {
+		return defaultIngest.App
+	}
+	return i.App
+}
+
+// GetRtmpListen 返回 RTMP 监听地址
+func (i Ingest) GetRtmpListen() string {
+	if i.RtmpListen == "" {
+		return defaultIngest.RtmpListen
+	}
+	return i.RtmpListen
+}
+
+// GetKey 按密钥查找推流配置
+func (i Ingest) GetKey(key string) (IngestKey, bool) {
+	for _, k := range i.Keys {
+		if k.Key == key {
+			return k, true
+		}
+	}
+	return IngestKey{}, false
+}
+
+// IngestRoomUrl 返回推流密钥对应的虚拟直播间 URL
+func IngestRoomUrl(key string) string {
+	return PlatformKeyIngest + "://" + key
+}
+
+// ValidateIngest 验证推流服务器配置
+func (c *Config) ValidateIngest() error {
+	if _, _, err := net.SplitHostPort(c.Ingest.GetRtmpListen()); err != nil {
+		return fmt.Errorf("推流服务器: 无效的监听地址 '%s': %w", c.Ingest.RtmpListen, err)
+	}
+	if strings.Contains(c.Ingest.GetApp(), "/") {
+		return fmt.Errorf("推流服务器: 应用名不能包含 '/'")
+	}
+	keys := make(map[string]struct{}, len(c.Ingest.Keys))
+	for i, k := range c.Ingest.Keys {
+		if strings.TrimSpace(k.Key) == "" {
+			return fmt.Errorf("推流服务器: 第 %d 个推流密钥不能为空", i+1)
+		}
+		if strings.ContainsAny(k.Key, "/?#:") {
+			return fmt.Errorf("推流服务器: 推流密钥 '%s' 不能包含 / ? # :", k.Key)
+		}
+		if _, ok := keys[k.Key]; ok {
+			return fmt.Errorf("推流服务器: 推流密钥 '%s' 重复", k.Key)
+		}
+		keys[k.Key] = struct{}{}
+	}
+	return nil
+}
+
+// EnsureIngestLiveRooms 为已启用的推流密钥补充对应的虚拟直播间（默认监听）
+// 所有密钥都已有直播间时不修改配置
+func EnsureIngestLiveRooms() (*Config, error) {
+	missing := func(c *Config) []LiveRoom {
+		if !c.Ingest.Enable {
+			return nil
+		}
+		var rooms []LiveRoom
+		for _, k := range c.Ingest.Keys {
+			if k.Disabled {
+				continue
+			}
+			u := IngestRoomUrl(k.Key)
+			if _, err := c.GetLiveRoomByUrl(u); err != nil {
+				rooms = append(rooms, LiveRoom{Url: u, IsListening: true})
+			}
+		}
+		return rooms
+	}
+	if len(missing(GetCurrentConfig())) == 0 {
+		return GetCurrentConfig(), nil
+	}
+	return UpdateWithRetry(func(c *Config) error {
+		c.LiveRooms = append(c.LiveRooms, missing(c)...)
+		return nil
+	}, 3, 10*time.Millisecond)
+}
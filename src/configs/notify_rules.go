@@ -0,0 +1,218 @@
+package configs
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// KnownNotifyEvents 通知规则中可以使用的事件名称，与 notify 包中的事件类型一致
+var KnownNotifyEvents = []string{
+	"live_start",
+	"live_end",
+	"live_resumed",
+	"recording_failed",
+	"pipeline_done",
+	"pipeline_failed",
+	"cookie_expired",
+	"risk_control",
+	"test",
+}
+
+// validateNotifyEvent 检查事件名称是否有效
+func validateNotifyEvent(event string) error {
+	if !slices.Contains(KnownNotifyEvents, event) {
+		return fmt.Errorf("未知的通知事件 '%s'", event)
+	}
+	return nil
+}
+
+// NotifyTemplate 某个通知事件的自定义消息模板（Go text/template 语法）
+// 模板数据为 notify.Message，可使用 {{ .HostName }}、{{ .RoomName }}、{{ .FlapCount }} 等字段
+type NotifyTemplate struct {
+	Title string `yaml:"title,omitempty" json:"title,omitempty"`
+	Body  string `yaml:"body,omitempty" json:"body,omitempty"`
+}
+
+// QuietHours 免打扰时段，格式为 "HH:MM"，支持跨零点（如 23:00 - 08:00）
+type QuietHours struct {
+	Start string `yaml:"start" json:"start"`
+	End   string `yaml:"end" json:"end"`
+	// AllowEvents 免打扰时段内仍然发送的事件
+	AllowEvents []string `yaml:"allow_events,omitempty" json:"allow_events,omitempty"`
+}
+
+// Contains 判断指定时间是否处于免打扰时段
+func (q *QuietHours) Contains(t time.Time) bool {
+	if q == nil {
+		return false
+	}
+	start, errStart := parseClock(q.Start)
+	end, errEnd := parseClock(q.End)
+	if errStart != nil || errEnd != nil || start == end {
+		return false
+	}
+	now := t.Hour()*60 + t.Minute()
+	if start < end {
+		return now >= start && now < end
+	}
+	// 跨零点
+	return now >= start || now < end
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// NotifyRules 通知规则
+// 可以在 全局(notify.rules) / 平台 / 标签(notify.tag_rules) / 配置模板 / 直播间 层级配置，
+// 下级中非空的字段覆盖上级，EventChannels 和 Templates 按事件合并
+type NotifyRules struct {
+	// Events 需要发送的事件，为空时使用默认事件（开播、下播、中断恢复、Cookie 失效）
+	Events []string `yaml:"events,omitempty" json:"events,omitempty"`
+	// Channels 默认发送渠道，为空时发送到所有已启用的渠道
+	Channels []string `yaml:"channels,omitempty" json:"channels,omitempty"`
+	// EventChannels 按事件指定发送渠道，优先于 Channels
+	EventChannels map[string][]string `yaml:"event_channels,omitempty" json:"event_channels,omitempty"`
+	// QuietHours 免打扰时段
+	QuietHours *QuietHours `yaml:"quiet_hours,omitempty" json:"quiet_hours,omitempty"`
+	// Templates 按事件自定义消息模板
+	Templates map[string]NotifyTemplate `yaml:"templates,omitempty" json:"templates,omitempty"`
+	// FlapWindowSec 防抖窗口（秒）：下播后在此窗口内重新开播，合并为一条“中断后恢复”通知
+	// 开启直播会话宽限期（session_grace）时由宽限期合并中断，防抖窗口不生效
+	FlapWindowSec *int `yaml:"flap_window_sec,omitempty" json:"flap_window_sec,omitempty"`
+}
+
+// MergeNotifyRules 合并通知规则，child 的非空字段覆盖 parent
+func MergeNotifyRules(parent, child *NotifyRules) *NotifyRules {
+	if child == nil {
+		return parent
+	}
+	if parent == nil {
+		parent = &NotifyRules{}
+	}
+	merged := *parent
+
+	if child.Events != nil {
+		merged.Events = child.Events
+	}
+	if child.Channels != nil {
+		merged.Channels = child.Channels
+	}
+	if child.QuietHours != nil {
+		merged.QuietHours = child.QuietHours
+	}
+	if child.FlapWindowSec != nil {
+		merged.FlapWindowSec = child.FlapWindowSec
+	}
+	if parent.EventChannels != nil || child.EventChannels != nil {
+		merged.EventChannels = make(map[string][]string, len(parent.EventChannels)+len(child.EventChannels))
+		for k, v := range parent.EventChannels {
+			merged.EventChannels[k] = v
+		}
+		for k, v := range child.EventChannels {
+			merged.EventChannels[k] = v
+		}
+	}
+	if parent.Templates != nil || child.Templates != nil {
+		merged.Templates = make(map[string]NotifyTemplate, len(parent.Templates)+len(child.Templates))
+		for k, v := range parent.Templates {
+			merged.Templates[k] = v
+		}
+		for k, v := range child.Templates {
+			merged.Templates[k] = v
+		}
+	}
+	return &merged
+}
+
+// GetFlapWindow 返回防抖窗口时长
+func (r *NotifyRules) GetFlapWindow() time.Duration {
+	if r == nil || r.FlapWindowSec == nil || *r.FlapWindowSec <= 0 {
+		return 0
+	}
+	return time.Duration(*r.FlapWindowSec) * time.Second
+}
+
+// validate 验证通知规则
+func (r *NotifyRules) validate() error {
+	if r == nil {
+		return nil
+	}
+	for _, event := range r.Events {
+		if err := validateNotifyEvent(event); err != nil {
+			return err
+		}
+	}
+	for event := range r.EventChannels {
+		if err := validateNotifyEvent(event); err != nil {
+			return err
+		}
+	}
+	for event := range r.Templates {
+		if err := validateNotifyEvent(event); err != nil {
+			return err
+		}
+	}
+	if r.QuietHours != nil {
+		for _, event := range r.QuietHours.AllowEvents {
+			if err := validateNotifyEvent(event); err != nil {
+				return err
+			}
+		}
+		if _, err := parseClock(r.QuietHours.Start); err != nil {
+			return fmt.Errorf("免打扰开始时间格式错误(应为 HH:MM): %s", r.QuietHours.Start)
+		}
+		if _, err := parseClock(r.QuietHours.End); err != nil {
+			return fmt.Errorf("免打扰结束时间格式错误(应为 HH:MM): %s", r.QuietHours.End)
+		}
+	}
+	if r.FlapWindowSec != nil && *r.FlapWindowSec < 0 {
+		return fmt.Errorf("防抖窗口不能为负数")
+	}
+	return nil
+}
+
+// applyTagNotifyRules 按直播间标签顺序应用标签级通知规则
+func (r *ResolvedConfig) applyTagNotifyRules(tagRules map[string]NotifyRules, tags []string) {
+	for _, tag := range tags {
+		rules, ok := tagRules[tag]
+		if !ok {
+			continue
+		}
+		r.NotifyRules = *MergeNotifyRules(&r.NotifyRules, &rules)
+		r.Sources["notify_rules"] = configSourceTagPrefix + tag
+	}
+}
+
+// ValidateNotifyRules 验证各层级的通知规则
+func (c *Config) ValidateNotifyRules() error {
+	if err := c.Notify.Rules.validate(); err != nil {
+		return fmt.Errorf("全局通知规则: %w", err)
+	}
+	for tag, rules := range c.Notify.TagRules {
+		if err := rules.validate(); err != nil {
+			return fmt.Errorf("标签 '%s' 通知规则: %w", tag, err)
+		}
+	}
+	for platformKey, platformConfig := range c.PlatformConfigs {
+		if err := platformConfig.NotifyRules.validate(); err != nil {
+			return fmt.Errorf("平台 '%s' 通知规则: %w", platformKey, err)
+		}
+	}
+	for name, profile := range c.Profiles {
+		if err := profile.NotifyRules.validate(); err != nil {
+			return fmt.Errorf("配置模板 '%s' 通知规则: %w", name, err)
+		}
+	}
+	for _, room := range c.LiveRooms {
+		if err := room.NotifyRules.validate(); err != nil {
+			return fmt.Errorf("直播间 '%s' 通知规则: %w", room.Url, err)
+		}
+	}
+	return nil
+}
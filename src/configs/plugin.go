@@ -0,0 +1,103 @@
+package configs
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// defaultPluginTimeout 外部解析插件单次调用的默认超时
+const defaultPluginTimeout = 30 * time.Second
+
+// PluginConfig 外部解析插件配置
+// 插件是一个可执行文件，通过 stdin/stdout 交换 JSON 实现 GetInfo / GetStreamInfos，
+// 用于在不重新编译的情况下支持新的直播平台
+type PluginConfig struct {
+	Name       string            `yaml:"name" json:"name"`                                   // 插件名称，同时作为平台标识（platform_configs 的键）
+	CNName     string            `yaml:"cn_name,omitempty" json:"cn_name,omitempty"`         // 平台显示名称，为空时使用 name
+	Command    string            `yaml:"command" json:"command"`                             // 可执行文件路径
+	Args       []string          `yaml:"args,omitempty" json:"args,omitempty"`               // 启动参数
+	Env        map[string]string `yaml:"env,omitempty" json:"env,omitempty"`                 // 额外的环境变量
+	Hosts      []string          `yaml:"hosts" json:"hosts"`                                 // 匹配的直播间 host，支持通配符，如 *.example.com
+	TimeoutSec int               `yaml:"timeout_sec,omitempty" json:"timeout_sec,omitempty"` // 单次调用超时（秒，默认 30）
+	Disabled   bool              `yaml:"disabled,omitempty" json:"disabled,omitempty"`       // 是否停用
+}
+
+// GetTimeout 返回单次调用超时
+func (p PluginConfig) GetTimeout() time.Duration {
+	if p.TimeoutSec <= 0 {
+		return defaultPluginTimeout
+	}
+	return time.Duration(p.TimeoutSec) * time.Second
+}
+
+// GetCNName 返回平台显示名称
+func (p PluginConfig) GetCNName() string {
+	if p.CNName != "" {
+		return p.CNName
+	}
+	return p.Name
+}
+
+// MatchHost 判断 host 是否匹配插件声明的任一 host 模式
+func (p PluginConfig) MatchHost(host string) bool {
+	for _, pattern := range p.Hosts {
+		if MatchHostPattern(pattern, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchHostPattern 判断 host 是否匹配模式，模式支持 path.Match 通配符，不区分大小写
+func MatchHostPattern(pattern, host string) bool {
+	ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(host))
+	return err == nil && ok
+}
+
+// FindPluginForHost 返回第一个匹配 host 且启用的插件
+func (c *Config) FindPluginForHost(host string) (PluginConfig, bool) {
+	for _, plugin := range c.Plugins {
+		if !plugin.Disabled && plugin.MatchHost(host) {
+			return plugin, true
+		}
+	}
+	return PluginConfig{}, false
+}
+
+// GetPlugin 按名称查找插件
+func (c *Config) GetPlugin(name string) (PluginConfig, bool) {
+	for _, plugin := range c.Plugins {
+		if plugin.Name == name {
+			return plugin, true
+		}
+	}
+	return PluginConfig{}, false
+}
+
+// ValidatePlugins 验证插件配置
+func (c *Config) ValidatePlugins() error {
+	names := make(map[string]struct{}, len(c.Plugins))
+	for i, plugin := range c.Plugins {
+		if strings.TrimSpace(plugin.Name) == "" {
+			return fmt.Errorf("第 %d 个插件: 名称不能为空", i+1)
+		}
+		if _, ok := names[plugin.Name]; ok {
+			return fmt.Errorf("插件 '%s': 名称重复", plugin.Name)
+		}
+		names[plugin.Name] = struct{}{}
+		if plugin.Command == "" {
+			return fmt.Errorf("插件 '%s': command 不能为空", plugin.Name)
+		}
+		if len(plugin.Hosts) == 0 {
+			return fmt.Errorf("插件 '%s': 至少需要声明一个 host", plugin.Name)
+		}
+		for _, pattern := range plugin.Hosts {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("插件 '%s': 无效的 host 模式 '%s'", plugin.Name, pattern)
+			}
+		}
+	}
+	return nil
+}
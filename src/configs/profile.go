@@ -0,0 +1,114 @@
+package configs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 配置来源标识，用于说明某个生效值来自哪一层级
+const (
+	ConfigSourceGlobal   = "global"
+	ConfigSourcePlatform = "platform"
+	ConfigSourceRoom     = "room"
+
+	// configSourceProfilePrefix 配置模板来源前缀，完整格式为 "profile:<模板名>"
+	configSourceProfilePrefix = "profile:"
+	// configSourceTagPrefix 标签来源前缀，完整格式为 "tag:<标签名>"，目前仅用于通知规则
+	configSourceTagPrefix = "tag:"
+)
+
+// ConfigProfile 可复用的命名配置模板
+// 直播间或平台可以通过 profiles 字段引用一个或多个模板，
+// 避免在大量直播间中重复填写相同的覆盖配置
+type ConfigProfile struct {
+	OverridableConfig `yaml:",inline" json:",inline"`
+	Description       string `yaml:"description,omitempty" json:"description,omitempty"` // 模板说明
+}
+
+// ProfileConfigSource 返回配置模板对应的来源标识
+func ProfileConfigSource(name string) string {
+	return configSourceProfilePrefix + name
+}
+
+// IsProfileConfigSource 判断来源标识是否来自配置模板，并返回模板名
+func IsProfileConfigSource(source string) (string, bool) {
+	if !strings.HasPrefix(source, configSourceProfilePrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(source, configSourceProfilePrefix), true
+}
+
+// applyProfiles 按顺序应用引用的配置模板，后面的模板覆盖前面的模板
+// 不存在的模板会被忽略（加载时由 ValidateProfiles 报告）
+func (r *ResolvedConfig) applyProfiles(profiles map[string]ConfigProfile, names []string) {
+	for _, name := range names {
+		profile, ok := profiles[name]
+		if !ok {
+			continue
+		}
+		r.applyOverridesFrom(&profile.OverridableConfig, ProfileConfigSource(name))
+	}
+}
+
+// ProfileUsage 描述某个配置模板被哪些平台和直播间引用
+type ProfileUsage struct {
+	Platforms []string `json:"platforms"`
+	Rooms     []string `json:"rooms"`
+}
+
+// GetProfileUsage 统计指定配置模板的引用情况
+func (c *Config) GetProfileUsage(name string) ProfileUsage {
+	usage := ProfileUsage{
+		Platforms: []string{},
+		Rooms:     []string{},
+	}
+	for platformKey, platformConfig := range c.PlatformConfigs {
+		if containsString(platformConfig.Profiles, name) {
+			usage.Platforms = append(usage.Platforms, platformKey)
+		}
+	}
+	sort.Strings(usage.Platforms)
+	for _, room := range c.LiveRooms {
+		if containsString(room.Profiles, name) {
+			usage.Rooms = append(usage.Rooms, room.Url)
+		}
+	}
+	return usage
+}
+
+// ValidateProfiles 验证配置模板本身及所有引用是否有效
+func (c *Config) ValidateProfiles() error {
+	for name, profile := range c.Profiles {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("配置模板名称不能为空")
+		}
+		if profile.Interval != nil && *profile.Interval <= 0 {
+			return fmt.Errorf("配置模板 '%s': 检测间隔必须大于 0", name)
+		}
+	}
+	for platformKey, platformConfig := range c.PlatformConfigs {
+		for _, name := range platformConfig.Profiles {
+			if _, ok := c.Profiles[name]; !ok {
+				return fmt.Errorf("平台 '%s': 引用的配置模板 '%s' 不存在", platformKey, name)
+			}
+		}
+	}
+	for _, room := range c.LiveRooms {
+		for _, name := range room.Profiles {
+			if _, ok := c.Profiles[name]; !ok {
+				return fmt.Errorf("直播间 '%s': 引用的配置模板 '%s' 不存在", room.Url, name)
+			}
+		}
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
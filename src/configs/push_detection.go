@@ -0,0 +1,51 @@
+package configs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bililive-go/bililive-go/src/pkg/pushdetect"
+)
+
+// PushDetection 推送开播检测配置
+// 平台支持时订阅直播间的推送通道（如 B站直播间 websocket），收到开播/下播消息时立即处理，
+// 推送通道连接正常时放慢轮询，连接断开时恢复按 interval 轮询
+type PushDetection struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// PollIntervalSec 推送通道连接正常时的轮询间隔（秒，默认 300），用于补充推送消息遗漏的状态变化
+	PollIntervalSec int `yaml:"poll_interval_sec" json:"poll_interval_sec"`
+	// MaxConnections 推送通道的最大连接数（默认 200），超出的直播间继续按 interval 轮询
+	// B站每个连接只能订阅一个直播间，即最多 max_connections 个 B站直播间使用推送检测，
+	// 未分配连接的直播间数见 /api/push-detection 的 stats.unassigned
+	MaxConnections int `yaml:"max_connections" json:"max_connections"`
+}
+
+var defaultPushDetection = PushDetection{
+	Enable:          false,
+	PollIntervalSec: 300,
+	MaxConnections:  pushdetect.DefaultMaxConns,
+}
+
+// GetPollInterval 返回推送通道连接正常时的轮询间隔
+func (p PushDetection) GetPollInterval() time.Duration {
+	if p.PollIntervalSec <= 0 {
+		return time.Duration(defaultPushDetection.PollIntervalSec) * time.Second
+	}
+	return time.Duration(p.PollIntervalSec) * time.Second
+}
+
+// ValidatePushDetection 验证推送开播检测配置
+func (c *Config) ValidatePushDetection() error {
+	if c.PushDetection.PollIntervalSec < 0 {
+		return fmt.Errorf("推送开播检测的轮询间隔不能为负数")
+	}
+	if c.PushDetection.MaxConnections < 0 {
+		return fmt.Errorf("推送开播检测的最大连接数不能为负数")
+	}
+	return nil
+}
+
+// syncPushDetection 同步推送通道的最大连接数
+func (c *Config) syncPushDetection() {
+	pushdetect.GetHub().SetMaxConns(c.PushDetection.MaxConnections)
+}
@@ -0,0 +1,149 @@
+package configs
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/bililive-go/bililive-go/src/pkg/ratelimit"
+)
+
+// RateLimitBucket 平台某类接口的令牌桶配置
+// 令牌按 interval_sec 匀速补充，最多积攒 burst 个，每次请求消耗一个令牌
+type RateLimitBucket struct {
+	// IntervalSec 补充一个令牌的间隔（秒），即长期平均请求间隔，最小 1 秒
+	IntervalSec float64 `yaml:"interval_sec" json:"interval_sec"`
+	// Burst 桶容量，空闲后允许连续发出的请求数，最小 1
+	Burst int `yaml:"burst" json:"burst"`
+}
+
+// minRateLimitIntervalSec 配置了令牌桶时补充间隔的最小值，不允许无限制高频访问
+const minRateLimitIntervalSec = 1
+
+// toBucketConfig 转换为限制器使用的配置，补充间隔和容量不低于最小值
+func (b RateLimitBucket) toBucketConfig() ratelimit.BucketConfig {
+	interval := max(b.IntervalSec, minRateLimitIntervalSec)
+	return ratelimit.BucketConfig{
+		Interval: time.Duration(interval * float64(time.Second)),
+		Burst:    max(b.Burst, 1),
+	}
+}
+
+// GetPlatformRateLimit 返回平台某类接口生效的令牌桶配置，没有任何配置时返回 false，表示不限制访问频率
+// 优先级：平台 rate_limits（info 兼容 min_access_interval_sec）> 全局 rate_limits
+func (c *Config) GetPlatformRateLimit(platformKey string, endpoint ratelimit.Endpoint) (RateLimitBucket, bool) {
+	pc := c.PlatformConfigs[platformKey]
+	bucket, ok := pc.RateLimits[string(endpoint)]
+	if !ok && endpoint == ratelimit.EndpointInfo && pc.MinAccessIntervalSec > 0 {
+		// 尚未迁移的 min_access_interval_sec
+		bucket, ok = RateLimitBucket{IntervalSec: float64(pc.MinAccessIntervalSec), Burst: 1}, true
+	}
+	if !ok {
+		if bucket, ok = c.RateLimits[string(endpoint)]; !ok {
+			return RateLimitBucket{}, false
+		}
+	}
+	bucket.IntervalSec = max(bucket.IntervalSec, minRateLimitIntervalSec)
+	bucket.Burst = max(bucket.Burst, 1)
+	return bucket, true
+}
+
+// GetPlatformMinAccessInterval 返回指定平台开播状态检测的平均请求间隔（秒，向上取整）
+// 强制最小值为 1 秒，不允许无限制高频访问
+func (c *Config) GetPlatformMinAccessInterval(platformName string) int {
+	bucket, ok := c.GetPlatformRateLimit(platformName, ratelimit.EndpointInfo)
+	if !ok {
+		return minRateLimitIntervalSec
+	}
+	return int(math.Ceil(bucket.IntervalSec))
+}
+
+// migrateRateLimits 将已废弃的 min_access_interval_sec 迁移为 rate_limits.info（容量为 1，与原来的最小间隔行为一致）
+func (c *Config) migrateRateLimits() {
+	for platformKey, pc := range c.PlatformConfigs {
+		if pc.MinAccessIntervalSec <= 0 {
+			continue
+		}
+		if _, ok := pc.RateLimits[string(ratelimit.EndpointInfo)]; !ok {
+			limits := make(map[string]RateLimitBucket, len(pc.RateLimits)+1)
+			for endpoint, bucket := range pc.RateLimits {
+				limits[endpoint] = bucket
+			}
+			limits[string(ratelimit.EndpointInfo)] = RateLimitBucket{IntervalSec: float64(pc.MinAccessIntervalSec), Burst: 1}
+			pc.RateLimits = limits
+		}
+		pc.MinAccessIntervalSec = 0
+		c.PlatformConfigs[platformKey] = pc
+	}
+}
+
+// validateRateLimits 验证一组令牌桶配置
+func validateRateLimits(limits map[string]RateLimitBucket) error {
+	for endpoint, bucket := range limits {
+		if !slices.Contains(ratelimit.Endpoints, ratelimit.Endpoint(endpoint)) {
+			return fmt.Errorf("未知的接口类别 '%s'，可选值为 info、stream、cookie", endpoint)
+		}
+		if bucket.IntervalSec < 0 {
+			return fmt.Errorf("接口类别 '%s' 的令牌补充间隔不能为负数", endpoint)
+		}
+		if bucket.Burst < 0 {
+			return fmt.Errorf("接口类别 '%s' 的令牌桶容量不能为负数", endpoint)
+		}
+	}
+	return nil
+}
+
+// ValidateRateLimits 验证全局和各平台的令牌桶配置
+func (c *Config) ValidateRateLimits() error {
+	if err := validateRateLimits(c.RateLimits); err != nil {
+		return fmt.Errorf("访问频率限制: %w", err)
+	}
+	for platformKey, pc := range c.PlatformConfigs {
+		if err := validateRateLimits(pc.RateLimits); err != nil {
+			return fmt.Errorf("平台 '%s' 的访问频率限制: %w", platformKey, err)
+		}
+		if pc.RateLimitWeight != nil && *pc.RateLimitWeight < 1 {
+			return fmt.Errorf("平台 '%s' 的排队权重必须大于 0", platformKey)
+		}
+	}
+	for _, room := range c.LiveRooms {
+		if room.RateLimitWeight != nil && *room.RateLimitWeight < 1 {
+			return fmt.Errorf("直播间 '%s' 的排队权重必须大于 0", room.Url)
+		}
+	}
+	return nil
+}
+
+// syncPlatformRateLimits 同步令牌桶配置到全局限制器，没有配置的接口类别不限制访问频率
+func (c *Config) syncPlatformRateLimits() {
+	rateLimiter := ratelimit.GetGlobalRateLimiter()
+	rateLimiter.SetDefaultBuckets(c.rateLimitBuckets(""))
+
+	// 设置平台覆盖配置，清除配置中不再存在的平台
+	stale := make(map[string]bool)
+	for _, platformKey := range rateLimiter.GetPlatformsWithOverrides() {
+		stale[platformKey] = true
+	}
+	for platformKey, pc := range c.PlatformConfigs {
+		if len(pc.RateLimits) == 0 && pc.MinAccessIntervalSec <= 0 {
+			continue
+		}
+		rateLimiter.SetPlatformBuckets(platformKey, c.rateLimitBuckets(platformKey))
+		delete(stale, platformKey)
+	}
+	for platformKey := range stale {
+		rateLimiter.SetPlatformBuckets(platformKey, nil)
+	}
+}
+
+// rateLimitBuckets 返回平台各接口类别生效的令牌桶配置，只包含配置了令牌桶的接口类别
+func (c *Config) rateLimitBuckets(platformKey string) map[ratelimit.Endpoint]ratelimit.BucketConfig {
+	buckets := make(map[ratelimit.Endpoint]ratelimit.BucketConfig, len(ratelimit.Endpoints))
+	for _, endpoint := range ratelimit.Endpoints {
+		if bucket, ok := c.GetPlatformRateLimit(platformKey, endpoint); ok {
+			buckets[endpoint] = bucket.toBucketConfig()
+		}
+	}
+	return buckets
+}
@@ -0,0 +1,25 @@
+package configs
+
+import "fmt"
+
+// RecordingLimit 同时录制数量限制
+// 达到上限后开播的直播间按 recording_priority 排队，有空位时优先级高的先开始录制
+type RecordingLimit struct {
+	// MaxConcurrent 最多同时录制的直播间数量，0 表示不限制
+	MaxConcurrent int `yaml:"max_concurrent" json:"max_concurrent"`
+	// Preempt 达到上限时，优先级更高的直播间是否抢占优先级最低的录制，被抢占的直播间重新排队
+	Preempt bool `yaml:"preempt" json:"preempt"`
+}
+
+var defaultRecordingLimit = RecordingLimit{
+	MaxConcurrent: 0,
+	Preempt:       true,
+}
+
+// ValidateRecordingLimit 验证同时录制数量限制
+func (c *Config) ValidateRecordingLimit() error {
+	if c.RecordingLimit.MaxConcurrent < 0 {
+		return fmt.Errorf("最大同时录制数量不能为负数")
+	}
+	return nil
+}
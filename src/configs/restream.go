@@ -0,0 +1,58 @@
+package configs
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// RestreamTarget 转推目标，录制的同时将同一路流推送到其他 RTMP 服务器
+type RestreamTarget struct {
+	// Name 目标名称，用于日志和状态展示，默认使用目标服务器地址
+	Name     string `yaml:"name,omitempty" json:"name,omitempty"`
+	Url      string `yaml:"url" json:"url"` // rtmp:// 或 rtmps:// 推流地址
+	Disabled bool   `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+// GetName 返回转推目标名称
+func (t RestreamTarget) GetName() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	if u, err := url.Parse(t.Url); err == nil {
+		return u.Host
+	}
+	return t.Url
+}
+
+// EnabledRestreamTargets 返回直播间启用的转推目标
+func (l *LiveRoom) EnabledRestreamTargets() []RestreamTarget {
+	var targets []RestreamTarget
+	for _, t := range l.Restream {
+		if !t.Disabled {
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
+
+// ValidateRestream 验证直播间的转推目标
+func (c *Config) ValidateRestream() error {
+	for _, room := range c.LiveRooms {
+		names := make(map[string]struct{}, len(room.Restream))
+		for _, t := range room.Restream {
+			u, err := url.Parse(t.Url)
+			if err != nil || u.Host == "" {
+				return fmt.Errorf("直播间 %s: 无效的转推地址 '%s'", room.Url, t.Url)
+			}
+			if scheme := strings.ToLower(u.Scheme); scheme != "rtmp" && scheme != "rtmps" {
+				return fmt.Errorf("直播间 %s: 转推地址只支持 rtmp:// 或 rtmps://", room.Url)
+			}
+			if _, ok := names[t.GetName()]; ok {
+				return fmt.Errorf("直播间 %s: 转推目标 '%s' 重复", room.Url, t.GetName())
+			}
+			names[t.GetName()] = struct{}{}
+		}
+	}
+	return nil
+}
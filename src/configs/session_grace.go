@@ -0,0 +1,48 @@
+package configs
+
+import (
+	"fmt"
+	"time"
+)
+
+// SessionGrace 直播会话宽限期配置
+// 检测到下播后先进入宽限期：录制器继续重试、会话保持打开、加快轮询，
+// 宽限期内重新开播视为同一场直播（合并为一个会话，只发送一次开播和下播通知），宽限期结束仍未开播才真正下播
+type SessionGrace struct {
+	// PeriodSec 宽限期（秒，默认 0 即关闭），0 表示检测到下播立即结束会话；开启后下播通知和录制收尾会延后相应时长
+	PeriodSec int `yaml:"period_sec" json:"period_sec"`
+	// PollIntervalSec 宽限期内的轮询间隔（秒，默认 5），仍受平台访问频率限制
+	PollIntervalSec int `yaml:"poll_interval_sec" json:"poll_interval_sec"`
+}
+
+var defaultSessionGrace = SessionGrace{
+	PeriodSec:       0,
+	PollIntervalSec: 5,
+}
+
+// GetPeriod 返回宽限期，关闭时返回 0
+func (g SessionGrace) GetPeriod() time.Duration {
+	if g.PeriodSec <= 0 {
+		return 0
+	}
+	return time.Duration(g.PeriodSec) * time.Second
+}
+
+// GetPollInterval 返回宽限期内的轮询间隔
+func (g SessionGrace) GetPollInterval() time.Duration {
+	if g.PollIntervalSec <= 0 {
+		return time.Duration(defaultSessionGrace.PollIntervalSec) * time.Second
+	}
+	return time.Duration(g.PollIntervalSec) * time.Second
+}
+
+// ValidateSessionGrace 验证直播会话宽限期配置
+func (c *Config) ValidateSessionGrace() error {
+	if c.SessionGrace.PeriodSec < 0 {
+		return fmt.Errorf("直播会话宽限期不能为负数")
+	}
+	if c.SessionGrace.PollIntervalSec < 0 {
+		return fmt.Errorf("宽限期内的轮询间隔不能为负数")
+	}
+	return nil
+}
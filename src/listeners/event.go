@@ -9,6 +9,8 @@ const (
 	ListenStop               events.EventType = "ListenStop"
 	LiveStart                events.EventType = "LiveStart"
 	LiveEnd                  events.EventType = "LiveEnd"
+	LiveInterrupted          events.EventType = "LiveInterrupted" // 直播中断，进入宽限期
+	LiveResumed              events.EventType = "LiveResumed"     // 宽限期内直播恢复，继续同一个会话
 	RoomNameChanged          events.EventType = "RoomNameChanged"
 	RoomInitializingFinished events.EventType = "RoomInitializingFinished"
 )
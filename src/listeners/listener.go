@@ -3,15 +3,17 @@ package listeners
 
 import (
 	"context"
+	"errors"
+	"net/url"
 	"sync/atomic"
 	"time"
 
 	"github.com/bililive-go/bililive-go/src/configs"
-	"github.com/bililive-go/bililive-go/src/consts"
 	"github.com/bililive-go/bililive-go/src/instance"
 	"github.com/bililive-go/bililive-go/src/live"
 	applog "github.com/bililive-go/bililive-go/src/log"
 	"github.com/bililive-go/bililive-go/src/notify"
+	"github.com/bililive-go/bililive-go/src/pkg/accounts"
 	"github.com/bililive-go/bililive-go/src/pkg/events"
 	bilisentry "github.com/bililive-go/bililive-go/src/pkg/sentry"
 )
@@ -52,6 +54,12 @@ type listener struct {
 	stop      chan struct{}
 	runCtx    context.Context    // 用于控制 run 循环中的等待
 	runCancel context.CancelFunc // 取消 runCtx
+	// unsubscribe 取消订阅直播间信息，Live 支持订阅时由集中调度器周期性请求，不再占用 run 循环的 goroutine
+	unsubscribe func()
+
+	// 直播中断的宽限期：宽限期内恢复视为同一场直播，只在 processInfo 中访问
+	graceUntil time.Time // 宽限期结束时间，零值表示不在宽限期
+	flapCount  int       // 本场直播中断后恢复的次数
 }
 
 func (l *listener) Start() error {
@@ -62,6 +70,10 @@ func (l *listener) Start() error {
 
 	l.ed.DispatchEvent(events.NewEvent(ListenStart, l.Live))
 	l.refresh()
+	if subscriber, ok := l.Live.(live.InfoSubscriber); ok {
+		l.unsubscribe = subscriber.SubscribeInfo(l.onInfo)
+		return nil
+	}
 	bilisentry.Go(func() { l.run() })
 	return nil
 }
@@ -72,13 +84,41 @@ func (l *listener) Close() {
 	}
 	l.ed.DispatchEvent(events.NewEvent(ListenStop, l.Live))
 	l.runCancel() // 取消 run 循环中的等待
+	if l.unsubscribe != nil {
+		l.unsubscribe()
+	}
 	close(l.stop)
 }
 
+// onInfo 处理集中调度器周期性请求的结果
+func (l *listener) onInfo(info *live.Info, err error) {
+	if atomic.LoadUint32(&l.state) == stopped || l.runCtx.Err() != nil {
+		return
+	}
+	if err != nil {
+		l.Live.GetLogger().
+			WithError(err).
+			WithField("url", l.Live.GetRawUrl()).
+			Error("failed to load room info")
+		l.handleInfoError(err)
+		return
+	}
+	l.processInfo(info)
+}
+
 // sendLiveNotification 发送直播状态变更通知
-func (l *listener) sendLiveNotification(hostName, status string) {
+func (l *listener) sendLiveNotification(info *live.Info, hostName, event string) {
 	// 发送通知
-	if err := notify.SendNotification(l.Live.GetLogger(), hostName, l.Live.GetPlatformCNName(), l.Live.GetRawUrl(), status); err != nil {
+	msg := &notify.Message{
+		Event:     event,
+		HostName:  hostName,
+		RoomName:  info.RoomName,
+		Platform:  l.Live.GetPlatformCNName(),
+		LiveURL:   l.Live.GetRawUrl(),
+		CoverURL:  info.Cover,
+		FlapCount: l.flapCount,
+	}
+	if err := notify.Send(context.Background(), l.Live.GetLogger(), msg); err != nil {
 		l.Live.GetLogger().WithError(err).WithField("host", hostName).Error("failed to send notification")
 	}
 }
@@ -91,11 +131,47 @@ func (l *listener) refresh() {
 			WithError(err).
 			WithField("url", l.Live.GetRawUrl()).
 			Error("failed to load room info")
+		l.handleInfoError(err)
 		return
 	}
 	l.processInfo(info)
 }
 
+// handleInfoError 处理获取直播间信息时的错误
+// 请求被风控拦截时记录当前账号进入冷却，并重新解析账号，平台开启 account_rotation 时会切换到其他账号
+func (l *listener) handleInfoError(err error) {
+	if !errors.Is(err, live.ErrRiskControl) {
+		return
+	}
+	u, parseErr := url.Parse(l.Live.GetRawUrl())
+	if parseErr != nil {
+		return
+	}
+	previous := ""
+	if opts := l.Live.GetOptions(); opts != nil {
+		previous = opts.Account
+	}
+	accounts.GetPool().ReportRiskControl(u.Host, previous)
+
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil || !cfg.IsAccountRotationEnabled(configs.GetPlatformKeyFromUrl(u.String())) {
+		return
+	}
+	room, roomErr := cfg.GetLiveRoomByUrl(l.Live.GetRawUrl())
+	if roomErr != nil {
+		return
+	}
+	if updateErr := l.Live.UpdateLiveOptionsbyConfig(l.runCtx, room); updateErr != nil {
+		return
+	}
+	if opts := l.Live.GetOptions(); opts != nil && opts.Account != previous {
+		l.Live.GetLogger().
+			WithField("from", previous).
+			WithField("to", opts.Account).
+			Warn("account blocked by risk control, switched to another account")
+	}
+}
+
 func (l *listener) run() {
 	// 使用 GetInfoWithInterval 来处理等待和请求
 	// 它会自动获取配置的间隔时间，并在尊重平台速率限制的前提下等待后发送请求
@@ -115,6 +191,7 @@ func (l *listener) run() {
 					WithError(err).
 					WithField("url", l.Live.GetRawUrl()).
 					Error("failed to load room info")
+				l.handleInfoError(err)
 				continue
 			}
 			l.processInfo(info)
@@ -149,18 +226,43 @@ func (l *listener) processInfo(info *live.Info) {
 	switch l.status.Diff(latestStatus) {
 	case 0:
 		isStatusChanged = false
+		if !info.Status && !l.graceUntil.IsZero() && !time.Now().Before(l.graceUntil) {
+			// 宽限期结束仍未恢复，真正下播
+			l.endGracePeriod()
+			isStatusChanged = true
+			evtTyp = LiveEnd
+			logInfo = "Live end"
+			fields["flap_count"] = l.flapCount
+			l.sendLiveNotification(info, hostName, notify.EventLiveEnd)
+		}
 	case statusToTrueEvt:
+		if !l.graceUntil.IsZero() {
+			// 宽限期内恢复，继续同一场直播，不再发送开播通知
+			l.endGracePeriod()
+			l.flapCount++
+			evtTyp = LiveResumed
+			logInfo = "Live resumed"
+			fields["flap_count"] = l.flapCount
+			break
+		}
+		l.flapCount = 0
 		l.Live.SetLastStartTime(time.Now())
 		evtTyp = LiveStart
 		logInfo = "Live Start"
 		// 发送开播提醒和录像通知
-		l.sendLiveNotification(hostName, consts.LiveStatusStart)
+		l.sendLiveNotification(info, hostName, notify.EventLiveStart)
 
 	case statusToFalseEvt:
+		if l.startGracePeriod() {
+			// 进入宽限期，录制器继续重试，宽限期结束仍未恢复才下播
+			evtTyp = LiveInterrupted
+			logInfo = "Live interrupted"
+			break
+		}
 		evtTyp = LiveEnd
 		logInfo = "Live end"
 		// 发送结束直播提醒和录像通知
-		l.sendLiveNotification(hostName, consts.LiveStatusStop)
+		l.sendLiveNotification(info, hostName, notify.EventLiveEnd)
 	case roomNameChangedEvt:
 		cfg := configs.GetCurrentConfig()
 		if cfg == nil {
@@ -177,3 +279,28 @@ func (l *listener) processInfo(info *live.Info) {
 		applog.GetLogger().WithFields(fields).Info(logInfo)
 	}
 }
+
+// startGracePeriod 检测到下播时进入宽限期并加快轮询，未开启宽限期时返回 false
+func (l *listener) startGracePeriod() bool {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		return false
+	}
+	period := cfg.SessionGrace.GetPeriod()
+	if period <= 0 {
+		return false
+	}
+	l.graceUntil = time.Now().Add(period)
+	if poller, ok := l.Live.(live.GracePoller); ok {
+		poller.SetGracePeriod(l.graceUntil)
+	}
+	return true
+}
+
+// endGracePeriod 宽限期结束（恢复或真正下播），恢复正常轮询间隔
+func (l *listener) endGracePeriod() {
+	l.graceUntil = time.Time{}
+	if poller, ok := l.Live.(live.GracePoller); ok {
+		poller.SetGracePeriod(time.Time{})
+	}
+}
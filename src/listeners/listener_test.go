@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/bluele/gcache"
 	"github.com/sirupsen/logrus"
@@ -79,6 +80,61 @@ func TestRefresh(t *testing.T) {
 	assert.False(t, l.status.roomStatus)
 }
 
+func TestRefreshGracePeriod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ed := evtmock.NewMockDispatcher(ctrl)
+	cfg := configs.NewConfig()
+	cfg.SessionGrace.PeriodSec = 60
+	configs.SetCurrentConfig(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = context.WithValue(ctx, instance.Key, &instance.Instance{
+		EventDispatcher: ed,
+	})
+	log.New(ctx)
+	live := livemock.NewMockLive(ctrl)
+	testLogger := livelogger.New(1024, logrus.Fields{"test": "listener"})
+	live.EXPECT().GetLogger().Return(testLogger).AnyTimes()
+	live.EXPECT().GetRawUrl().Return("").AnyTimes()
+	live.EXPECT().GetPlatformCNName().Return("platform").AnyTimes()
+	l := NewListener(ctx, live).(*listener)
+
+	// false -> true：正常开播，只设置一次开播时间
+	live.EXPECT().GetInfo().Return(&livepkg.Info{Status: true}, nil)
+	live.EXPECT().SetLastStartTime(gomock.Any()).Times(1)
+	ed.EXPECT().DispatchEvent(events.NewEvent(LiveStart, live))
+	l.refresh()
+
+	// true -> false：进入宽限期
+	live.EXPECT().GetInfo().Return(&livepkg.Info{Status: false}, nil)
+	ed.EXPECT().DispatchEvent(events.NewEvent(LiveInterrupted, live))
+	l.refresh()
+	assert.False(t, l.graceUntil.IsZero())
+
+	// 宽限期内仍未开播，不下播
+	live.EXPECT().GetInfo().Return(&livepkg.Info{Status: false}, nil)
+	l.refresh()
+
+	// false -> true：宽限期内恢复，继续同一场直播
+	live.EXPECT().GetInfo().Return(&livepkg.Info{Status: true}, nil)
+	ed.EXPECT().DispatchEvent(events.NewEvent(LiveResumed, live))
+	l.refresh()
+	assert.True(t, l.graceUntil.IsZero())
+	assert.Equal(t, 1, l.flapCount)
+
+	// 再次中断，宽限期结束后下播
+	live.EXPECT().GetInfo().Return(&livepkg.Info{Status: false}, nil)
+	ed.EXPECT().DispatchEvent(events.NewEvent(LiveInterrupted, live))
+	l.refresh()
+	l.graceUntil = time.Now().Add(-time.Second)
+	live.EXPECT().GetInfo().Return(&livepkg.Info{Status: false}, nil)
+	ed.EXPECT().DispatchEvent(events.NewEvent(LiveEnd, live))
+	l.refresh()
+	assert.True(t, l.graceUntil.IsZero())
+	assert.Equal(t, 1, l.flapCount)
+}
+
 func TestRefreshWithError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -0,0 +1,331 @@
+// Package apiadapter 实现由配置驱动的声明式 JSON API 平台
+// 每个 api_adapters 条目描述如何从直播间 URL 提取房间号、请求哪个接口，以及从响应的哪些 gjson 路径读取字段
+package apiadapter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/hr3lxphr6j/requests"
+	"github.com/tidwall/gjson"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/live/internal"
+)
+
+func init() {
+	live.RegisterResolver(resolve)
+}
+
+// resolve 根据当前配置中的适配器声明匹配直播间 URL，外部插件优先
+func resolve(u *url.URL) (live.Builder, bool) {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		return nil, false
+	}
+	if _, ok := cfg.FindPluginForHost(u.Host); ok {
+		return nil, false
+	}
+	adapter, ok := cfg.FindApiAdapterForHost(u.Host)
+	if !ok {
+		return nil, false
+	}
+	return &builder{name: adapter.Name}, true
+}
+
+type builder struct {
+	name string
+}
+
+func (b *builder) Build(url *url.URL) (live.Live, error) {
+	return &Live{
+		BaseLive: internal.NewBaseLive(url),
+		name:     b.name,
+	}, nil
+}
+
+// Live 由声明式 API 适配器提供信息的直播间
+type Live struct {
+	internal.BaseLive
+	name string
+}
+
+// templateData 请求模板可用的字段
+type templateData struct {
+	RoomID  string
+	Url     string
+	Cookies string
+	Quality int
+}
+
+// config 每次调用时从当前配置读取适配器声明，修改配置后无需重建直播间
+func (l *Live) config() (configs.ApiAdapterConfig, error) {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		return configs.ApiAdapterConfig{}, errors.New("config not initialized")
+	}
+	adapter, ok := cfg.GetApiAdapter(l.name)
+	if !ok {
+		return configs.ApiAdapterConfig{}, fmt.Errorf("api adapter %s not found", l.name)
+	}
+	if adapter.Disabled {
+		return configs.ApiAdapterConfig{}, fmt.Errorf("api adapter %s is disabled", l.name)
+	}
+	return adapter, nil
+}
+
+func (l *Live) GetPlatformCNName() string {
+	if adapter, err := l.config(); err == nil {
+		return adapter.GetCNName()
+	}
+	return l.name
+}
+
+func (l *Live) GetInfo() (*live.Info, error) {
+	adapter, err := l.config()
+	if err != nil {
+		return nil, err
+	}
+	data, err := l.templateData(adapter)
+	if err != nil {
+		return nil, err
+	}
+	body, err := l.request(&adapter.Info, data)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := adapter.InfoFields
+	info := &live.Info{
+		Live:      l,
+		Status:    isLive(gjson.GetBytes(body, fields.Live), fields.LiveValues),
+		AudioOnly: l.Options.AudioOnly,
+	}
+	if fields.RoomName != "" {
+		info.RoomName = gjson.GetBytes(body, fields.RoomName).String()
+	}
+	if fields.HostName != "" {
+		info.HostName = gjson.GetBytes(body, fields.HostName).String()
+	}
+	if fields.Cover != "" {
+		info.Cover = gjson.GetBytes(body, fields.Cover).String()
+	}
+	if fields.CustomLiveId != "" {
+		info.CustomLiveId = gjson.GetBytes(body, fields.CustomLiveId).String()
+	}
+	return info, nil
+}
+
+func (l *Live) GetStreamInfos() ([]*live.StreamUrlInfo, error) {
+	adapter, err := l.config()
+	if err != nil {
+		return nil, err
+	}
+	data, err := l.templateData(adapter)
+	if err != nil {
+		return nil, err
+	}
+	req := adapter.Streams
+	if req == nil {
+		req = &adapter.Info
+	}
+	body, err := l.request(req, data)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string, len(adapter.StreamHeaders))
+	for k, v := range adapter.StreamHeaders {
+		if headers[k], err = render(v, data); err != nil {
+			return nil, err
+		}
+	}
+
+	fields := adapter.StreamFields
+	items := []gjson.Result{gjson.ParseBytes(body)}
+	if fields.List != "" {
+		items = gjson.GetBytes(body, fields.List).Array()
+	}
+	infos := make([]*live.StreamUrlInfo, 0, len(items))
+	for _, item := range items {
+		info, err := parseStream(item, fields, headers)
+		if err != nil {
+			return nil, err
+		}
+		if info != nil {
+			infos = append(infos, info)
+		}
+	}
+	if len(infos) == 0 {
+		return nil, fmt.Errorf("api adapter %s: no stream found", l.name)
+	}
+	return infos, nil
+}
+
+// templateData 从直播间 URL 中提取房间号并准备模板字段
+func (l *Live) templateData(adapter configs.ApiAdapterConfig) (templateData, error) {
+	data := templateData{Url: l.GetRawUrl()}
+	if l.Options != nil {
+		data.Quality = l.Options.Quality
+		if l.Options.Cookies != nil {
+			pairs := make([]string, 0)
+			for _, c := range l.Options.Cookies.Cookies(l.Url) {
+				pairs = append(pairs, c.Name+"="+c.Value)
+			}
+			data.Cookies = strings.Join(pairs, "; ")
+		}
+	}
+	if adapter.RoomIDPattern == "" {
+		data.RoomID = strings.Trim(l.Url.Path, "/")
+		return data, nil
+	}
+	re, err := regexp.Compile(adapter.RoomIDPattern)
+	if err != nil {
+		return data, err
+	}
+	match := re.FindStringSubmatch(data.Url)
+	if match == nil {
+		return data, live.ErrRoomUrlIncorrect
+	}
+	index := re.SubexpIndex("room_id")
+	if index < 0 {
+		index = 1
+	}
+	data.RoomID = match[index]
+	return data, nil
+}
+
+// request 按模板发送请求并返回响应体
+func (l *Live) request(req *configs.ApiAdapterRequest, data templateData) ([]byte, error) {
+	apiUrl, err := render(req.Url, data)
+	if err != nil {
+		return nil, err
+	}
+	opts := []requests.RequestOption{live.CommonUserAgent}
+	for k, v := range req.Headers {
+		value, err := render(v, data)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, requests.Header(k, value))
+	}
+	if req.SendCookies && data.Cookies != "" {
+		opts = append(opts, requests.Header("Cookie", data.Cookies))
+	}
+	if req.Body != "" {
+		body, err := render(req.Body, data)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, requests.Body(strings.NewReader(body)))
+	}
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	resp, err := l.RequestSession.Request(method, apiUrl, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, live.ErrRoomNotExist
+	}
+	if err := live.DetectRiskControl(l.name, resp.StatusCode, nil); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("api adapter %s: unexpected status code %d", l.name, resp.StatusCode)
+	}
+	body, err := resp.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	if !gjson.ValidBytes(body) {
+		return nil, fmt.Errorf("api adapter %s: response is not valid json", l.name)
+	}
+	if req.OkPath != "" {
+		if got := gjson.GetBytes(body, req.OkPath).String(); got != req.OkValue {
+			return nil, fmt.Errorf("api adapter %s: %s = %q, expected %q", l.name, req.OkPath, got, req.OkValue)
+		}
+	}
+	return body, nil
+}
+
+func render(text string, data templateData) (string, error) {
+	tmpl, err := template.New("").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// isLive 判断开播字段是否表示正在直播
+func isLive(value gjson.Result, liveValues []string) bool {
+	if len(liveValues) == 0 {
+		return value.Bool()
+	}
+	for _, v := range liveValues {
+		if value.String() == v {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStream 从一个响应元素中读取流信息，没有流地址时返回 nil
+func parseStream(item gjson.Result, fields configs.ApiAdapterStreamFields, headers map[string]string) (*live.StreamUrlInfo, error) {
+	rawUrl := item.Get(fields.Url).String()
+	if rawUrl == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+	get := func(path string) gjson.Result {
+		if path == "" {
+			return gjson.Result{}
+		}
+		return item.Get(path)
+	}
+	info := &live.StreamUrlInfo{
+		Url:       u,
+		Quality:   get(fields.Quality).String(),
+		Format:    get(fields.Format).String(),
+		Codec:     get(fields.Codec).String(),
+		Width:     int(get(fields.Width).Int()),
+		Height:    int(get(fields.Height).Int()),
+		Bitrate:   int(get(fields.Bitrate).Int()),
+		FrameRate: get(fields.FrameRate).Float(),
+	}
+	info.Name = info.Quality
+	if len(headers) > 0 {
+		info.HeadersForDownloader = headers
+	}
+	attributes := make(map[string]string)
+	if info.Quality != "" {
+		attributes["画质"] = info.Quality
+	}
+	if info.Format != "" {
+		attributes["format"] = info.Format
+	}
+	if info.Codec != "" {
+		attributes["codec"] = info.Codec
+	}
+	if len(attributes) > 0 {
+		info.AttributesForStreamSelect = attributes
+	}
+	return info, nil
+}
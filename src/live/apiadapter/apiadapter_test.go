@@ -0,0 +1,180 @@
+package apiadapter
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/live"
+)
+
+// newFixtureServer 模拟一个小平台的房间接口和播放地址接口
+func newFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/room", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("id") {
+		case "100":
+			assert.Equal(t, "token=abc", r.Header.Get("Cookie"))
+			assert.Equal(t, "100", r.Header.Get("X-Room"))
+			_, _ = w.Write([]byte(`{"code":0,"data":{"status":"1","title":"测试直播","anchor":{"name":"主播"},"cover":"https://img.example.com/c.jpg"}}`))
+		case "200":
+			_, _ = w.Write([]byte(`{"code":0,"data":{"status":"0","title":"休息中","anchor":{"name":"主播2"}}}`))
+		case "404":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			_, _ = w.Write([]byte(`{"code":1,"msg":"房间不存在"}`))
+		}
+	})
+	mux.HandleFunc("/api/play", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, `{"room":"100"}`, string(body))
+		_, _ = w.Write([]byte(`{"code":0,"data":{"lines":[
+			{"url":"https://cdn.example.com/100_origin.flv","name":"原画","format":"flv","height":1080},
+			{"url":"https://cdn.example.com/100_720.m3u8","name":"高清","format":"hls","height":720},
+			{"url":"","name":"无效"}
+		]}}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func setupAdapter(t *testing.T, apiBase string, separateStreams bool) {
+	t.Helper()
+	adapter := configs.ApiAdapterConfig{
+		Name:          "example",
+		CNName:        "示例平台",
+		Hosts:         []string{"live.example.com"},
+		RoomIDPattern: `live\.example\.com/(?:room/)?(?P<room_id>\d+)`,
+		Info: configs.ApiAdapterRequest{
+			Url:         apiBase + "/api/room?id={{ .RoomID }}",
+			Headers:     map[string]string{"X-Room": "{{ .RoomID }}"},
+			SendCookies: true,
+			OkPath:      "code",
+			OkValue:     "0",
+		},
+		InfoFields: configs.ApiAdapterInfoFields{
+			Live:       "data.status",
+			LiveValues: []string{"1"},
+			RoomName:   "data.title",
+			HostName:   "data.anchor.name",
+			Cover:      "data.cover",
+		},
+		StreamFields: configs.ApiAdapterStreamFields{
+			List:    "data.lines",
+			Url:     "url",
+			Quality: "name",
+			Format:  "format",
+			Height:  "height",
+		},
+		StreamHeaders: map[string]string{"Referer": "{{ .Url }}"},
+	}
+	if separateStreams {
+		adapter.Streams = &configs.ApiAdapterRequest{
+			Url:    apiBase + "/api/play",
+			Method: "post",
+			Body:   `{"room":"{{ .RoomID }}"}`,
+		}
+	}
+	cfg := configs.NewConfig()
+	cfg.Cookies = map[string]string{"live.example.com": "token=abc"}
+	cfg.ApiAdapters = []configs.ApiAdapterConfig{adapter}
+	require.NoError(t, cfg.ValidateApiAdapters())
+	configs.SetCurrentConfig(cfg)
+}
+
+func newTestLive(t *testing.T, rawUrl string) live.Live {
+	t.Helper()
+	u, err := url.Parse(rawUrl)
+	require.NoError(t, err)
+	b, ok := resolve(u)
+	require.True(t, ok)
+	l, err := b.Build(u)
+	require.NoError(t, err)
+	require.NoError(t, l.UpdateLiveOptionsbyConfig(t.Context(), &configs.LiveRoom{Url: rawUrl}))
+	return l
+}
+
+func TestApiAdapterGetInfo(t *testing.T) {
+	server := newFixtureServer(t)
+	setupAdapter(t, server.URL, true)
+
+	l := newTestLive(t, "https://live.example.com/room/100")
+	assert.Equal(t, "示例平台", l.GetPlatformCNName())
+	assert.Equal(t, "example", configs.GetPlatformKeyFromUrl(l.GetRawUrl()))
+
+	info, err := l.GetInfo()
+	require.NoError(t, err)
+	assert.True(t, info.Status)
+	assert.Equal(t, "测试直播", info.RoomName)
+	assert.Equal(t, "主播", info.HostName)
+	assert.Equal(t, "https://img.example.com/c.jpg", info.Cover)
+
+	info, err = newTestLive(t, "https://live.example.com/200").GetInfo()
+	require.NoError(t, err)
+	assert.False(t, info.Status)
+	assert.Equal(t, "主播2", info.HostName)
+}
+
+func TestApiAdapterGetStreamInfos(t *testing.T) {
+	server := newFixtureServer(t)
+	setupAdapter(t, server.URL, true)
+
+	streams, err := newTestLive(t, "https://live.example.com/100").GetStreamInfos()
+	require.NoError(t, err)
+	require.Len(t, streams, 2)
+	assert.Equal(t, "https://cdn.example.com/100_origin.flv", streams[0].Url.String())
+	assert.Equal(t, "原画", streams[0].Quality)
+	assert.Equal(t, 1080, streams[0].Height)
+	assert.Equal(t, "https://live.example.com/100", streams[0].HeadersForDownloader["Referer"])
+	assert.Equal(t, map[string]string{"画质": "高清", "format": "hls"}, streams[1].AttributesForStreamSelect)
+}
+
+func TestApiAdapterErrors(t *testing.T) {
+	server := newFixtureServer(t)
+	setupAdapter(t, server.URL, false)
+
+	_, err := newTestLive(t, "https://live.example.com/404").GetInfo()
+	assert.True(t, errors.Is(err, live.ErrRoomNotExist))
+
+	_, err = newTestLive(t, "https://live.example.com/300").GetInfo()
+	assert.ErrorContains(t, err, "expected \"0\"")
+
+	_, err = newTestLive(t, "https://live.example.com/abc").GetInfo()
+	assert.True(t, errors.Is(err, live.ErrRoomUrlIncorrect))
+
+	// 未配置 streams 请求时从房间接口读取，房间接口中没有流地址
+	_, err = newTestLive(t, "https://live.example.com/200").GetStreamInfos()
+	assert.ErrorContains(t, err, "no stream found")
+
+	_, ok := resolve(&url.URL{Host: "www.other.com"})
+	assert.False(t, ok)
+}
+
+func TestValidateApiAdapters(t *testing.T) {
+	cfg := configs.NewConfig()
+	cfg.ApiAdapters = []configs.ApiAdapterConfig{{
+		Name:          "bad",
+		Hosts:         []string{"a.com"},
+		RoomIDPattern: `\d+`,
+		Info:          configs.ApiAdapterRequest{Url: "https://a.com"},
+		InfoFields:    configs.ApiAdapterInfoFields{Live: "live"},
+		StreamFields:  configs.ApiAdapterStreamFields{Url: "url"},
+	}}
+	assert.ErrorContains(t, cfg.ValidateApiAdapters(), "分组")
+
+	cfg.ApiAdapters[0].RoomIDPattern = `(\d+)`
+	assert.NoError(t, cfg.ValidateApiAdapters())
+
+	cfg.ApiAdapters[0].Info.Url = "https://a.com/{{ .RoomID"
+	assert.Error(t, cfg.ValidateApiAdapters())
+}
@@ -0,0 +1,97 @@
+package bilibili
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hr3lxphr6j/requests"
+	"github.com/tidwall/gjson"
+
+	"github.com/bililive-go/bililive-go/src/live"
+)
+
+// statusInfoApiUrl 按主播 UID 批量查询直播间状态
+var statusInfoApiUrl = "https://api.live.bilibili.com/room/v1/Room/get_status_info_by_uids"
+
+// maxBatchSize 单次批量查询的最大 UID 数量
+const maxBatchSize = 50
+
+// BatchKey 解析出主播 UID 后才能批量查询；接口不需要登录，所有直播间可以合并到同一分组
+func (l *Live) BatchKey() string {
+	if l.getUID() == "" {
+		return ""
+	}
+	return "status_info"
+}
+
+// MaxBatchSize 单次批量查询的最大直播间数量
+func (l *Live) MaxBatchSize() int {
+	return maxBatchSize
+}
+
+// GetInfoBatch 通过 get_status_info_by_uids 一次获取多个直播间的标题、开播状态、封面和主播名
+func (l *Live) GetInfoBatch(lives []live.Live) ([]*live.Info, error) {
+	uids := make([]int64, 0, len(lives))
+	for _, item := range lives {
+		if bl, ok := item.(*Live); ok {
+			if uid, err := strconv.ParseInt(bl.getUID(), 10, 64); err == nil {
+				uids = append(uids, uid)
+			}
+		}
+	}
+	if len(uids) == 0 {
+		return make([]*live.Info, len(lives)), nil
+	}
+
+	resp, err := l.RequestSession.Post(statusInfoApiUrl, live.CommonUserAgent, requests.JSON(map[string]any{"uids": uids}))
+	if err != nil {
+		return nil, err
+	}
+	body, err := resp.Bytes()
+	if rcErr := live.DetectRiskControl(platformKey, resp.StatusCode, body); rcErr != nil {
+		return nil, rcErr
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, live.ErrInternalError
+	}
+	if err != nil {
+		return nil, err
+	}
+	if code := gjson.GetBytes(body, "code").Int(); code != 0 {
+		return nil, live.ErrInternalError
+	}
+
+	data := gjson.GetBytes(body, "data")
+	infos := make([]*live.Info, len(lives))
+	for i, item := range lives {
+		bl, ok := item.(*Live)
+		if !ok || bl.getUID() == "" {
+			continue
+		}
+		room := data.Get(bl.getUID())
+		if !room.Exists() {
+			continue
+		}
+		infos[i] = &live.Info{
+			Live:      bl,
+			HostName:  room.Get("uname").String(),
+			RoomName:  room.Get("title").String(),
+			Status:    room.Get("live_status").Int() == 1,
+			AudioOnly: bl.Options.AudioOnly,
+			Cover:     room.Get("cover_from_user").String(),
+		}
+	}
+	return infos, nil
+}
+
+func (l *Live) getUID() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.uid
+}
+
+func (l *Live) setUID(uid string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.uid = uid
+}
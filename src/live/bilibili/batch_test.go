@@ -0,0 +1,117 @@
+package bilibili
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bililive-go/bililive-go/src/live"
+)
+
+// statusInfoFixture get_status_info_by_uids 的响应示例，UID 3 不在结果中
+const statusInfoFixture = `{
+	"code": 0,
+	"msg": "success",
+	"data": {
+		"1": {"title": "直播中", "room_id": 100, "uid": 1, "live_status": 1, "uname": "主播一", "cover_from_user": "https://i0.hdslb.com/1.jpg"},
+		"2": {"title": "轮播", "room_id": 200, "uid": 2, "live_status": 2, "uname": "主播二", "cover_from_user": ""}
+	}
+}`
+
+func newFakeStatusServer(t *testing.T, response string) *[]int64 {
+	t.Helper()
+	var requested []int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Uids []int64 `json:"uids"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requested = req.Uids
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+	old := statusInfoApiUrl
+	statusInfoApiUrl = srv.URL
+	t.Cleanup(func() { statusInfoApiUrl = old })
+	return &requested
+}
+
+func newTestLive(t *testing.T, roomID, uid string) *Live {
+	t.Helper()
+	u, err := url.Parse("https://live.bilibili.com/" + roomID)
+	require.NoError(t, err)
+	l, err := new(builder).Build(u)
+	require.NoError(t, err)
+	bl := l.(*Live)
+	bl.Options = live.MustNewOptions()
+	bl.realID = roomID
+	bl.setUID(uid)
+	return bl
+}
+
+func TestGetInfoBatch(t *testing.T) {
+	requested := newFakeStatusServer(t, statusInfoFixture)
+	a, b, c := newTestLive(t, "100", "1"), newTestLive(t, "200", "2"), newTestLive(t, "300", "3")
+	noUID := newTestLive(t, "400", "")
+	assert.Equal(t, "status_info", a.BatchKey())
+	assert.Equal(t, "", noUID.BatchKey())
+
+	infos, err := a.GetInfoBatch([]live.Live{a, b, c, noUID})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, *requested)
+	require.Len(t, infos, 4)
+
+	assert.True(t, infos[0].Status)
+	assert.Equal(t, "直播中", infos[0].RoomName)
+	assert.Equal(t, "主播一", infos[0].HostName)
+	assert.Equal(t, "https://i0.hdslb.com/1.jpg", infos[0].Cover)
+	assert.Same(t, a, infos[0].Live)
+
+	assert.False(t, infos[1].Status, "轮播不视为开播")
+	assert.Nil(t, infos[2], "缺少的直播间回退为单独请求")
+	assert.Nil(t, infos[3])
+}
+
+func TestGetInfoBatchRiskControl(t *testing.T) {
+	newFakeStatusServer(t, `{"code":-412,"message":"请求被拦截"}`)
+	a := newTestLive(t, "100", "1")
+	_, err := a.GetInfoBatch([]live.Live{a})
+	assert.ErrorIs(t, err, live.ErrRiskControl)
+	var rcErr *live.RiskControlError
+	require.ErrorAs(t, err, &rcErr)
+	assert.Equal(t, platformKey, rcErr.Platform)
+	assert.Equal(t, int64(-412), rcErr.Code)
+}
+
+func TestDetectRiskControl(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		statusCode int
+		body       string
+		want       bool
+	}{
+		{"ok", http.StatusOK, `{"code":0}`, false},
+		{"not found", http.StatusOK, `{"code":60004}`, false},
+		{"code -352", http.StatusOK, `{"code":-352,"message":"-352"}`, true},
+		{"code -412", http.StatusOK, `{"code":-412,"message":"请求被拦截"}`, true},
+		{"http 412", http.StatusPreconditionFailed, ``, true},
+		{"http 429", http.StatusTooManyRequests, ``, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := live.DetectRiskControl(platformKey, tc.statusCode, []byte(tc.body))
+			if !tc.want {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, live.ErrRiskControl)
+			var rcErr *live.RiskControlError
+			require.ErrorAs(t, err, &rcErr)
+			assert.Equal(t, tc.statusCode, rcErr.StatusCode)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/hr3lxphr6j/requests"
 	"github.com/tidwall/gjson"
@@ -20,6 +21,8 @@ const (
 	domain = "live.bilibili.com"
 	cnName = "哔哩哔哩"
 
+	platformKey = "bilibili"
+
 	roomInitUrl     = "https://api.live.bilibili.com/room/v1/Room/room_init"
 	roomApiUrl      = "https://api.live.bilibili.com/room/v1/Room/get_info"
 	userApiUrl      = "https://api.live.bilibili.com/live_user/v1/UserInfo/get_anchor_in_room"
@@ -31,6 +34,7 @@ const (
 
 func init() {
 	live.Register(domain, new(builder))
+	live.RegisterRiskControlDetector(platformKey, detectRiskControl)
 }
 
 type builder struct{}
@@ -44,6 +48,26 @@ func (b *builder) Build(url *url.URL) (live.Live, error) {
 type Live struct {
 	internal.BaseLive
 	realID string
+
+	mu  sync.Mutex
+	uid string // 主播 UID，用于批量查询直播状态
+}
+
+// isRiskControlCode 判断 B站接口返回码是否表示请求被风控拦截
+// -352 为风控校验失败，-412 为请求被拦截
+func isRiskControlCode(code int64) bool {
+	return code == -352 || code == -412
+}
+
+// detectRiskControl B站的风控检测器，根据接口返回码判断
+func detectRiskControl(statusCode int, body []byte) *live.RiskControlError {
+	if code := gjson.GetBytes(body, "code").Int(); isRiskControlCode(code) {
+		return &live.RiskControlError{
+			Code:   code,
+			Reason: fmt.Sprintf("code %d: %s", code, gjson.GetBytes(body, "message").String()),
+		}
+	}
+	return nil
 }
 
 func (l *Live) parseRealId() error {
@@ -60,14 +84,18 @@ func (l *Live) parseRealId() error {
 	if err != nil {
 		return err
 	}
+	body, err := resp.Bytes()
+	if rcErr := live.DetectRiskControl(platformKey, resp.StatusCode, body); rcErr != nil {
+		return rcErr
+	}
 	if resp.StatusCode != http.StatusOK {
 		return live.ErrRoomNotExist
 	}
-	body, err := resp.Bytes()
 	if err != nil || gjson.GetBytes(body, "code").Int() != 0 {
 		return live.ErrRoomNotExist
 	}
 	l.realID = gjson.GetBytes(body, "data.room_id").String()
+	l.setUID(gjson.GetBytes(body, "data.uid").String())
 	return nil
 }
 
@@ -93,14 +121,17 @@ func (l *Live) GetInfo() (info *live.Info, err error) {
 	if err != nil {
 		return nil, err
 	}
+	body, err := resp.Bytes()
+	if rcErr := live.DetectRiskControl(platformKey, resp.StatusCode, body); rcErr != nil {
+		return nil, rcErr
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, live.ErrRoomNotExist
 	}
-	body, err := resp.Bytes()
 	if err != nil {
 		return nil, err
 	}
-	if gjson.GetBytes(body, "code").Int() != 0 {
+	if code := gjson.GetBytes(body, "code").Int(); code != 0 {
 		return nil, live.ErrRoomNotExist
 	}
 
@@ -109,6 +140,7 @@ func (l *Live) GetInfo() (info *live.Info, err error) {
 		RoomName:  gjson.GetBytes(body, "data.title").String(),
 		Status:    gjson.GetBytes(body, "data.live_status").Int() == 1,
 		AudioOnly: l.Options.AudioOnly,
+		Cover:     gjson.GetBytes(body, "data.user_cover").String(),
 	}
 
 	resp, err = l.RequestSession.Get(userApiUrl, live.CommonUserAgent, requests.Query("roomid", l.realID))
@@ -0,0 +1,268 @@
+package bilibili
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+
+	"github.com/bililive-go/bililive-go/src/pkg/cookiemonitor"
+	"github.com/bililive-go/bililive-go/src/pkg/utils"
+)
+
+// Cookie 检查与刷新相关接口，定义为变量便于测试时替换为 fake 服务
+var (
+	navApiUrl         = "https://api.bilibili.com/x/web-interface/nav"
+	passportBaseUrl   = "https://passport.bilibili.com"
+	correspondBaseUrl = "https://www.bilibili.com"
+)
+
+// correspondPublicKey 用于生成 correspondPath 的 RSA 公钥
+const correspondPublicKey = `-----BEGIN PUBLIC KEY-----
+MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQDLgd2OAkcGVtoE3ThUREbio0Eg
+Uc/prcajMKXvkCKFCWhJYJcLkcM2DKKcSeFpD/j6Boy538YXnR6VhcuUJOhH2x71
+nzPjfdTcqMz7djHum0qSZA0AyCBDABUqCrfNgCiJ00Ra7GmRj+YCK1NJEuewlb40
+JNrRuoEUXpabUzGB8QIDAQAB
+-----END PUBLIC KEY-----`
+
+var refreshCsrfRegexp = regexp.MustCompile(`<div id="1-name">([^<]+)</div>`)
+
+func init() {
+	cookiemonitor.Register(domain, &cookieChecker{})
+}
+
+// cookieChecker B站 Cookie 登录状态检查与自动刷新
+// 刷新流程参考 https://socialsisteryi.github.io/bilibili-API-collect/docs/login/cookie_refresh.html
+type cookieChecker struct {
+	client *http.Client
+}
+
+func (c *cookieChecker) httpClient() *http.Client {
+	if c.client == nil {
+		c.client = utils.CreateDefaultClient()
+	}
+	return c.client
+}
+
+// Check 通过 nav 接口检查登录状态，并查询是否需要刷新 Cookie
+func (c *cookieChecker) Check(ctx context.Context, cookie string) (*cookiemonitor.CheckResult, error) {
+	body, _, err := c.do(ctx, http.MethodGet, navApiUrl, cookie, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &cookiemonitor.CheckResult{}
+	switch code := gjson.GetBytes(body, "code").Int(); code {
+	case 0:
+		result.LoggedIn = gjson.GetBytes(body, "data.isLogin").Bool()
+	case -101:
+		// 账号未登录
+		return result, nil
+	default:
+		return nil, fmt.Errorf("nav api error code %d: %s", code, gjson.GetBytes(body, "message").String())
+	}
+	if !result.LoggedIn {
+		return result, nil
+	}
+	result.UserName = gjson.GetBytes(body, "data.uname").String()
+
+	kvs := parseCookieKVs(cookie)
+	result.ExpiresAt = sessdataExpiry(kvs["SESSDATA"])
+	if csrf := kvs["bili_jct"]; csrf != "" {
+		if needRefresh, _, err := c.cookieInfo(ctx, cookie, csrf); err == nil {
+			result.NeedRefresh = needRefresh
+		}
+	}
+	return result, nil
+}
+
+// Refresh 使用 refresh_token 刷新 Cookie，成功后确认刷新使旧 Cookie 失效
+func (c *cookieChecker) Refresh(ctx context.Context, cookie, refreshToken string) (string, string, error) {
+	kvs := parseCookieKVs(cookie)
+	csrf := kvs["bili_jct"]
+	if csrf == "" {
+		return "", "", fmt.Errorf("bili_jct not found in cookie")
+	}
+
+	_, timestamp, err := c.cookieInfo(ctx, cookie, csrf)
+	if err != nil {
+		return "", "", err
+	}
+	correspondPath, err := getCorrespondPath(timestamp)
+	if err != nil {
+		return "", "", err
+	}
+
+	// 获取 refresh_csrf
+	body, _, err := c.do(ctx, http.MethodGet, correspondBaseUrl+"/correspond/1/"+correspondPath, cookie, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get refresh_csrf: %w", err)
+	}
+	match := refreshCsrfRegexp.FindSubmatch(body)
+	if match == nil {
+		return "", "", fmt.Errorf("refresh_csrf not found")
+	}
+	refreshCsrf := strings.TrimSpace(string(match[1]))
+
+	// 刷新 Cookie
+	body, setCookies, err := c.do(ctx, http.MethodPost, passportBaseUrl+"/x/passport-login/web/cookie/refresh", cookie, url.Values{
+		"csrf":          {csrf},
+		"refresh_csrf":  {refreshCsrf},
+		"source":        {"main_web"},
+		"refresh_token": {refreshToken},
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to refresh cookie: %w", err)
+	}
+	if code := gjson.GetBytes(body, "code").Int(); code != 0 {
+		return "", "", fmt.Errorf("refresh cookie error code %d: %s", code, gjson.GetBytes(body, "message").String())
+	}
+	newRefreshToken := gjson.GetBytes(body, "data.refresh_token").String()
+	if len(setCookies) == 0 || newRefreshToken == "" {
+		return "", "", fmt.Errorf("refresh cookie response missing cookies or refresh_token")
+	}
+	newCookie := mergeCookie(cookie, setCookies)
+
+	// 确认刷新，使旧的 refresh_token 失效；失败不影响新 Cookie 的使用
+	newCsrf := parseCookieKVs(newCookie)["bili_jct"]
+	body, _, err = c.do(ctx, http.MethodPost, passportBaseUrl+"/x/passport-login/web/confirm/refresh", newCookie, url.Values{
+		"csrf":          {newCsrf},
+		"refresh_token": {refreshToken},
+	})
+	if err != nil || gjson.GetBytes(body, "code").Int() != 0 {
+		logrus.WithError(err).WithField("response", string(body)).Warn("failed to confirm bilibili cookie refresh")
+	}
+	return newCookie, newRefreshToken, nil
+}
+
+// cookieInfo 查询是否需要刷新 Cookie，同时返回服务器时间戳（毫秒）
+func (c *cookieChecker) cookieInfo(ctx context.Context, cookie, csrf string) (bool, int64, error) {
+	body, _, err := c.do(ctx, http.MethodGet, passportBaseUrl+"/x/passport-login/web/cookie/info?csrf="+url.QueryEscape(csrf), cookie, nil)
+	if err != nil {
+		return false, 0, err
+	}
+	if code := gjson.GetBytes(body, "code").Int(); code != 0 {
+		return false, 0, fmt.Errorf("cookie info error code %d: %s", code, gjson.GetBytes(body, "message").String())
+	}
+	return gjson.GetBytes(body, "data.refresh").Bool(), gjson.GetBytes(body, "data.timestamp").Int(), nil
+}
+
+func (c *cookieChecker) do(ctx context.Context, method, rawUrl, cookie string, form url.Values) ([]byte, []*http.Cookie, error) {
+	var reqBody io.Reader
+	if form != nil {
+		reqBody = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawUrl, reqBody)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", biliWebAgent)
+	req.Header.Set("Cookie", cookie)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return body, resp.Cookies(), nil
+}
+
+// getCorrespondPath 使用 RSA-OAEP 加密 "refresh_{timestamp}" 生成 correspondPath
+func getCorrespondPath(timestamp int64) (string, error) {
+	block, _ := pem.Decode([]byte(correspondPublicKey))
+	if block == nil {
+		return "", fmt.Errorf("invalid correspond public key")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return "", fmt.Errorf("correspond public key is not RSA")
+	}
+	encrypted, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, rsaPub, []byte(fmt.Sprintf("refresh_%d", timestamp)), nil)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(encrypted), nil
+}
+
+// sessdataExpiry 从 SESSDATA 中解析过期时间，格式为 "xxx,{unix 时间戳},xxx"（可能经过 URL 编码）
+func sessdataExpiry(sessdata string) time.Time {
+	if decoded, err := url.QueryUnescape(sessdata); err == nil {
+		sessdata = decoded
+	}
+	parts := strings.Split(sessdata, ",")
+	if len(parts) < 2 {
+		return time.Time{}
+	}
+	ts, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || ts <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(ts, 0)
+}
+
+// parseCookieKVs 解析 "k1=v1; k2=v2" 格式的 Cookie 字符串
+func parseCookieKVs(cookie string) map[string]string {
+	kvs := make(map[string]string)
+	for _, item := range strings.Split(cookie, ";") {
+		k, v, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || k == "" {
+			continue
+		}
+		kvs[k] = v
+	}
+	return kvs
+}
+
+// mergeCookie 用响应中的 Set-Cookie 更新 Cookie 字符串，保留原有的键顺序
+func mergeCookie(cookie string, setCookies []*http.Cookie) string {
+	updates := make(map[string]string, len(setCookies))
+	for _, sc := range setCookies {
+		updates[sc.Name] = sc.Value
+	}
+	items := make([]string, 0)
+	for _, item := range strings.Split(cookie, ";") {
+		k, _, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || k == "" {
+			continue
+		}
+		if v, exists := updates[k]; exists {
+			items = append(items, k+"="+v)
+			delete(updates, k)
+			continue
+		}
+		items = append(items, strings.TrimSpace(item))
+	}
+	for _, sc := range setCookies {
+		if v, exists := updates[sc.Name]; exists {
+			items = append(items, sc.Name+"="+v)
+			delete(updates, sc.Name)
+		}
+	}
+	return strings.Join(items, "; ")
+}
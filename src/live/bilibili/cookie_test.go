@@ -0,0 +1,125 @@
+package bilibili
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuthServer 模拟 B站 nav / cookie info / correspond / refresh / confirm 接口
+type fakeAuthServer struct {
+	*httptest.Server
+	loggedIn     bool
+	needRefresh  bool
+	refreshToken string // 服务端期望的 refresh_token
+	confirmed    string // confirm 接口收到的旧 refresh_token
+}
+
+func newFakeAuthServer(t *testing.T) *fakeAuthServer {
+	t.Helper()
+	f := &fakeAuthServer{loggedIn: true, refreshToken: "old_token"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/x/web-interface/nav", func(w http.ResponseWriter, r *http.Request) {
+		if !f.loggedIn {
+			_, _ = w.Write([]byte(`{"code":-101,"message":"账号未登录","data":{"isLogin":false}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":0,"data":{"isLogin":true,"uname":"测试用户"}}`))
+	})
+	mux.HandleFunc("/x/passport-login/web/cookie/info", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("csrf") != "old_jct" && r.URL.Query().Get("csrf") != "new_jct" {
+			_, _ = w.Write([]byte(`{"code":-111,"message":"csrf 校验失败"}`))
+			return
+		}
+		if f.needRefresh {
+			_, _ = w.Write([]byte(`{"code":0,"data":{"refresh":true,"timestamp":1700000000000}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":0,"data":{"refresh":false,"timestamp":1700000000000}}`))
+	})
+	mux.HandleFunc("/correspond/1/", func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path[len("/correspond/1/"):]
+		if b, err := hex.DecodeString(path); err != nil || len(b) != 128 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`<html><div id="1-name">csrf_from_page</div></html>`))
+	})
+	mux.HandleFunc("/x/passport-login/web/cookie/refresh", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("refresh_csrf") != "csrf_from_page" || r.PostForm.Get("refresh_token") != f.refreshToken {
+			_, _ = w.Write([]byte(`{"code":86095,"message":"refresh_csrf 错误或 refresh_token 与 cookie 不匹配"}`))
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "SESSDATA", Value: "new_sess%2C1800000000%2Cabc"})
+		http.SetCookie(w, &http.Cookie{Name: "bili_jct", Value: "new_jct"})
+		_, _ = w.Write([]byte(`{"code":0,"data":{"status":0,"refresh_token":"new_token"}}`))
+	})
+	mux.HandleFunc("/x/passport-login/web/confirm/refresh", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		f.confirmed = r.PostForm.Get("refresh_token")
+		_, _ = w.Write([]byte(`{"code":0}`))
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+
+	oldNav, oldPassport, oldCorrespond := navApiUrl, passportBaseUrl, correspondBaseUrl
+	navApiUrl = f.URL + "/x/web-interface/nav"
+	passportBaseUrl = f.URL
+	correspondBaseUrl = f.URL
+	t.Cleanup(func() {
+		navApiUrl, passportBaseUrl, correspondBaseUrl = oldNav, oldPassport, oldCorrespond
+	})
+	return f
+}
+
+const testCookie = "buvid3=abc; SESSDATA=old_sess%2C1700000000%2Cabc; bili_jct=old_jct; DedeUserID=1"
+
+func TestCookieCheck(t *testing.T) {
+	f := newFakeAuthServer(t)
+	checker := &cookieChecker{client: http.DefaultClient}
+
+	res, err := checker.Check(context.Background(), testCookie)
+	require.NoError(t, err)
+	assert.True(t, res.LoggedIn)
+	assert.Equal(t, "测试用户", res.UserName)
+	assert.Equal(t, time.Unix(1700000000, 0), res.ExpiresAt)
+	assert.False(t, res.NeedRefresh)
+
+	f.needRefresh = true
+	res, err = checker.Check(context.Background(), testCookie)
+	require.NoError(t, err)
+	assert.True(t, res.NeedRefresh)
+
+	f.loggedIn = false
+	res, err = checker.Check(context.Background(), testCookie)
+	require.NoError(t, err)
+	assert.False(t, res.LoggedIn)
+}
+
+func TestCookieRefresh(t *testing.T) {
+	f := newFakeAuthServer(t)
+	f.needRefresh = true
+	checker := &cookieChecker{client: http.DefaultClient}
+
+	newCookie, newToken, err := checker.Refresh(context.Background(), testCookie, "old_token")
+	require.NoError(t, err)
+	assert.Equal(t, "new_token", newToken)
+	assert.Equal(t, "buvid3=abc; SESSDATA=new_sess%2C1800000000%2Cabc; bili_jct=new_jct; DedeUserID=1", newCookie)
+	assert.Equal(t, "old_token", f.confirmed)
+
+	_, _, err = checker.Refresh(context.Background(), testCookie, "wrong_token")
+	assert.Error(t, err)
+}
+
+func TestSessdataExpiry(t *testing.T) {
+	assert.Equal(t, time.Unix(1700000000, 0), sessdataExpiry("abc%2C1700000000%2Cdef"))
+	assert.Equal(t, time.Unix(1700000000, 0), sessdataExpiry("abc,1700000000,def"))
+	assert.True(t, sessdataExpiry("invalid").IsZero())
+}
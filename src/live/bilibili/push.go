@@ -0,0 +1,255 @@
+package bilibili
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hr3lxphr6j/requests"
+	"github.com/tidwall/gjson"
+	"golang.org/x/net/websocket"
+
+	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/pkg/pushdetect"
+	"github.com/bililive-go/bililive-go/src/pkg/ratelimit"
+)
+
+var (
+	// danmuInfoApiUrl 获取直播间消息服务器地址和认证令牌
+	danmuInfoApiUrl = "https://api.live.bilibili.com/xlive/web-room/v1/index/getDanmuInfo"
+	// defaultPushServer 获取消息服务器地址失败时使用的默认地址
+	defaultPushServer = "wss://broadcastlv.chat.bilibili.com/sub"
+)
+
+// 直播间消息服务器协议：每个数据包为 16 字节头部加正文
+// 头部依次为包长度(4)、头部长度(2)、协议版本(2)、操作码(4)、序号(4)
+const (
+	packetHeaderLen = 16
+
+	protoJSON      = 0
+	protoHeartbeat = 1
+	protoZlib      = 2
+
+	opHeartbeat      = 2
+	opHeartbeatReply = 3
+	opMessage        = 5
+	opAuth           = 7
+	opAuthReply      = 8
+
+	pushHeartbeatInterval = 30 * time.Second
+	pushReadTimeout       = 2 * pushHeartbeatInterval
+
+	// maxInflatedSize 解压后的消息上限，压缩数据来自服务器，避免解压出过大的数据
+	maxInflatedSize = 1 << 20
+)
+
+// pushPlatform B站直播间消息推送通道
+type pushPlatform struct {
+	session *requests.Session
+	limiter *ratelimit.PlatformRateLimiter // 为空时使用全局速率限制器
+}
+
+// PushChannel 解析出真实房间号后才能订阅直播间消息
+func (l *Live) PushChannel() (pushdetect.Platform, string, bool) {
+	if l.realID == "" {
+		return nil, "", false
+	}
+	return &pushPlatform{session: l.RequestSession}, l.realID, true
+}
+
+func (p *pushPlatform) Name() string {
+	return domain
+}
+
+// RoomsPerConn B站每个连接只能进入一个直播间，推送检测的直播间数受 push_detection.max_connections 限制
+func (p *pushPlatform) RoomsPerConn() int {
+	return 1
+}
+
+// Serve 连接直播间消息服务器，收到 LIVE / PREPARING 消息时通知开播和下播
+func (p *pushPlatform) Serve(ctx context.Context, rooms []string, ready func(), emit func(room string, living bool)) error {
+	if len(rooms) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	roomID := rooms[0]
+	server, token, err := p.getServer(ctx, roomID)
+	if err != nil {
+		return err
+	}
+
+	config, err := websocket.NewConfig(server, "https://"+domain)
+	if err != nil {
+		return err
+	}
+	config.Header.Set("User-Agent", biliWebAgent)
+	conn, err := config.DialContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	id, _ := strconv.ParseInt(roomID, 10, 64)
+	auth, _ := json.Marshal(map[string]any{
+		"uid":      0,
+		"roomid":   id,
+		"protover": protoZlib,
+		"platform": "web",
+		"type":     2,
+		"key":      token,
+	})
+	if err := websocket.Message.Send(conn, encodePacket(opAuth, auth)); err != nil {
+		return err
+	}
+
+	heartbeatCtx, cancelHeartbeat := context.WithCancel(ctx)
+	defer cancelHeartbeat()
+	go func() {
+		ticker := time.NewTicker(pushHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-heartbeatCtx.Done():
+				return
+			case <-ticker.C:
+				if err := websocket.Message.Send(conn, encodePacket(opHeartbeat, nil)); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(pushReadTimeout))
+		var data []byte
+		if err := websocket.Message.Receive(conn, &data); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		err := walkPackets(data, func(op uint32, body []byte) error {
+			switch op {
+			case opAuthReply:
+				if code := gjson.GetBytes(body, "code").Int(); code != 0 {
+					return fmt.Errorf("auth failed with code %d", code)
+				}
+				ready()
+			case opMessage:
+				switch gjson.GetBytes(body, "cmd").String() {
+				case "LIVE":
+					emit(roomID, true)
+				case "PREPARING":
+					emit(roomID, false)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// getServer 获取消息服务器地址和认证令牌，失败时使用默认地址匿名连接
+// 每次连接和重连都会请求该接口，和其他接口一样经过平台访问频率限制和风控检测，
+// 触发风控时返回错误，由调用方退避重连
+func (p *pushPlatform) getServer(ctx context.Context, roomID string) (string, string, error) {
+	limiter := p.limiter
+	if limiter == nil {
+		limiter = ratelimit.GetGlobalRateLimiter()
+	}
+	if !limiter.Wait(ctx, ratelimit.Request{
+		Platform: platformKey,
+		Endpoint: ratelimit.EndpointInfo,
+		Room:     roomID,
+	}) {
+		return "", "", ctx.Err()
+	}
+	session := p.session
+	if session == nil {
+		session = requests.DefaultSession
+	}
+	resp, err := session.Get(danmuInfoApiUrl, live.CommonUserAgent, requests.Query("id", roomID), requests.Query("type", "0"))
+	if err != nil {
+		return defaultPushServer, "", nil
+	}
+	body, err := resp.Bytes()
+	if err != nil {
+		return defaultPushServer, "", nil
+	}
+	if rcErr := live.DetectRiskControl(platformKey, resp.StatusCode, body); rcErr != nil {
+		limiter.ReportRiskControl(platformKey, cnName, rcErr.Error())
+		return "", "", rcErr
+	}
+	if resp.StatusCode != http.StatusOK || gjson.GetBytes(body, "code").Int() != 0 {
+		return defaultPushServer, "", nil
+	}
+	limiter.ReportSuccess(platformKey)
+	token := gjson.GetBytes(body, "data.token").String()
+	host := gjson.GetBytes(body, "data.host_list.0")
+	if !host.Exists() {
+		return defaultPushServer, token, nil
+	}
+	return fmt.Sprintf("wss://%s:%d/sub", host.Get("host").String(), host.Get("wss_port").Int()), token, nil
+}
+
+// encodePacket 编码一个数据包
+func encodePacket(op uint32, body []byte) []byte {
+	buf := make([]byte, packetHeaderLen, packetHeaderLen+len(body))
+	binary.BigEndian.PutUint32(buf[0:], uint32(packetHeaderLen+len(body)))
+	binary.BigEndian.PutUint16(buf[4:], packetHeaderLen)
+	binary.BigEndian.PutUint16(buf[6:], protoHeartbeat)
+	binary.BigEndian.PutUint32(buf[8:], op)
+	binary.BigEndian.PutUint32(buf[12:], 1)
+	return append(buf, body...)
+}
+
+// walkPackets 遍历一条 websocket 消息中的所有数据包，zlib 压缩的数据包解压后递归处理
+func walkPackets(data []byte, fn func(op uint32, body []byte) error) error {
+	for len(data) >= packetHeaderLen {
+		size := binary.BigEndian.Uint32(data[0:])
+		headerLen := binary.BigEndian.Uint16(data[4:])
+		// 数据包长度来自服务器，头部长度不足时 data 无法前进，必须拒绝
+		if size < packetHeaderLen || headerLen < packetHeaderLen || size < uint32(headerLen) || int(size) > len(data) {
+			return fmt.Errorf("invalid packet size %d (header %d)", size, headerLen)
+		}
+		proto := binary.BigEndian.Uint16(data[6:])
+		op := binary.BigEndian.Uint32(data[8:])
+		body := data[headerLen:size]
+		data = data[size:]
+
+		if proto == protoZlib && op == opMessage {
+			r, err := zlib.NewReader(bytes.NewReader(body))
+			if err != nil {
+				return err
+			}
+			inner, err := io.ReadAll(io.LimitReader(r, maxInflatedSize+1))
+			r.Close()
+			if err != nil {
+				return err
+			}
+			if len(inner) > maxInflatedSize {
+				return fmt.Errorf("inflated packet exceeds %d bytes", maxInflatedSize)
+			}
+			if err := walkPackets(inner, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(op, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package bilibili
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"golang.org/x/net/websocket"
+
+	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/pkg/ratelimit"
+)
+
+// packet 按协议版本编码测试数据包
+func packet(proto uint16, op uint32, body []byte) []byte {
+	buf := encodePacket(op, body)
+	binary.BigEndian.PutUint16(buf[6:], proto)
+	return buf
+}
+
+func zlibPacket(t *testing.T, inner ...[]byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	for _, p := range inner {
+		_, err := w.Write(p)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return packet(protoZlib, opMessage, buf.Bytes())
+}
+
+// newFakePushServer 本地模拟的直播间消息服务器：校验认证包并回复 authReply 后依次发送 messages，然后断开连接
+func newFakePushServer(t *testing.T, roomID int64, authReply string, messages [][]byte) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/danmu_info", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code": -400, "message": "-400"}`))
+	})
+	mux.Handle("/sub", websocket.Handler(func(conn *websocket.Conn) {
+		var data []byte
+		if err := websocket.Message.Receive(conn, &data); err != nil {
+			return
+		}
+		_ = walkPackets(data, func(op uint32, body []byte) error {
+			assert.Equal(t, uint32(opAuth), op)
+			assert.Equal(t, roomID, gjson.GetBytes(body, "roomid").Int())
+			return nil
+		})
+		_ = websocket.Message.Send(conn, packet(protoHeartbeat, opAuthReply, []byte(authReply)))
+		for _, m := range messages {
+			_ = websocket.Message.Send(conn, m)
+		}
+	}))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	oldInfo, oldServer := danmuInfoApiUrl, defaultPushServer
+	danmuInfoApiUrl = srv.URL + "/danmu_info"
+	defaultPushServer = "ws" + strings.TrimPrefix(srv.URL, "http") + "/sub"
+	t.Cleanup(func() { danmuInfoApiUrl, defaultPushServer = oldInfo, oldServer })
+}
+
+func TestWalkPacketsInvalidHeader(t *testing.T) {
+	zeroHeader := make([]byte, packetHeaderLen)
+	shortHeader := packet(protoJSON, opMessage, []byte(`{}`))
+	binary.BigEndian.PutUint16(shortHeader[4:], 4)
+
+	for name, data := range map[string][]byte{
+		"zero length header": zeroHeader,
+		"short header":       shortHeader,
+		"zlib zero header":   zlibPacket(t, zeroHeader),
+	} {
+		t.Run(name, func(t *testing.T) {
+			done := make(chan error, 1)
+			go func() {
+				done <- walkPackets(data, func(uint32, []byte) error { return nil })
+			}()
+			select {
+			case err := <-done:
+				assert.Error(t, err)
+			case <-time.After(time.Second):
+				t.Fatal("walkPackets did not return")
+			}
+		})
+	}
+}
+
+func TestWalkPacketsInflateLimit(t *testing.T) {
+	big := packet(protoJSON, opMessage, bytes.Repeat([]byte(" "), maxInflatedSize))
+	err := walkPackets(zlibPacket(t, big), func(uint32, []byte) error { return nil })
+	assert.ErrorContains(t, err, "exceeds")
+
+	small := packet(protoJSON, opMessage, []byte(`{}`))
+	assert.NoError(t, walkPackets(zlibPacket(t, small), func(uint32, []byte) error { return nil }))
+}
+
+func TestPushServe(t *testing.T) {
+	newFakePushServer(t, 100, `{"code":0}`, [][]byte{
+		zlibPacket(t,
+			packet(protoJSON, opMessage, []byte(`{"cmd":"DANMU_MSG","info":[]}`)),
+			packet(protoJSON, opMessage, []byte(`{"cmd":"LIVE","roomid":100}`)),
+		),
+		packet(protoJSON, opMessage, []byte(`{"cmd":"PREPARING","roomid":"100"}`)),
+	})
+
+	var ready bool
+	var statuses []bool
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := newTestPushPlatform().Serve(ctx, []string{"100"}, func() { ready = true }, func(room string, living bool) {
+		assert.Equal(t, "100", room)
+		statuses = append(statuses, living)
+	})
+	// 服务器发送完消息后断开连接，Serve 返回错误以便重连
+	assert.Error(t, err)
+	assert.NoError(t, ctx.Err())
+	assert.True(t, ready)
+	assert.Equal(t, []bool{true, false}, statuses)
+}
+
+func TestPushServeAuthFailed(t *testing.T) {
+	newFakePushServer(t, 100, `{"code":-101}`, [][]byte{
+		packet(protoJSON, opMessage, []byte(`{"cmd":"LIVE","roomid":100}`)),
+	})
+	err := newTestPushPlatform().Serve(context.Background(), []string{"100"}, func() {
+		t.Error("ready should not be called")
+	}, func(string, bool) {
+		t.Error("emit should not be called")
+	})
+	assert.ErrorContains(t, err, "auth failed")
+}
+
+func TestPushGetServer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "100", r.URL.Query().Get("id"))
+		_, _ = w.Write([]byte(`{"code":0,"data":{"token":"abc","host_list":[{"host":"a.chat.bilibili.com","wss_port":443}]}}`))
+	}))
+	defer srv.Close()
+	old := danmuInfoApiUrl
+	danmuInfoApiUrl = srv.URL
+	defer func() { danmuInfoApiUrl = old }()
+
+	server, token, err := newTestPushPlatform().getServer(context.Background(), "100")
+	assert.NoError(t, err)
+	assert.Equal(t, "wss://a.chat.bilibili.com:443/sub", server)
+	assert.Equal(t, "abc", token)
+}
+
+func TestPushGetServerRiskControl(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+	}))
+	defer srv.Close()
+	old := danmuInfoApiUrl
+	danmuInfoApiUrl = srv.URL
+	defer func() { danmuInfoApiUrl = old }()
+
+	p := newTestPushPlatform()
+	_, _, err := p.getServer(context.Background(), "100")
+	assert.ErrorIs(t, err, live.ErrRiskControl)
+	// 触发风控后平台进入冷却，之后的请求需要等待
+	_, inCooldown := p.limiter.GetPlatformCooldown(platformKey)
+	assert.True(t, inCooldown)
+}
+
+// newTestPushPlatform 使用独立的速率限制器，避免测试之间互相影响
+func newTestPushPlatform() *pushPlatform {
+	return &pushPlatform{limiter: ratelimit.NewPlatformRateLimiter()}
+}
@@ -0,0 +1,202 @@
+// Package direct 实现直接录制流地址的伪平台
+// 直播间 URL 本身就是流地址（rtmp://、http(s)://…flv、http(s)://…m3u8），
+// 适用于录制 CDN 或 OBS 中继地址等不属于任何已支持平台的流，开播状态通过探测流地址得到
+package direct
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/live/internal"
+	"github.com/bililive-go/bililive-go/src/pkg/proxy"
+	"github.com/bililive-go/bililive-go/src/pkg/rtmp"
+)
+
+const (
+	cnName = "直链"
+
+	// probeTimeout 单次探测的超时
+	probeTimeout = 10 * time.Second
+	// rtmpMediaTimeout 开始播放后等待第一个媒体消息的时间
+	rtmpMediaTimeout = 5 * time.Second
+)
+
+func init() {
+	live.RegisterResolver(resolve)
+	RegisterProber("http", probeHTTP)
+	RegisterProber("https", probeHTTP)
+	RegisterProber("rtmp", probeRTMP)
+	RegisterProber("rtmps", probeRTMP)
+}
+
+// resolve 匹配流地址形式的直播间 URL，host 已被外部插件或 API 适配器声明时交给它们处理
+func resolve(u *url.URL) (live.Builder, bool) {
+	if !configs.IsDirectStreamUrl(u) {
+		return nil, false
+	}
+	if cfg := configs.GetCurrentConfig(); cfg != nil {
+		if _, ok := cfg.FindPluginForHost(u.Host); ok {
+			return nil, false
+		}
+		if _, ok := cfg.FindApiAdapterForHost(u.Host); ok {
+			return nil, false
+		}
+	}
+	return new(builder), true
+}
+
+// Prober 探测流地址当前是否可以拉流
+type Prober func(ctx context.Context, u *url.URL, headers map[string]string) (bool, error)
+
+var (
+	probersMu sync.RWMutex
+	probers   = map[string]Prober{}
+)
+
+// RegisterProber 注册某个协议的探测方法，后注册的覆盖先注册的
+func RegisterProber(scheme string, p Prober) {
+	probersMu.Lock()
+	defer probersMu.Unlock()
+	probers[scheme] = p
+}
+
+func getProber(scheme string) (Prober, bool) {
+	probersMu.RLock()
+	defer probersMu.RUnlock()
+	p, ok := probers[strings.ToLower(scheme)]
+	return p, ok
+}
+
+type builder struct{}
+
+func (b *builder) Build(url *url.URL) (live.Live, error) {
+	return &Live{
+		BaseLive: internal.NewBaseLive(url),
+	}, nil
+}
+
+// Live 直接录制流地址的直播间
+type Live struct {
+	internal.BaseLive
+}
+
+func (l *Live) GetPlatformCNName() string {
+	return cnName
+}
+
+func (l *Live) GetInfo() (*live.Info, error) {
+	prober, ok := getProber(l.Url.Scheme)
+	if !ok {
+		return nil, fmt.Errorf("unsupported scheme %s", l.Url.Scheme)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	online, err := prober(ctx, l.Url, l.headers())
+	if err != nil {
+		l.Logger.WithError(err).Debug("stream probe failed, treat as offline")
+	}
+
+	roomName := path.Base(l.Url.Path)
+	if roomName == "." || roomName == "/" {
+		roomName = l.Url.Host
+	}
+	return &live.Info{
+		Live:      l,
+		HostName:  l.Url.Host,
+		RoomName:  roomName,
+		Status:    online,
+		AudioOnly: l.Options.AudioOnly,
+	}, nil
+}
+
+func (l *Live) GetStreamInfos() ([]*live.StreamUrlInfo, error) {
+	format := "flv"
+	switch {
+	case strings.HasPrefix(strings.ToLower(l.Url.Scheme), "rtmp"):
+		format = "rtmp"
+	case strings.HasSuffix(strings.ToLower(l.Url.Path), ".m3u8"):
+		format = "hls"
+	}
+	return []*live.StreamUrlInfo{{
+		Url:                  l.Url,
+		Name:                 "原始流",
+		Format:               format,
+		HeadersForDownloader: l.headers(),
+	}}, nil
+}
+
+// headers 为直链附带该 host 配置的 Cookie
+func (l *Live) headers() map[string]string {
+	if l.Options == nil || l.Options.Cookies == nil {
+		return nil
+	}
+	cookies := l.Options.Cookies.Cookies(l.Url)
+	if len(cookies) == 0 {
+		return nil
+	}
+	pairs := make([]string, 0, len(cookies))
+	for _, c := range cookies {
+		pairs = append(pairs, c.Name+"="+c.Value)
+	}
+	return map[string]string{"Cookie": strings.Join(pairs, "; ")}
+}
+
+// probeHTTP 请求流地址并检查响应开头：FLV 以 "FLV" 签名开头，HLS 播放列表以 "#EXTM3U" 开头
+// 非 2xx 响应视为未开播
+func probeHTTP(ctx context.Context, u *url.URL, headers map[string]string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return false, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false, nil
+	}
+	head, err := bufio.NewReader(resp.Body).Peek(7)
+	if err != nil && len(head) < 3 {
+		return false, err
+	}
+	if bytes.HasPrefix(head, []byte("FLV")) || bytes.HasPrefix(head, []byte("#EXTM3U")) {
+		return true, nil
+	}
+	return false, fmt.Errorf("unrecognized stream header %q", head)
+}
+
+// probeRTMP 使用内置 RTMP 客户端发起播放，收到第一个媒体消息视为开播
+// 部分服务器对不存在的流也会返回 NetStream.Play.Start，因此不能只看播放命令的结果
+func probeRTMP(ctx context.Context, u *url.URL, _ map[string]string) (bool, error) {
+	client, err := rtmp.Dial(ctx, u, rtmp.ClientOptions{Dialer: proxy.DownloadDialContext()})
+	if err != nil {
+		return false, err
+	}
+	defer client.Close()
+	if err := client.Play(ctx); err != nil {
+		if errors.Is(err, rtmp.ErrStreamNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	client.Timeout = rtmpMediaTimeout
+	if _, err := client.ReadMediaMessage(); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
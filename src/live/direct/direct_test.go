@@ -0,0 +1,181 @@
+package direct
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/pkg/rtmp"
+)
+
+func newTestLive(t *testing.T, rawUrl string) live.Live {
+	t.Helper()
+	u, err := url.Parse(rawUrl)
+	require.NoError(t, err)
+	b, ok := resolve(u)
+	require.True(t, ok)
+	l, err := b.Build(u)
+	require.NoError(t, err)
+	require.NoError(t, l.UpdateLiveOptionsbyConfig(t.Context(), &configs.LiveRoom{Url: rawUrl}))
+	return l
+}
+
+func TestDirectHTTPProbe(t *testing.T) {
+	configs.SetCurrentConfig(configs.NewConfig())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/live/on.flv", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte{'F', 'L', 'V', 1, 5, 0, 0, 0, 9})
+	})
+	mux.HandleFunc("/live/on.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("#EXTM3U\n#EXT-X-VERSION:3\n"))
+	})
+	mux.HandleFunc("/live/html.flv", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html>offline</html>"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cases := map[string]bool{
+		"/live/on.flv":   true,
+		"/live/on.m3u8":  true,
+		"/live/off.flv":  false,
+		"/live/html.flv": false,
+	}
+	for path, online := range cases {
+		l := newTestLive(t, server.URL+path)
+		info, err := l.GetInfo()
+		require.NoError(t, err, path)
+		assert.Equal(t, online, info.Status, path)
+	}
+
+	l := newTestLive(t, server.URL+"/live/on.m3u8")
+	assert.Equal(t, configs.PlatformKeyDirect, configs.GetPlatformKeyFromUrl(l.GetRawUrl()))
+	info, _ := l.GetInfo()
+	assert.Equal(t, "on.m3u8", info.RoomName)
+	streams, err := l.GetStreamInfos()
+	require.NoError(t, err)
+	require.Len(t, streams, 1)
+	assert.Equal(t, "hls", streams[0].Format)
+	assert.Equal(t, l.GetRawUrl(), streams[0].Url.String())
+}
+
+// serveRTMPPlay 启动进程内的 RTMP 服务器，握手后依次回应 connect、createStream 和 play，
+// 再发送一个视频消息。客户端的命令顺序固定，这里不解析，直接按顺序回应
+func serveRTMPPlay(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	writeMessage := func(w io.Writer, csid, typeID uint8, streamID uint32, payload []byte) error {
+		const chunkSize = 128
+		header := []byte{csid, 0, 0, 0, byte(len(payload) >> 16), byte(len(payload) >> 8), byte(len(payload)), typeID, 0, 0, 0, 0}
+		binary.LittleEndian.PutUint32(header[8:], streamID)
+		buf := append([]byte(nil), header...)
+		for len(payload) > chunkSize {
+			buf = append(buf, payload[:chunkSize]...)
+			buf = append(buf, 0xc0|csid)
+			payload = payload[chunkSize:]
+		}
+		_, err := w.Write(append(buf, payload...))
+		return err
+	}
+	respond := func(nc net.Conn) error {
+		if _, err := io.ReadFull(nc, make([]byte, 1+1536)); err != nil {
+			return err
+		}
+		s := make([]byte, 1+2*1536)
+		s[0] = 3
+		if _, err := nc.Write(s); err != nil {
+			return err
+		}
+		commands := []struct {
+			streamID uint32
+			values   []any
+		}{
+			{0, []any{"_result", 1.0, rtmp.Object{"fmsVer": "test"}, rtmp.Object{"level": "status", "code": "NetConnection.Connect.Success"}}},
+			{0, []any{"_result", 2.0, nil, 1.0}},
+			{1, []any{"onStatus", 0.0, nil, rtmp.Object{"level": "status", "code": "NetStream.Play.Start"}}},
+		}
+		for _, cmd := range commands {
+			payload, err := rtmp.EncodeAMF0(cmd.values...)
+			if err != nil {
+				return err
+			}
+			if err := writeMessage(nc, 3, rtmp.TypeCommandAMF0, cmd.streamID, payload); err != nil {
+				return err
+			}
+		}
+		if err := writeMessage(nc, 6, rtmp.TypeVideo, 1, []byte{0x17, 0, 0, 0, 0}); err != nil {
+			return err
+		}
+		_, err := io.Copy(io.Discard, nc)
+		return err
+	}
+	go func() {
+		for {
+			nc, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer nc.Close()
+				_ = respond(nc)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestDirectRTMPProbe(t *testing.T) {
+	configs.SetCurrentConfig(configs.NewConfig())
+
+	// 开始播放后收到媒体消息视为开播
+	l := newTestLive(t, "rtmp://"+serveRTMPPlay(t)+"/live/key")
+	info, err := l.GetInfo()
+	require.NoError(t, err)
+	assert.True(t, info.Status)
+	streams, _ := l.GetStreamInfos()
+	assert.Equal(t, "rtmp", streams[0].Format)
+
+	// 端口未监听时视为未开播
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	l = newTestLive(t, "rtmp://"+addr+"/live/key")
+	info, err = l.GetInfo()
+	require.NoError(t, err)
+	assert.False(t, info.Status)
+	assert.Equal(t, "key", info.RoomName)
+	streams, _ = l.GetStreamInfos()
+	assert.Equal(t, "rtmp", streams[0].Format)
+}
+
+func TestDirectResolve(t *testing.T) {
+	cfg := configs.NewConfig()
+	cfg.ApiAdapters = []configs.ApiAdapterConfig{{Name: "example", Hosts: []string{"cdn.example.com"}}}
+	configs.SetCurrentConfig(cfg)
+
+	for rawUrl, ok := range map[string]bool{
+		"https://cdn.other.com/live/a.flv?token=1": true,
+		"https://cdn.other.com/live/a.m3u8":        true,
+		"rtmp://relay.local/live/a":                true,
+		"https://cdn.other.com/live/a":             false,
+		"https://cdn.example.com/live/a.flv":       false,
+	} {
+		u, _ := url.Parse(rawUrl)
+		_, got := resolve(u)
+		assert.Equal(t, ok, got, rawUrl)
+	}
+}
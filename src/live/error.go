@@ -9,4 +9,7 @@ var (
 	ErrRoomUrlIncorrect = errors.New("room url incorrect")
 	ErrInternalError    = errors.New("internal error")
 	ErrNotImplemented   = errors.New("not implemented")
+	// ErrRiskControl 请求被平台风控拦截，开启账号轮换时会切换到其他账号，平台会进入冷却
+	// 平台检测到的风控错误为 *RiskControlError，使用 errors.Is 判断
+	ErrRiskControl = errors.New("blocked by risk control")
 )
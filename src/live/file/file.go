@@ -0,0 +1,108 @@
+// Package file 实现以本地媒体文件模拟直播间的伪平台
+// 直播间 URL 形如 file:///path/to/video.flv?loop=true，文件通过本机 HTTP 服务以直播流的方式提供，
+// 录制和后处理流程与真实直播间完全相同，便于测试后处理流水线
+//
+// 支持的参数：
+//   - loop=true：循环播放，FLV 文件的时间戳会在每轮之间继续递增；默认只播放一次，播放完毕后直播间变为未开播
+//   - realtime=false：不按时间戳节奏发送，尽快发送完整个文件
+package file
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/live/internal"
+)
+
+const cnName = "本地文件"
+
+func init() {
+	live.RegisterResolver(resolve)
+}
+
+func resolve(u *url.URL) (live.Builder, bool) {
+	if !strings.EqualFold(u.Scheme, "file") {
+		return nil, false
+	}
+	return new(builder), true
+}
+
+type builder struct{}
+
+func (b *builder) Build(url *url.URL) (live.Live, error) {
+	return &Live{
+		BaseLive: internal.NewBaseLive(url),
+	}, nil
+}
+
+// Live 以本地文件模拟的直播间
+type Live struct {
+	internal.BaseLive
+}
+
+func (l *Live) GetPlatformCNName() string {
+	return cnName
+}
+
+// FilePath 从 URL 中取出本地文件路径
+// file:///C:/video.flv 的路径为 /C:/video.flv，需要去掉盘符前的斜杠；file://relative/video.flv 视为相对路径
+func FilePath(u *url.URL) string {
+	p := u.Host + u.Path
+	if len(p) >= 3 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+	return filepath.FromSlash(p)
+}
+
+func (l *Live) options() sourceOptions {
+	query := l.Url.Query()
+	opts := sourceOptions{realtime: true}
+	if v, err := strconv.ParseBool(query.Get("loop")); err == nil {
+		opts.loop = v
+	}
+	if v, err := strconv.ParseBool(query.Get("realtime")); err == nil {
+		opts.realtime = v
+	}
+	return opts
+}
+
+func (l *Live) GetInfo() (*live.Info, error) {
+	path := FilePath(l.Url)
+	stat, err := os.Stat(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	info := &live.Info{
+		Live:      l,
+		HostName:  cnName,
+		RoomName:  strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		AudioOnly: l.Options.AudioOnly,
+	}
+	if err == nil && !stat.IsDir() {
+		info.Status = !getServer().finished(string(l.LiveId), stat)
+	}
+	return info, nil
+}
+
+func (l *Live) GetStreamInfos() ([]*live.StreamUrlInfo, error) {
+	path := FilePath(l.Url)
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	streamUrl, err := getServer().register(string(l.LiveId), path, stat, l.options())
+	if err != nil {
+		return nil, err
+	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	return []*live.StreamUrlInfo{{
+		Url:    streamUrl,
+		Name:   filepath.Base(path),
+		Format: format,
+	}}, nil
+}
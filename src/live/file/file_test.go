@@ -0,0 +1,145 @@
+package file
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/live"
+)
+
+// writeTestFLV 生成一个只包含若干视频 tag 的 FLV 文件
+func writeTestFLV(t *testing.T, timestamps ...uint32) string {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.Write([]byte{'F', 'L', 'V', 1, 0x01, 0, 0, 0, 9, 0, 0, 0, 0})
+	for _, ts := range timestamps {
+		data := []byte{0x17, 0x01, 0, 0, 0}
+		tag := []byte{9, 0, 0, byte(len(data)), 0, 0, 0, 0, 0, 0, 0}
+		setTimestamp(tag, ts)
+		buf.Write(tag)
+		buf.Write(data)
+		size := len(tag) + len(data)
+		buf.Write([]byte{0, 0, byte(size >> 8), byte(size)})
+	}
+	path := filepath.Join(t.TempDir(), "sample.flv")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+	return path
+}
+
+func newTestLive(t *testing.T, rawUrl string) live.Live {
+	t.Helper()
+	u, err := url.Parse(rawUrl)
+	require.NoError(t, err)
+	b, ok := resolve(u)
+	require.True(t, ok)
+	l, err := b.Build(u)
+	require.NoError(t, err)
+	require.NoError(t, l.UpdateLiveOptionsbyConfig(t.Context(), &configs.LiveRoom{Url: rawUrl}))
+	return l
+}
+
+func fileUrl(path string, query string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path), RawQuery: query}).String()
+}
+
+func fetch(t *testing.T, u *url.URL) []byte {
+	t.Helper()
+	resp, err := http.Get(u.String())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return body
+}
+
+func TestFilePlayOnce(t *testing.T) {
+	configs.SetCurrentConfig(configs.NewConfig())
+	path := writeTestFLV(t, 0, 40, 80)
+	l := newTestLive(t, fileUrl(path, "realtime=false"))
+	assert.Equal(t, configs.PlatformKeyFile, configs.GetPlatformKeyFromUrl(l.GetRawUrl()))
+
+	info, err := l.GetInfo()
+	require.NoError(t, err)
+	assert.True(t, info.Status)
+	assert.Equal(t, "sample", info.RoomName)
+
+	streams, err := l.GetStreamInfos()
+	require.NoError(t, err)
+	require.Len(t, streams, 1)
+	assert.Equal(t, "flv", streams[0].Format)
+	assert.Equal(t, "127.0.0.1", streams[0].Url.Hostname())
+
+	original, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, fetch(t, streams[0].Url))
+
+	// 播放完毕后直播间变为未开播
+	info, err = l.GetInfo()
+	require.NoError(t, err)
+	assert.False(t, info.Status)
+
+	// 文件被修改后重新开播
+	require.NoError(t, os.WriteFile(path, append(original, 0), 0644))
+	info, err = l.GetInfo()
+	require.NoError(t, err)
+	assert.True(t, info.Status)
+
+	info, err = newTestLive(t, fileUrl(filepath.Join(t.TempDir(), "missing.flv"), "")).GetInfo()
+	require.NoError(t, err)
+	assert.False(t, info.Status)
+}
+
+func TestFileLoopTimestamps(t *testing.T) {
+	path := writeTestFLV(t, 100, 140, 180)
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	// 只读取前两轮
+	w := &limitWriter{limit: 13 + 2*3*20}
+	err = streamFLV(t.Context(), w, f, sourceOptions{loop: true})
+	assert.ErrorIs(t, err, errLimit)
+
+	var timestamps []uint32
+	data := w.buf.Bytes()[13:]
+	for len(data) >= 20 {
+		_, ts, err := readTag(bytes.NewReader(data[:20]))
+		require.NoError(t, err)
+		timestamps = append(timestamps, ts)
+		data = data[20:]
+	}
+	assert.Equal(t, []uint32{100, 140, 180, 220, 260, 300}, timestamps)
+}
+
+func TestFilePath(t *testing.T) {
+	u, _ := url.Parse("file:///C:/videos/a.flv")
+	assert.Equal(t, filepath.FromSlash("C:/videos/a.flv"), FilePath(u))
+	u, _ = url.Parse("file:///data/a.flv?loop=true")
+	assert.Equal(t, filepath.FromSlash("/data/a.flv"), FilePath(u))
+	u, _ = url.Parse("file://videos/a.flv")
+	assert.Equal(t, filepath.FromSlash("videos/a.flv"), FilePath(u))
+}
+
+var errLimit = io.ErrShortWrite
+
+type limitWriter struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > w.limit {
+		return 0, errLimit
+	}
+	return w.buf.Write(p)
+}
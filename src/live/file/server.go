@@ -0,0 +1,265 @@
+package file
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	flvHeaderSize    = 9
+	flvTagHeaderSize = 11
+	flvTagScript     = 18
+
+	// loopGap 循环播放时两轮之间的时间戳间隔（毫秒）
+	loopGap = 40
+)
+
+// sourceOptions 文件的播放方式
+type sourceOptions struct {
+	loop     bool
+	realtime bool
+}
+
+// source 一个已注册的本地文件
+type source struct {
+	path    string
+	modTime time.Time
+	size    int64
+	opts    sourceOptions
+	// done 只播放一次的文件已完整播放过
+	done bool
+}
+
+// streamServer 本机 HTTP 服务，以直播流的方式提供已注册的文件
+type streamServer struct {
+	mu       sync.Mutex
+	listener net.Listener
+	sources  map[string]*source
+}
+
+var (
+	serverOnce    sync.Once
+	defaultServer *streamServer
+)
+
+func getServer() *streamServer {
+	serverOnce.Do(func() {
+		defaultServer = &streamServer{sources: make(map[string]*source)}
+	})
+	return defaultServer
+}
+
+// start 首次使用时在 127.0.0.1 的随机端口上启动服务
+func (s *streamServer) start() error {
+	if s.listener != nil {
+		return nil
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stream/{token}/{name}", s.handleStream)
+	go func() {
+		_ = http.Serve(listener, mux)
+	}()
+	return nil
+}
+
+// register 注册或更新文件并返回拉流地址
+// 文件被修改后重新注册会清除“已播放完毕”状态
+func (s *streamServer) register(token, path string, stat os.FileInfo, opts sourceOptions) (*url.URL, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.start(); err != nil {
+		return nil, err
+	}
+	src, ok := s.sources[token]
+	if !ok || src.path != path || !src.modTime.Equal(stat.ModTime()) || src.size != stat.Size() {
+		src = &source{path: path, modTime: stat.ModTime(), size: stat.Size()}
+		s.sources[token] = src
+	}
+	src.opts = opts
+	return &url.URL{
+		Scheme: "http",
+		Host:   s.listener.Addr().String(),
+		Path:   fmt.Sprintf("/stream/%s/stream%s", token, strings.ToLower(filepath.Ext(path))),
+	}, nil
+}
+
+// finished 判断只播放一次的文件是否已播放完毕，文件被修改后视为未播放
+func (s *streamServer) finished(token string, stat os.FileInfo) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	src, ok := s.sources[token]
+	if !ok || !src.done {
+		return false
+	}
+	return src.modTime.Equal(stat.ModTime()) && src.size == stat.Size()
+}
+
+func (s *streamServer) getSource(token string) (source, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	src, ok := s.sources[token]
+	if !ok {
+		return source{}, false
+	}
+	return *src, true
+}
+
+func (s *streamServer) markDone(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if src, ok := s.sources[token]; ok {
+		src.done = true
+	}
+}
+
+func (s *streamServer) handleStream(w http.ResponseWriter, r *http.Request) {
+	token := r.PathValue("token")
+	src, ok := s.getSource(token)
+	if !ok || (src.done && !src.opts.loop) {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(src.path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	if !strings.EqualFold(filepath.Ext(src.path), ".flv") {
+		// 非 FLV 文件不做节奏控制，按普通文件提供
+		stat, err := f.Stat()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.ServeContent(w, r, filepath.Base(src.path), stat.ModTime(), f)
+		if !src.opts.loop {
+			s.markDone(token)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "video/x-flv")
+	err = streamFLV(r.Context(), w, f, src.opts)
+	if err == nil && !src.opts.loop {
+		s.markDone(token)
+	}
+}
+
+// streamFLV 按时间戳节奏发送 FLV 文件，循环播放时每轮的时间戳接在上一轮之后
+func streamFLV(ctx context.Context, w io.Writer, f io.ReadSeeker, opts sourceOptions) error {
+	header := make([]byte, flvHeaderSize+4)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return err
+	}
+	if string(header[:3]) != "FLV" {
+		return errors.New("not a flv file")
+	}
+	dataOffset := int64(binary.BigEndian.Uint32(header[5:9])) + 4
+	if _, err := w.Write(header[:flvHeaderSize]); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte{0, 0, 0, 0}); err != nil {
+		return err
+	}
+	flusher, _ := w.(http.Flusher)
+
+	start := time.Now()
+	var offset, lastTimestamp uint32
+	// base 文件第一个 tag 的时间戳，节奏控制以它为起点
+	base := int64(-1)
+	for round := 0; ; round++ {
+		if _, err := f.Seek(dataOffset, io.SeekStart); err != nil {
+			return err
+		}
+		for {
+			tag, timestamp, err := readTag(f)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if round > 0 && tag[0]&0x1f == flvTagScript {
+				continue
+			}
+			timestamp += offset
+			setTimestamp(tag, timestamp)
+			lastTimestamp = timestamp
+
+			if base < 0 {
+				base = int64(timestamp)
+			}
+			if opts.realtime {
+				wait := time.Until(start.Add(time.Duration(int64(timestamp)-base) * time.Millisecond))
+				if wait > 0 {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(wait):
+					}
+				}
+			}
+			if _, err := w.Write(tag); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if !opts.loop {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		offset = lastTimestamp + loopGap - uint32(base)
+	}
+}
+
+// readTag 读取一个完整的 tag（包括末尾的 PreviousTagSize），返回 tag 的时间戳
+func readTag(r io.Reader) ([]byte, uint32, error) {
+	head := make([]byte, flvTagHeaderSize)
+	if _, err := io.ReadFull(r, head); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			// 文件末尾的不完整 tag 直接丢弃
+			return nil, 0, io.EOF
+		}
+		return nil, 0, err
+	}
+	size := int(head[1])<<16 | int(head[2])<<8 | int(head[3])
+	tag := make([]byte, flvTagHeaderSize+size+4)
+	copy(tag, head)
+	if _, err := io.ReadFull(r, tag[flvTagHeaderSize:]); err != nil {
+		if err == io.ErrUnexpectedEOF || err == io.EOF {
+			return nil, 0, io.EOF
+		}
+		return nil, 0, err
+	}
+	timestamp := uint32(head[7])<<24 | uint32(head[4])<<16 | uint32(head[5])<<8 | uint32(head[6])
+	return tag, timestamp, nil
+}
+
+func setTimestamp(tag []byte, timestamp uint32) {
+	tag[4] = byte(timestamp >> 16)
+	tag[5] = byte(timestamp >> 8)
+	tag[6] = byte(timestamp)
+	tag[7] = byte(timestamp >> 24)
+}
@@ -2,6 +2,7 @@ package live
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/bililive-go/bililive-go/src/types"
 )
@@ -57,15 +58,22 @@ type Info struct {
 	Status               bool // means isLiving, maybe better to rename it
 	Listening, Recording bool
 	RecordingPreparing   bool // 有 recorder 但尚未真正开始录制（重试中）
-	Initializing         bool
-	CustomLiveId         string
-	AudioOnly            bool
+	// 因达到同时录制上限排队的位置（从 1 开始），0 表示未排队
+	RecordingQueuePosition int
+	Initializing           bool
+	CustomLiveId           string
+	AudioOnly              bool
+	// 直播间封面图片地址（平台支持时填充，用于通知卡片等）
+	Cover string
 	// 最近一次 API 请求的错误信息（用于前端显示错误提示）
 	LastError string
 	// 可用流列表（最近一次获取的）
 	AvailableStreams []*AvailableStreamInfo
 	// 可用流更新时间
 	AvailableStreamsUpdatedAt int64
+	// 当前实际使用的检测间隔（秒）和预测的下次开播时间（开启自适应轮询时填充）
+	EffectiveIntervalSeconds int
+	PredictedNextStartAt     time.Time
 }
 
 type InfoCookie struct {
@@ -85,6 +93,7 @@ func (i *Info) MarshalJSON() ([]byte, error) {
 		Listening                 bool                   `json:"listening"`
 		Recording                 bool                   `json:"recording"`
 		RecordingPreparing        bool                   `json:"recording_preparing,omitempty"`
+		RecordingQueuePosition    int                    `json:"recording_queue_position,omitempty"`
 		Initializing              bool                   `json:"initializing"`
 		LastStartTime             string                 `json:"last_start_time,omitempty"`
 		LastStartTimeUnix         int64                  `json:"last_start_time_unix,omitempty"`
@@ -93,6 +102,9 @@ func (i *Info) MarshalJSON() ([]byte, error) {
 		LastError                 string                 `json:"last_error,omitempty"`
 		AvailableStreams          []*AvailableStreamInfo `json:"available_streams,omitempty"`
 		AvailableStreamsUpdatedAt int64                  `json:"available_streams_updated_at,omitempty"`
+		EffectiveIntervalSeconds  int                    `json:"effective_interval_seconds,omitempty"`
+		PredictedNextStart        string                 `json:"predicted_next_start,omitempty"`
+		PredictedNextStartUnix    int64                  `json:"predicted_next_start_unix,omitempty"`
 	}{
 		Id:                        i.Live.GetLiveId(),
 		LiveUrl:                   i.Live.GetRawUrl(),
@@ -103,12 +115,18 @@ func (i *Info) MarshalJSON() ([]byte, error) {
 		Listening:                 i.Listening,
 		Recording:                 i.Recording,
 		RecordingPreparing:        i.RecordingPreparing,
+		RecordingQueuePosition:    i.RecordingQueuePosition,
 		Initializing:              i.Initializing,
 		AudioOnly:                 i.AudioOnly,
 		NickName:                  i.Live.GetOptions().NickName,
 		LastError:                 i.LastError,
 		AvailableStreams:          i.AvailableStreams,
 		AvailableStreamsUpdatedAt: i.AvailableStreamsUpdatedAt,
+		EffectiveIntervalSeconds:  i.EffectiveIntervalSeconds,
+	}
+	if !i.PredictedNextStartAt.IsZero() {
+		t.PredictedNextStart = i.PredictedNextStartAt.Format("2006-01-02 15:04:05")
+		t.PredictedNextStartUnix = i.PredictedNextStartAt.Unix()
 	}
 	if !i.Live.GetLastStartTime().IsZero() {
 		t.LastStartTime = i.Live.GetLastStartTime().Format("2006-01-02 15:04:05")
@@ -0,0 +1,175 @@
+package ingest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bililive-go/bililive-go/src/pkg/rtmp"
+)
+
+const (
+	// subscriberBuffer 每个订阅者的消息缓冲数量，下载器跟不上时断开该订阅者
+	subscriberBuffer = 4096
+	// maxGopCache 缓存的最近一个 GOP 的最大消息数量，超过后不再缓存
+	maxGopCache = 4096
+)
+
+// hub 一个推流密钥的分发中心
+// 缓存元数据、音视频序列头和最近一个 GOP，下载器连接时先发送缓存，保证录制文件从关键帧开始
+type hub struct {
+	key string
+
+	mu          sync.Mutex
+	publishing  bool
+	startTime   time.Time
+	remote      string
+	meta        *rtmp.Message
+	videoHeader *rtmp.Message
+	audioHeader *rtmp.Message
+	gop         []*rtmp.Message
+	subscribers map[*subscriber]struct{}
+}
+
+// subscriber 一个下载器连接
+type subscriber struct {
+	ch   chan *rtmp.Message
+	done chan struct{}
+	once sync.Once
+}
+
+func (s *subscriber) close() {
+	s.once.Do(func() { close(s.done) })
+}
+
+func newHub(key string) *hub {
+	return &hub{key: key, subscribers: make(map[*subscriber]struct{})}
+}
+
+// begin 开始一次推流，同一时间只允许一个推流端
+func (h *hub) begin(remote string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.publishing {
+		return false
+	}
+	h.publishing = true
+	h.startTime = time.Now()
+	h.remote = remote
+	return true
+}
+
+// end 推流结束，断开所有订阅者并清空缓存
+func (h *hub) end() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.publishing = false
+	h.meta, h.videoHeader, h.audioHeader, h.gop = nil, nil, nil, nil
+	for s := range h.subscribers {
+		s.close()
+		delete(h.subscribers, s)
+	}
+}
+
+func (h *hub) isPublishing() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.publishing
+}
+
+// publish 缓存并分发一个消息
+func (h *hub) publish(msg *rtmp.Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch msg.Type {
+	case rtmp.TypeDataAMF0:
+		msg = &rtmp.Message{Type: msg.Type, Timestamp: msg.Timestamp, Payload: rtmp.StripSetDataFrame(msg.Payload)}
+		h.meta = msg
+	case rtmp.TypeVideo:
+		switch {
+		case rtmp.IsVideoSequenceHeader(msg.Payload):
+			h.videoHeader = msg
+		case rtmp.IsKeyFrame(msg.Payload):
+			h.gop = append(h.gop[:0], msg)
+		case len(h.gop) > 0 && len(h.gop) < maxGopCache:
+			h.gop = append(h.gop, msg)
+		}
+	case rtmp.TypeAudio:
+		if rtmp.IsAudioSequenceHeader(msg.Payload) {
+			h.audioHeader = msg
+		} else if len(h.gop) > 0 && len(h.gop) < maxGopCache {
+			h.gop = append(h.gop, msg)
+		}
+	}
+	for s := range h.subscribers {
+		select {
+		case s.ch <- msg:
+		default:
+			s.close()
+			delete(h.subscribers, s)
+		}
+	}
+}
+
+// subscribe 注册一个订阅者，返回需要先发送的缓存消息
+func (h *hub) subscribe() (*subscriber, []*rtmp.Message, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.publishing {
+		return nil, nil, false
+	}
+	cached := make([]*rtmp.Message, 0, len(h.gop)+3)
+	for _, m := range []*rtmp.Message{h.meta, h.videoHeader, h.audioHeader} {
+		if m != nil {
+			cached = append(cached, m)
+		}
+	}
+	cached = append(cached, h.gop...)
+	s := &subscriber{ch: make(chan *rtmp.Message, subscriberBuffer), done: make(chan struct{})}
+	h.subscribers[s] = struct{}{}
+	return s, cached, true
+}
+
+func (h *hub) unsubscribe(s *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscribers, s)
+	s.close()
+}
+
+// hubs 所有推流密钥的分发中心
+var (
+	hubsMu sync.Mutex
+	hubs   = map[string]*hub{}
+)
+
+func getHub(key string) *hub {
+	hubsMu.Lock()
+	defer hubsMu.Unlock()
+	h, ok := hubs[key]
+	if !ok {
+		h = newHub(key)
+		hubs[key] = h
+	}
+	return h
+}
+
+// Status 推流状态
+type Status struct {
+	Key        string    `json:"key"`
+	Publishing bool      `json:"publishing"`
+	StartTime  time.Time `json:"start_time,omitempty"`
+	Remote     string    `json:"remote,omitempty"`
+}
+
+// GetStatus 返回推流密钥当前的推流状态
+func GetStatus(key string) Status {
+	h := getHub(key)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	status := Status{Key: key, Publishing: h.publishing}
+	if h.publishing {
+		status.StartTime = h.startTime
+		status.Remote = h.remote
+	}
+	return status
+}
@@ -0,0 +1,90 @@
+// Package ingest 实现内置 RTMP 推流服务器的虚拟直播间
+// 直播间 URL 为 ingest://推流密钥，推流端（OBS 等）推流到 rtmp://服务器地址/live/推流密钥 时直播间视为开播，
+// 推流内容通过本机 HTTP-FLV 地址提供给录制器，录制、后处理和通知流程与真实直播间完全相同
+package ingest
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/live/internal"
+)
+
+const cnName = "推流"
+
+// ErrNotPublishing 推流端未连接
+var ErrNotPublishing = errors.New("ingest: stream is not publishing")
+
+func init() {
+	live.RegisterResolver(resolve)
+}
+
+func resolve(u *url.URL) (live.Builder, bool) {
+	if !strings.EqualFold(u.Scheme, configs.PlatformKeyIngest) {
+		return nil, false
+	}
+	return new(builder), true
+}
+
+type builder struct{}
+
+func (b *builder) Build(url *url.URL) (live.Live, error) {
+	return &Live{
+		BaseLive: internal.NewBaseLive(url),
+	}, nil
+}
+
+// Live 推流密钥对应的虚拟直播间
+type Live struct {
+	internal.BaseLive
+}
+
+func (l *Live) GetPlatformCNName() string {
+	return cnName
+}
+
+// Key 返回直播间对应的推流密钥
+func (l *Live) Key() string {
+	return l.Url.Host
+}
+
+func (l *Live) GetInfo() (*live.Info, error) {
+	key := l.Key()
+	info := &live.Info{
+		Live:      l,
+		HostName:  key,
+		RoomName:  cnName + " " + key,
+		AudioOnly: l.Options.AudioOnly,
+	}
+	cfg, ok := configs.GetCurrentConfig().Ingest.GetKey(key)
+	if !ok {
+		return info, nil
+	}
+	if cfg.HostName != "" {
+		info.HostName = cfg.HostName
+	}
+	if cfg.RoomName != "" {
+		info.RoomName = cfg.RoomName
+	}
+	info.Status = !cfg.Disabled && getHub(key).isPublishing()
+	return info, nil
+}
+
+func (l *Live) GetStreamInfos() ([]*live.StreamUrlInfo, error) {
+	key := l.Key()
+	if !getHub(key).isPublishing() {
+		return nil, ErrNotPublishing
+	}
+	streamUrl, err := getStreamServer().streamUrl(key)
+	if err != nil {
+		return nil, err
+	}
+	return []*live.StreamUrlInfo{{
+		Url:    streamUrl,
+		Name:   key,
+		Format: "flv",
+	}}, nil
+}
@@ -0,0 +1,153 @@
+package ingest
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/pkg/rtmp"
+)
+
+func newTestLive(t *testing.T, key string) live.Live {
+	t.Helper()
+	u, err := url.Parse(configs.IngestRoomUrl(key))
+	require.NoError(t, err)
+	b, ok := resolve(u)
+	require.True(t, ok)
+	l, err := b.Build(u)
+	require.NoError(t, err)
+	require.NoError(t, l.UpdateLiveOptionsbyConfig(t.Context(), &configs.LiveRoom{Url: u.String()}))
+	return l
+}
+
+// readTag 读取一个 FLV tag，返回类型、时间戳和数据
+func readTag(t *testing.T, r io.Reader) (uint8, uint32, []byte) {
+	t.Helper()
+	head := make([]byte, 11)
+	_, err := io.ReadFull(r, head)
+	require.NoError(t, err)
+	size := int(head[1])<<16 | int(head[2])<<8 | int(head[3])
+	data := make([]byte, size+4)
+	_, err = io.ReadFull(r, data)
+	require.NoError(t, err)
+	ts := uint32(head[7])<<24 | uint32(head[4])<<16 | uint32(head[5])<<8 | uint32(head[6])
+	return head[0], ts, data[:size]
+}
+
+func TestIngestPublish(t *testing.T) {
+	cfg := configs.NewConfig()
+	cfg.Ingest.Enable = true
+	cfg.Ingest.Keys = []configs.IngestKey{
+		{Key: "obs", Secret: "s3cret", RoomName: "测试推流"},
+		{Key: "off", Disabled: true},
+	}
+	configs.SetCurrentConfig(cfg)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	serve(t.Context(), listener)
+	base := "rtmp://" + listener.Addr().String()
+
+	publish := func(path string) (*rtmp.Client, error) {
+		u, _ := url.Parse(base + path)
+		client, err := rtmp.Dial(t.Context(), u, rtmp.ClientOptions{})
+		require.NoError(t, err)
+		if err := client.Publish(t.Context()); err != nil {
+			client.Close()
+			return nil, err
+		}
+		return client, nil
+	}
+	for _, path := range []string{"/live/obs", "/live/obs?secret=wrong", "/other/obs?secret=s3cret", "/live/off", "/live/unknown"} {
+		_, err := publish(path)
+		assert.Error(t, err, path)
+	}
+
+	l := newTestLive(t, "obs")
+	info, err := l.GetInfo()
+	require.NoError(t, err)
+	assert.False(t, info.Status)
+	assert.Equal(t, "测试推流", info.RoomName)
+	assert.Equal(t, configs.PlatformKeyIngest, configs.GetPlatformKeyFromUrl(l.GetRawUrl()))
+	_, err = l.GetStreamInfos()
+	assert.ErrorIs(t, err, ErrNotPublishing)
+
+	client, err := publish("/live/obs?secret=s3cret")
+	require.NoError(t, err)
+	defer client.Close()
+	// 同一密钥只允许一个推流端
+	_, err = publish("/live/obs?secret=s3cret")
+	assert.Error(t, err)
+
+	// 缓存从关键帧开始，关键帧之前的普通帧不会发送给后连接的录制器
+	for _, msg := range []*rtmp.Message{
+		{Type: rtmp.TypeVideo, Timestamp: 1000, Payload: []byte{0x17, 0, 1}},
+		{Type: rtmp.TypeAudio, Timestamp: 1000, Payload: []byte{0xaf, 0, 2}},
+		{Type: rtmp.TypeVideo, Timestamp: 1000, Payload: []byte{0x27, 1, 3}},
+		{Type: rtmp.TypeVideo, Timestamp: 1040, Payload: []byte{0x17, 1, 4}},
+		{Type: rtmp.TypeAudio, Timestamp: 1050, Payload: []byte{0xaf, 1, 5}},
+	} {
+		require.NoError(t, client.WriteMedia(msg))
+	}
+	require.Eventually(t, func() bool {
+		h := getHub("obs")
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return len(h.gop) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	info, err = l.GetInfo()
+	require.NoError(t, err)
+	assert.True(t, info.Status)
+	assert.True(t, GetStatus("obs").Publishing)
+	streams, err := l.GetStreamInfos()
+	require.NoError(t, err)
+	require.Len(t, streams, 1)
+
+	resp, err := http.Get(streams[0].Url.String())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	header := make([]byte, len(rtmp.FLVHeader))
+	_, err = io.ReadFull(resp.Body, header)
+	require.NoError(t, err)
+	assert.Equal(t, rtmp.FLVHeader, header)
+
+	type tag struct {
+		typeID uint8
+		ts     uint32
+		data   byte
+	}
+	var got []tag
+	for range 4 {
+		typeID, ts, data := readTag(t, resp.Body)
+		got = append(got, tag{typeID, ts, data[2]})
+	}
+	// 连接后收到的新消息
+	require.NoError(t, client.WriteMedia(&rtmp.Message{Type: rtmp.TypeVideo, Timestamp: 1080, Payload: []byte{0x27, 1, 6}}))
+	typeID, ts, data := readTag(t, resp.Body)
+	got = append(got, tag{typeID, ts, data[2]})
+	assert.Equal(t, []tag{
+		{rtmp.TypeVideo, 0, 1},
+		{rtmp.TypeAudio, 0, 2},
+		{rtmp.TypeVideo, 40, 4},
+		{rtmp.TypeAudio, 50, 5},
+		{rtmp.TypeVideo, 80, 6},
+	}, got)
+
+	// 推流结束后 HTTP 流结束，直播间变为未开播
+	client.Close()
+	_, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		info, err := l.GetInfo()
+		return err == nil && !info.Status
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,202 @@
+package ingest
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	blog "github.com/bililive-go/bililive-go/src/log"
+	"github.com/bililive-go/bililive-go/src/pkg/rtmp"
+)
+
+var (
+	errWrongApp       = errors.New("unknown app")
+	errUnknownKey     = errors.New("unknown stream key")
+	errWrongSecret    = errors.New("wrong secret")
+	errAlreadyStarted = errors.New("stream key is already publishing")
+)
+
+// Start 按配置启动 RTMP 推流服务器，ctx 结束时关闭
+// 推流服务器未启用时直接返回；启用状态的修改需要重启后生效，推流密钥的修改立即生效
+func Start(ctx context.Context) error {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil || !cfg.Ingest.Enable {
+		return nil
+	}
+	listener, err := net.Listen("tcp", cfg.Ingest.GetRtmpListen())
+	if err != nil {
+		return err
+	}
+	serve(ctx, listener)
+	blog.GetLogger().Infof("RTMP 推流服务器已启动: rtmp://%s/%s/<推流密钥>", listener.Addr(), cfg.Ingest.GetApp())
+	return nil
+}
+
+// serve 在 listener 上处理推流，ctx 结束时关闭
+func serve(ctx context.Context, listener net.Listener) *rtmp.Server {
+	server := &rtmp.Server{OnPublish: onPublish}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, rtmp.ErrServerClosed) {
+			blog.GetLogger().WithError(err).Error("RTMP 推流服务器异常退出")
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	return server
+}
+
+// onPublish 校验应用名、推流密钥和密码，同一密钥同一时间只允许一个推流端
+func onPublish(req *rtmp.PublishRequest) (rtmp.Publisher, error) {
+	cfg := configs.GetCurrentConfig().Ingest
+	if req.App != cfg.GetApp() {
+		return nil, errWrongApp
+	}
+	key, ok := cfg.GetKey(req.Stream)
+	if !ok || key.Disabled {
+		return nil, errUnknownKey
+	}
+	if key.Secret != "" && subtle.ConstantTimeCompare([]byte(req.Query.Get("secret")), []byte(key.Secret)) != 1 {
+		return nil, errWrongSecret
+	}
+	h := getHub(key.Key)
+	if !h.begin(req.Remote.String()) {
+		return nil, errAlreadyStarted
+	}
+	blog.GetLogger().Infof("推流密钥 %s 开始推流: %s", key.Key, req.Remote)
+	return &publisher{hub: h}, nil
+}
+
+// publisher 将推流端的消息交给分发中心
+type publisher struct {
+	hub *hub
+}
+
+func (p *publisher) OnMessage(msg *rtmp.Message) error {
+	if msg.Type == rtmp.TypeAggregate {
+		for _, sub := range rtmp.SplitAggregate(msg) {
+			p.hub.publish(sub)
+		}
+		return nil
+	}
+	p.hub.publish(msg)
+	return nil
+}
+
+func (p *publisher) Close() {
+	p.hub.end()
+	blog.GetLogger().Infof("推流密钥 %s 推流结束", p.hub.key)
+}
+
+// streamServer 本机 HTTP 服务，将推流内容以 HTTP-FLV 提供给录制器
+type streamServer struct {
+	mu       sync.Mutex
+	listener net.Listener
+}
+
+var (
+	streamServerOnce    sync.Once
+	defaultStreamServer *streamServer
+)
+
+func getStreamServer() *streamServer {
+	streamServerOnce.Do(func() {
+		defaultStreamServer = &streamServer{}
+	})
+	return defaultStreamServer
+}
+
+// streamUrl 首次使用时在 127.0.0.1 的随机端口上启动服务，返回推流密钥的拉流地址
+func (s *streamServer) streamUrl(key string) (*url.URL, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		s.listener = listener
+		mux := http.NewServeMux()
+		mux.HandleFunc("/ingest/{name}", handleStream)
+		go func() {
+			_ = http.Serve(listener, mux)
+		}()
+	}
+	return &url.URL{
+		Scheme: "http",
+		Host:   s.listener.Addr().String(),
+		Path:   fmt.Sprintf("/ingest/%s.flv", key),
+	}, nil
+}
+
+// handleStream 先发送缓存的元数据、序列头和最近一个 GOP，再持续发送新消息直到推流结束
+// 时间戳以第一个音视频消息为起点
+func handleStream(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimSuffix(r.PathValue("name"), ".flv")
+	sub, cached, ok := getHub(key).subscribe()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	defer getHub(key).unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "video/x-flv")
+	if _, err := w.Write(rtmp.FLVHeader); err != nil {
+		return
+	}
+	flusher, _ := w.(http.Flusher)
+	base := int64(-1)
+	var buf []byte
+	write := func(msg *rtmp.Message) error {
+		var ts uint32
+		if msg.Type != rtmp.TypeDataAMF0 {
+			if base < 0 {
+				base = int64(msg.Timestamp)
+			}
+			if int64(msg.Timestamp) > base {
+				ts = uint32(int64(msg.Timestamp) - base)
+			}
+		}
+		buf = rtmp.AppendFLVTag(buf[:0], msg.Type, ts, msg.Payload)
+		_, err := w.Write(buf)
+		return err
+	}
+	for _, msg := range cached {
+		if err := write(msg); err != nil {
+			return
+		}
+	}
+	for {
+		if flusher != nil {
+			flusher.Flush()
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case msg := <-sub.ch:
+			if err := write(msg); err != nil {
+				return
+			}
+		case <-sub.done:
+			// 推流结束前已分发的消息仍然写出
+			for {
+				select {
+				case msg := <-sub.ch:
+					if err := write(msg); err != nil {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/bililive-go/bililive-go/src/configs"
 	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/pkg/accounts"
 	"github.com/bililive-go/bililive-go/src/pkg/livelogger"
 	"github.com/bililive-go/bililive-go/src/pkg/utils"
 	"github.com/bililive-go/bililive-go/src/types"
@@ -80,8 +81,9 @@ func (a *BaseLive) UpdateLiveOptionsbyConfig(ctx context.Context, room *configs.
 	}
 	opts := make([]live.Option, 0)
 	if cfg := configs.GetCurrentConfig(); cfg != nil {
-		if v, ok := cfg.Cookies[url.Host]; ok {
-			opts = append(opts, live.WithKVStringCookies(url, v))
+		if account, ok := accounts.GetPool().Resolve(cfg, room, url.Host); ok {
+			opts = append(opts, live.WithKVStringCookies(url, account.Cookie))
+			opts = append(opts, live.WithAccount(account.Name))
 		}
 	}
 	opts = append(opts, live.WithQuality(room.Quality))
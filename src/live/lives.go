@@ -9,12 +9,14 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bililive-go/bililive-go/src/configs"
 	"github.com/bililive-go/bililive-go/src/pkg/livelogger"
+	"github.com/bililive-go/bililive-go/src/pkg/pushdetect"
 	"github.com/bililive-go/bililive-go/src/pkg/ratelimit"
-	bilisentry "github.com/bililive-go/bililive-go/src/pkg/sentry"
+	"github.com/bililive-go/bililive-go/src/pkg/scheduler"
 	"github.com/bililive-go/bililive-go/src/types"
 	"github.com/bluele/gcache"
 )
@@ -36,6 +38,25 @@ func SetSchedulerRefreshCallback(callback SchedulerRefreshCallback) {
 	schedulerRefreshCallback = callback
 }
 
+// PollingAdvice 自适应轮询建议
+type PollingAdvice struct {
+	// Interval 当前建议的检测间隔
+	Interval time.Duration
+	// PredictedNextStart 预测的下次开播时间，没有规律时为零值
+	PredictedNextStart time.Time
+}
+
+// PollingAdvisor 根据直播间和配置的检测间隔给出自适应轮询建议，返回 false 时使用配置的检测间隔
+type PollingAdvisor func(live Live, base time.Duration) (PollingAdvice, bool)
+
+// 全局自适应轮询建议（由 livestate 设置，避免循环依赖）
+var pollingAdvisor PollingAdvisor
+
+// SetPollingAdvisor 设置自适应轮询建议的回调函数
+func SetPollingAdvisor(advisor PollingAdvisor) {
+	pollingAdvisor = advisor
+}
+
 // SetRequestStatusCallback 设置请求状态追踪的回调函数
 func SetRequestStatusCallback(callback RequestStatusCallback) {
 	requestStatusCallback = callback
@@ -50,9 +71,27 @@ func Register(domain string, b Builder) {
 	m[domain] = b
 }
 
-func getBuilder(domain string) (Builder, bool) {
-	builder, ok := m[domain]
-	return builder, ok
+// BuilderResolver 按完整 URL 动态匹配 Builder
+// 用于外部插件等无法在 init 中按固定域名注册的平台，仅在没有按域名注册的 Builder 时使用
+type BuilderResolver func(u *url.URL) (Builder, bool)
+
+var resolvers []BuilderResolver
+
+// RegisterResolver 注册动态 Builder 解析器，按注册顺序匹配
+func RegisterResolver(r BuilderResolver) {
+	resolvers = append(resolvers, r)
+}
+
+func getBuilder(u *url.URL) (Builder, bool) {
+	if builder, ok := m[u.Host]; ok {
+		return builder, true
+	}
+	for _, r := range resolvers {
+		if builder, ok := r(u); ok {
+			return builder, true
+		}
+	}
+	return nil, false
 }
 
 type Builder interface {
@@ -101,6 +140,8 @@ type Options struct {
 	Quality   int
 	AudioOnly bool
 	NickName  string
+	// Account 当前使用的账号名称，为空表示使用 cookies 中该平台的 Cookie
+	Account string
 }
 
 func NewOptions(opts ...Option) (*Options, error) {
@@ -160,6 +201,12 @@ func WithNickName(nickName string) Option {
 	}
 }
 
+func WithAccount(account string) Option {
+	return func(opts *Options) {
+		opts.Account = account
+	}
+}
+
 type StreamUrlInfo struct {
 	Url         *url.URL
 	Name        string
@@ -197,7 +244,7 @@ type Live interface {
 	// GetInfoWithInterval 是一个会阻塞的 GetInfo 方法
 	// 它会先等待配置的访问间隔（同时尊重平台最小访问频率），然后再发送请求
 	// ctx 可用于取消等待，返回 ctx.Err()
-	// 这个方法适用于需要周期性获取信息的场景，如 listener 的 refresh 循环
+	// 需要周期性获取信息时优先使用 InfoSubscriber，不必为每个直播间占用一个阻塞的 goroutine
 	GetInfoWithInterval(ctx context.Context) (*Info, error)
 	// Deprecated: GetStreamUrls is deprecated, using GetStreamInfos instead
 	GetStreamUrls() ([]*url.URL, error)
@@ -208,10 +255,46 @@ type Live interface {
 	UpdateLiveOptionsbyConfig(context.Context, *configs.LiveRoom) error
 	GetOptions() *Options
 	GetLogger() *livelogger.LiveLogger
-	// Close 关闭 Live 对象，释放相关资源（如调度器 goroutine）
+	// Close 关闭 Live 对象，释放相关资源（如调度任务）
 	Close()
 }
 
+// InfoSubscriber 支持订阅 GetInfo 结果的 Live
+// 有订阅者时由集中调度器按配置的访问间隔周期性请求，每次请求的结果（包括其他调用方触发的 GetInfo）都会回调给订阅者
+type InfoSubscriber interface {
+	// SubscribeInfo 订阅 GetInfo 结果，返回取消订阅的函数
+	// 回调在调度器的 worker 中执行，同一个 Live 的回调不会并发，回调中再次调用 GetInfo 不会重复回调
+	SubscribeInfo(fn func(*Info, error)) (unsubscribe func())
+}
+
+// GracePoller 支持在直播中断的宽限期内加快轮询的 Live
+type GracePoller interface {
+	// SetGracePeriod 在 until 之前按宽限期的轮询间隔检测，传入零值恢复正常间隔
+	SetGracePeriod(until time.Time)
+	// InGracePeriod 是否处于直播中断的宽限期
+	InGracePeriod() bool
+}
+
+// BatchInfoProvider 平台 Live 可选实现的批量查询接口
+// 平台支持一次请求获取多个直播间的状态时实现，调度器会把同一分组中到期的直播间合并为一次请求
+type BatchInfoProvider interface {
+	// BatchKey 返回批量分组键，相同键的直播间可以合并到一次请求中；
+	// 返回空字符串表示暂时只能单独请求（例如还没有解析出批量接口需要的 ID）
+	BatchKey() string
+	// MaxBatchSize 单次批量请求最多包含的直播间数量
+	MaxBatchSize() int
+	// GetInfoBatch 批量获取直播间信息，lives 中的元素都与接收者属于同一分组
+	// 返回值与 lives 一一对应，某个直播间的结果为 nil 时会回退为单独调用它的 GetInfo
+	GetInfoBatch(lives []Live) ([]*Info, error)
+}
+
+// PushDetector 平台 Live 可选实现的推送检测接口
+// 平台提供直播间推送通道时实现，有订阅者且开启推送开播检测时订阅，收到开播/下播消息后立即通知订阅者
+type PushDetector interface {
+	// PushChannel 返回推送通道和直播间在通道中的标识，暂时不能订阅时返回 false（例如还没有解析出房间号）
+	PushChannel() (pushdetect.Platform, string, bool)
+}
+
 // infoResult 用于传递 GetInfo 的结果
 type infoResult struct {
 	info *Info
@@ -236,6 +319,14 @@ type SchedulerStatus struct {
 	NextRequestAt time.Time `json:"next_request_at"`
 	// IntervalSeconds 配置的访问间隔（秒）
 	IntervalSeconds int `json:"interval_seconds"`
+	// EffectiveIntervalSeconds 当前实际使用的访问间隔（秒），开启自适应轮询时可能与配置不同
+	EffectiveIntervalSeconds int `json:"effective_interval_seconds"`
+	// Adaptive 当前访问间隔是否由自适应轮询给出
+	Adaptive bool `json:"adaptive"`
+	// PredictedNextStartAt 根据历史开播时间预测的下次开播时间
+	PredictedNextStartAt time.Time `json:"predicted_next_start_at,omitempty"`
+	// PushConnected 推送通道是否连接正常，连接正常时开播/下播会被立即发现
+	PushConnected bool `json:"push_connected"`
 	// SecondsUntilNextRequest 距离下次请求的秒数（如果有计划的话）
 	SecondsUntilNextRequest float64 `json:"seconds_until_next_request"`
 	// SecondsSinceLastRequest 距离上次请求的秒数
@@ -254,51 +345,91 @@ type WrappedLive struct {
 	cache gcache.Cache
 
 	// 请求调度相关字段
-	mu               sync.Mutex
-	waiters          []waiter      // 等待下一次请求结果的调用方
-	lastRequestAt    time.Time     // 上次发送请求的时间
-	schedulerOnce    sync.Once     // 确保调度器只启动一次
-	schedulerStarted bool          // 调度器是否已启动
-	schedulerStop    chan struct{} // 停止调度器的信号
-	schedulerCtx     context.Context
-	schedulerCancel  context.CancelFunc
+	mu              sync.Mutex
+	waiters         []waiter                   // 等待下一次请求结果的调用方
+	subscribers     map[int]func(*Info, error) // 订阅每次请求结果的回调
+	nextSubscriber  int                        // 下一个订阅者的 ID
+	notifyMu        sync.Mutex                 // 回调订阅者时持有，回调中的 GetInfo 获取不到锁时跳过，避免重复回调
+	lastRequestAt   time.Time                  // 上次发送请求的时间
+	job             *scheduler.Job             // 在集中调度器中的周期性请求任务
+	pushUnsubscribe func()                     // 取消推送通道订阅，未订阅时为 nil
+	pushConnected   atomic.Bool                // 推送通道是否连接正常
+	liveSince       time.Time                  // 本次开播被发现的时间，未开播时为零值
+	graceUntil      time.Time                  // 直播中断的宽限期结束时间，之前加快轮询
+	schedulerCtx    context.Context
+	schedulerCancel context.CancelFunc
 }
 
 // NewWrappedLive 创建一个带有缓存功能的 Live 包装器
 // 外部包可以使用此函数将原始 Live 对象包装为支持缓存的 WrappedLive
-// ctx 用于控制调度器的生命周期，当 ctx 被取消时调度器会停止
+// ctx 用于控制调度任务的生命周期，当 ctx 被取消时不再发送周期性请求
 func NewWrappedLive(ctx context.Context, live Live, cache gcache.Cache) Live {
 	schedulerCtx, schedulerCancel := context.WithCancel(ctx)
-	return &WrappedLive{
+	w := &WrappedLive{
 		Live:            live,
 		cache:           cache,
-		schedulerStop:   make(chan struct{}),
+		subscribers:     make(map[int]func(*Info, error)),
 		schedulerCtx:    schedulerCtx,
 		schedulerCancel: schedulerCancel,
 	}
+	platformKey := configs.GetPlatformKeyFromUrl(live.GetRawUrl())
+	w.job = scheduler.NewJob(platformKey, w.runScheduledRequest, w.nextRequestAt)
+	w.job.Value = w
+	if provider, ok := live.(BatchInfoProvider); ok {
+		w.job.SetBatch(&scheduler.Batch{
+			Key: func() string {
+				if key := provider.BatchKey(); key != "" {
+					return platformKey + "/" + key
+				}
+				return ""
+			},
+			Max: provider.MaxBatchSize(),
+			Run: runBatchRequest,
+		})
+	}
+	return w
 }
 
-// Close 停止请求调度器，释放相关资源
+// Close 从调度器中移除周期性请求任务，释放相关资源
 func (w *WrappedLive) Close() {
 	w.schedulerCancel()
-	// 使用 select 避免重复 close panic
-	select {
-	case <-w.schedulerStop:
-		// 已经关闭
-	default:
-		close(w.schedulerStop)
-	}
+	scheduler.GetScheduler().Remove(w.job)
+	w.stopPush()
 }
 
 func (w *WrappedLive) GetInfo() (*Info, error) {
 	// 在通用位置应用平台访问频率限制
 	// 如果被取消则直接返回
-	if !w.waitForPlatformRateLimit() {
+	if !w.waitForPlatformRateLimit(ratelimit.EndpointInfo) {
 		return nil, w.schedulerCtx.Err()
 	}
 
 	i, err := w.Live.GetInfo()
+	return w.handleInfoResult(i, err)
+}
+
+// GetStreamInfos 在通用位置应用平台获取直播流地址的访问频率限制，刚开播的直播间优先
+func (w *WrappedLive) GetStreamInfos() ([]*StreamUrlInfo, error) {
+	if !w.waitForPlatformRateLimit(ratelimit.EndpointStream) {
+		return nil, w.schedulerCtx.Err()
+	}
+	infos, err := w.Live.GetStreamInfos()
+	w.reportRateLimitResult(err)
+	return infos, err
+}
+
+// GetStreamUrls 同 GetStreamInfos
+func (w *WrappedLive) GetStreamUrls() ([]*url.URL, error) {
+	if !w.waitForPlatformRateLimit(ratelimit.EndpointStream) {
+		return nil, w.schedulerCtx.Err()
+	}
+	urls, err := w.Live.GetStreamUrls() //nolint:staticcheck // deprecated 方法的委托实现
+	w.reportRateLimitResult(err)
+	return urls, err
+}
 
+// handleInfoResult 处理一次请求的结果：更新缓存和请求时间，并通知等待的调用方和订阅者
+func (w *WrappedLive) handleInfoResult(i *Info, err error) (*Info, error) {
 	// 记录请求状态到 IO 统计（通过回调避免循环依赖）
 	if requestStatusCallback != nil {
 		liveID := string(w.GetLiveId())
@@ -310,8 +441,11 @@ func (w *WrappedLive) GetInfo() (*Info, error) {
 		}
 	}
 
-	// 不管成功还是失败，都通知所有等待的调用方
+	w.reportRateLimitResult(err)
+
+	// 不管成功还是失败，都通知所有等待的调用方和订阅者
 	w.notifyWaiters(i, err)
+	defer w.notifySubscribers(i, err)
 
 	if err != nil {
 		if info, err2 := w.cache.Get(w); err2 == nil {
@@ -326,18 +460,56 @@ func (w *WrappedLive) GetInfo() (*Info, error) {
 		i.LastError = ""
 		w.cache.Set(w, i)
 	}
+	w.updateLiveSince(i.Status)
 
 	// 更新最后请求时间
 	w.mu.Lock()
 	w.lastRequestAt = time.Now()
 	w.mu.Unlock()
 
+	// 首次订阅时可能还不能订阅推送通道（例如还没有解析出房间号），请求成功后重试
+	w.syncPush()
+
 	// 发送调度器刷新完成事件，通知前端更新倒计时
 	w.dispatchSchedulerRefreshEvent()
 
 	return i, nil
 }
 
+// reportRateLimitResult 被风控拦截时平台进入冷却，成功时逐步恢复请求频率
+func (w *WrappedLive) reportRateLimitResult(err error) {
+	platformKey := configs.GetPlatformKeyFromUrl(w.GetRawUrl())
+	if platformKey == "" {
+		return
+	}
+	if errors.Is(err, ErrRiskControl) {
+		ratelimit.GetGlobalRateLimiter().ReportRiskControl(platformKey, w.GetPlatformCNName(), err.Error())
+	} else if err == nil {
+		ratelimit.GetGlobalRateLimiter().ReportSuccess(platformKey)
+	}
+}
+
+// updateLiveSince 记录本次开播被发现的时间
+func (w *WrappedLive) updateLiveSince(living bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !living {
+		w.liveSince = time.Time{}
+	} else if w.liveSince.IsZero() {
+		w.liveSince = time.Now()
+	}
+}
+
+// streamPriorityWindow 发现开播后多长时间内获取直播流地址优先于其他请求（包括录制启动失败后的重试）
+const streamPriorityWindow = 2 * time.Minute
+
+// justWentLive 是否刚开播，刚开播的直播间获取直播流地址时优先
+func (w *WrappedLive) justWentLive() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !w.liveSince.IsZero() && time.Since(w.liveSince) < streamPriorityWindow
+}
+
 // dispatchSchedulerRefreshEvent 发送调度器刷新完成事件
 func (w *WrappedLive) dispatchSchedulerRefreshEvent() {
 	if schedulerRefreshCallback != nil {
@@ -364,14 +536,162 @@ func (w *WrappedLive) notifyWaiters(info *Info, err error) {
 	}
 }
 
+// notifySubscribers 回调所有订阅者，回调中再次调用 GetInfo 时跳过，避免重入
+func (w *WrappedLive) notifySubscribers(info *Info, err error) {
+	if !w.notifyMu.TryLock() {
+		return
+	}
+	defer w.notifyMu.Unlock()
+
+	w.callSubscribers(info, err)
+}
+
+// callSubscribers 依次回调所有订阅者，调用方需要持有 notifyMu
+func (w *WrappedLive) callSubscribers(info *Info, err error) {
+	w.mu.Lock()
+	subscribers := make([]func(*Info, error), 0, len(w.subscribers))
+	for _, fn := range w.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	w.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(info, err)
+	}
+}
+
+// SubscribeInfo 订阅 GetInfo 结果，有订阅者时由集中调度器按配置的间隔周期性请求
+func (w *WrappedLive) SubscribeInfo(fn func(*Info, error)) func() {
+	w.mu.Lock()
+	id := w.nextSubscriber
+	w.nextSubscriber++
+	w.subscribers[id] = fn
+	w.mu.Unlock()
+
+	scheduler.GetScheduler().Ensure(w.job)
+	w.syncPush()
+	return func() {
+		w.mu.Lock()
+		delete(w.subscribers, id)
+		empty := len(w.subscribers) == 0
+		w.mu.Unlock()
+		if empty {
+			w.stopPush()
+		}
+	}
+}
+
+// syncPush 有订阅者且开启推送开播检测时订阅直播间的推送通道，关闭推送开播检测后取消订阅
+func (w *WrappedLive) syncPush() {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil || !cfg.PushDetection.Enable || w.schedulerCtx.Err() != nil {
+		w.stopPush()
+		return
+	}
+	detector, ok := w.Live.(PushDetector)
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	subscribed := w.pushUnsubscribe != nil || len(w.subscribers) == 0
+	w.mu.Unlock()
+	if subscribed {
+		return
+	}
+	platform, room, ok := detector.PushChannel()
+	if !ok {
+		return
+	}
+
+	// 订阅时可能立即回调连接状态，不能持有 w.mu
+	unsubscribe := pushdetect.GetHub().Subscribe(platform, room, pushdetect.Handler{
+		OnStatus:    w.handlePushStatus,
+		OnConnected: w.setPushConnected,
+	})
+	w.mu.Lock()
+	if w.pushUnsubscribe != nil {
+		w.mu.Unlock()
+		unsubscribe()
+		return
+	}
+	w.pushUnsubscribe = unsubscribe
+	w.mu.Unlock()
+}
+
+// stopPush 取消推送通道订阅，恢复按配置的间隔轮询
+func (w *WrappedLive) stopPush() {
+	w.mu.Lock()
+	unsubscribe := w.pushUnsubscribe
+	w.pushUnsubscribe = nil
+	w.mu.Unlock()
+	if unsubscribe != nil {
+		unsubscribe()
+		w.setPushConnected(false)
+	}
+}
+
+// setPushConnected 推送通道连接状态变化后按新的轮询间隔重新安排下一次请求
+func (w *WrappedLive) setPushConnected(connected bool) {
+	if w.pushConnected.Swap(connected) == connected {
+		return
+	}
+	if next, ok := w.nextRequestAt(); ok {
+		scheduler.GetScheduler().Reschedule(w.job, next)
+	}
+}
+
+// SetGracePeriod 直播中断进入宽限期时加快轮询，并按新的轮询间隔重新安排下一次请求
+func (w *WrappedLive) SetGracePeriod(until time.Time) {
+	w.mu.Lock()
+	w.graceUntil = until
+	w.mu.Unlock()
+	if next, ok := w.nextRequestAt(); ok {
+		scheduler.GetScheduler().Reschedule(w.job, next)
+	}
+}
+
+// InGracePeriod 是否处于直播中断的宽限期
+func (w *WrappedLive) InGracePeriod() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return time.Now().Before(w.graceUntil)
+}
+
+// handlePushStatus 收到推送通道的开播/下播消息：立即更新缓存的直播间信息并通知订阅者，
+// 监听器据此发送开播/下播事件，不必等待下一次轮询
+func (w *WrappedLive) handlePushStatus(living bool) {
+	if w.schedulerCtx.Err() != nil || w.cache == nil {
+		return
+	}
+	obj, err := w.cache.Get(w)
+	if err != nil {
+		// 还没有直播间信息时立即请求一次
+		w.GetInfo()
+		return
+	}
+	cached := obj.(*Info)
+	if cached.Status == living {
+		return
+	}
+	info := *cached
+	info.Status = living
+	info.LastError = ""
+	w.cache.Set(w, &info)
+	w.updateLiveSince(living)
+	w.notifyWaiters(&info, nil)
+
+	// 等待正在进行的订阅者回调完成，避免推送消息被跳过
+	w.notifyMu.Lock()
+	w.callSubscribers(&info, nil)
+	w.notifyMu.Unlock()
+	w.dispatchSchedulerRefreshEvent()
+}
+
 // GetInfoWithInterval 是一个会阻塞的 GetInfo 方法
 // 调用方会等待直到下一次 GetInfo 请求完成，然后获得该请求的结果
 // 多个调用方会共享同一次请求的结果
 // ctx 可用于取消等待
 func (w *WrappedLive) GetInfoWithInterval(ctx context.Context) (*Info, error) {
-	// 确保调度器已启动
-	w.startScheduler()
-
 	// 创建等待通道
 	ch := make(chan infoResult, 1)
 
@@ -379,6 +699,9 @@ func (w *WrappedLive) GetInfoWithInterval(ctx context.Context) (*Info, error) {
 	w.waiters = append(w.waiters, waiter{ch: ch, ctx: ctx})
 	w.mu.Unlock()
 
+	// 确保周期性请求任务在调度器中
+	scheduler.GetScheduler().Ensure(w.job)
+
 	// 等待结果或取消
 	select {
 	case <-ctx.Done():
@@ -402,31 +725,29 @@ func (w *WrappedLive) removeWaiter(ch chan infoResult) {
 	}
 }
 
-// startScheduler 启动请求调度器
-func (w *WrappedLive) startScheduler() {
-	w.schedulerOnce.Do(func() {
-		w.mu.Lock()
-		w.schedulerStarted = true
-		w.mu.Unlock()
-		bilisentry.Go(w.runScheduler)
-	})
-}
-
 // GetSchedulerStatus 获取调度器状态信息
 func (w *WrappedLive) GetSchedulerStatus() SchedulerStatus {
+	sched := scheduler.GetScheduler()
+	scheduled := sched.IsScheduled(w.job)
+	queuedAt, queued := sched.NextRunAt(w.job)
+	interval := w.getConfiguredInterval()
+	effective, advice, adaptive := w.getEffectiveInterval(interval)
+
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
 	now := time.Now()
-	interval := w.getConfiguredInterval()
-	intervalDuration := time.Duration(interval) * time.Second
-
+	waiterCount := len(w.waiters) + len(w.subscribers)
 	status := SchedulerStatus{
-		HasWaiters:       len(w.waiters) > 0,
-		WaiterCount:      len(w.waiters),
-		LastRequestAt:    w.lastRequestAt,
-		IntervalSeconds:  interval,
-		SchedulerRunning: w.schedulerStarted,
+		HasWaiters:               waiterCount > 0,
+		WaiterCount:              waiterCount,
+		LastRequestAt:            w.lastRequestAt,
+		IntervalSeconds:          interval,
+		EffectiveIntervalSeconds: int(effective / time.Second),
+		Adaptive:                 adaptive,
+		PredictedNextStartAt:     advice.PredictedNextStart,
+		PushConnected:            w.pushConnected.Load(),
+		SchedulerRunning:         scheduled,
 	}
 
 	// 计算距离上次请求的秒数
@@ -434,9 +755,12 @@ func (w *WrappedLive) GetSchedulerStatus() SchedulerStatus {
 		status.SecondsSinceLastRequest = now.Sub(w.lastRequestAt).Seconds()
 	}
 
-	// 只有在有等待者且调度器运行时才计算下次请求时间
+	// 只有在有等待者且任务在调度器中时才计算下次请求时间
 	if status.HasWaiters && status.SchedulerRunning {
-		nextRequestAt := w.lastRequestAt.Add(intervalDuration)
+		nextRequestAt := w.lastRequestAt.Add(effective)
+		if queued {
+			nextRequestAt = queuedAt
+		}
 		status.NextRequestAt = nextRequestAt
 		if nextRequestAt.After(now) {
 			status.SecondsUntilNextRequest = nextRequestAt.Sub(now).Seconds()
@@ -452,69 +776,115 @@ func (w *WrappedLive) GetSchedulerStatus() SchedulerStatus {
 	return status
 }
 
-// runScheduler 运行请求调度循环
-func (w *WrappedLive) runScheduler() {
-	for {
-		// 先检查是否有等待的调用方，如果没有就等待一小段时间再检查
-		w.mu.Lock()
-		hasWaiters := len(w.waiters) > 0
-		w.mu.Unlock()
-
-		if !hasWaiters {
-			// 没有等待者，休眠一小段时间再检查
-			select {
-			case <-w.schedulerStop:
-				return
-			case <-w.schedulerCtx.Done():
-				return
-			case <-time.After(100 * time.Millisecond):
-				continue
-			}
-		}
-
-		// 有等待者，计算需要等待的时间
-		interval := time.Duration(w.getConfiguredInterval()) * time.Second
+// runScheduledRequest 调度器到期时执行：仍有等待者或订阅者时发送请求（GetInfo 会通知所有等待者和订阅者）
+func (w *WrappedLive) runScheduledRequest() {
+	if w.hasWaiters() && w.schedulerCtx.Err() == nil {
+		w.GetInfo()
+	}
+}
 
-		w.mu.Lock()
-		nextRequestAt := w.lastRequestAt.Add(interval)
-		w.mu.Unlock()
+// hasWaiters 是否有等待的调用方或订阅者
+func (w *WrappedLive) hasWaiters() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.waiters) > 0 || len(w.subscribers) > 0
+}
 
-		now := time.Now()
-		var waitDuration time.Duration
-		if nextRequestAt.After(now) {
-			waitDuration = nextRequestAt.Sub(now)
-		} else {
-			// 已经过了下一次请求时间，添加一点随机抖动避免同时请求
-			waitDuration = time.Duration(randomJitter()+3000) * time.Millisecond
-			if waitDuration < 0 {
-				waitDuration = 0
-			}
+// runBatchRequest 调度器合并到期的同组直播间后执行：一次批量请求获取所有直播间的信息，
+// 再分发给各自的等待者和订阅者；批量请求失败或缺少某个直播间的结果时回退为单独请求
+func runBatchRequest(jobs []*scheduler.Job) {
+	lives := make([]*WrappedLive, 0, len(jobs))
+	for _, job := range jobs {
+		if w := job.Value.(*WrappedLive); w.hasWaiters() && w.schedulerCtx.Err() == nil {
+			lives = append(lives, w)
 		}
+	}
+	if len(lives) == 0 {
+		return
+	}
+	provider, ok := lives[0].Live.(BatchInfoProvider)
+	if !ok || len(lives) == 1 {
+		for _, w := range lives {
+			w.GetInfo()
+		}
+		return
+	}
 
-		// 等待直到下一次请求时间
-		if waitDuration > 0 {
-			timer := time.NewTimer(waitDuration)
-			select {
-			case <-w.schedulerStop:
-				timer.Stop()
-				return
-			case <-w.schedulerCtx.Done():
-				timer.Stop()
-				return
-			case <-timer.C:
-			}
+	// 批量请求只占用一次平台访问频率
+	if !lives[0].waitForPlatformRateLimit(ratelimit.EndpointInfo) {
+		return
+	}
+	inner := make([]Live, len(lives))
+	for i, w := range lives {
+		inner[i] = w.Live
+	}
+	infos, err := provider.GetInfoBatch(inner)
+	for i, w := range lives {
+		switch {
+		case err == nil && i < len(infos) && infos[i] != nil:
+			w.handleInfoResult(infos[i], nil)
+		case errors.Is(err, ErrRiskControl):
+			// 被风控时不再逐个重试，避免加重风控
+			w.handleInfoResult(nil, err)
+		default:
+			w.GetInfo()
 		}
+	}
+}
 
-		// 再次检查是否还有等待者（可能在等待期间被取消了）
-		w.mu.Lock()
-		hasWaiters = len(w.waiters) > 0
-		w.mu.Unlock()
+// nextRequestAt 计算下一次周期性请求的时间，没有等待者和订阅者时返回 false，任务暂停直到再次有调用方
+func (w *WrappedLive) nextRequestAt() (time.Time, bool) {
+	if w.schedulerCtx.Err() != nil {
+		return time.Time{}, false
+	}
+	interval, _, _ := w.getEffectiveInterval(w.getConfiguredInterval())
 
-		if hasWaiters {
-			// 发送请求（GetInfo 会通知所有等待者）
-			w.GetInfo()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.waiters) == 0 && len(w.subscribers) == 0 {
+		return time.Time{}, false
+	}
+	now := time.Now()
+	next := w.lastRequestAt.Add(interval)
+	if !next.After(now) {
+		// 已经过了下一次请求时间，添加一点随机抖动避免同时请求
+		next = now.Add(time.Duration(randomJitter()+3000) * time.Millisecond)
+	}
+	return next, true
+}
+
+// getEffectiveInterval 获取实际使用的访问间隔：直播中或没有自适应建议时使用配置的间隔，
+// 推送通道连接正常时轮询只作为补充，间隔不小于推送开播检测的轮询间隔；
+// 直播中断的宽限期内间隔不大于宽限期的轮询间隔，尽快发现恢复
+func (w *WrappedLive) getEffectiveInterval(configured int) (time.Duration, PollingAdvice, bool) {
+	interval := time.Duration(configured) * time.Second
+	var advice PollingAdvice
+	adaptive := false
+	if pollingAdvisor != nil && !w.isLiving() {
+		if a, ok := pollingAdvisor(w, interval); ok && a.Interval > 0 {
+			interval, advice, adaptive = a.Interval, a, true
 		}
 	}
+	cfg := configs.GetCurrentConfig()
+	if w.pushConnected.Load() && cfg != nil {
+		interval = max(interval, cfg.PushDetection.GetPollInterval())
+	}
+	w.mu.Lock()
+	inGrace := time.Now().Before(w.graceUntil)
+	w.mu.Unlock()
+	if inGrace && cfg != nil {
+		interval = min(interval, cfg.SessionGrace.GetPollInterval())
+	}
+	return interval, advice, adaptive
+}
+
+// isLiving 最近一次获取的信息是否为直播中
+func (w *WrappedLive) isLiving() bool {
+	if w.cache == nil {
+		return false
+	}
+	info, err := w.cache.Get(w)
+	return err == nil && info.(*Info).Status
 }
 
 // getConfiguredInterval 获取此直播间配置的访问间隔（秒）
@@ -543,14 +913,29 @@ func randomJitter() int64 {
 	return (time.Now().UnixNano() % 6001) - 3000
 }
 
-// waitForPlatformRateLimit 在通用位置等待平台访问频率限制
+// waitForPlatformRateLimit 在通用位置等待平台某类接口的访问频率限制
 // 使用 scheduler 的 context，这样在关闭时可以被取消
-func (w *WrappedLive) waitForPlatformRateLimit() bool {
-	platformKey := configs.GetPlatformKeyFromUrl(w.GetRawUrl())
-	if platformKey != "" {
-		return ratelimit.GetGlobalRateLimiter().WaitForPlatformWithContext(w.schedulerCtx, platformKey)
+func (w *WrappedLive) waitForPlatformRateLimit(endpoint ratelimit.Endpoint) bool {
+	return ratelimit.GetGlobalRateLimiter().Wait(w.schedulerCtx, ratelimit.Request{
+		Platform: configs.GetPlatformKeyFromUrl(w.GetRawUrl()),
+		Endpoint: endpoint,
+		Room:     string(w.GetLiveId()),
+		Weight:   w.getRateLimitWeight(),
+		Priority: endpoint == ratelimit.EndpointStream && w.justWentLive(),
+	})
+}
+
+// getRateLimitWeight 获取此直播间配置的访问频率限制排队权重
+func (w *WrappedLive) getRateLimitWeight() int {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		return 1
+	}
+	room, err := cfg.GetLiveRoomByUrl(w.GetRawUrl())
+	if err != nil {
+		return 1
 	}
-	return true
+	return cfg.ResolveConfigForRoom(room, configs.GetPlatformKeyFromUrl(w.GetRawUrl())).RateLimitWeight
 }
 
 func New(ctx context.Context, room *configs.LiveRoom, cache gcache.Cache) (live Live, err error) {
@@ -558,7 +943,7 @@ func New(ctx context.Context, room *configs.LiveRoom, cache gcache.Cache) (live
 	if err != nil {
 		return nil, err
 	}
-	builder, ok := getBuilder(url.Host)
+	builder, ok := getBuilder(url)
 	if !ok {
 		return nil, errors.New("not support this url")
 	}
@@ -600,7 +985,7 @@ func NewInitializing(ctx context.Context, room *configs.LiveRoom, cache gcache.C
 	if err != nil {
 		return nil, err
 	}
-	builder, ok := getBuilder(u.Host)
+	builder, ok := getBuilder(u)
 	if !ok {
 		return nil, errors.New("not support this url")
 	}
@@ -0,0 +1,193 @@
+// Package plugin 实现外部解析插件协议
+// 插件是一个独立的可执行文件，每次调用时从 stdin 读取一个 Request JSON，向 stdout 输出一个 Response JSON，
+// 通过配置中的 plugins 声明其匹配的 host。插件直播间与内置平台一样经过 WrappedLive，
+// 因此自动获得访问频率限制、日志和录制能力
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/live/internal"
+)
+
+func init() {
+	live.RegisterResolver(resolve)
+}
+
+// resolve 根据当前配置中的插件声明匹配直播间 URL
+func resolve(u *url.URL) (live.Builder, bool) {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		return nil, false
+	}
+	plugin, ok := cfg.FindPluginForHost(u.Host)
+	if !ok {
+		return nil, false
+	}
+	return &builder{name: plugin.Name}, true
+}
+
+type builder struct {
+	name string
+}
+
+func (b *builder) Build(url *url.URL) (live.Live, error) {
+	return &Live{
+		BaseLive: internal.NewBaseLive(url),
+		name:     b.name,
+	}, nil
+}
+
+// Live 由外部插件提供信息的直播间
+type Live struct {
+	internal.BaseLive
+	name string
+}
+
+// config 每次调用时从当前配置读取插件声明，修改配置后无需重建直播间
+func (l *Live) config() (configs.PluginConfig, error) {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		return configs.PluginConfig{}, errors.New("config not initialized")
+	}
+	plugin, ok := cfg.GetPlugin(l.name)
+	if !ok {
+		return configs.PluginConfig{}, fmt.Errorf("plugin %s not found", l.name)
+	}
+	if plugin.Disabled {
+		return configs.PluginConfig{}, fmt.Errorf("plugin %s is disabled", l.name)
+	}
+	return plugin, nil
+}
+
+func (l *Live) GetPlatformCNName() string {
+	if plugin, err := l.config(); err == nil {
+		return plugin.GetCNName()
+	}
+	return l.name
+}
+
+func (l *Live) GetInfo() (*live.Info, error) {
+	resp, err := l.call(MethodGetInfo)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Info == nil {
+		return nil, fmt.Errorf("plugin %s returned no info", l.name)
+	}
+	return &live.Info{
+		Live:         l,
+		HostName:     resp.Info.HostName,
+		RoomName:     resp.Info.RoomName,
+		Status:       resp.Info.Status,
+		Cover:        resp.Info.Cover,
+		CustomLiveId: resp.Info.CustomLiveId,
+		AudioOnly:    l.Options.AudioOnly,
+	}, nil
+}
+
+func (l *Live) GetStreamInfos() ([]*live.StreamUrlInfo, error) {
+	resp, err := l.call(MethodGetStreamInfos)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]*live.StreamUrlInfo, 0, len(resp.Streams))
+	for _, stream := range resp.Streams {
+		info, err := stream.toStreamUrlInfo()
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s returned invalid stream url: %w", l.name, err)
+		}
+		infos = append(infos, info)
+	}
+	if len(infos) == 0 {
+		return nil, fmt.Errorf("plugin %s returned no stream", l.name)
+	}
+	return infos, nil
+}
+
+// call 启动插件进程执行一次调用
+func (l *Live) call(method string) (*Response, error) {
+	plugin, err := l.config()
+	if err != nil {
+		return nil, err
+	}
+
+	req := Request{
+		Version: ProtocolVersion,
+		Method:  method,
+		Url:     l.GetRawUrl(),
+	}
+	if l.Options != nil {
+		req.Quality = l.Options.Quality
+		req.AudioOnly = l.Options.AudioOnly
+		if l.Options.Cookies != nil {
+			pairs := make([]string, 0)
+			for _, c := range l.Options.Cookies.Cookies(l.Url) {
+				pairs = append(pairs, c.Name+"="+c.Value)
+			}
+			req.Cookies = strings.Join(pairs, "; ")
+		}
+	}
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), plugin.GetTimeout())
+	defer cancel()
+	cmd := exec.CommandContext(ctx, plugin.Command, plugin.Args...)
+	cmd.Env = os.Environ()
+	for k, v := range plugin.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+	if stderr.Len() > 0 && l.Logger != nil {
+		l.Logger.WithField("plugin", l.name).Debug(strings.TrimSpace(stderr.String()))
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("plugin %s timed out after %s", l.name, plugin.GetTimeout())
+	}
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("plugin %s failed: %w", l.name, runErr)
+		}
+		return nil, fmt.Errorf("plugin %s returned invalid response: %w", l.name, err)
+	}
+	if resp.Error != "" || runErr != nil {
+		return nil, responseError(l.name, &resp, runErr)
+	}
+	return &resp, nil
+}
+
+// responseError 将插件返回的错误转换为 live 包中的错误，便于上层按错误类型处理
+func responseError(name string, resp *Response, runErr error) error {
+	msg := resp.Error
+	if msg == "" {
+		msg = runErr.Error()
+	}
+	switch resp.ErrorCode {
+	case ErrorCodeRoomNotExist:
+		return fmt.Errorf("plugin %s: %s: %w", name, msg, live.ErrRoomNotExist)
+	case ErrorCodeRiskControl:
+		return fmt.Errorf("plugin %s: %s: %w", name, msg, live.ErrRiskControl)
+	default:
+		return fmt.Errorf("plugin %s: %s", name, msg)
+	}
+}
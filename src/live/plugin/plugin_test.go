@@ -0,0 +1,129 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/live"
+)
+
+// testPluginEnv 设置后测试二进制以插件模式运行，作为被测插件进程
+const testPluginEnv = "BILILIVE_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(testPluginEnv) == "1" {
+		runTestPlugin()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// runTestPlugin 一个用 Go 编写的测试插件：
+// 路径 /online 在线，/offline 未开播，/missing 返回房间不存在，/crash 非零退出且无输出
+func runTestPlugin() {
+	var req Request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		os.Exit(2)
+	}
+	u, _ := url.Parse(req.Url)
+	var resp Response
+	switch {
+	case u.Path == "/crash":
+		os.Stderr.WriteString("boom")
+		os.Exit(3)
+	case u.Path == "/missing":
+		resp = Response{Error: "no such room", ErrorCode: ErrorCodeRoomNotExist}
+	case req.Method == MethodGetInfo:
+		resp.Info = &Info{
+			HostName: "host:" + req.Cookies,
+			RoomName: "room" + u.Path,
+			Status:   u.Path == "/online",
+		}
+	case req.Method == MethodGetStreamInfos:
+		resp.Streams = []Stream{
+			{Url: "https://cdn.example.com/live.flv", Quality: "原画", Format: "flv", Headers: map[string]string{"Referer": req.Url}},
+			{Url: "https://cdn.example.com/live.m3u8", Quality: "高清", Format: "hls"},
+		}
+	default:
+		resp.Error = "unknown method " + req.Method
+	}
+	_ = json.NewEncoder(os.Stdout).Encode(resp)
+}
+
+func setupPlugin(t *testing.T) {
+	t.Helper()
+	t.Setenv(testPluginEnv, "1")
+	cfg := configs.NewConfig()
+	cfg.Plugins = []configs.PluginConfig{{
+		Name:    "example",
+		CNName:  "示例平台",
+		Command: os.Args[0],
+		Hosts:   []string{"*.example.com"},
+	}}
+	require.NoError(t, cfg.ValidatePlugins())
+	configs.SetCurrentConfig(cfg)
+}
+
+func newTestLive(t *testing.T, rawUrl string) live.Live {
+	t.Helper()
+	u, err := url.Parse(rawUrl)
+	require.NoError(t, err)
+	b, ok := resolve(u)
+	require.True(t, ok)
+	l, err := b.Build(u)
+	require.NoError(t, err)
+	require.NoError(t, l.UpdateLiveOptionsbyConfig(t.Context(), &configs.LiveRoom{Url: rawUrl}))
+	return l
+}
+
+func TestPluginGetInfo(t *testing.T) {
+	setupPlugin(t)
+	configs.GetCurrentConfig().Cookies = map[string]string{"live.example.com": "sid=abc"}
+
+	l := newTestLive(t, "https://live.example.com/online")
+	assert.Equal(t, "示例平台", l.GetPlatformCNName())
+	assert.Equal(t, "example", configs.GetPlatformKeyFromUrl(l.GetRawUrl()))
+
+	info, err := l.GetInfo()
+	require.NoError(t, err)
+	assert.True(t, info.Status)
+	assert.Equal(t, "room/online", info.RoomName)
+	assert.Equal(t, "host:sid=abc", info.HostName)
+
+	info, err = newTestLive(t, "https://live.example.com/offline").GetInfo()
+	require.NoError(t, err)
+	assert.False(t, info.Status)
+}
+
+func TestPluginGetStreamInfos(t *testing.T) {
+	setupPlugin(t)
+	streams, err := newTestLive(t, "https://live.example.com/online").GetStreamInfos()
+	require.NoError(t, err)
+	require.Len(t, streams, 2)
+	assert.Equal(t, "https://cdn.example.com/live.flv", streams[0].Url.String())
+	assert.Equal(t, "flv", streams[0].Format)
+	assert.Equal(t, "https://live.example.com/online", streams[0].HeadersForDownloader["Referer"])
+	assert.Equal(t, map[string]string{"画质": "高清", "format": "hls"}, streams[1].AttributesForStreamSelect)
+}
+
+func TestPluginErrors(t *testing.T) {
+	setupPlugin(t)
+
+	_, err := newTestLive(t, "https://live.example.com/missing").GetInfo()
+	assert.True(t, errors.Is(err, live.ErrRoomNotExist))
+
+	_, err = newTestLive(t, "https://live.example.com/crash").GetInfo()
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "exit status 3"))
+
+	_, ok := resolve(&url.URL{Host: "www.other.com"})
+	assert.False(t, ok)
+}
@@ -0,0 +1,102 @@
+package plugin
+
+import (
+	"net/url"
+
+	"github.com/bililive-go/bililive-go/src/live"
+)
+
+// ProtocolVersion 当前插件协议版本
+const ProtocolVersion = 1
+
+// 插件支持的方法
+const (
+	MethodGetInfo        = "get_info"
+	MethodGetStreamInfos = "get_stream_infos"
+)
+
+// 插件可返回的错误码，会被转换为 live 包中对应的错误
+const (
+	ErrorCodeRoomNotExist = "room_not_exist"
+	ErrorCodeRiskControl  = "risk_control"
+)
+
+// Request 每次调用时写入插件 stdin 的 JSON
+type Request struct {
+	Version   int    `json:"version"`
+	Method    string `json:"method"`
+	Url       string `json:"url"`
+	Cookies   string `json:"cookies,omitempty"` // 该直播间使用的 Cookie，格式为 "k1=v1; k2=v2"
+	Quality   int    `json:"quality"`
+	AudioOnly bool   `json:"audio_only"`
+}
+
+// Response 插件从 stdout 输出的 JSON
+type Response struct {
+	// Error 非空表示调用失败，ErrorCode 可选，取值见 ErrorCode* 常量
+	Error     string `json:"error,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"`
+
+	Info    *Info    `json:"info,omitempty"`    // get_info 的结果
+	Streams []Stream `json:"streams,omitempty"` // get_stream_infos 的结果
+}
+
+// Info 直播间信息
+type Info struct {
+	HostName     string `json:"host_name"`
+	RoomName     string `json:"room_name"`
+	Status       bool   `json:"status"`
+	Cover        string `json:"cover,omitempty"`
+	CustomLiveId string `json:"custom_live_id,omitempty"`
+}
+
+// Stream 一路可录制的流
+type Stream struct {
+	Url         string            `json:"url"`
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Quality     string            `json:"quality,omitempty"`
+	Format      string            `json:"format,omitempty"`
+	Width       int               `json:"width,omitempty"`
+	Height      int               `json:"height,omitempty"`
+	Bitrate     int               `json:"bitrate,omitempty"`
+	FrameRate   float64           `json:"frame_rate,omitempty"`
+	Codec       string            `json:"codec,omitempty"`
+	AudioCodec  string            `json:"audio_codec,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"` // 下载时需要附带的请求头
+}
+
+func (s Stream) toStreamUrlInfo() (*live.StreamUrlInfo, error) {
+	u, err := url.Parse(s.Url)
+	if err != nil {
+		return nil, err
+	}
+	info := &live.StreamUrlInfo{
+		Url:                  u,
+		Name:                 s.Name,
+		Description:          s.Description,
+		Quality:              s.Quality,
+		Format:               s.Format,
+		Width:                s.Width,
+		Height:               s.Height,
+		Bitrate:              s.Bitrate,
+		FrameRate:            s.FrameRate,
+		Codec:                s.Codec,
+		AudioCodec:           s.AudioCodec,
+		HeadersForDownloader: s.Headers,
+	}
+	attributes := make(map[string]string)
+	if s.Quality != "" {
+		attributes["画质"] = s.Quality
+	}
+	if s.Format != "" {
+		attributes["format"] = s.Format
+	}
+	if s.Codec != "" {
+		attributes["codec"] = s.Codec
+	}
+	if len(attributes) > 0 {
+		info.AttributesForStreamSelect = attributes
+	}
+	return info, nil
+}
@@ -0,0 +1,54 @@
+package live
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// RiskControlError 请求被平台风控拦截的详细信息，errors.Is(err, ErrRiskControl) 为 true
+type RiskControlError struct {
+	Platform   string // 平台标识，如 bilibili
+	StatusCode int    // HTTP 状态码
+	Code       int64  // 平台接口返回码，没有时为 0
+	Reason     string // 风控原因
+}
+
+func (e *RiskControlError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrRiskControl.Error(), e.Reason)
+}
+
+// Is 使 errors.Is(err, ErrRiskControl) 对所有风控错误成立
+func (e *RiskControlError) Is(target error) bool {
+	return target == ErrRiskControl
+}
+
+// RiskControlDetector 根据平台接口的响应判断是否被风控拦截，未被拦截时返回 nil
+type RiskControlDetector func(statusCode int, body []byte) *RiskControlError
+
+var riskControlDetectors = make(map[string]RiskControlDetector)
+
+// RegisterRiskControlDetector 注册平台的风控检测器，platform 为平台标识，需要在 init 中调用
+func RegisterRiskControlDetector(platform string, detector RiskControlDetector) {
+	riskControlDetectors[platform] = detector
+}
+
+// DetectRiskControl 检查平台接口的响应是否表示被风控拦截，是则返回 *RiskControlError
+// HTTP 412 和 429 对所有平台都视为风控，其他情况交给平台注册的检测器判断（例如接口返回码、验证码）
+func DetectRiskControl(platform string, statusCode int, body []byte) error {
+	switch statusCode {
+	case http.StatusPreconditionFailed, http.StatusTooManyRequests:
+		return &RiskControlError{
+			Platform:   platform,
+			StatusCode: statusCode,
+			Reason:     fmt.Sprintf("HTTP %d", statusCode),
+		}
+	}
+	if detector, ok := riskControlDetectors[platform]; ok {
+		if e := detector(statusCode, body); e != nil {
+			e.Platform = platform
+			e.StatusCode = statusCode
+			return e
+		}
+	}
+	return nil
+}
@@ -58,6 +58,36 @@ func RegisterEventListeners(ed events.Dispatcher, manager *Manager, cache gcache
 		manager.OnLiveEnd(liveID)
 	}))
 
+	// 监听直播中断事件（宽限期内会话保持打开）
+	ed.AddEventListener(listeners.LiveInterrupted, events.NewEventListener(func(event *events.Event) {
+		l, ok := event.Object.(live.Live)
+		if !ok {
+			return
+		}
+
+		manager.OnLiveInterrupted(string(l.GetLiveId()))
+	}))
+
+	// 监听宽限期内直播恢复事件
+	ed.AddEventListener(listeners.LiveResumed, events.NewEventListener(func(event *events.Event) {
+		l, ok := event.Object.(live.Live)
+		if !ok {
+			return
+		}
+
+		manager.OnLiveResumed(string(l.GetLiveId()))
+	}))
+
+	// 监听停止监控事件（停止监控或删除直播间）
+	ed.AddEventListener(listeners.ListenStop, events.NewEventListener(func(event *events.Event) {
+		l, ok := event.Object.(live.Live)
+		if !ok {
+			return
+		}
+
+		manager.OnListenStop(string(l.GetLiveId()))
+	}))
+
 	// 监听录制开始事件
 	ed.AddEventListener(recorders.RecorderStart, events.NewEventListener(func(event *events.Event) {
 		l, ok := event.Object.(live.Live)
@@ -80,6 +110,27 @@ func RegisterEventListeners(ed events.Dispatcher, manager *Manager, cache gcache
 		manager.OnRecordingStop(liveID)
 	}))
 
+	// 监听录制被抢占事件
+	ed.AddEventListener(recorders.RecorderPreempted, events.NewEventListener(func(event *events.Event) {
+		param, ok := event.Object.(recorders.PreemptedParam)
+		if !ok || param.Live == nil || param.By == nil {
+			return
+		}
+
+		manager.OnRecordingPreempted(string(param.Live.GetLiveId()), param.Priority, string(param.By.GetLiveId()), param.ByPriority)
+	}))
+
+	// 监听下载器切换事件
+	ed.AddEventListener(recorders.RecorderDownloaderSwitched, events.NewEventListener(func(event *events.Event) {
+		param, ok := event.Object.(recorders.DownloaderSwitchedParam)
+		if !ok || param.Live == nil {
+			return
+		}
+
+		manager.OnDownloaderSwitched(string(param.Live.GetLiveId()), string(param.From), string(param.To),
+			param.Failures, param.Reason, param.SwitchedAt)
+	}))
+
 	// 监听直播间初始化完成事件（用于保存初始信息）
 	ed.AddEventListener(listeners.RoomInitializingFinished, events.NewEventListener(func(event *events.Event) {
 		param, ok := event.Object.(live.InitializingFinishedParam)
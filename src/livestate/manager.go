@@ -20,7 +20,9 @@ type Manager struct {
 	heartbeatTicker *time.Ticker
 	ctx             context.Context
 	cancel          context.CancelFunc
-	recordingRooms  map[string]bool // 当前正在录制的直播间
+	recordingRooms  map[string]bool      // 当前正在录制的直播间
+	patterns        *patternCache        // 开播规律模型缓存，用于自适应轮询
+	interruptions   map[string]time.Time // 处于宽限期的直播间 -> 直播中断的时间
 	mu              sync.RWMutex
 }
 
@@ -37,6 +39,8 @@ func NewManager(dbPath string) (*Manager, error) {
 		ctx:            ctx,
 		cancel:         cancel,
 		recordingRooms: make(map[string]bool),
+		patterns:       newPatternCache(),
+		interruptions:  make(map[string]time.Time),
 	}, nil
 }
 
@@ -141,6 +145,8 @@ func (m *Manager) RecoverFromCrash() error {
 // OnLiveStart 直播开始时调用
 func (m *Manager) OnLiveStart(liveID, url, platform, hostName, roomName string) {
 	now := time.Now()
+	// 新的会话开始，之前遗留的中断记录不再适用
+	m.clearInterruption(liveID)
 
 	// 更新直播间信息
 	room := &LiveRoom{
@@ -161,6 +167,7 @@ func (m *Manager) OnLiveStart(liveID, url, platform, hostName, roomName string)
 	if _, err := m.store.StartSession(m.ctx, liveID, hostName, roomName, now); err != nil {
 		logrus.WithError(err).WithField("live_id", liveID).Warn("创建直播会话失败")
 	}
+	m.patterns.invalidate(liveID)
 
 	logrus.WithFields(logrus.Fields{
 		"live_id":   liveID,
@@ -174,9 +181,52 @@ func (m *Manager) OnLiveEnd(liveID string) {
 	m.OnLiveEndWithReason(liveID, EndReasonNormal)
 }
 
+// OnLiveInterrupted 直播中断、进入宽限期时调用，会话保持打开
+// 宽限期内恢复时继续同一个会话，否则在宽限期结束后以中断时间结束会话
+func (m *Manager) OnLiveInterrupted(liveID string) {
+	m.mu.Lock()
+	if _, ok := m.interruptions[liveID]; !ok {
+		m.interruptions[liveID] = time.Now()
+	}
+	m.mu.Unlock()
+
+	logrus.WithField("live_id", liveID).Debug("记录直播中断")
+}
+
+// OnListenStop 停止监控直播间（包括删除直播间）时调用，清除宽限期的中断记录
+// 避免之后的下播使用停止监控前的中断时间结束会话
+func (m *Manager) OnListenStop(liveID string) {
+	m.clearInterruption(liveID)
+}
+
+// clearInterruption 清除直播间的中断记录
+func (m *Manager) clearInterruption(liveID string) {
+	m.mu.Lock()
+	delete(m.interruptions, liveID)
+	m.mu.Unlock()
+}
+
+// OnLiveResumed 宽限期内直播恢复时调用，记录会话的中断恢复次数
+func (m *Manager) OnLiveResumed(liveID string) {
+	m.clearInterruption(liveID)
+
+	if err := m.store.IncrementSessionFlap(m.ctx, liveID); err != nil {
+		logrus.WithError(err).WithField("live_id", liveID).Warn("记录直播中断恢复失败")
+	}
+
+	logrus.WithField("live_id", liveID).Debug("记录直播中断后恢复")
+}
+
 // OnLiveEndWithReason 直播结束时调用（指定结束原因）
+// 直播间处于宽限期时使用中断的时间作为结束时间
 func (m *Manager) OnLiveEndWithReason(liveID string, reason string) {
 	now := time.Now()
+	m.mu.Lock()
+	if interruptedAt, ok := m.interruptions[liveID]; ok {
+		now = interruptedAt
+		delete(m.interruptions, liveID)
+	}
+	m.mu.Unlock()
 
 	// 更新关播时间
 	if err := m.store.UpdateLiveEndTime(m.ctx, liveID, now); err != nil {
@@ -315,16 +365,115 @@ func (m *Manager) GetPreviouslyRecordingRooms() []*LiveRoom {
 	return rooms
 }
 
-// GetSessionHistory 获取直播间的会话历史
+// RecordSessionFile 将录制完成的文件关联到直播间最近的会话
+func (m *Manager) RecordSessionFile(liveID, filePath string) {
+	if err := m.store.AddSessionFile(m.ctx, liveID, filePath, time.Now()); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"live_id": liveID,
+			"file":    filePath,
+		}).Warn("关联会话录制文件失败")
+	}
+}
+
+// GetRecentSessionFiles 获取最近的录制文件（按录制完成时间倒序），liveID 为空时返回所有直播间的录制文件
+func (m *Manager) GetRecentSessionFiles(liveID string, limit int) []*SessionFile {
+	files, err := m.store.GetRecentSessionFiles(m.ctx, liveID, limit)
+	if err != nil {
+		logrus.WithError(err).WithField("live_id", liveID).Warn("获取录制文件失败")
+		return nil
+	}
+	return files
+}
+
+// GetSessionFile 按 ID 获取录制文件记录
+func (m *Manager) GetSessionFile(id int64) (*SessionFile, error) {
+	return m.store.GetSessionFile(m.ctx, id)
+}
+
+// GetSessionHistory 获取直播间的会话历史（包含每个会话的录制文件）
 func (m *Manager) GetSessionHistory(liveID string, limit int) []*LiveSession {
 	sessions, err := m.store.GetSessionsByLiveID(m.ctx, liveID, limit)
 	if err != nil {
 		logrus.WithError(err).WithField("live_id", liveID).Warn("获取会话历史失败")
 		return nil
 	}
+	ids := make([]int64, len(sessions))
+	for i, session := range sessions {
+		ids[i] = session.ID
+	}
+	files, err := m.store.GetSessionFiles(m.ctx, ids)
+	if err != nil {
+		logrus.WithError(err).WithField("live_id", liveID).Debug("获取会话录制文件失败")
+		return sessions
+	}
+	for _, session := range sessions {
+		session.Files = files[session.ID]
+	}
 	return sessions
 }
 
+// OnRecordingPreempted 录制被优先级更高的直播间抢占时调用
+func (m *Manager) OnRecordingPreempted(liveID string, priority int, byLiveID string, byPriority int) {
+	p := &Preemption{
+		LiveID:              liveID,
+		Priority:            priority,
+		PreemptedBy:         byLiveID,
+		PreemptedByPriority: byPriority,
+		PreemptedAt:         time.Now(),
+	}
+	if err := m.store.RecordPreemption(m.ctx, p); err != nil {
+		logrus.WithError(err).WithField("live_id", liveID).Warn("记录录制抢占失败")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"live_id":      liveID,
+		"preempted_by": byLiveID,
+	}).Debug("记录录制被抢占")
+}
+
+// GetPreemptionHistory 获取直播间录制被抢占的历史
+func (m *Manager) GetPreemptionHistory(liveID string, limit int) []*Preemption {
+	preemptions, err := m.store.GetPreemptions(m.ctx, liveID, limit)
+	if err != nil {
+		logrus.WithError(err).WithField("live_id", liveID).Warn("获取录制抢占历史失败")
+		return nil
+	}
+	return preemptions
+}
+
+// OnDownloaderSwitched 下载器连续快速失败后切换时调用
+func (m *Manager) OnDownloaderSwitched(liveID, from, to string, failures int, reason string, switchedAt time.Time) {
+	sw := &DownloaderSwitch{
+		LiveID:     liveID,
+		From:       from,
+		To:         to,
+		Failures:   failures,
+		Reason:     reason,
+		SwitchedAt: switchedAt,
+	}
+	if err := m.store.RecordDownloaderSwitch(m.ctx, sw); err != nil {
+		logrus.WithError(err).WithField("live_id", liveID).Warn("记录下载器切换失败")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"live_id": liveID,
+		"from":    from,
+		"to":      to,
+	}).Debug("记录下载器切换")
+}
+
+// GetDownloaderSwitchHistory 获取直播间的下载器切换历史
+func (m *Manager) GetDownloaderSwitchHistory(liveID string, limit int) []*DownloaderSwitch {
+	switches, err := m.store.GetDownloaderSwitches(m.ctx, liveID, limit)
+	if err != nil {
+		logrus.WithError(err).WithField("live_id", liveID).Warn("获取下载器切换历史失败")
+		return nil
+	}
+	return switches
+}
+
 // GetNameHistory 获取直播间的名称变更历史
 func (m *Manager) GetNameHistory(liveID string, limit int) []*NameChange {
 	changes, err := m.store.GetNameHistory(m.ctx, liveID, limit)
@@ -335,6 +484,30 @@ func (m *Manager) GetNameHistory(liveID string, limit int) []*NameChange {
 	return changes
 }
 
+// GetRoomTitlesSince 获取 since 之后使用过的直播间标题，按时间顺序去重
+// 包含 since 时正在使用的标题；期间没有变更时返回空，由调用方使用当前标题
+func (m *Manager) GetRoomTitlesSince(liveID string, since time.Time) []string {
+	changes := m.GetNameHistory(liveID, 100)
+	var titles []string
+	seen := make(map[string]bool)
+	add := func(title string) {
+		if title != "" && !seen[title] {
+			seen[title] = true
+			titles = append(titles, title)
+		}
+	}
+	// 历史按时间倒序返回
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		if c.NameType != NameTypeRoom || c.ChangedAt.Before(since) {
+			continue
+		}
+		add(c.OldValue)
+		add(c.NewValue)
+	}
+	return titles
+}
+
 // GetStore 获取底层存储（用于测试或高级操作）
 func (m *Manager) GetStore() Store {
 	return m.store
@@ -0,0 +1,64 @@
+package livestate
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	m, err := NewManager(filepath.Join(t.TempDir(), "state.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = m.Close() })
+	return m
+}
+
+func (m *Manager) hasInterruption(liveID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.interruptions[liveID]
+	return ok
+}
+
+func TestInterruptionClearedOnListenStop(t *testing.T) {
+	m := newTestManager(t)
+	m.OnLiveStart("1", "https://live.bilibili.com/1", "bilibili", "host", "room")
+	m.OnLiveInterrupted("1")
+	require.True(t, m.hasInterruption("1"))
+
+	// 宽限期内停止监控，之后重新开播的会话不能使用之前的中断时间
+	m.OnListenStop("1")
+	assert.False(t, m.hasInterruption("1"))
+
+	m.OnLiveStart("1", "https://live.bilibili.com/1", "bilibili", "host", "room")
+	m.OnLiveEnd("1")
+	sessions := m.GetSessionHistory("1", 1)
+	require.Len(t, sessions, 1)
+	assert.False(t, sessions[0].EndTime.Before(sessions[0].StartTime))
+}
+
+func TestInterruptionClearedOnLiveStart(t *testing.T) {
+	m := newTestManager(t)
+	m.OnLiveStart("1", "https://live.bilibili.com/1", "bilibili", "host", "room")
+	m.OnLiveInterrupted("1")
+
+	// 没有经过恢复或下播直接开始新的会话
+	m.OnLiveStart("1", "https://live.bilibili.com/1", "bilibili", "host", "room")
+	assert.False(t, m.hasInterruption("1"))
+}
+
+func TestGetSessionHistoryFiles(t *testing.T) {
+	m := newTestManager(t)
+	m.OnLiveStart("1", "https://live.bilibili.com/1", "bilibili", "host", "room")
+	m.OnLiveEnd("1")
+	require.NoError(t, m.store.AddSessionFile(m.ctx, "1", "/a.flv", time.Now()))
+	require.NoError(t, m.store.AddSessionFile(m.ctx, "1", "/b.flv", time.Now().Add(time.Second)))
+
+	sessions := m.GetSessionHistory("1", 10)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, []string{"/a.flv", "/b.flv"}, sessions[0].Files)
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_session_files_session_id;
+DROP TABLE IF EXISTS session_files;
+-- 无法直接删除列，SQLite 不支持 DROP COLUMN
+-- 需要重建表，但这里只做标记
+-- 实际回滚需要手动处理
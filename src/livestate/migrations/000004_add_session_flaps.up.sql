@@ -0,0 +1,15 @@
+-- 在 live_sessions 表中添加中断恢复次数字段（宽限期内中断后恢复的直播合并为同一个会话）
+ALTER TABLE live_sessions ADD COLUMN flap_count INTEGER DEFAULT 0;
+
+-- 会话录制文件表（记录每个直播会话产生的录制文件）
+CREATE TABLE IF NOT EXISTS session_files (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER NOT NULL,            -- 直播会话ID
+    live_id TEXT NOT NULL,                  -- 直播间ID
+    file_path TEXT NOT NULL,                -- 录制文件路径
+    created_at INTEGER DEFAULT 0,           -- 录制完成时间 (Unix timestamp)
+    FOREIGN KEY (session_id) REFERENCES live_sessions(id) ON DELETE CASCADE
+);
+
+-- 索引
+CREATE INDEX IF NOT EXISTS idx_session_files_session_id ON session_files(session_id);
@@ -0,0 +1,2 @@
+-- 删除录制抢占历史表
+DROP TABLE IF EXISTS recording_preemptions;
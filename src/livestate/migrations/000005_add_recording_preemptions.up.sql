@@ -0,0 +1,13 @@
+-- 录制抢占历史表（达到同时录制上限时，优先级更高的直播间抢占了录制位置）
+CREATE TABLE IF NOT EXISTS recording_preemptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    live_id TEXT NOT NULL,                  -- 被抢占的直播间ID
+    priority INTEGER DEFAULT 0,             -- 被抢占的直播间的录制优先级
+    preempted_by TEXT NOT NULL,             -- 抢占录制位置的直播间ID
+    preempted_by_priority INTEGER DEFAULT 0,-- 抢占的直播间的录制优先级
+    preempted_at INTEGER DEFAULT 0,         -- 抢占时间 (Unix timestamp)
+    FOREIGN KEY (live_id) REFERENCES live_rooms(live_id) ON DELETE CASCADE
+);
+
+-- 索引
+CREATE INDEX IF NOT EXISTS idx_recording_preemptions_live_id ON recording_preemptions(live_id);
@@ -0,0 +1,2 @@
+-- 删除下载器切换历史表
+DROP TABLE IF EXISTS downloader_switches;
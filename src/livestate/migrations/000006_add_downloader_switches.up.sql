@@ -0,0 +1,14 @@
+-- 下载器切换历史表（下载器连续快速失败后切换到回退链中的下一个下载器）
+CREATE TABLE IF NOT EXISTS downloader_switches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    live_id TEXT NOT NULL,                  -- 直播间ID
+    from_downloader TEXT NOT NULL,          -- 切换前的下载器
+    to_downloader TEXT NOT NULL,            -- 切换后的下载器
+    failures INTEGER DEFAULT 0,             -- 切换前连续快速失败的次数
+    reason TEXT DEFAULT '',                 -- 最后一次快速失败的原因
+    switched_at INTEGER DEFAULT 0,          -- 切换时间 (Unix timestamp)
+    FOREIGN KEY (live_id) REFERENCES live_rooms(live_id) ON DELETE CASCADE
+);
+
+-- 索引
+CREATE INDEX IF NOT EXISTS idx_downloader_switches_live_id ON downloader_switches(live_id);
@@ -0,0 +1,3 @@
+-- 删除按直播间查询录制文件的索引
+DROP INDEX IF EXISTS idx_session_files_created_at;
+DROP INDEX IF EXISTS idx_session_files_live_id_created_at;
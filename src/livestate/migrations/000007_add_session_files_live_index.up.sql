@@ -0,0 +1,3 @@
+-- 按直播间查询最近录制文件（订阅源）使用的索引
+CREATE INDEX IF NOT EXISTS idx_session_files_live_id_created_at ON session_files(live_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_session_files_created_at ON session_files(created_at);
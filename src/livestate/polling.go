@@ -0,0 +1,106 @@
+package livestate
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/pkg/livepattern"
+	bilisentry "github.com/bililive-go/bililive-go/src/pkg/sentry"
+)
+
+const (
+	// patternRefreshInterval 开播规律模型的重建间隔，期间有新的开播记录时立即重建
+	patternRefreshInterval = time.Hour
+	// patternSessionLimit 学习开播规律时最多读取的历史场次
+	patternSessionLimit = 500
+	// patternMinSessionDuration 短于该时长的场次视为误报，不参与学习
+	patternMinSessionDuration = time.Minute
+)
+
+// patternCache 各直播间的开播规律模型缓存
+// 模型在后台重建，调度器计算下一次请求时间时只读取缓存，不会在调度器的锁内访问数据库
+type patternCache struct {
+	mu       sync.Mutex
+	entries  map[string]patternEntry
+	building map[string]bool
+}
+
+type patternEntry struct {
+	model   *livepattern.Model
+	builtAt time.Time
+	stale   bool
+}
+
+func newPatternCache() *patternCache {
+	return &patternCache{
+		entries:  make(map[string]patternEntry),
+		building: make(map[string]bool),
+	}
+}
+
+// invalidate 直播间有新的开播记录时将缓存的模型标记为过期，重建完成前仍使用旧模型
+func (c *patternCache) invalidate(liveID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := c.entries[liveID]
+	entry.stale = true
+	c.entries[liveID] = entry
+}
+
+// getPattern 获取直播间缓存的开播规律模型，缓存不存在或过期时在后台重建
+// 首次重建完成前返回 nil
+func (m *Manager) getPattern(liveID string, historyDays int, now time.Time) *livepattern.Model {
+	c := m.patterns
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[liveID]
+	if (!ok || entry.stale || now.Sub(entry.builtAt) >= patternRefreshInterval) && !c.building[liveID] {
+		c.building[liveID] = true
+		// 重建期间的 invalidate 会让新模型保持过期状态，下次调用时再次重建
+		entry.stale = false
+		c.entries[liveID] = entry
+		bilisentry.Go(func() { m.rebuildPattern(liveID, historyDays, now) })
+	}
+	return entry.model
+}
+
+// rebuildPattern 根据会话历史重建直播间的开播规律模型
+func (m *Manager) rebuildPattern(liveID string, historyDays int, now time.Time) {
+	since := now.AddDate(0, 0, -historyDays)
+	starts, err := m.store.GetSessionStartTimes(m.ctx, liveID, since, patternMinSessionDuration, patternSessionLimit)
+	if err != nil {
+		logrus.WithError(err).WithField("live_id", liveID).Debug("获取开播时间失败")
+	}
+	model := livepattern.Build(starts, now)
+
+	c := m.patterns
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.building, liveID)
+	entry := c.entries[liveID]
+	c.entries[liveID] = patternEntry{model: model, builtAt: now, stale: entry.stale}
+}
+
+// PollingAdvice 根据直播间的历史开播时间给出检测间隔建议，可作为 live.PollingAdvisor 使用
+// 未开启自适应轮询或历史场次不足时返回 false
+func (m *Manager) PollingAdvice(l live.Live, base time.Duration) (live.PollingAdvice, bool) {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil || !cfg.AdaptivePolling.Enable {
+		return live.PollingAdvice{}, false
+	}
+	now := time.Now()
+	model := m.getPattern(string(l.GetLiveId()), cfg.AdaptivePolling.GetHistoryDays(), now)
+	if model == nil || model.Sessions() < cfg.AdaptivePolling.GetMinSessions() {
+		return live.PollingAdvice{}, false
+	}
+	min, max := cfg.GetAdaptivePollingBounds(configs.GetPlatformKeyFromUrl(l.GetRawUrl()))
+	interval, next, ok := model.Interval(now, base, min, max, cfg.AdaptivePolling.GetWindow())
+	if !ok {
+		return live.PollingAdvice{}, false
+	}
+	return live.PollingAdvice{Interval: interval, PredictedNextStart: next}, true
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,6 +24,8 @@ var (
 	ErrLiveRoomNotFound = errors.New("live room not found")
 	// ErrSessionNotFound 会话不存在
 	ErrSessionNotFound = errors.New("session not found")
+	// ErrSessionFileNotFound 录制文件记录不存在
+	ErrSessionFileNotFound = errors.New("session file not found")
 )
 
 // Store 直播间状态存储接口
@@ -44,11 +47,25 @@ type Store interface {
 	EndSessionByHeartbeat(ctx context.Context, liveID string, reason string) error
 	GetOpenSessions(ctx context.Context) ([]*LiveSession, error)
 	GetSessionsByLiveID(ctx context.Context, liveID string, limit int) ([]*LiveSession, error)
+	GetSessionStartTimes(ctx context.Context, liveID string, since time.Time, minDuration time.Duration, limit int) ([]time.Time, error)
+	IncrementSessionFlap(ctx context.Context, liveID string) error
+	AddSessionFile(ctx context.Context, liveID, filePath string, createdAt time.Time) error
+	GetSessionFiles(ctx context.Context, sessionIDs []int64) (map[int64][]string, error)
+	GetRecentSessionFiles(ctx context.Context, liveID string, limit int) ([]*SessionFile, error)
+	GetSessionFile(ctx context.Context, id int64) (*SessionFile, error)
 
 	// 名称变更历史
 	RecordNameChange(ctx context.Context, liveID, nameType, oldValue, newValue string) error
 	GetNameHistory(ctx context.Context, liveID string, limit int) ([]*NameChange, error)
 
+	// 录制抢占历史
+	RecordPreemption(ctx context.Context, p *Preemption) error
+	GetPreemptions(ctx context.Context, liveID string, limit int) ([]*Preemption, error)
+
+	// 下载器切换历史
+	RecordDownloaderSwitch(ctx context.Context, sw *DownloaderSwitch) error
+	GetDownloaderSwitches(ctx context.Context, liveID string, limit int) ([]*DownloaderSwitch, error)
+
 	// 可用流信息
 	SaveAvailableStreams(ctx context.Context, liveID string, streams []*AvailableStream) error
 	GetAvailableStreams(ctx context.Context, liveID string) ([]*AvailableStream, error)
@@ -450,7 +467,7 @@ func (s *SQLiteStore) GetOpenSessions(ctx context.Context) ([]*LiveSession, erro
 	defer s.mu.RUnlock()
 
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, live_id, start_time, end_time, end_reason, created_at
+		SELECT id, live_id, host_name, room_name, start_time, end_time, end_reason, flap_count, created_at
 		FROM live_sessions WHERE end_time = 0
 	`)
 	if err != nil {
@@ -467,7 +484,7 @@ func (s *SQLiteStore) GetSessionsByLiveID(ctx context.Context, liveID string, li
 	defer s.mu.RUnlock()
 
 	query := `
-		SELECT id, live_id, host_name, room_name, start_time, end_time, end_reason, created_at
+		SELECT id, live_id, host_name, room_name, start_time, end_time, end_reason, flap_count, created_at
 		FROM live_sessions WHERE live_id = ? ORDER BY start_time DESC
 	`
 	if limit > 0 {
@@ -483,6 +500,169 @@ func (s *SQLiteStore) GetSessionsByLiveID(ctx context.Context, liveID string, li
 	return s.scanSessions(rows)
 }
 
+// GetSessionStartTimes 获取直播间 since 之后开始的会话的开始时间（按开始时间倒序），
+// 已结束且时长短于 minDuration 的会话视为误报，不返回
+func (s *SQLiteStore) GetSessionStartTimes(ctx context.Context, liveID string, since time.Time, minDuration time.Duration, limit int) ([]time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	query := `
+		SELECT start_time FROM live_sessions
+		WHERE live_id = ? AND start_time >= ? AND (end_time = 0 OR end_time - start_time >= ?)
+		ORDER BY start_time DESC
+	`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, liveID, since.Unix(), int64(minDuration/time.Second))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var starts []time.Time
+	for rows.Next() {
+		var startTime int64
+		if err := rows.Scan(&startTime); err != nil {
+			return nil, err
+		}
+		starts = append(starts, time.Unix(startTime, 0))
+	}
+	return starts, rows.Err()
+}
+
+// IncrementSessionFlap 当前打开的直播会话的中断恢复次数加一
+func (s *SQLiteStore) IncrementSessionFlap(ctx context.Context, liveID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE live_sessions SET flap_count = flap_count + 1
+		WHERE live_id = ? AND end_time = 0
+	`, liveID)
+	return err
+}
+
+// AddSessionFile 将录制文件关联到直播间最近的会话（录制可能在会话结束后才完成）
+func (s *SQLiteStore) AddSessionFile(ctx context.Context, liveID, filePath string, createdAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sessionID int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id FROM live_sessions WHERE live_id = ? ORDER BY start_time DESC, id DESC LIMIT 1
+	`, liveID).Scan(&sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO session_files (session_id, live_id, file_path, created_at) VALUES (?, ?, ?, ?)
+	`, sessionID, liveID, filePath, createdAt.Unix())
+	return err
+}
+
+// GetSessionFiles 批量获取直播会话的录制文件列表（按录制完成时间排序），返回会话 ID -> 文件列表
+func (s *SQLiteStore) GetSessionFiles(ctx context.Context, sessionIDs []int64) (map[int64][]string, error) {
+	files := make(map[int64][]string)
+	if len(sessionIDs) == 0 {
+		return files, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(sessionIDs)), ",")
+	args := make([]any, len(sessionIDs))
+	for i, id := range sessionIDs {
+		args[i] = id
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT session_id, file_path FROM session_files
+		WHERE session_id IN (`+placeholders+`) ORDER BY created_at, id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sessionID int64
+		var path string
+		if err := rows.Scan(&sessionID, &path); err != nil {
+			return nil, err
+		}
+		files[sessionID] = append(files[sessionID], path)
+	}
+	return files, rows.Err()
+}
+
+// sessionFileColumns 查询录制文件时选择的列，主播名称取自文件所属的会话
+const sessionFileColumns = `
+	SELECT f.id, f.session_id, f.live_id, COALESCE(s.host_name, ''), f.file_path, f.created_at
+	FROM session_files f LEFT JOIN live_sessions s ON s.id = f.session_id
+`
+
+// GetRecentSessionFiles 获取最近的录制文件（按录制完成时间倒序），liveID 为空时返回所有直播间的录制文件
+func (s *SQLiteStore) GetRecentSessionFiles(ctx context.Context, liveID string, limit int) ([]*SessionFile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	query := sessionFileColumns
+	var args []any
+	if liveID != "" {
+		query += " WHERE f.live_id = ?"
+		args = append(args, liveID)
+	}
+	query += " ORDER BY f.created_at DESC, f.id DESC"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []*SessionFile
+	for rows.Next() {
+		file, err := scanSessionFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, rows.Err()
+}
+
+// GetSessionFile 按 ID 获取录制文件记录
+func (s *SQLiteStore) GetSessionFile(ctx context.Context, id int64) (*SessionFile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	file, err := scanSessionFile(s.db.QueryRowContext(ctx, sessionFileColumns+" WHERE f.id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSessionFileNotFound
+	}
+	return file, err
+}
+
+// scanSessionFile 扫描一条录制文件记录
+func scanSessionFile(row interface{ Scan(dest ...any) error }) (*SessionFile, error) {
+	file := &SessionFile{}
+	var createdAt int64
+	if err := row.Scan(&file.ID, &file.SessionID, &file.LiveID, &file.HostName, &file.FilePath, &createdAt); err != nil {
+		return nil, err
+	}
+	file.CreatedAt = time.Unix(createdAt, 0)
+	return file, nil
+}
+
 // scanSessions 从 rows 扫描会话列表
 func (s *SQLiteStore) scanSessions(rows *sql.Rows) ([]*LiveSession, error) {
 	var sessions []*LiveSession
@@ -491,14 +671,16 @@ func (s *SQLiteStore) scanSessions(rows *sql.Rows) ([]*LiveSession, error) {
 		var startTime, endTime int64
 		var createdAtStr string
 		var hostName, roomName sql.NullString
+		var flapCount sql.NullInt64
 
-		err := rows.Scan(&session.ID, &session.LiveID, &hostName, &roomName, &startTime, &endTime, &session.EndReason, &createdAtStr)
+		err := rows.Scan(&session.ID, &session.LiveID, &hostName, &roomName, &startTime, &endTime, &session.EndReason, &flapCount, &createdAtStr)
 		if err != nil {
 			return nil, err
 		}
 
 		session.HostName = hostName.String
 		session.RoomName = roomName.String
+		session.FlapCount = int(flapCount.Int64)
 
 		if startTime > 0 {
 			session.StartTime = time.Unix(startTime, 0)
@@ -518,6 +700,98 @@ func (s *SQLiteStore) scanSessions(rows *sql.Rows) ([]*LiveSession, error) {
 	return sessions, nil
 }
 
+// RecordPreemption 记录录制被抢占
+func (s *SQLiteStore) RecordPreemption(ctx context.Context, p *Preemption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO recording_preemptions (live_id, priority, preempted_by, preempted_by_priority, preempted_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, p.LiveID, p.Priority, p.PreemptedBy, p.PreemptedByPriority, p.PreemptedAt.Unix())
+	return err
+}
+
+// GetPreemptions 获取直播间录制被抢占的历史（按时间倒序）
+func (s *SQLiteStore) GetPreemptions(ctx context.Context, liveID string, limit int) ([]*Preemption, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	query := `
+		SELECT id, live_id, priority, preempted_by, preempted_by_priority, preempted_at
+		FROM recording_preemptions WHERE live_id = ? ORDER BY preempted_at DESC, id DESC
+	`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, liveID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var preemptions []*Preemption
+	for rows.Next() {
+		p := &Preemption{}
+		var preemptedAt int64
+		if err := rows.Scan(&p.ID, &p.LiveID, &p.Priority, &p.PreemptedBy, &p.PreemptedByPriority, &preemptedAt); err != nil {
+			return nil, err
+		}
+		if preemptedAt > 0 {
+			p.PreemptedAt = time.Unix(preemptedAt, 0)
+		}
+		preemptions = append(preemptions, p)
+	}
+	return preemptions, rows.Err()
+}
+
+// RecordDownloaderSwitch 记录下载器切换
+func (s *SQLiteStore) RecordDownloaderSwitch(ctx context.Context, sw *DownloaderSwitch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO downloader_switches (live_id, from_downloader, to_downloader, failures, reason, switched_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, sw.LiveID, sw.From, sw.To, sw.Failures, sw.Reason, sw.SwitchedAt.Unix())
+	return err
+}
+
+// GetDownloaderSwitches 获取直播间的下载器切换历史（按时间倒序）
+func (s *SQLiteStore) GetDownloaderSwitches(ctx context.Context, liveID string, limit int) ([]*DownloaderSwitch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	query := `
+		SELECT id, live_id, from_downloader, to_downloader, failures, reason, switched_at
+		FROM downloader_switches WHERE live_id = ? ORDER BY switched_at DESC, id DESC
+	`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, liveID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var switches []*DownloaderSwitch
+	for rows.Next() {
+		sw := &DownloaderSwitch{}
+		var switchedAt int64
+		if err := rows.Scan(&sw.ID, &sw.LiveID, &sw.From, &sw.To, &sw.Failures, &sw.Reason, &switchedAt); err != nil {
+			return nil, err
+		}
+		if switchedAt > 0 {
+			sw.SwitchedAt = time.Unix(switchedAt, 0)
+		}
+		switches = append(switches, sw)
+	}
+	return switches, rows.Err()
+}
+
 // RecordNameChange 记录名称变更
 func (s *SQLiteStore) RecordNameChange(ctx context.Context, liveID, nameType, oldValue, newValue string) error {
 	s.mu.Lock()
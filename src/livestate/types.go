@@ -18,7 +18,7 @@ type LiveRoom struct {
 	UpdatedAt     time.Time `json:"updated_at"`
 }
 
-// LiveSession 直播会话记录（每次开播到下播为一个会话）
+// LiveSession 直播会话记录（每次开播到下播为一个会话，宽限期内中断后恢复仍为同一个会话）
 type LiveSession struct {
 	ID        int64     `json:"id"`
 	LiveID    string    `json:"live_id"`
@@ -27,9 +27,21 @@ type LiveSession struct {
 	StartTime time.Time `json:"start_time"` // 开播时间
 	EndTime   time.Time `json:"end_time"`   // 下播时间，零值表示仍在直播或崩溃未记录
 	EndReason string    `json:"end_reason"` // 结束原因
+	FlapCount int       `json:"flap_count"` // 宽限期内中断后恢复的次数
+	Files     []string  `json:"files"`      // 会话产生的录制文件
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// SessionFile 直播会话产生的录制文件
+type SessionFile struct {
+	ID        int64     `json:"id"`
+	SessionID int64     `json:"session_id"`
+	LiveID    string    `json:"live_id"`
+	HostName  string    `json:"host_name"`  // 主播名称（会话开始时）
+	FilePath  string    `json:"file_path"`  // 录制完成时的文件路径，后处理转换后可能已被替换为其他格式
+	CreatedAt time.Time `json:"created_at"` // 录制完成时间
+}
+
 // NameChange 名称变更记录
 type NameChange struct {
 	ID        int64     `json:"id"`
@@ -40,6 +52,27 @@ type NameChange struct {
 	ChangedAt time.Time `json:"changed_at"` // 变更时间
 }
 
+// Preemption 录制被抢占记录（达到同时录制上限时被优先级更高的直播间抢占录制位置）
+type Preemption struct {
+	ID                  int64     `json:"id"`
+	LiveID              string    `json:"live_id"`               // 被抢占的直播间ID
+	Priority            int       `json:"priority"`              // 被抢占的直播间的录制优先级
+	PreemptedBy         string    `json:"preempted_by"`          // 抢占录制位置的直播间ID
+	PreemptedByPriority int       `json:"preempted_by_priority"` // 抢占的直播间的录制优先级
+	PreemptedAt         time.Time `json:"preempted_at"`          // 抢占时间
+}
+
+// DownloaderSwitch 下载器切换记录（下载器连续快速失败后切换到回退链中的下一个）
+type DownloaderSwitch struct {
+	ID         int64     `json:"id"`
+	LiveID     string    `json:"live_id"`     // 直播间ID
+	From       string    `json:"from"`        // 切换前的下载器
+	To         string    `json:"to"`          // 切换后的下载器
+	Failures   int       `json:"failures"`    // 切换前连续快速失败的次数
+	Reason     string    `json:"reason"`      // 最后一次快速失败的原因
+	SwitchedAt time.Time `json:"switched_at"` // 切换时间
+}
+
 // 名称类型常量
 const (
 	NameTypeHost = "host_name"
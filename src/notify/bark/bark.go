@@ -0,0 +1,81 @@
+package bark
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/notify"
+)
+
+const defaultServerURL = "https://api.day.app"
+
+func init() {
+	notify.Register("bark", new(builder))
+}
+
+type builder struct{}
+
+func (b *builder) Build(cfg *configs.Notify) (notify.Notifier, error) {
+	if !cfg.Bark.Enable {
+		return nil, nil
+	}
+	if cfg.Bark.DeviceKey == "" {
+		return nil, fmt.Errorf("bark device_key is empty")
+	}
+	return &notifier{cfg: cfg.Bark}, nil
+}
+
+type notifier struct {
+	cfg configs.Bark
+}
+
+// pushRequest Bark v2 推送接口请求体
+type pushRequest struct {
+	DeviceKey string `json:"device_key"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Group     string `json:"group,omitempty"`
+	Sound     string `json:"sound,omitempty"`
+	Level     string `json:"level,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Icon      string `json:"icon,omitempty"`
+	Image     string `json:"image,omitempty"`
+}
+
+// Send 点击通知跳转到直播间（优先使用 scheme 地址），封面作为通知图片
+func (n *notifier) Send(ctx context.Context, msg *notify.Message) error {
+	serverURL := strings.TrimRight(n.cfg.ServerURL, "/")
+	if serverURL == "" {
+		serverURL = defaultServerURL
+	}
+	clickURL := msg.SchemeURL
+	if clickURL == "" {
+		clickURL = msg.LiveURL
+	}
+	body := fmt.Sprintf("%s\n%s", msg.StatusText(), msg.Platform)
+	if msg.RoomName != "" {
+		body += "\n" + msg.RoomName
+	}
+	req := pushRequest{
+		DeviceKey: n.cfg.DeviceKey,
+		Title:     msg.HostName,
+		Body:      body,
+		Group:     n.cfg.Group,
+		Sound:     n.cfg.Sound,
+		Level:     n.cfg.Level,
+		URL:       clickURL,
+		Image:     msg.CoverURL,
+	}
+	resp, err := notify.PostJSON(ctx, serverURL+"/push", req, nil)
+	if err != nil {
+		return err
+	}
+	if code := gjson.GetBytes(resp, "code").Int(); code != 200 {
+		return fmt.Errorf("bark error code %d: %s", code, gjson.GetBytes(resp, "message").String())
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/notify"
+	_ "github.com/bililive-go/bililive-go/src/notify/bark"
+	_ "github.com/bililive-go/bililive-go/src/notify/dingtalk"
+	_ "github.com/bililive-go/bililive-go/src/notify/discord"
+	_ "github.com/bililive-go/bililive-go/src/notify/feishu"
+	_ "github.com/bililive-go/bililive-go/src/notify/gotify"
+	_ "github.com/bililive-go/bililive-go/src/notify/ntfy"
+	_ "github.com/bililive-go/bililive-go/src/notify/pushover"
+	_ "github.com/bililive-go/bililive-go/src/notify/serverchan"
+	_ "github.com/bililive-go/bililive-go/src/notify/wecom"
+)
+
+// capturedRequest 记录 fake 服务端收到的请求
+type capturedRequest struct {
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   string
+}
+
+// newFakeServer 启动一个返回固定响应的 httptest 服务端
+func newFakeServer(t *testing.T, status int, resp string) (*httptest.Server, *capturedRequest) {
+	t.Helper()
+	captured := &capturedRequest{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		captured.Path = r.URL.Path
+		captured.Query = r.URL.Query()
+		captured.Header = r.Header.Clone()
+		captured.Body = string(b)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, captured
+}
+
+func testMessage() *notify.Message {
+	return &notify.Message{
+		Event:    notify.EventLiveStart,
+		HostName: "测试主播",
+		RoomName: "测试标题",
+		Platform: "哔哩哔哩",
+		LiveURL:  "https://live.bilibili.com/1",
+		CoverURL: "https://example.com/cover.jpg",
+		Time:     time.Unix(1700000000, 0),
+	}
+}
+
+func sendVia(t *testing.T, channel string, cfg *configs.Notify) error {
+	t.Helper()
+	n, err := notify.BuildNotifier(channel, cfg)
+	require.NoError(t, err)
+	require.NotNil(t, n)
+	return n.Send(context.Background(), testMessage())
+}
+
+func TestChannelsRegistered(t *testing.T) {
+	channels := notify.Channels()
+	for _, name := range []string{"bark", "serverchan", "dingtalk", "feishu", "wecom", "discord", "gotify", "pushover", "ntfy"} {
+		assert.Contains(t, channels, name)
+	}
+}
+
+func TestDisabledChannelNotBuilt(t *testing.T) {
+	for _, name := range notify.Channels() {
+		n, err := notify.BuildNotifier(name, &configs.Notify{})
+		assert.NoError(t, err, name)
+		assert.Nil(t, n, name)
+	}
+}
+
+func TestBark(t *testing.T) {
+	srv, req := newFakeServer(t, http.StatusOK, `{"code":200,"message":"success"}`)
+	err := sendVia(t, "bark", &configs.Notify{Bark: configs.Bark{Enable: true, ServerURL: srv.URL, DeviceKey: "key", Group: "live"}})
+	require.NoError(t, err)
+	assert.Equal(t, "/push", req.Path)
+	assert.Equal(t, "key", gjson.Get(req.Body, "device_key").String())
+	assert.Equal(t, "测试主播", gjson.Get(req.Body, "title").String())
+	assert.Equal(t, "https://example.com/cover.jpg", gjson.Get(req.Body, "image").String())
+	assert.Equal(t, "live", gjson.Get(req.Body, "group").String())
+
+	srv, _ = newFakeServer(t, http.StatusOK, `{"code":400,"message":"failed"}`)
+	err = sendVia(t, "bark", &configs.Notify{Bark: configs.Bark{Enable: true, ServerURL: srv.URL, DeviceKey: "key"}})
+	assert.Error(t, err)
+}
+
+func TestServerChan(t *testing.T) {
+	srv, req := newFakeServer(t, http.StatusOK, `{"code":0,"message":""}`)
+	err := sendVia(t, "serverchan", &configs.Notify{ServerChan: configs.ServerChan{Enable: true, SendKey: "SCT1", APIURL: srv.URL + "/SCT1.send"}})
+	require.NoError(t, err)
+	form, _ := url.ParseQuery(req.Body)
+	assert.Equal(t, "/SCT1.send", req.Path)
+	assert.Contains(t, form.Get("title"), "测试主播")
+	assert.Contains(t, form.Get("desp"), "![封面](https://example.com/cover.jpg)")
+}
+
+func TestDingTalk(t *testing.T) {
+	srv, req := newFakeServer(t, http.StatusOK, `{"errcode":0,"errmsg":"ok"}`)
+	err := sendVia(t, "dingtalk", &configs.Notify{DingTalk: configs.DingTalk{Enable: true, Webhook: srv.URL + "/robot/send?access_token=abc", Secret: "SEC"}})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", req.Query.Get("access_token"))
+	assert.NotEmpty(t, req.Query.Get("timestamp"))
+	assert.NotEmpty(t, req.Query.Get("sign"))
+	assert.Equal(t, "actionCard", gjson.Get(req.Body, "msgtype").String())
+	assert.Equal(t, "https://live.bilibili.com/1", gjson.Get(req.Body, "actionCard.singleURL").String())
+	assert.Contains(t, gjson.Get(req.Body, "actionCard.text").String(), "cover.jpg")
+
+	srv, _ = newFakeServer(t, http.StatusOK, `{"errcode":310000,"errmsg":"sign not match"}`)
+	err = sendVia(t, "dingtalk", &configs.Notify{DingTalk: configs.DingTalk{Enable: true, Webhook: srv.URL}})
+	assert.Error(t, err)
+}
+
+func TestFeishu(t *testing.T) {
+	srv, req := newFakeServer(t, http.StatusOK, `{"code":0,"msg":"success"}`)
+	err := sendVia(t, "feishu", &configs.Notify{Feishu: configs.Feishu{Enable: true, Webhook: srv.URL, Secret: "SEC"}})
+	require.NoError(t, err)
+	assert.Equal(t, "interactive", gjson.Get(req.Body, "msg_type").String())
+	assert.Equal(t, "green", gjson.Get(req.Body, "card.header.template").String())
+	assert.NotEmpty(t, gjson.Get(req.Body, "sign").String())
+	assert.Equal(t, "https://live.bilibili.com/1", gjson.Get(req.Body, "card.elements.1.actions.0.url").String())
+
+	srv, _ = newFakeServer(t, http.StatusOK, `{"code":19021,"msg":"sign match fail"}`)
+	err = sendVia(t, "feishu", &configs.Notify{Feishu: configs.Feishu{Enable: true, Webhook: srv.URL}})
+	assert.Error(t, err)
+}
+
+func TestWeCom(t *testing.T) {
+	srv, req := newFakeServer(t, http.StatusOK, `{"errcode":0,"errmsg":"ok"}`)
+	err := sendVia(t, "wecom", &configs.Notify{WeCom: configs.WeCom{Enable: true, Webhook: srv.URL}})
+	require.NoError(t, err)
+	assert.Equal(t, "news", gjson.Get(req.Body, "msgtype").String())
+	assert.Equal(t, "https://example.com/cover.jpg", gjson.Get(req.Body, "news.articles.0.picurl").String())
+}
+
+func TestDiscord(t *testing.T) {
+	srv, req := newFakeServer(t, http.StatusNoContent, "")
+	err := sendVia(t, "discord", &configs.Notify{Discord: configs.Discord{Enable: true, Webhook: srv.URL, Username: "bililive-go"}})
+	require.NoError(t, err)
+	assert.Equal(t, "bililive-go", gjson.Get(req.Body, "username").String())
+	assert.Equal(t, "https://example.com/cover.jpg", gjson.Get(req.Body, "embeds.0.image.url").String())
+	assert.Equal(t, "https://live.bilibili.com/1", gjson.Get(req.Body, "embeds.0.url").String())
+
+	srv, _ = newFakeServer(t, http.StatusBadRequest, `{"message":"Invalid Webhook Token"}`)
+	err = sendVia(t, "discord", &configs.Notify{Discord: configs.Discord{Enable: true, Webhook: srv.URL}})
+	assert.Error(t, err)
+}
+
+func TestGotify(t *testing.T) {
+	srv, req := newFakeServer(t, http.StatusOK, `{"id":1}`)
+	err := sendVia(t, "gotify", &configs.Notify{Gotify: configs.Gotify{Enable: true, ServerURL: srv.URL + "/", Token: "tok"}})
+	require.NoError(t, err)
+	assert.Equal(t, "/message", req.Path)
+	assert.Equal(t, "tok", req.Header.Get("X-Gotify-Key"))
+	assert.Equal(t, int64(5), gjson.Get(req.Body, "priority").Int())
+	assert.Equal(t, "https://example.com/cover.jpg", gjson.Get(req.Body, `extras.client::notification.bigImageUrl`).String())
+}
+
+func TestPushover(t *testing.T) {
+	srv, req := newFakeServer(t, http.StatusOK, `{"status":1,"request":"x"}`)
+	err := sendVia(t, "pushover", &configs.Notify{Pushover: configs.Pushover{Enable: true, Token: "app", UserKey: "user", APIURL: srv.URL}})
+	require.NoError(t, err)
+	form, _ := url.ParseQuery(req.Body)
+	assert.Equal(t, "app", form.Get("token"))
+	assert.Equal(t, "user", form.Get("user"))
+	assert.Equal(t, "https://live.bilibili.com/1", form.Get("url"))
+	assert.Equal(t, "1700000000", form.Get("timestamp"))
+
+	srv, _ = newFakeServer(t, http.StatusOK, `{"status":0,"errors":["user identifier is invalid"]}`)
+	err = sendVia(t, "pushover", &configs.Notify{Pushover: configs.Pushover{Enable: true, Token: "app", UserKey: "user", APIURL: srv.URL}})
+	assert.Error(t, err)
+}
+
+func TestNtfy(t *testing.T) {
+	srv, req := newFakeServer(t, http.StatusOK, `{}`)
+	err := sendVia(t, "ntfy", &configs.Notify{Ntfy: configs.Ntfy{Enable: true, URL: srv.URL + "/live", Tag: "tv"}})
+	require.NoError(t, err)
+	assert.Equal(t, "/live", req.Path)
+	assert.Equal(t, "https://example.com/cover.jpg", req.Header.Get("Attach"))
+	assert.True(t, strings.HasPrefix(req.Body, "哔哩哔哩"))
+	var actions []map[string]string
+	require.NoError(t, json.Unmarshal([]byte(req.Header.Get("Actions")), &actions))
+	assert.Equal(t, "https://live.bilibili.com/1", actions[0]["url"])
+}
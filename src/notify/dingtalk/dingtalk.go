@@ -0,0 +1,93 @@
+package dingtalk
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/notify"
+)
+
+func init() {
+	notify.Register("dingtalk", new(builder))
+}
+
+type builder struct{}
+
+func (b *builder) Build(cfg *configs.Notify) (notify.Notifier, error) {
+	if !cfg.DingTalk.Enable {
+		return nil, nil
+	}
+	if cfg.DingTalk.Webhook == "" {
+		return nil, fmt.Errorf("dingtalk webhook is empty")
+	}
+	return &notifier{cfg: cfg.DingTalk}, nil
+}
+
+type notifier struct {
+	cfg configs.DingTalk
+}
+
+type actionCard struct {
+	Title       string `json:"title"`
+	Text        string `json:"text"`
+	SingleTitle string `json:"singleTitle,omitempty"`
+	SingleURL   string `json:"singleURL,omitempty"`
+}
+
+type message struct {
+	MsgType    string      `json:"msgtype"`
+	ActionCard *actionCard `json:"actionCard"`
+}
+
+// Send 使用 ActionCard 卡片：Markdown 正文 + 封面 + “打开直播间”按钮
+func (n *notifier) Send(ctx context.Context, msg *notify.Message) error {
+	webhook, err := n.signedWebhook(time.Now())
+	if err != nil {
+		return err
+	}
+	card := &actionCard{
+		Title: msg.Title(),
+		Text:  fmt.Sprintf("### %s\n\n%s", msg.Title(), msg.Markdown(true)),
+	}
+	if msg.LiveURL != "" {
+		card.SingleTitle = "打开直播间"
+		card.SingleURL = msg.LiveURL
+	}
+	resp, err := notify.PostJSON(ctx, webhook, message{MsgType: "actionCard", ActionCard: card}, nil)
+	if err != nil {
+		return err
+	}
+	if code := gjson.GetBytes(resp, "errcode").Int(); code != 0 {
+		return fmt.Errorf("dingtalk error code %d: %s", code, gjson.GetBytes(resp, "errmsg").String())
+	}
+	return nil
+}
+
+// signedWebhook 配置了加签密钥时，在 webhook 上追加 timestamp 和 sign 参数
+func (n *notifier) signedWebhook(now time.Time) (string, error) {
+	if n.cfg.Secret == "" {
+		return n.cfg.Webhook, nil
+	}
+	u, err := url.Parse(n.cfg.Webhook)
+	if err != nil {
+		return "", fmt.Errorf("invalid dingtalk webhook: %w", err)
+	}
+	timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(n.cfg.Secret))
+	mac.Write([]byte(timestamp + "\n" + n.cfg.Secret))
+	q := u.Query()
+	q.Set("timestamp", timestamp)
+	q.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	u.RawQuery = strings.ReplaceAll(q.Encode(), "+", "%20")
+	return u.String(), nil
+}
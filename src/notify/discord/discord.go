@@ -0,0 +1,103 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/notify"
+)
+
+// 嵌入卡片的侧边颜色
+const (
+	colorLiveStart = 0x2ECC71
+	colorLiveEnd   = 0x95A5A6
+	colorFailed    = 0xE74C3C
+	colorDefault   = 0x3498DB
+)
+
+func init() {
+	notify.Register("discord", new(builder))
+}
+
+type builder struct{}
+
+func (b *builder) Build(cfg *configs.Notify) (notify.Notifier, error) {
+	if !cfg.Discord.Enable {
+		return nil, nil
+	}
+	if cfg.Discord.Webhook == "" {
+		return nil, fmt.Errorf("discord webhook is empty")
+	}
+	return &notifier{cfg: cfg.Discord}, nil
+}
+
+type notifier struct {
+	cfg configs.Discord
+}
+
+type embedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type embedImage struct {
+	URL string `json:"url"`
+}
+
+type embed struct {
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	Color       int          `json:"color"`
+	Timestamp   string       `json:"timestamp,omitempty"`
+	Fields      []embedField `json:"fields,omitempty"`
+	Image       *embedImage  `json:"image,omitempty"`
+}
+
+type webhookPayload struct {
+	Username string  `json:"username,omitempty"`
+	Embeds   []embed `json:"embeds"`
+}
+
+// Send 发送 Embed 卡片，封面作为大图展示
+func (n *notifier) Send(ctx context.Context, msg *notify.Message) error {
+	color := colorDefault
+	switch msg.Event {
+	case notify.EventLiveStart:
+		color = colorLiveStart
+	case notify.EventLiveEnd:
+		color = colorLiveEnd
+	case notify.EventRecordingFailed, notify.EventPipelineFailed, notify.EventCookieExpired, notify.EventRiskControl:
+		color = colorFailed
+	}
+	description := msg.RoomName
+	if msg.CustomBody != "" {
+		description = msg.CustomBody
+	} else if msg.Detail != "" {
+		description = strings.TrimSpace(msg.RoomName + "\n" + msg.Detail)
+	}
+	e := embed{
+		Title:       msg.Title(),
+		Description: description,
+		URL:         msg.LiveURL,
+		Color:       color,
+		Timestamp:   msg.Time.UTC().Format(time.RFC3339),
+		Fields: []embedField{
+			{Name: "主播", Value: msg.HostName, Inline: true},
+			{Name: "平台", Value: msg.Platform, Inline: true},
+		},
+	}
+	if msg.CoverURL != "" {
+		e.Image = &embedImage{URL: msg.CoverURL}
+	}
+	// Discord 成功时返回 204，错误时 PostJSON 会返回非 2xx 错误
+	_, err := notify.PostJSON(ctx, n.cfg.Webhook, webhookPayload{
+		Username: n.cfg.Username,
+		Embeds:   []embed{e},
+	}, nil)
+	return err
+}
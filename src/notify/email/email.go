@@ -1,13 +1,37 @@
 package email
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/bililive-go/bililive-go/src/configs"
 	blog "github.com/bililive-go/bililive-go/src/log"
+	"github.com/bililive-go/bililive-go/src/notify"
 	"gopkg.in/gomail.v2"
 )
 
+func init() {
+	notify.Register("email", new(builder))
+}
+
+type builder struct{}
+
+func (b *builder) Build(cfg *configs.Notify) (notify.Notifier, error) {
+	if !cfg.Email.Enable {
+		return nil, nil
+	}
+	return &notifier{cfg: cfg.Email}, nil
+}
+
+type notifier struct {
+	cfg configs.Email
+}
+
+func (n *notifier) Send(ctx context.Context, msg *notify.Message) error {
+	subject := fmt.Sprintf("%s - %s", msg.Title(), msg.Platform)
+	return SendEmail(n.cfg, subject, msg.Text())
+}
+
 // EmailMessage represents an email message
 type EmailMessage struct {
 	Subject string
@@ -15,11 +39,7 @@ type EmailMessage struct {
 }
 
 // SendEmail 发送邮件 subject 主题 body 内容
-func SendEmail(subject, body string) error {
-
-	cfg := configs.GetCurrentConfig()
-	emailConfig := cfg.Notify.Email
-
+func SendEmail(emailConfig configs.Email, subject, body string) error {
 	m := gomail.NewMessage()
 	m.SetHeader("From", emailConfig.SenderEmail)
 	m.SetHeader("To", emailConfig.RecipientEmail)
@@ -0,0 +1,123 @@
+package feishu
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/notify"
+)
+
+func init() {
+	notify.Register("feishu", new(builder))
+}
+
+type builder struct{}
+
+func (b *builder) Build(cfg *configs.Notify) (notify.Notifier, error) {
+	if !cfg.Feishu.Enable {
+		return nil, nil
+	}
+	if cfg.Feishu.Webhook == "" {
+		return nil, fmt.Errorf("feishu webhook is empty")
+	}
+	return &notifier{cfg: cfg.Feishu}, nil
+}
+
+type notifier struct {
+	cfg configs.Feishu
+}
+
+// Send 发送消息卡片；飞书卡片中的图片需要先上传换取 image_key，
+// 机器人 webhook 无此权限，因此封面以链接形式展示
+func (n *notifier) Send(ctx context.Context, msg *notify.Message) error {
+	payload := map[string]any{
+		"msg_type": "interactive",
+		"card":     buildCard(msg),
+	}
+	if n.cfg.Secret != "" {
+		timestamp, sign := genSign(n.cfg.Secret, time.Now())
+		payload["timestamp"] = timestamp
+		payload["sign"] = sign
+	}
+	resp, err := notify.PostJSON(ctx, n.cfg.Webhook, payload, nil)
+	if err != nil {
+		return err
+	}
+	// 新版接口返回 code，旧版返回 StatusCode
+	code := gjson.GetBytes(resp, "code").Int()
+	if code == 0 {
+		code = gjson.GetBytes(resp, "StatusCode").Int()
+	}
+	if code != 0 {
+		return fmt.Errorf("feishu error code %d: %s", code, gjson.GetBytes(resp, "msg").String())
+	}
+	return nil
+}
+
+func buildCard(msg *notify.Message) map[string]any {
+	template := "blue"
+	switch msg.Event {
+	case notify.EventLiveStart:
+		template = "green"
+	case notify.EventLiveEnd:
+		template = "grey"
+	case notify.EventRecordingFailed, notify.EventPipelineFailed, notify.EventCookieExpired, notify.EventRiskControl:
+		template = "red"
+	}
+
+	content := fmt.Sprintf("**主播**：%s\n**平台**：%s", msg.HostName, msg.Platform)
+	if msg.RoomName != "" {
+		content += fmt.Sprintf("\n**标题**：%s", msg.RoomName)
+	}
+	if msg.Detail != "" {
+		content += fmt.Sprintf("\n**详情**：%s", msg.Detail)
+	}
+	if msg.CustomBody != "" {
+		content = msg.CustomBody
+	}
+	if msg.CoverURL != "" {
+		content += fmt.Sprintf("\n[查看封面](%s)", msg.CoverURL)
+	}
+	elements := []any{
+		map[string]any{
+			"tag":  "div",
+			"text": map[string]any{"tag": "lark_md", "content": content},
+		},
+	}
+	if msg.LiveURL != "" {
+		elements = append(elements, map[string]any{
+			"tag": "action",
+			"actions": []any{
+				map[string]any{
+					"tag":  "button",
+					"text": map[string]any{"tag": "plain_text", "content": "打开直播间"},
+					"type": "primary",
+					"url":  msg.LiveURL,
+				},
+			},
+		})
+	}
+	return map[string]any{
+		"config": map[string]any{"wide_screen_mode": true},
+		"header": map[string]any{
+			"template": template,
+			"title":    map[string]any{"tag": "plain_text", "content": msg.Title()},
+		},
+		"elements": elements,
+	}
+}
+
+// genSign 飞书签名：以 timestamp + "\n" + secret 为密钥对空串做 HmacSHA256
+func genSign(secret string, now time.Time) (string, string) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return timestamp, base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
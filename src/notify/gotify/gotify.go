@@ -0,0 +1,64 @@
+package gotify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/notify"
+)
+
+const defaultPriority = 5
+
+func init() {
+	notify.Register("gotify", new(builder))
+}
+
+type builder struct{}
+
+func (b *builder) Build(cfg *configs.Notify) (notify.Notifier, error) {
+	if !cfg.Gotify.Enable {
+		return nil, nil
+	}
+	if cfg.Gotify.ServerURL == "" || cfg.Gotify.Token == "" {
+		return nil, fmt.Errorf("gotify server_url or token is empty")
+	}
+	return &notifier{cfg: cfg.Gotify}, nil
+}
+
+type notifier struct {
+	cfg configs.Gotify
+}
+
+// Send 使用 Markdown 正文，客户端点击跳转直播间，封面作为通知大图（Android 客户端）
+func (n *notifier) Send(ctx context.Context, msg *notify.Message) error {
+	priority := n.cfg.Priority
+	if priority <= 0 {
+		priority = defaultPriority
+	}
+	extras := map[string]any{
+		"client::display": map[string]any{"contentType": "text/markdown"},
+	}
+	notification := map[string]any{}
+	if msg.LiveURL != "" {
+		notification["click"] = map[string]any{"url": msg.LiveURL}
+	}
+	if msg.CoverURL != "" {
+		notification["bigImageUrl"] = msg.CoverURL
+	}
+	if len(notification) > 0 {
+		extras["client::notification"] = notification
+	}
+	payload := map[string]any{
+		"title":    msg.Title(),
+		"message":  msg.Markdown(true),
+		"priority": priority,
+		"extras":   extras,
+	}
+	header := http.Header{}
+	header.Set("X-Gotify-Key", n.cfg.Token)
+	_, err := notify.PostJSON(ctx, strings.TrimRight(n.cfg.ServerURL, "/")+"/message", payload, header)
+	return err
+}
@@ -0,0 +1,189 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/consts"
+)
+
+// 通知事件类型，同时也是通知规则中 events / event_channels / templates 使用的名称
+const (
+	EventLiveStart       = "live_start"       // 开播
+	EventLiveEnd         = "live_end"         // 下播
+	EventLiveResumed     = "live_resumed"     // 防抖窗口内中断后恢复
+	EventRecordingFailed = "recording_failed" // 录制失败
+	EventPipelineDone    = "pipeline_done"    // 录制后处理完成
+	EventPipelineFailed  = "pipeline_failed"  // 录制后处理失败
+	EventCookieExpired   = "cookie_expired"   // Cookie 失效
+	EventRiskControl     = "risk_control"     // 平台风控冷却
+	EventTest            = "test"             // 测试通知
+)
+
+// DefaultEvents 未配置 events 时默认发送的事件
+var DefaultEvents = []string{EventLiveStart, EventLiveEnd, EventLiveResumed, EventCookieExpired, EventRiskControl}
+
+// EventFromLiveStatus 将直播状态（consts.LiveStatusStart/consts.LiveStatusStop）转换为通知事件
+func EventFromLiveStatus(status string) string {
+	switch status {
+	case consts.LiveStatusStart:
+		return EventLiveStart
+	case consts.LiveStatusStop:
+		return EventLiveEnd
+	default:
+		return status
+	}
+}
+
+// Message 一条待发送的通知
+// 各通知渠道根据自身能力决定如何排版（纯文本、Markdown、卡片等）
+type Message struct {
+	Event     string    // 事件类型，见 EventLiveStart 等
+	HostName  string    // 主播名称
+	RoomName  string    // 直播间标题
+	Platform  string    // 平台中文名
+	LiveURL   string    // 直播间地址
+	SchemeURL string    // 客户端跳转地址（可选）
+	CoverURL  string    // 封面图片地址（可选）
+	Time      time.Time // 事件发生时间
+	Detail    string    // 附加信息，如失败原因、处理结果（可选）
+	FlapCount int       // 防抖窗口内合并的中断次数
+
+	// 由通知规则中的模板渲染得到，非空时替代默认的标题和正文
+	CustomTitle string
+	CustomBody  string
+}
+
+// StatusText 返回事件对应的状态描述
+func (m *Message) StatusText() string {
+	switch m.Event {
+	case EventLiveStart:
+		return "已开始直播,正在录制中"
+	case EventLiveEnd:
+		if m.FlapCount > 0 {
+			return fmt.Sprintf("已结束直播,录制已停止(期间中断 %d 次)", m.FlapCount)
+		}
+		return "已结束直播,录制已停止"
+	case EventLiveResumed:
+		return fmt.Sprintf("直播中断 %d 次后已恢复,录制继续", m.FlapCount)
+	case EventRecordingFailed:
+		return "录制失败"
+	case EventPipelineDone:
+		return "录制后处理已完成"
+	case EventPipelineFailed:
+		return "录制后处理失败"
+	case EventCookieExpired:
+		return "Cookie 已失效,请重新登录"
+	case EventRiskControl:
+		return "请求被平台风控拦截,已暂停访问该平台"
+	case EventTest:
+		return "这是一条测试通知"
+	default:
+		return "直播状态未知"
+	}
+}
+
+// Title 返回通知标题
+func (m *Message) Title() string {
+	if m.CustomTitle != "" {
+		return m.CustomTitle
+	}
+	return fmt.Sprintf("%s,%s", m.HostName, m.StatusText())
+}
+
+// Text 返回纯文本格式的通知正文
+func (m *Message) Text() string {
+	if m.CustomBody != "" {
+		return m.CustomBody
+	}
+	text := fmt.Sprintf("主播：%s\n平台：%s\n直播地址：%s", m.Title(), m.Platform, m.LiveURL)
+	if m.RoomName != "" {
+		text += fmt.Sprintf("\n直播间标题：%s", m.RoomName)
+	}
+	if m.Detail != "" {
+		text += fmt.Sprintf("\n详情：%s", m.Detail)
+	}
+	return text
+}
+
+// Markdown 返回 Markdown 格式的通知正文，withCover 为 true 时附带封面图
+func (m *Message) Markdown(withCover bool) string {
+	if m.CustomBody != "" {
+		md := m.CustomBody + "\n\n"
+		if withCover && m.CoverURL != "" {
+			md += fmt.Sprintf("![封面](%s)\n", m.CoverURL)
+		}
+		return md
+	}
+	md := fmt.Sprintf("**主播**：%s\n\n**状态**：%s\n\n**平台**：%s\n\n", m.HostName, m.StatusText(), m.Platform)
+	if m.RoomName != "" {
+		md += fmt.Sprintf("**标题**：%s\n\n", m.RoomName)
+	}
+	if m.Detail != "" {
+		md += fmt.Sprintf("**详情**：%s\n\n", m.Detail)
+	}
+	if m.LiveURL != "" {
+		md += fmt.Sprintf("[打开直播间](%s)\n\n", m.LiveURL)
+	}
+	if withCover && m.CoverURL != "" {
+		md += fmt.Sprintf("![封面](%s)\n", m.CoverURL)
+	}
+	return md
+}
+
+// Notifier 通知渠道
+type Notifier interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Builder 根据通知配置构建通知渠道
+type Builder interface {
+	// Build 构建通知渠道，渠道未启用时返回 nil, nil
+	Build(cfg *configs.Notify) (Notifier, error)
+}
+
+var builders = map[string]Builder{}
+
+// Register 注册一个通知渠道，通常在渠道包的 init 中调用
+func Register(name string, b Builder) {
+	builders[name] = b
+}
+
+// Channels 返回所有已注册的通知渠道名称
+func Channels() []string {
+	names := make([]string, 0, len(builders))
+	for name := range builders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuildNotifier 使用指定配置构建某个通知渠道
+func BuildNotifier(name string, cfg *configs.Notify) (Notifier, error) {
+	b, ok := builders[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown notify channel: %s", name)
+	}
+	return b.Build(cfg)
+}
+
+// enabledNotifiers 构建当前配置中所有启用的通知渠道
+func enabledNotifiers(cfg *configs.Notify) (map[string]Notifier, map[string]error) {
+	notifiers := make(map[string]Notifier)
+	errs := make(map[string]error)
+	for _, name := range Channels() {
+		n, err := builders[name].Build(cfg)
+		if err != nil {
+			errs[name] = err
+			continue
+		}
+		if n != nil {
+			notifiers[name] = n
+		}
+	}
+	return notifiers, errs
+}
@@ -1,16 +1,56 @@
 package ntfy
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/notify"
 )
 
-// 创建一个共享的HTTP客户端，设置合理的超时时间
-var httpClient = &http.Client{
-	Timeout: 10 * time.Second,
+func init() {
+	notify.Register("ntfy", new(builder))
+}
+
+type builder struct{}
+
+func (b *builder) Build(cfg *configs.Notify) (notify.Notifier, error) {
+	if !cfg.Ntfy.Enable {
+		return nil, nil
+	}
+	if cfg.Ntfy.URL == "" {
+		return nil, fmt.Errorf("ntfy URL is empty")
+	}
+	return &notifier{cfg: cfg.Ntfy}, nil
+}
+
+type notifier struct {
+	cfg configs.Ntfy
+}
+
+// Send 开播通知带 scheme 跳转，结束通知不带；有封面时作为附件展示
+func (n *notifier) Send(ctx context.Context, msg *notify.Message) error {
+	var message, schemeUrl string
+	switch msg.Event {
+	case notify.EventLiveStart:
+		message = fmt.Sprintf("%s正在录制中", msg.Platform)
+		schemeUrl = msg.SchemeURL
+	case notify.EventLiveEnd:
+		message = fmt.Sprintf("%s录制已停止", msg.Platform)
+	default:
+		message = fmt.Sprintf("%s %s", msg.Platform, msg.StatusText())
+	}
+	title := msg.HostName
+	if msg.CustomTitle != "" {
+		title = msg.CustomTitle
+	}
+	if msg.CustomBody != "" {
+		message = msg.CustomBody
+	}
+	return sendNtfyRequest(ctx, n.cfg.URL, n.cfg.Token, n.cfg.Tag, title, message, msg.LiveURL, schemeUrl, msg.CoverURL)
 }
 
 // NtfyAction 定义ntfy的Action结构
@@ -21,11 +61,11 @@ type NtfyAction struct {
 }
 
 // sendNtfyRequest 发送ntfy请求的通用函数
-func sendNtfyRequest(url, token, tag, hostname, message, liveURL, schemeUrl string) error {
+func sendNtfyRequest(ctx context.Context, url, token, tag, hostname, message, liveURL, schemeUrl, attachURL string) error {
 	title := hostname
 
 	// 创建HTTP请求
-	req, err := http.NewRequest("POST", url, strings.NewReader(message))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(message))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -41,6 +81,11 @@ func sendNtfyRequest(url, token, tag, hostname, message, liveURL, schemeUrl stri
 		req.Header.Set("Click", schemeUrl)
 	}
 
+	// 附带封面图片
+	if attachURL != "" {
+		req.Header.Set("Attach", attachURL)
+	}
+
 	// 设置Actions头，用于打开直播间
 	if liveURL != "" {
 		// 确保liveURL有https://前缀
@@ -72,7 +117,7 @@ func sendNtfyRequest(url, token, tag, hostname, message, liveURL, schemeUrl stri
 	}
 
 	// 发送请求
-	resp, err := httpClient.Do(req)
+	resp, err := notify.HTTPClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
@@ -92,7 +137,7 @@ func SendMessage(url, token, tag, hostname, platform, liveURL, schemeUrl string)
 	message := fmt.Sprintf("%s正在录制中", platform)
 
 	// 发送请求
-	return sendNtfyRequest(url, token, tag, hostname, message, liveURL, schemeUrl)
+	return sendNtfyRequest(context.Background(), url, token, tag, hostname, message, liveURL, schemeUrl, "")
 }
 
 // SendStopMessage 发送ntfy停止录制消息
@@ -101,5 +146,5 @@ func SendStopMessage(url, token, tag, hostname, platform, liveURL string) error
 	message := fmt.Sprintf("%s录制已停止", platform)
 
 	// 发送请求，注意停止录制通知不使用schemeUrl
-	return sendNtfyRequest(url, token, tag, hostname, message, liveURL, "")
+	return sendNtfyRequest(context.Background(), url, token, tag, hostname, message, liveURL, "", "")
 }
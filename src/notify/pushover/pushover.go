@@ -0,0 +1,78 @@
+package pushover
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/notify"
+)
+
+const defaultAPIURL = "https://api.pushover.net/1/messages.json"
+
+func init() {
+	notify.Register("pushover", new(builder))
+}
+
+type builder struct{}
+
+func (b *builder) Build(cfg *configs.Notify) (notify.Notifier, error) {
+	if !cfg.Pushover.Enable {
+		return nil, nil
+	}
+	if cfg.Pushover.Token == "" || cfg.Pushover.UserKey == "" {
+		return nil, fmt.Errorf("pushover token or user_key is empty")
+	}
+	return &notifier{cfg: cfg.Pushover}, nil
+}
+
+type notifier struct {
+	cfg configs.Pushover
+}
+
+// Send 正文使用 HTML 格式，附带直播间链接；
+// Pushover 的图片附件需要上传文件，封面以链接形式附在正文中
+func (n *notifier) Send(ctx context.Context, msg *notify.Message) error {
+	apiURL := n.cfg.APIURL
+	if apiURL == "" {
+		apiURL = defaultAPIURL
+	}
+	message := fmt.Sprintf("<b>%s</b>\n%s", msg.Platform, msg.StatusText())
+	if msg.RoomName != "" {
+		message += "\n" + msg.RoomName
+	}
+	if msg.CoverURL != "" {
+		message += fmt.Sprintf("\n<a href=\"%s\">封面</a>", msg.CoverURL)
+	}
+	form := url.Values{}
+	form.Set("token", n.cfg.Token)
+	form.Set("user", n.cfg.UserKey)
+	form.Set("title", msg.Title())
+	form.Set("message", message)
+	form.Set("html", "1")
+	if !msg.Time.IsZero() {
+		form.Set("timestamp", strconv.FormatInt(msg.Time.Unix(), 10))
+	}
+	if msg.LiveURL != "" {
+		form.Set("url", msg.LiveURL)
+		form.Set("url_title", "打开直播间")
+	}
+	if n.cfg.Device != "" {
+		form.Set("device", n.cfg.Device)
+	}
+	if n.cfg.Priority != 0 {
+		form.Set("priority", strconv.Itoa(n.cfg.Priority))
+	}
+	resp, err := notify.PostForm(ctx, apiURL, form)
+	if err != nil {
+		return err
+	}
+	if status := gjson.GetBytes(resp, "status").Int(); status != 1 {
+		return fmt.Errorf("pushover error: %s", gjson.GetBytes(resp, "errors").String())
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/pkg/livelogger"
+)
+
+// dispatcher 按通知规则分发消息
+// 负责事件过滤、渠道路由、免打扰、模板渲染以及开播/下播的防抖合并
+type dispatcher struct {
+	mu    sync.Mutex
+	flaps map[string]*flapState // key: 直播间地址
+	now   func() time.Time
+}
+
+// flapState 记录一个直播间在防抖窗口内的状态
+type flapState struct {
+	count      int         // 窗口内合并的中断次数
+	pendingEnd *Message    // 尚未发出的下播通知
+	timer      *time.Timer // 下播通知或恢复汇总通知的定时器
+}
+
+var defaultDispatcher = newDispatcher()
+
+func newDispatcher() *dispatcher {
+	return &dispatcher{
+		flaps: make(map[string]*flapState),
+		now:   time.Now,
+	}
+}
+
+// resolveRules 返回消息对应直播间的生效通知规则，没有直播间地址时使用全局规则
+func resolveRules(cfg *configs.Config, msg *Message) *configs.NotifyRules {
+	if msg.LiveURL == "" {
+		return configs.MergeNotifyRules(nil, &cfg.Notify.Rules)
+	}
+	resolved := cfg.GetEffectiveConfigForRoom(msg.LiveURL)
+	return &resolved.NotifyRules
+}
+
+// dispatch 处理一条消息：开播/下播事件在配置了防抖窗口时会被延迟或合并，其他事件直接投递
+// 开启直播会话宽限期时由宽限期负责合并中断（FlapCount 已包含合并的次数），不再经过防抖窗口，避免通知被重复延迟
+func (d *dispatcher) dispatch(ctx context.Context, logger *livelogger.LiveLogger, cfg *configs.Config, msg *Message) {
+	rules := resolveRules(cfg, msg)
+	window := rules.GetFlapWindow()
+	if cfg.SessionGrace.GetPeriod() > 0 {
+		window = 0
+	}
+	if window <= 0 || msg.LiveURL == "" || (msg.Event != EventLiveStart && msg.Event != EventLiveEnd) {
+		d.deliver(ctx, logger, cfg, rules, msg)
+		return
+	}
+
+	d.mu.Lock()
+	state := d.flaps[msg.LiveURL]
+
+	switch msg.Event {
+	case EventLiveEnd:
+		if state == nil {
+			state = &flapState{}
+			d.flaps[msg.LiveURL] = state
+		}
+		if state.timer != nil {
+			state.timer.Stop()
+		}
+		state.pendingEnd = msg
+		state.timer = time.AfterFunc(window, func() {
+			d.flushEnd(logger, msg.LiveURL, msg)
+		})
+		d.mu.Unlock()
+
+	case EventLiveStart:
+		if state == nil || state.pendingEnd == nil {
+			d.mu.Unlock()
+			d.deliver(ctx, logger, cfg, rules, msg)
+			return
+		}
+		// 窗口内重新开播：取消下播通知，等窗口结束后发送一条恢复汇总
+		state.timer.Stop()
+		state.pendingEnd = nil
+		state.count++
+		resumed := *msg
+		resumed.Event = EventLiveResumed
+		state.timer = time.AfterFunc(window, func() {
+			d.flushResumed(logger, msg.LiveURL, &resumed)
+		})
+		d.mu.Unlock()
+	}
+}
+
+// flushEnd 防抖窗口结束且未重新开播，发送下播通知（附带窗口内的中断次数）
+func (d *dispatcher) flushEnd(logger *livelogger.LiveLogger, liveURL string, msg *Message) {
+	d.mu.Lock()
+	state := d.flaps[liveURL]
+	if state == nil || state.pendingEnd != msg {
+		d.mu.Unlock()
+		return
+	}
+	msg.FlapCount += state.count
+	delete(d.flaps, liveURL)
+	d.mu.Unlock()
+	d.redeliver(logger, msg)
+}
+
+// flushResumed 恢复后在防抖窗口内未再中断，发送恢复汇总通知
+func (d *dispatcher) flushResumed(logger *livelogger.LiveLogger, liveURL string, msg *Message) {
+	d.mu.Lock()
+	state := d.flaps[liveURL]
+	if state == nil || state.pendingEnd != nil {
+		d.mu.Unlock()
+		return
+	}
+	msg.FlapCount += state.count
+	delete(d.flaps, liveURL)
+	d.mu.Unlock()
+	d.redeliver(logger, msg)
+}
+
+// redeliver 使用最新的配置投递延迟发送的消息
+func (d *dispatcher) redeliver(logger *livelogger.LiveLogger, msg *Message) {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		return
+	}
+	d.deliver(context.Background(), logger, cfg, resolveRules(cfg, msg), msg)
+}
+
+// deliver 按规则过滤并发送到目标渠道
+func (d *dispatcher) deliver(ctx context.Context, logger *livelogger.LiveLogger, cfg *configs.Config, rules *configs.NotifyRules, msg *Message) {
+	if !eventEnabled(rules, msg.Event) {
+		return
+	}
+	if rules.QuietHours != nil && rules.QuietHours.Contains(d.now()) && !containsEvent(rules.QuietHours.AllowEvents, msg.Event) {
+		if logger != nil && logger.Logger != nil {
+			logger.WithField("event", msg.Event).Debug("notification suppressed by quiet hours")
+		}
+		return
+	}
+	if tmpl, ok := rules.Templates[msg.Event]; ok {
+		if err := renderTemplate(msg, tmpl); err != nil {
+			logNotifyError(logger, "template", err)
+		}
+	}
+
+	channels := rules.Channels
+	if eventChannels, ok := rules.EventChannels[msg.Event]; ok {
+		channels = eventChannels
+	}
+	if len(channels) == 0 {
+		notifiers, buildErrs := enabledNotifiers(&cfg.Notify)
+		for name, err := range buildErrs {
+			logNotifyError(logger, name, err)
+		}
+		for name, n := range notifiers {
+			if err := sendOne(ctx, n, msg); err != nil {
+				logNotifyError(logger, name, err)
+			}
+		}
+		return
+	}
+	for _, name := range channels {
+		n, err := BuildNotifier(name, &cfg.Notify)
+		if err != nil {
+			logNotifyError(logger, name, err)
+			continue
+		}
+		if n == nil {
+			// 规则中引用了未启用的渠道，忽略
+			continue
+		}
+		if err := sendOne(ctx, n, msg); err != nil {
+			logNotifyError(logger, name, err)
+		}
+	}
+}
+
+// eventEnabled 判断事件是否需要发送，测试通知总是发送
+func eventEnabled(rules *configs.NotifyRules, event string) bool {
+	if event == EventTest {
+		return true
+	}
+	if rules.Events == nil {
+		return containsEvent(DefaultEvents, event)
+	}
+	return containsEvent(rules.Events, event)
+}
+
+func containsEvent(events []string, event string) bool {
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// renderTemplate 使用模板渲染消息的自定义标题和正文
+func renderTemplate(msg *Message, tmpl configs.NotifyTemplate) error {
+	render := func(name, text string) (string, error) {
+		if text == "" {
+			return "", nil
+		}
+		t, err := template.New(name).Funcs(sprig.TxtFuncMap()).Parse(text)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse %s template of event %s: %w", name, msg.Event, err)
+		}
+		buf := new(bytes.Buffer)
+		if err := t.Execute(buf, msg); err != nil {
+			return "", fmt.Errorf("failed to render %s template of event %s: %w", name, msg.Event, err)
+		}
+		return buf.String(), nil
+	}
+
+	title, err := render("title", tmpl.Title)
+	if err != nil {
+		return err
+	}
+	body, err := render("body", tmpl.Body)
+	if err != nil {
+		return err
+	}
+	msg.CustomTitle = title
+	msg.CustomBody = body
+	return nil
+}
@@ -0,0 +1,246 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+)
+
+const testRoomURL = "https://live.bilibili.com/1"
+
+// fakeSink 记录 fake 渠道收到的消息
+type fakeSink struct {
+	mu   sync.Mutex
+	msgs map[string][]Message
+}
+
+var sink *fakeSink
+
+type fakeBuilder struct{ name string }
+
+// Build 只有测试中设置了 sink 时才启用，避免影响其他测试对已注册渠道的遍历
+func (b *fakeBuilder) Build(cfg *configs.Notify) (Notifier, error) {
+	if sink == nil {
+		return nil, nil
+	}
+	return &fakeNotifier{name: b.name}, nil
+}
+
+type fakeNotifier struct{ name string }
+
+func (n *fakeNotifier) Send(ctx context.Context, msg *Message) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	sink.msgs[n.name] = append(sink.msgs[n.name], *msg)
+	return nil
+}
+
+func init() {
+	Register("fake_a", &fakeBuilder{name: "fake_a"})
+	Register("fake_b", &fakeBuilder{name: "fake_b"})
+}
+
+func (s *fakeSink) get(channel string) []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.msgs[channel]...)
+}
+
+func setupRules(t *testing.T, mutate func(c *configs.Config)) {
+	t.Helper()
+	cfg := configs.NewConfig()
+	cfg.LiveRooms = []configs.LiveRoom{{Url: testRoomURL, IsListening: true}}
+	mutate(cfg)
+	cfg.RefreshLiveRoomIndexCache()
+	require.NoError(t, cfg.ValidateNotifyRules())
+
+	oldCfg := configs.GetCurrentConfig()
+	configs.SetCurrentConfig(cfg)
+	sink = &fakeSink{msgs: map[string][]Message{}}
+	defaultDispatcher = newDispatcher()
+	t.Cleanup(func() {
+		sink = nil
+		defaultDispatcher = newDispatcher()
+		if oldCfg != nil {
+			configs.SetCurrentConfig(oldCfg)
+		}
+	})
+}
+
+func send(t *testing.T, event string) {
+	t.Helper()
+	require.NoError(t, Send(context.Background(), nil, &Message{
+		Event:    event,
+		HostName: "主播",
+		Platform: "哔哩哔哩",
+		LiveURL:  testRoomURL,
+	}))
+}
+
+func TestKnownNotifyEvents(t *testing.T) {
+	// 配置校验使用的事件名称需要与这里的事件类型保持一致
+	events := []string{
+		EventLiveStart, EventLiveEnd, EventLiveResumed, EventRecordingFailed,
+		EventPipelineDone, EventPipelineFailed, EventCookieExpired, EventRiskControl, EventTest,
+	}
+	assert.ElementsMatch(t, events, configs.KnownNotifyEvents)
+}
+
+func TestRulesEventFilterAndRouting(t *testing.T) {
+	setupRules(t, func(c *configs.Config) {
+		c.Notify.Rules = configs.NotifyRules{
+			Events:        []string{EventLiveStart, EventRecordingFailed},
+			Channels:      []string{"fake_a"},
+			EventChannels: map[string][]string{EventRecordingFailed: {"fake_b"}},
+		}
+	})
+
+	send(t, EventLiveStart)
+	send(t, EventLiveEnd)
+	send(t, EventRecordingFailed)
+
+	a, b := sink.get("fake_a"), sink.get("fake_b")
+	require.Len(t, a, 1)
+	assert.Equal(t, EventLiveStart, a[0].Event)
+	require.Len(t, b, 1)
+	assert.Equal(t, EventRecordingFailed, b[0].Event)
+}
+
+func TestRulesDefaultEvents(t *testing.T) {
+	setupRules(t, func(c *configs.Config) {})
+
+	send(t, EventLiveStart)
+	send(t, EventPipelineDone)
+
+	// 未配置渠道时发送到所有已启用渠道，未配置事件时只发送默认事件
+	require.Len(t, sink.get("fake_a"), 1)
+	require.Len(t, sink.get("fake_b"), 1)
+}
+
+func TestRulesTagAndRoomOverride(t *testing.T) {
+	setupRules(t, func(c *configs.Config) {
+		c.Notify.Rules = configs.NotifyRules{Channels: []string{"fake_a"}}
+		c.Notify.TagRules = map[string]configs.NotifyRules{
+			"vip": {Channels: []string{"fake_b"}},
+		}
+		c.LiveRooms[0].Tags = []string{"vip"}
+		c.LiveRooms[0].NotifyRules = &configs.NotifyRules{
+			Templates: map[string]configs.NotifyTemplate{
+				EventLiveStart: {Title: "{{ .HostName }} 开播啦", Body: "{{ .Platform }} | {{ .LiveURL }}"},
+			},
+		}
+	})
+
+	resolved := configs.GetCurrentConfig().GetEffectiveConfigForRoom(testRoomURL)
+	assert.Equal(t, configs.ConfigSourceRoom, resolved.SourceOf("notify_rules"))
+	assert.Equal(t, []string{"fake_b"}, resolved.NotifyRules.Channels)
+
+	send(t, EventLiveStart)
+
+	assert.Empty(t, sink.get("fake_a"))
+	b := sink.get("fake_b")
+	require.Len(t, b, 1)
+	assert.Equal(t, "主播 开播啦", b[0].Title())
+	assert.Equal(t, "哔哩哔哩 | "+testRoomURL, b[0].Text())
+}
+
+func TestRulesQuietHours(t *testing.T) {
+	setupRules(t, func(c *configs.Config) {
+		c.Notify.Rules = configs.NotifyRules{
+			Events: []string{EventLiveStart, EventRecordingFailed},
+			QuietHours: &configs.QuietHours{
+				Start:       "23:00",
+				End:         "08:00",
+				AllowEvents: []string{EventRecordingFailed},
+			},
+		}
+	})
+	defaultDispatcher.now = func() time.Time { return time.Date(2024, 1, 1, 2, 30, 0, 0, time.Local) }
+
+	send(t, EventLiveStart)
+	send(t, EventRecordingFailed)
+
+	a := sink.get("fake_a")
+	require.Len(t, a, 1)
+	assert.Equal(t, EventRecordingFailed, a[0].Event)
+
+	defaultDispatcher.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local) }
+	send(t, EventLiveStart)
+	assert.Len(t, sink.get("fake_a"), 2)
+}
+
+func TestQuietHoursContains(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2024, 1, 1, h, m, 0, 0, time.Local) }
+
+	overnight := &configs.QuietHours{Start: "23:00", End: "08:00"}
+	assert.True(t, overnight.Contains(at(23, 0)))
+	assert.True(t, overnight.Contains(at(7, 59)))
+	assert.False(t, overnight.Contains(at(8, 0)))
+	assert.False(t, overnight.Contains(at(12, 0)))
+
+	daytime := &configs.QuietHours{Start: "09:00", End: "18:00"}
+	assert.True(t, daytime.Contains(at(9, 0)))
+	assert.False(t, daytime.Contains(at(18, 0)))
+	assert.False(t, daytime.Contains(at(8, 59)))
+}
+
+func TestRulesFlapSuppression(t *testing.T) {
+	window := 1
+	setupRules(t, func(c *configs.Config) {
+		c.Notify.Rules = configs.NotifyRules{FlapWindowSec: &window}
+	})
+
+	send(t, EventLiveStart)
+	// 窗口内两次中断后恢复，只发送一条恢复汇总
+	send(t, EventLiveEnd)
+	send(t, EventLiveStart)
+	send(t, EventLiveEnd)
+	send(t, EventLiveStart)
+
+	require.Eventually(t, func() bool { return len(sink.get("fake_a")) == 2 }, 3*time.Second, 20*time.Millisecond)
+	a := sink.get("fake_a")
+	assert.Equal(t, EventLiveStart, a[0].Event)
+	assert.Equal(t, EventLiveResumed, a[1].Event)
+	assert.Equal(t, 2, a[1].FlapCount)
+
+	// 真正下播：窗口结束后发送下播通知
+	send(t, EventLiveEnd)
+	require.Eventually(t, func() bool { return len(sink.get("fake_a")) == 3 }, 3*time.Second, 20*time.Millisecond)
+	a = sink.get("fake_a")
+	assert.Equal(t, EventLiveEnd, a[2].Event)
+	assert.Equal(t, 0, a[2].FlapCount)
+}
+
+func TestRulesGraceOwnsFlapMerging(t *testing.T) {
+	window := 60
+	setupRules(t, func(c *configs.Config) {
+		c.Notify.Rules = configs.NotifyRules{FlapWindowSec: &window}
+		c.SessionGrace.PeriodSec = 30
+	})
+
+	// 宽限期已经合并了中断，下播通知立即发送，不再等待防抖窗口
+	send(t, EventLiveStart)
+	require.NoError(t, Send(context.Background(), nil, &Message{
+		Event:     EventLiveEnd,
+		HostName:  "主播",
+		Platform:  "哔哩哔哩",
+		LiveURL:   testRoomURL,
+		FlapCount: 2,
+	}))
+	a := sink.get("fake_a")
+	require.Len(t, a, 2)
+	assert.Equal(t, EventLiveEnd, a[1].Event)
+	assert.Equal(t, 2, a[1].FlapCount)
+
+	// 宽限期结束后重新开播同样立即通知
+	send(t, EventLiveStart)
+	a = sink.get("fake_a")
+	require.Len(t, a, 3)
+	assert.Equal(t, EventLiveStart, a[2].Event)
+}
@@ -0,0 +1,62 @@
+package serverchan
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/notify"
+)
+
+// Server酱³ 的 SendKey 形如 sctp{uid}t...，需要使用独立域名
+var sc3KeyRegexp = regexp.MustCompile(`^sctp(\d+)t`)
+
+func init() {
+	notify.Register("serverchan", new(builder))
+}
+
+type builder struct{}
+
+func (b *builder) Build(cfg *configs.Notify) (notify.Notifier, error) {
+	if !cfg.ServerChan.Enable {
+		return nil, nil
+	}
+	if cfg.ServerChan.SendKey == "" {
+		return nil, fmt.Errorf("serverchan send_key is empty")
+	}
+	return &notifier{cfg: cfg.ServerChan}, nil
+}
+
+type notifier struct {
+	cfg configs.ServerChan
+}
+
+// apiURL 根据 SendKey 推断接口地址
+func (n *notifier) apiURL() string {
+	if n.cfg.APIURL != "" {
+		return n.cfg.APIURL
+	}
+	if m := sc3KeyRegexp.FindStringSubmatch(n.cfg.SendKey); m != nil {
+		return fmt.Sprintf("https://%s.push.ft07.com/send/%s.send", m[1], n.cfg.SendKey)
+	}
+	return fmt.Sprintf("https://sctapi.ftqq.com/%s.send", n.cfg.SendKey)
+}
+
+// Send 正文使用 Markdown，附带封面图
+func (n *notifier) Send(ctx context.Context, msg *notify.Message) error {
+	form := url.Values{}
+	form.Set("title", msg.Title())
+	form.Set("desp", msg.Markdown(true))
+	resp, err := notify.PostForm(ctx, n.apiURL(), form)
+	if err != nil {
+		return err
+	}
+	if code := gjson.GetBytes(resp, "code").Int(); code != 0 {
+		return fmt.Errorf("serverchan error code %d: %s", code, gjson.GetBytes(resp, "message").String())
+	}
+	return nil
+}
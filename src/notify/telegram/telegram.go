@@ -2,47 +2,103 @@ package telegram
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/notify"
 )
 
+// APIBase Telegram Bot API 地址
+var APIBase = "https://api.telegram.org"
+
+func init() {
+	notify.Register("telegram", new(builder))
+}
+
+type builder struct{}
+
+func (b *builder) Build(cfg *configs.Notify) (notify.Notifier, error) {
+	if !cfg.Telegram.Enable {
+		return nil, nil
+	}
+	if cfg.Telegram.BotToken == "" || cfg.Telegram.ChatID == "" {
+		return nil, fmt.Errorf("telegram botToken or chatID is empty")
+	}
+	return &notifier{cfg: cfg.Telegram}, nil
+}
+
+type notifier struct {
+	cfg configs.Telegram
+}
+
+// Send 有封面时以图片+说明文字的形式发送，否则发送纯文本
+func (n *notifier) Send(ctx context.Context, msg *notify.Message) error {
+	if msg.CoverURL != "" {
+		return sendPhoto(ctx, n.cfg.BotToken, n.cfg.ChatID, msg.CoverURL, msg.Text(), n.cfg.WithNotification)
+	}
+	return sendMessage(ctx, n.cfg.BotToken, n.cfg.ChatID, msg.Text(), n.cfg.WithNotification)
+}
+
 type TelegramMessage struct {
 	ChatID              string `json:"chat_id"`
 	Text                string `json:"text"`
 	DisableNotification bool   `json:"disable_notification,omitempty"`
 }
 
+type telegramPhoto struct {
+	ChatID              string `json:"chat_id"`
+	Photo               string `json:"photo"`
+	Caption             string `json:"caption,omitempty"`
+	DisableNotification bool   `json:"disable_notification,omitempty"`
+}
+
 // SendMessage 发送Telegram消息
 // withNotification参数控制是否发送带通知的消息
 // true表示发送带提醒的消息，false表示发送静默消息
 func SendMessage(token, chatID, message string, withNotification bool) error {
-	// 确保token不包含"bot"前缀，因为URL中已经添加了
-	token = strings.TrimPrefix(token, "bot")
-
-	// 打印完整URL（仅用于调试，生产环境应移除）
-	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", token)
+	return sendMessage(context.Background(), token, chatID, message, withNotification)
+}
 
+func sendMessage(ctx context.Context, token, chatID, message string, withNotification bool) error {
 	msg := TelegramMessage{
 		ChatID:              chatID,
 		Text:                message,
 		DisableNotification: !withNotification, // 取反：true表示带通知，false表示静默
 	}
+	return call(ctx, token, "sendMessage", msg)
+}
+
+func sendPhoto(ctx context.Context, token, chatID, photo, caption string, withNotification bool) error {
+	msg := telegramPhoto{
+		ChatID:              chatID,
+		Photo:               photo,
+		Caption:             caption,
+		DisableNotification: !withNotification,
+	}
+	return call(ctx, token, "sendPhoto", msg)
+}
+
+func call(ctx context.Context, token, method string, payload any) error {
+	// 确保token不包含"bot"前缀，因为URL中已经添加了
+	token = strings.TrimPrefix(token, "bot")
+	url := fmt.Sprintf("%s/bot%s/%s", APIBase, token, method)
 
-	body, err := json.Marshal(msg)
+	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to encode message: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(body))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := notify.HTTPClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
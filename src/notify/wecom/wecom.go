@@ -0,0 +1,75 @@
+package wecom
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/notify"
+)
+
+func init() {
+	notify.Register("wecom", new(builder))
+}
+
+type builder struct{}
+
+func (b *builder) Build(cfg *configs.Notify) (notify.Notifier, error) {
+	if !cfg.WeCom.Enable {
+		return nil, nil
+	}
+	if cfg.WeCom.Webhook == "" {
+		return nil, fmt.Errorf("wecom webhook is empty")
+	}
+	return &notifier{cfg: cfg.WeCom}, nil
+}
+
+type notifier struct {
+	cfg configs.WeCom
+}
+
+type article struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+	PicURL      string `json:"picurl,omitempty"`
+}
+
+// Send 有封面时发送图文消息（卡片），否则发送 Markdown 消息
+func (n *notifier) Send(ctx context.Context, msg *notify.Message) error {
+	var payload map[string]any
+	if msg.CoverURL != "" && msg.LiveURL != "" {
+		description := fmt.Sprintf("%s · %s", msg.Platform, msg.StatusText())
+		if msg.RoomName != "" {
+			description = msg.RoomName + "\n" + description
+		}
+		payload = map[string]any{
+			"msgtype": "news",
+			"news": map[string]any{
+				"articles": []article{{
+					Title:       msg.HostName,
+					Description: description,
+					URL:         msg.LiveURL,
+					PicURL:      msg.CoverURL,
+				}},
+			},
+		}
+	} else {
+		payload = map[string]any{
+			"msgtype": "markdown",
+			"markdown": map[string]any{
+				"content": fmt.Sprintf("### %s\n%s", msg.Title(), msg.Markdown(false)),
+			},
+		}
+	}
+	resp, err := notify.PostJSON(ctx, n.cfg.Webhook, payload, nil)
+	if err != nil {
+		return err
+	}
+	if code := gjson.GetBytes(resp, "errcode").Int(); code != 0 {
+		return fmt.Errorf("wecom error code %d: %s", code, gjson.GetBytes(resp, "errmsg").String())
+	}
+	return nil
+}
@@ -11,6 +11,9 @@ const (
 	StageNameExtractCover = "extract_cover"
 	StageNameCloudUpload  = "cloud_upload"
 	StageNameCustomCmd    = "custom_command"
+	StageNameConvertAudio = "convert_audio"
+	StageNameMediaLibrary = "media_library"
+	StageNameStoryboard   = "storyboard"
 )
 
 // 阶段选项键常量
@@ -27,6 +30,14 @@ const (
 	OptionCommand = "command"
 	// OptionFileTypes 处理的文件类型过滤
 	OptionFileTypes = "file_types"
+	// OptionFormat 输出格式
+	OptionFormat = "format"
+	// OptionLibraryPath 媒体资料库根目录
+	OptionLibraryPath = "library_path"
+	// OptionLinkMode 放入资料库的方式
+	OptionLinkMode = "link_mode"
+	// OptionInterval 截图间隔（秒）
+	OptionInterval = "interval"
 )
 
 // OnRecordFinishedPipeline 扩展版的录制完成后配置
@@ -61,7 +72,18 @@ func ConvertLegacyConfig(legacy *configs.OnRecordFinished) *PipelineConfig {
 		})
 	}
 
-	// 2. MP4 转换
+	// 2. 音频转换（只处理音频文件，与 MP4 转换互不影响）
+	if legacy.AudioFormat != "" {
+		stages = append(stages, StageConfig{
+			Name: StageNameConvertAudio,
+			Options: map[string]any{
+				OptionFormat:       legacy.AudioFormat,
+				OptionDeleteSource: legacy.DeleteFlvAfterConvert,
+			},
+		})
+	}
+
+	// 3. MP4 转换
 	if legacy.ConvertToMp4 {
 		stages = append(stages, StageConfig{
 			Name: StageNameConvertMp4,
@@ -71,14 +93,32 @@ func ConvertLegacyConfig(legacy *configs.OnRecordFinished) *PipelineConfig {
 		})
 	}
 
-	// 3. 封面提取
+	// 4. 封面提取
 	if legacy.SaveCover {
 		stages = append(stages, StageConfig{
 			Name: StageNameExtractCover,
 		})
 	}
 
-	// 4. 云上传
+	// 5. 故事板（基于转换后的最终文件生成）
+	if legacy.GenerateStoryboard {
+		stages = append(stages, StageConfig{
+			Name: StageNameStoryboard,
+		})
+	}
+
+	// 6. 媒体资料库导出（使用提取的封面作为海报）
+	if legacy.MediaLibrary.Enable {
+		stages = append(stages, StageConfig{
+			Name: StageNameMediaLibrary,
+			Options: map[string]any{
+				OptionLibraryPath: legacy.MediaLibrary.Path,
+				OptionLinkMode:    legacy.MediaLibrary.LinkMode,
+			},
+		})
+	}
+
+	// 7. 云上传
 	if legacy.CloudUpload.Enable && legacy.CloudUpload.StorageName != "" {
 		stages = append(stages, StageConfig{
 			Name: StageNameCloudUpload,
@@ -90,7 +130,7 @@ func ConvertLegacyConfig(legacy *configs.OnRecordFinished) *PipelineConfig {
 		})
 	}
 
-	// 5. 自定义命令（在最后执行）
+	// 8. 自定义命令（在最后执行）
 	if legacy.CustomCommandline != "" {
 		stages = append(stages, StageConfig{
 			Name: StageNameCustomCmd,
@@ -3,11 +3,12 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/bililive-go/bililive-go/src/instance"
-	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/notify"
 	"github.com/bililive-go/bililive-go/src/pkg/events"
 	"github.com/bililive-go/bililive-go/src/pkg/livelogger"
 	bilisentry "github.com/bililive-go/bililive-go/src/pkg/sentry"
@@ -245,6 +246,34 @@ func (m *Manager) executeTask(ctx context.Context, task *PipelineTask) {
 
 	// 广播任务状态变化
 	m.broadcastTaskUpdate(task)
+
+	m.sendTaskNotification(task, err)
+}
+
+// sendTaskNotification 发送管道任务完成/失败通知，任务取消时不发送
+func (m *Manager) sendTaskNotification(task *PipelineTask, err error) {
+	msg := &notify.Message{
+		HostName: task.RecordInfo.HostName,
+		RoomName: task.RecordInfo.RoomName,
+		Platform: task.RecordInfo.Platform,
+		LiveURL:  task.RecordInfo.LiveURL,
+	}
+	switch {
+	case err == nil:
+		msg.Event = notify.EventPipelineDone
+		msg.Detail = fmt.Sprintf("输出文件 %d 个", len(task.CurrentFiles))
+		if len(task.CurrentFiles) == 1 {
+			msg.Detail = filepath.Base(task.CurrentFiles[0].Path)
+		}
+	case task.Status == PipelineStatusFailed:
+		msg.Event = notify.EventPipelineFailed
+		msg.Detail = err.Error()
+	default:
+		return
+	}
+	if sendErr := notify.Send(context.Background(), nil, msg); sendErr != nil {
+		logrus.WithError(sendErr).WithField("task_id", task.ID).Warn("failed to send pipeline notification")
+	}
 }
 
 // broadcastTaskUpdate 广播任务更新事件
@@ -276,20 +305,20 @@ func (m *Manager) EnqueueTask(task *PipelineTask) error {
 }
 
 // EnqueueRecordingTask 创建并入队录制完成后的处理任务
-// 这是 recorder.go 调用的主要入口
+// 这是 recorder.go 调用的主要入口，recordInfo 通常由 NewRecordInfo 创建
 func (m *Manager) EnqueueRecordingTask(
-	info *live.Info,
+	recordInfo RecordInfo,
 	pipelineConfig *PipelineConfig,
 	outputFiles []string,
 ) error {
 	// 构建文件信息列表
 	files := make([]FileInfo, len(outputFiles))
 	for i, path := range outputFiles {
-		files[i] = NewVideoFileInfo(path)
+		files[i] = NewRecordingFileInfo(path)
 	}
 
 	// 创建任务
-	task := NewPipelineTask(NewRecordInfo(info), pipelineConfig, files)
+	task := NewPipelineTask(recordInfo, pipelineConfig, files)
 
 	return m.EnqueueTask(task)
 }
@@ -0,0 +1,246 @@
+package stages
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/pipeline"
+	"github.com/bililive-go/bililive-go/src/pkg/utils"
+)
+
+const (
+	// maxCoverSize 下载直播间封面的大小上限
+	maxCoverSize = 10 << 20
+	// coverDownloadTimeout 下载直播间封面的超时
+	coverDownloadTimeout = 15 * time.Second
+)
+
+// ConvertAudioStage 音频转换阶段
+// 将音频直播间录制的 .aac 文件转换为 m4a 或 mp3，并写入主播名、标题、日期和封面标签，便于播客客户端和音乐软件识别
+type ConvertAudioStage struct {
+	config       pipeline.StageConfig
+	format       string
+	deleteSource bool
+	commands     []string
+	logs         string
+}
+
+// NewConvertAudioStage 创建音频转换阶段工厂
+func NewConvertAudioStage(config pipeline.StageConfig) (pipeline.Stage, error) {
+	format := strings.ToLower(config.GetStringOption(pipeline.OptionFormat, configs.AudioFormatM4a))
+	if format != configs.AudioFormatM4a && format != configs.AudioFormatMp3 {
+		return nil, fmt.Errorf("unsupported audio format: %s", format)
+	}
+	return &ConvertAudioStage{
+		config:       config,
+		format:       format,
+		deleteSource: config.GetBoolOption(pipeline.OptionDeleteSource, false),
+	}, nil
+}
+
+func (s *ConvertAudioStage) Name() string {
+	return pipeline.StageNameConvertAudio
+}
+
+func (s *ConvertAudioStage) Execute(ctx *pipeline.PipelineContext, input []pipeline.FileInfo) ([]pipeline.FileInfo, error) {
+	if len(input) == 0 {
+		s.logs = "没有输入文件"
+		return input, nil
+	}
+
+	ffmpegPath := ctx.FFmpegPath
+	if ffmpegPath == "" {
+		var err error
+		ffmpegPath, err = utils.GetFFmpegPath(ctx.Ctx)
+		if err != nil {
+			s.logs = fmt.Sprintf("ffmpeg 不可用: %s", err.Error())
+			return nil, fmt.Errorf("ffmpeg not available: %w", err)
+		}
+	}
+
+	var output []pipeline.FileInfo
+	var cover string
+	coverLoaded := false
+
+	for _, file := range input {
+		// 只处理音频文件
+		if file.Type != pipeline.FileTypeAudio {
+			output = append(output, file)
+			continue
+		}
+
+		if _, err := os.Stat(file.Path); os.IsNotExist(err) {
+			s.logs += fmt.Sprintf("文件不存在: %s\n", file.Path)
+			continue
+		}
+
+		ext := strings.ToLower(filepath.Ext(file.Path))
+		if ext == "."+s.format {
+			s.logs += fmt.Sprintf("文件 %s 已经是 %s 格式，跳过转换。\n", filepath.Base(file.Path), s.format)
+			output = append(output, file)
+			continue
+		}
+
+		// 封面只下载一次，所有分段共用
+		if !coverLoaded {
+			coverLoaded = true
+			if ctx.RecordInfo.Cover != "" {
+				path, err := downloadCover(ctx.Ctx, ctx.RecordInfo.Cover, filepath.Dir(file.Path))
+				if err != nil {
+					s.logs += fmt.Sprintf("下载直播间封面失败，不写入封面: %s\n", err.Error())
+				} else {
+					cover = path
+					defer os.Remove(cover)
+				}
+			}
+		}
+
+		outputPath := strings.TrimSuffix(file.Path, filepath.Ext(file.Path)) + "." + s.format
+		tempFile := filepath.Join(filepath.Dir(outputPath), ".converting_"+filepath.Base(outputPath))
+
+		ctx.Logger.Infof("转换音频: %s -> %s", file.Path, outputPath)
+		args := buildAudioArgs(file.Path, cover, tempFile, s.format, ctx.RecordInfo)
+		s.commands = append(s.commands, fmt.Sprintf("%s %s", ffmpegPath, strings.Join(args, " ")))
+
+		cmd := exec.CommandContext(ctx.Ctx, ffmpegPath, args...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			os.Remove(tempFile)
+			s.logs += fmt.Sprintf("ffmpeg 转换失败: %s - %s\n%s\n", file.Path, err.Error(), lastLines(string(out), 10))
+			return nil, fmt.Errorf("ffmpeg audio conversion failed for %s: %w", file.Path, err)
+		}
+		if err := os.Rename(tempFile, outputPath); err != nil {
+			os.Remove(tempFile)
+			return nil, fmt.Errorf("failed to rename temp file: %w", err)
+		}
+
+		output = append(output, pipeline.FileInfo{
+			Path:       outputPath,
+			Type:       pipeline.FileTypeAudio,
+			SourcePath: file.Path,
+		})
+
+		if s.deleteSource {
+			if err := os.Remove(file.Path); err != nil {
+				logrus.WithError(err).WithField("file", file.Path).Warn("failed to delete original file")
+				s.logs += fmt.Sprintf("删除原始文件失败: %s\n", file.Path)
+			} else {
+				s.logs += fmt.Sprintf("已删除原始文件: %s\n", file.Path)
+			}
+		} else {
+			output = append(output, file)
+		}
+
+		s.logs += fmt.Sprintf("转换完成: %s -> %s\n", filepath.Base(file.Path), filepath.Base(outputPath))
+		ctx.Logger.Infof("音频转换完成: %s", outputPath)
+	}
+
+	return output, nil
+}
+
+// buildAudioArgs 构建音频转换的 ffmpeg 参数
+// m4a 直接复制 AAC 音频，mp3 重新编码；封面统一转为 JPEG 作为附加图片写入
+func buildAudioArgs(input, cover, output, format string, info pipeline.RecordInfo) []string {
+	args := []string{"-hide_banner", "-i", input}
+	if cover != "" {
+		args = append(args, "-i", cover)
+	}
+	args = append(args, "-map", "0:a")
+	if cover != "" {
+		args = append(args, "-map", "1:v:0", "-c:v", "mjpeg", "-disposition:v:0", "attached_pic")
+	}
+
+	srcExt := strings.ToLower(filepath.Ext(input))
+	switch format {
+	case configs.AudioFormatMp3:
+		args = append(args, "-c:a", "libmp3lame", "-q:a", "2", "-id3v2_version", "3")
+		if cover != "" {
+			args = append(args, "-metadata:s:v", "title=Album cover", "-metadata:s:v", "comment=Cover (front)")
+		}
+	default:
+		if srcExt == ".aac" || srcExt == ".m4a" {
+			args = append(args, "-c:a", "copy")
+		} else {
+			args = append(args, "-c:a", "aac", "-b:a", "192k")
+		}
+		args = append(args, "-movflags", "+faststart")
+	}
+
+	for _, kv := range audioMetadata(info) {
+		args = append(args, "-metadata", kv)
+	}
+	return append(args, "-f", audioMuxer(format), "-y", output)
+}
+
+// audioMetadata 音频标签，专辑和艺术家使用主播名，便于按主播归档
+func audioMetadata(info pipeline.RecordInfo) []string {
+	var tags []string
+	add := func(key, value string) {
+		if value != "" {
+			tags = append(tags, key+"="+value)
+		}
+	}
+	add("title", info.RoomName)
+	add("artist", info.HostName)
+	add("album_artist", info.HostName)
+	add("album", info.HostName)
+	if !info.StartTime.IsZero() {
+		add("date", info.StartTime.Format("2006-01-02"))
+	}
+	add("genre", "Podcast")
+	add("comment", info.LiveURL)
+	return tags
+}
+
+func audioMuxer(format string) string {
+	if format == configs.AudioFormatMp3 {
+		return "mp3"
+	}
+	return "ipod"
+}
+
+// downloadCover 下载直播间封面到 dir 下的临时文件
+func downloadCover(ctx context.Context, coverURL, dir string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, coverDownloadTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, coverURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	f, err := os.CreateTemp(dir, ".cover_*")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, io.LimitReader(resp.Body, maxCoverSize)); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// lastLines 返回输出的最后 n 行
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,359 @@
+package stages
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/pipeline"
+	"github.com/bililive-go/bililive-go/src/pkg/utils"
+)
+
+// MediaLibraryStage 媒体资料库导出阶段
+// 为录制文件生成 Jellyfin/Emby/Plex 可识别的 nfo、海报和背景图。
+// 必须配置资料库目录，按 主播/Season 年份/剧集 组织，nfo 和图片只写入资料库目录，
+// 默认使用符号链接，除 move 方式外不会改动原输出目录
+type MediaLibraryStage struct {
+	config      pipeline.StageConfig
+	libraryPath string
+	linkMode    string
+	commands    []string
+	logs        string
+}
+
+// NewMediaLibraryStage 创建媒体资料库导出阶段工厂
+func NewMediaLibraryStage(config pipeline.StageConfig) (pipeline.Stage, error) {
+	linkMode := config.GetStringOption(pipeline.OptionLinkMode, "")
+	switch linkMode {
+	case "":
+		linkMode = configs.LinkModeSymlink
+	case configs.LinkModeSymlink, configs.LinkModeHardlink, configs.LinkModeCopy, configs.LinkModeMove:
+	default:
+		return nil, fmt.Errorf("unsupported link mode: %s", linkMode)
+	}
+	libraryPath := strings.TrimSpace(config.GetStringOption(pipeline.OptionLibraryPath, ""))
+	if libraryPath == "" {
+		// 不在录制输出目录中写入 nfo 和图片，避免混入录制文件列表和订阅
+		return nil, fmt.Errorf("library path is required")
+	}
+	return &MediaLibraryStage{
+		config:      config,
+		libraryPath: libraryPath,
+		linkMode:    linkMode,
+	}, nil
+}
+
+func (s *MediaLibraryStage) Name() string {
+	return pipeline.StageNameMediaLibrary
+}
+
+func (s *MediaLibraryStage) Execute(ctx *pipeline.PipelineContext, input []pipeline.FileInfo) ([]pipeline.FileInfo, error) {
+	if len(input) == 0 {
+		s.logs = "没有输入文件"
+		return input, nil
+	}
+
+	info := ctx.RecordInfo
+	var media []int
+	covers := make(map[string]string)
+	var anyCover string
+	for i, file := range input {
+		switch file.Type {
+		case pipeline.FileTypeVideo, pipeline.FileTypeAudio:
+			if _, err := os.Stat(file.Path); err == nil {
+				media = append(media, i)
+			} else {
+				s.logs += fmt.Sprintf("文件不存在: %s\n", file.Path)
+			}
+		case pipeline.FileTypeCover:
+			covers[file.SourcePath] = file.Path
+			if anyCover == "" {
+				anyCover = file.Path
+			}
+		}
+	}
+	if len(media) == 0 {
+		s.logs += "没有可导出的录制文件\n"
+		return input, nil
+	}
+
+	// 没有提取的封面时使用直播间封面
+	if anyCover == "" && info.Cover != "" {
+		path, err := downloadCover(ctx.Ctx, info.Cover, "")
+		if err != nil {
+			s.logs += fmt.Sprintf("下载直播间封面失败，不写入海报: %s\n", err.Error())
+		} else {
+			defer os.Remove(path)
+			anyCover = path
+		}
+	}
+
+	aired := info.StartTime
+	if aired.IsZero() {
+		if stat, err := os.Stat(input[media[0]].Path); err == nil {
+			aired = stat.ModTime()
+		} else {
+			aired = time.Now()
+		}
+	}
+	show := sanitizeName(info.HostName)
+	if show == "" {
+		show = "未知主播"
+	}
+
+	output := append([]pipeline.FileInfo(nil), input...)
+	for part, i := range media {
+		file := input[i]
+		cover := covers[file.Path]
+		if cover == "" {
+			cover = anyCover
+		}
+
+		showDir := filepath.Join(s.libraryPath, show)
+		name := fmt.Sprintf("%s - %s", show, aired.Format("2006-01-02 15-04-05"))
+		if title := sanitizeName(info.RoomName); title != "" {
+			name += " - " + title
+		}
+		// 多个分段按 Jellyfin 的多部分命名，作为同一集播放
+		if len(media) > 1 {
+			name += fmt.Sprintf(" - pt%d", part+1)
+		}
+		target := filepath.Join(showDir, fmt.Sprintf("Season %d", aired.Year()), name+filepath.Ext(file.Path))
+		if err := s.place(file.Path, target); err != nil {
+			s.logs += fmt.Sprintf("放入资料库失败: %s - %s\n", filepath.Base(file.Path), err.Error())
+			return nil, fmt.Errorf("failed to place %s into library: %w", file.Path, err)
+		}
+		if s.linkMode == configs.LinkModeMove {
+			output[i].Path = target
+		}
+
+		base := strings.TrimSuffix(target, filepath.Ext(target))
+		if err := writeNFO(base+".nfo", episodeNFO(info, aired, file.Type)); err != nil {
+			s.logs += fmt.Sprintf("写入 nfo 失败: %s\n", err.Error())
+			return nil, fmt.Errorf("failed to write nfo: %w", err)
+		}
+		s.logs += fmt.Sprintf("已写入: %s\n", base+".nfo")
+		if cover != "" {
+			if err := copyFile(cover, base+"-thumb"+imageExt(cover)); err != nil {
+				s.logs += fmt.Sprintf("写入剧集缩略图失败: %s\n", err.Error())
+			}
+		}
+
+		// 节目级别的 nfo 和图片只在不存在时写入，不覆盖用户自定义的内容
+		if err := writeFileIfAbsent(filepath.Join(showDir, "tvshow.nfo"), func(path string) error {
+			return writeNFO(path, showNFO(info))
+		}); err != nil {
+			s.logs += fmt.Sprintf("写入 tvshow.nfo 失败: %s\n", err.Error())
+		}
+		if cover != "" {
+			for _, name := range []string{"poster", "fanart"} {
+				if err := writeFileIfAbsent(filepath.Join(showDir, name+imageExt(cover)), func(path string) error {
+					return copyFile(cover, path)
+				}); err != nil {
+					s.logs += fmt.Sprintf("写入 %s 失败: %s\n", name, err.Error())
+				}
+			}
+		}
+		ctx.Logger.Infof("媒体资料库导出完成: %s", target)
+	}
+
+	return output, nil
+}
+
+// place 按放置方式将录制文件放入资料库，目标已存在时跳过
+func (s *MediaLibraryStage) place(source, target string) error {
+	if _, err := os.Lstat(target); err == nil {
+		s.logs += fmt.Sprintf("资料库中已存在，跳过: %s\n", target)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	s.commands = append(s.commands, fmt.Sprintf("%s %s %s", s.linkMode, source, target))
+	var err error
+	switch s.linkMode {
+	case configs.LinkModeHardlink:
+		err = os.Link(source, target)
+	case configs.LinkModeCopy:
+		err = copyFile(source, target)
+	case configs.LinkModeMove:
+		if err = os.Rename(source, target); err != nil {
+			// 跨文件系统时复制后删除
+			if err = copyFile(source, target); err == nil {
+				err = os.Remove(source)
+			}
+		}
+	default:
+		var abs string
+		if abs, err = filepath.Abs(source); err == nil {
+			err = os.Symlink(abs, target)
+		}
+	}
+	if err == nil {
+		s.logs += fmt.Sprintf("已放入资料库 (%s): %s\n", s.linkMode, target)
+	}
+	return err
+}
+
+func (s *MediaLibraryStage) GetCommands() []string {
+	return s.commands
+}
+
+func (s *MediaLibraryStage) GetLogs() string {
+	return s.logs
+}
+
+// nfoActor nfo 中的演员，主播作为唯一演员
+type nfoActor struct {
+	Name string `xml:"name"`
+	Role string `xml:"role"`
+}
+
+type episodeDetails struct {
+	XMLName   xml.Name   `xml:"episodedetails"`
+	Title     string     `xml:"title"`
+	ShowTitle string     `xml:"showtitle"`
+	Season    int        `xml:"season"`
+	Aired     string     `xml:"aired"`
+	DateAdded string     `xml:"dateadded"`
+	Plot      string     `xml:"plot"`
+	Studio    string     `xml:"studio,omitempty"`
+	Actors    []nfoActor `xml:"actor"`
+	Genres    []string   `xml:"genre"`
+	Tags      []string   `xml:"tag"`
+}
+
+type tvShow struct {
+	XMLName xml.Name   `xml:"tvshow"`
+	Title   string     `xml:"title"`
+	Plot    string     `xml:"plot"`
+	Studio  string     `xml:"studio,omitempty"`
+	Actors  []nfoActor `xml:"actor"`
+	Genres  []string   `xml:"genre"`
+	Tags    []string   `xml:"tag"`
+}
+
+// episodeNFO 构建剧集 nfo，简介包含录制期间的直播间标题变化
+func episodeNFO(info pipeline.RecordInfo, aired time.Time, fileType pipeline.FileType) episodeDetails {
+	titles := info.RoomTitles
+	if len(titles) == 0 && info.RoomName != "" {
+		titles = []string{info.RoomName}
+	}
+	var plot []string
+	if len(titles) > 0 {
+		plot = append(plot, "直播标题："+strings.Join(titles, " → "))
+	}
+	plot = append(plot, "主播："+info.HostName, "平台："+info.Platform)
+	if info.LiveURL != "" {
+		plot = append(plot, "直播间："+info.LiveURL)
+	}
+	tags := []string{"直播录制"}
+	if info.Platform != "" {
+		tags = append(tags, info.Platform)
+	}
+	if fileType == pipeline.FileTypeAudio {
+		tags = append(tags, "音频")
+	}
+	title := info.RoomName
+	if title == "" {
+		title = aired.Format("2006-01-02 15:04")
+	}
+	return episodeDetails{
+		Title:     title,
+		ShowTitle: info.HostName,
+		Season:    aired.Year(),
+		Aired:     aired.Format("2006-01-02"),
+		DateAdded: aired.Format("2006-01-02 15:04:05"),
+		Plot:      strings.Join(plot, "\n"),
+		Studio:    info.Platform,
+		Actors:    []nfoActor{{Name: info.HostName, Role: "主播"}},
+		Genres:    []string{"直播"},
+		Tags:      tags,
+	}
+}
+
+func showNFO(info pipeline.RecordInfo) tvShow {
+	show := tvShow{
+		Title:  info.HostName,
+		Plot:   fmt.Sprintf("%s 在%s的直播录制", info.HostName, info.Platform),
+		Studio: info.Platform,
+		Actors: []nfoActor{{Name: info.HostName, Role: "主播"}},
+		Genres: []string{"直播"},
+		Tags:   []string{"直播录制"},
+	}
+	if info.Platform != "" {
+		show.Tags = append(show.Tags, info.Platform)
+	}
+	return show
+}
+
+// writeNFO 写入带 XML 声明的 nfo 文件
+func writeNFO(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(xml.Header), append(data, '\n')...), 0644)
+}
+
+// writeFileIfAbsent 文件不存在时调用 write 写入
+func writeFileIfAbsent(path string, write func(path string) error) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	return write(path)
+}
+
+func copyFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(target)
+		return err
+	}
+	return out.Close()
+}
+
+// imageExt 根据图片内容返回扩展名，直播间封面可能不是 JPEG
+func imageExt(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ".jpg"
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, _ := f.Read(head)
+	switch http.DetectContentType(head[:n]) {
+	case "image/png":
+		return ".png"
+	case "image/webp":
+		return ".webp"
+	default:
+		return ".jpg"
+	}
+}
+
+// sanitizeName 去除文件名中的非法字符
+func sanitizeName(name string) string {
+	return strings.TrimSpace(utils.ParseString(name, utils.UnescapeHTMLEntity, utils.ReplaceIllegalChar))
+}
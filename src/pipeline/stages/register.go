@@ -11,9 +11,18 @@ func RegisterBuiltinStages(executor *pipeline.Executor) {
 	// MP4 转换
 	executor.RegisterStage(pipeline.StageNameConvertMp4, NewConvertMp4Stage)
 
+	// 音频转换
+	executor.RegisterStage(pipeline.StageNameConvertAudio, NewConvertAudioStage)
+
 	// 封面提取
 	executor.RegisterStage(pipeline.StageNameExtractCover, NewExtractCoverStage)
 
+	// 故事板
+	executor.RegisterStage(pipeline.StageNameStoryboard, NewStoryboardStage)
+
+	// 媒体资料库导出
+	executor.RegisterStage(pipeline.StageNameMediaLibrary, NewMediaLibraryStage)
+
 	// 云上传
 	executor.RegisterStage(pipeline.StageNameCloudUpload, NewCloudUploadStage)
 
@@ -35,9 +44,18 @@ func RegisterBuiltinStagesToManager(manager *pipeline.Manager) {
 	// MP4 转换
 	manager.RegisterStage(pipeline.StageNameConvertMp4, NewConvertMp4Stage)
 
+	// 音频转换
+	manager.RegisterStage(pipeline.StageNameConvertAudio, NewConvertAudioStage)
+
 	// 封面提取
 	manager.RegisterStage(pipeline.StageNameExtractCover, NewExtractCoverStage)
 
+	// 故事板
+	manager.RegisterStage(pipeline.StageNameStoryboard, NewStoryboardStage)
+
+	// 媒体资料库导出
+	manager.RegisterStage(pipeline.StageNameMediaLibrary, NewMediaLibraryStage)
+
 	// 云上传
 	manager.RegisterStage(pipeline.StageNameCloudUpload, NewCloudUploadStage)
 
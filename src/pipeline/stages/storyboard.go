@@ -0,0 +1,82 @@
+package stages
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bililive-go/bililive-go/src/pipeline"
+	"github.com/bililive-go/bililive-go/src/pkg/storyboard"
+	"github.com/bililive-go/bililive-go/src/pkg/utils"
+)
+
+// StoryboardStage 故事板阶段
+// 为视频生成雪碧图和 WebVTT 缩略图轨道，为视频和音频生成波形，保存在录制文件同目录的 .storyboard 文件夹中
+type StoryboardStage struct {
+	config   pipeline.StageConfig
+	interval time.Duration
+	commands []string
+	logs     string
+}
+
+// NewStoryboardStage 创建故事板阶段工厂
+func NewStoryboardStage(config pipeline.StageConfig) (pipeline.Stage, error) {
+	return &StoryboardStage{
+		config:   config,
+		interval: time.Duration(config.GetIntOption(pipeline.OptionInterval, 0)) * time.Second,
+	}, nil
+}
+
+func (s *StoryboardStage) Name() string {
+	return pipeline.StageNameStoryboard
+}
+
+func (s *StoryboardStage) Execute(ctx *pipeline.PipelineContext, input []pipeline.FileInfo) ([]pipeline.FileInfo, error) {
+	if len(input) == 0 {
+		s.logs = "没有输入文件"
+		return input, nil
+	}
+
+	ffmpegPath := ctx.FFmpegPath
+	if ffmpegPath == "" {
+		var err error
+		ffmpegPath, err = utils.GetFFmpegPath(ctx.Ctx)
+		if err != nil {
+			s.logs = fmt.Sprintf("ffmpeg 不可用: %s", err.Error())
+			return nil, fmt.Errorf("ffmpeg not available: %w", err)
+		}
+	}
+
+	for _, file := range input {
+		if file.Type != pipeline.FileTypeVideo && file.Type != pipeline.FileTypeAudio {
+			continue
+		}
+		if _, err := os.Stat(file.Path); os.IsNotExist(err) {
+			s.logs += fmt.Sprintf("文件不存在: %s\n", file.Path)
+			continue
+		}
+
+		dir := storyboard.SiblingDir(file.Path)
+		ctx.Logger.Infof("生成故事板: %s", file.Path)
+		s.commands = append(s.commands, fmt.Sprintf("storyboard %s -> %s", file.Path, dir))
+		m, err := storyboard.Generate(ctx.Ctx, ffmpegPath, file.Path, dir, storyboard.Options{Interval: s.interval})
+		if err != nil {
+			// 故事板只用于预览，失败不影响后续阶段
+			s.logs += fmt.Sprintf("生成故事板失败: %s - %s\n", filepath.Base(file.Path), err.Error())
+			ctx.Logger.Warnf("生成故事板失败: %s - %s", file.Path, err)
+			continue
+		}
+		s.logs += fmt.Sprintf("故事板已生成: %s（%d 张缩略图，%d 张雪碧图）\n", filepath.Base(file.Path), m.Count, len(m.Sprites))
+	}
+
+	return input, nil
+}
+
+func (s *StoryboardStage) GetCommands() []string {
+	return s.commands
+}
+
+func (s *StoryboardStage) GetLogs() string {
+	return s.logs
+}
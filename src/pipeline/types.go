@@ -4,6 +4,8 @@ package pipeline
 
 import (
 	"context"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/bililive-go/bililive-go/src/live"
@@ -21,6 +23,8 @@ type FileType string
 const (
 	// FileTypeVideo 视频文件
 	FileTypeVideo FileType = "video"
+	// FileTypeAudio 音频文件（音频直播间或只录音频时的录制文件）
+	FileTypeAudio FileType = "audio"
 	// FileTypeCover 封面文件
 	FileTypeCover FileType = "cover"
 	// FileTypeOther 其他文件
@@ -43,6 +47,26 @@ func NewVideoFileInfo(path string) FileInfo {
 	}
 }
 
+// audioExtensions 音频录制文件的扩展名
+var audioExtensions = map[string]bool{
+	".aac": true,
+	".m4a": true,
+	".mp3": true,
+}
+
+// IsAudioFile 根据扩展名判断是否为音频文件
+func IsAudioFile(path string) bool {
+	return audioExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// NewRecordingFileInfo 创建录制文件信息，按扩展名区分音频和视频
+func NewRecordingFileInfo(path string) FileInfo {
+	if IsAudioFile(path) {
+		return FileInfo{Path: path, Type: FileTypeAudio}
+	}
+	return NewVideoFileInfo(path)
+}
+
 // NewCoverFileInfo 创建封面文件信息
 func NewCoverFileInfo(path, sourcePath string) FileInfo {
 	return FileInfo{
@@ -59,6 +83,10 @@ type RecordInfo struct {
 	HostName  string       `json:"host_name"`
 	RoomName  string       `json:"room_name"`
 	StartTime time.Time    `json:"start_time"`
+	LiveURL   string       `json:"live_url,omitempty"` // 直播间地址，用于匹配直播间的通知规则
+	Cover     string       `json:"cover,omitempty"`    // 直播间封面地址，用于写入音频标签
+	// RoomTitles 录制期间使用过的直播间标题，按时间顺序
+	RoomTitles []string `json:"room_titles,omitempty"`
 }
 
 // NewRecordInfo 从 live.Info 创建录制信息
@@ -69,6 +97,8 @@ func NewRecordInfo(info *live.Info) RecordInfo {
 		HostName:  info.HostName,
 		RoomName:  info.RoomName,
 		StartTime: time.Now(),
+		LiveURL:   info.Live.GetRawUrl(),
+		Cover:     info.Cover,
 	}
 }
 
@@ -152,6 +182,23 @@ func (sc *StageConfig) GetStringOption(key string, defaultValue string) string {
 	return defaultValue
 }
 
+// GetIntOption 获取整数类型选项，兼容 JSON 解析出的浮点数
+func (sc *StageConfig) GetIntOption(key string, defaultValue int) int {
+	v, ok := sc.GetOption(key)
+	if !ok {
+		return defaultValue
+	}
+	switch val := v.(type) {
+	case int:
+		return val
+	case int64:
+		return int(val)
+	case float64:
+		return int(val)
+	}
+	return defaultValue
+}
+
 // GetStringSliceOption 获取字符串切片类型选项
 func (sc *StageConfig) GetStringSliceOption(key string) []string {
 	v, ok := sc.GetOption(key)
@@ -0,0 +1,160 @@
+// Package accounts 为直播间选择登录账号，并在账号触发风控时进行冷却和轮换
+package accounts
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+)
+
+// DefaultCooldown 账号触发风控后的默认冷却时间，冷却期间轮换到其他账号
+const DefaultCooldown = 30 * time.Minute
+
+// Selection 为直播间选中的账号
+// Name 为空表示使用 cookies 中该平台的 Cookie
+type Selection struct {
+	Name   string
+	Cookie string
+}
+
+// Status 账号的运行时状态
+type Status struct {
+	Host          string     `json:"host"`
+	Name          string     `json:"name"`
+	CooldownUntil *time.Time `json:"cooldown_until,omitempty"`
+	RiskCount     int        `json:"risk_count"`
+	LastRiskAt    *time.Time `json:"last_risk_at,omitempty"`
+}
+
+type accountKey struct {
+	host string
+	name string
+}
+
+type riskState struct {
+	cooldownUntil time.Time
+	count         int
+	lastRiskAt    time.Time
+}
+
+// Pool 记录各账号的风控冷却状态
+type Pool struct {
+	mu       sync.Mutex
+	states   map[accountKey]*riskState
+	cooldown time.Duration
+	now      func() time.Time
+}
+
+var globalPool = NewPool(DefaultCooldown)
+
+// GetPool 获取全局账号池实例
+func GetPool() *Pool {
+	return globalPool
+}
+
+// NewPool 创建账号池
+func NewPool(cooldown time.Duration) *Pool {
+	return &Pool{
+		states:   make(map[accountKey]*riskState),
+		cooldown: cooldown,
+		now:      time.Now,
+	}
+}
+
+// ReportRiskControl 记录账号触发了风控，账号在冷却时间内不会被轮换选中
+// name 为空表示 cookies 中该平台的 Cookie
+func (p *Pool) ReportRiskControl(host, name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := accountKey{host: host, name: name}
+	state, ok := p.states[key]
+	if !ok {
+		state = &riskState{}
+		p.states[key] = state
+	}
+	now := p.now()
+	state.count++
+	state.lastRiskAt = now
+	state.cooldownUntil = now.Add(p.cooldown)
+}
+
+// Reset 清除账号的风控冷却状态
+func (p *Pool) Reset(host, name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.states, accountKey{host: host, name: name})
+}
+
+func (p *Pool) coolingDown(host, name string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state, ok := p.states[accountKey{host: host, name: name}]
+	return ok && p.now().Before(state.cooldownUntil)
+}
+
+// Resolve 为直播间选择账号
+// 优先使用直播间指定的账号，否则使用 cookies 中该平台的 Cookie，再否则使用该平台的第一个账号；
+// 平台开启 account_rotation 且选中的账号处于风控冷却时，依次尝试该平台的其他账号，
+// 全部处于冷却时仍使用原账号
+func (p *Pool) Resolve(cfg *configs.Config, room *configs.LiveRoom, host string) (Selection, bool) {
+	candidates := make([]Selection, 0)
+	if room != nil && room.Account != "" {
+		if account, ok := cfg.Accounts[room.Account]; ok && !account.Disabled && account.Host == host {
+			candidates = append(candidates, Selection{Name: room.Account, Cookie: account.Cookie})
+		}
+	}
+	if cookie, ok := cfg.Cookies[host]; ok {
+		candidates = append(candidates, Selection{Cookie: cookie})
+	}
+	for _, name := range cfg.AccountsForHost(host) {
+		if len(candidates) > 0 && candidates[0].Name == name {
+			continue
+		}
+		candidates = append(candidates, Selection{Name: name, Cookie: cfg.Accounts[name].Cookie})
+	}
+	if len(candidates) == 0 {
+		return Selection{}, false
+	}
+
+	primary := candidates[0]
+	if room == nil || !cfg.IsAccountRotationEnabled(configs.GetPlatformKeyFromUrl(room.Url)) {
+		return primary, true
+	}
+	for _, candidate := range candidates {
+		if !p.coolingDown(host, candidate.Name) {
+			return candidate, true
+		}
+	}
+	return primary, true
+}
+
+// GetStatuses 返回所有触发过风控的账号状态（按 host 和名称排序）
+func (p *Pool) GetStatuses() []Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	statuses := make([]Status, 0, len(p.states))
+	for key, state := range p.states {
+		status := Status{
+			Host:      key.host,
+			Name:      key.name,
+			RiskCount: state.count,
+		}
+		lastRiskAt := state.lastRiskAt
+		status.LastRiskAt = &lastRiskAt
+		if now.Before(state.cooldownUntil) {
+			cooldownUntil := state.cooldownUntil
+			status.CooldownUntil = &cooldownUntil
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Host != statuses[j].Host {
+			return statuses[i].Host < statuses[j].Host
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
@@ -0,0 +1,94 @@
+package accounts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+)
+
+const testHost = "live.bilibili.com"
+
+func newTestConfig(rotation bool) *configs.Config {
+	cfg := configs.NewConfig()
+	cfg.Cookies = map[string]string{testHost: "legacy=1"}
+	cfg.Accounts = map[string]configs.Account{
+		"main":     {Host: testHost, Cookie: "main=1"},
+		"backup":   {Host: testHost, Cookie: "backup=1"},
+		"disabled": {Host: testHost, Cookie: "disabled=1", Disabled: true},
+		"douyu":    {Host: "www.douyu.com", Cookie: "douyu=1"},
+	}
+	cfg.PlatformConfigs["bilibili"] = configs.PlatformConfig{AccountRotation: rotation}
+	return cfg
+}
+
+func TestResolveAccount(t *testing.T) {
+	pool := NewPool(time.Minute)
+	cfg := newTestConfig(false)
+
+	room := &configs.LiveRoom{Url: "https://live.bilibili.com/1", Account: "main"}
+	sel, ok := pool.Resolve(cfg, room, testHost)
+	assert.True(t, ok)
+	assert.Equal(t, Selection{Name: "main", Cookie: "main=1"}, sel)
+
+	// 未指定账号时使用 cookies 中的 Cookie
+	room.Account = ""
+	sel, ok = pool.Resolve(cfg, room, testHost)
+	assert.True(t, ok)
+	assert.Equal(t, Selection{Cookie: "legacy=1"}, sel)
+
+	// 没有 cookies 时使用该平台的第一个启用账号
+	delete(cfg.Cookies, testHost)
+	sel, ok = pool.Resolve(cfg, room, testHost)
+	assert.True(t, ok)
+	assert.Equal(t, "backup", sel.Name)
+
+	// 停用或属于其他平台的账号不会被选中
+	room.Account = "douyu"
+	sel, _ = pool.Resolve(cfg, room, testHost)
+	assert.Equal(t, "backup", sel.Name)
+
+	_, ok = pool.Resolve(cfg, &configs.LiveRoom{Url: "https://www.huya.com/1"}, "www.huya.com")
+	assert.False(t, ok)
+}
+
+func TestResolveRotation(t *testing.T) {
+	pool := NewPool(time.Minute)
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+	room := &configs.LiveRoom{Url: "https://live.bilibili.com/1", Account: "main"}
+
+	// 未开启轮换时，即使账号处于冷却也继续使用
+	pool.ReportRiskControl(testHost, "main")
+	sel, _ := pool.Resolve(newTestConfig(false), room, testHost)
+	assert.Equal(t, "main", sel.Name)
+
+	cfg := newTestConfig(true)
+	sel, _ = pool.Resolve(cfg, room, testHost)
+	assert.Equal(t, "", sel.Name, "应轮换到 cookies 中的 Cookie")
+
+	pool.ReportRiskControl(testHost, "")
+	sel, _ = pool.Resolve(cfg, room, testHost)
+	assert.Equal(t, "backup", sel.Name)
+
+	// 全部冷却时回到原账号
+	pool.ReportRiskControl(testHost, "backup")
+	sel, _ = pool.Resolve(cfg, room, testHost)
+	assert.Equal(t, "main", sel.Name)
+
+	// 冷却结束后恢复使用原账号
+	now = now.Add(2 * time.Minute)
+	pool.ReportRiskControl(testHost, "backup")
+	sel, _ = pool.Resolve(cfg, room, testHost)
+	assert.Equal(t, "main", sel.Name)
+
+	statuses := pool.GetStatuses()
+	assert.Len(t, statuses, 3)
+	assert.Equal(t, "", statuses[0].Name)
+	assert.Nil(t, statuses[0].CooldownUntil)
+	assert.Equal(t, "backup", statuses[1].Name)
+	assert.Equal(t, 2, statuses[1].RiskCount)
+	assert.NotNil(t, statuses[1].CooldownUntil)
+}
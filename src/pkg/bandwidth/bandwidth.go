@@ -0,0 +1,292 @@
+// Package bandwidth 管理下载和上传占用的带宽
+// 全局上限扣除为接口轮询等控制流量保留的带宽后，由所有下载和上传共享；
+// 另外可以限制单个直播间的下载带宽和上传的总带宽。限速在读取数据后按读取的字节数等待，
+// 对上游表现为读取变慢，TCP 流控会相应降低对端的发送速度
+// 只有经过 NewReader 的数据受限制：原生 FLV 下载器、流探测代理（FFmpeg 和录播姬下载 FLV 流时经过）和上传；
+// FFmpeg 直接下载的 HLS 等流不受限制，也不计入全局上限，这些下载通过 MarkUnlimited 记录并在 Snapshot 中列出
+package bandwidth
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kind 带宽使用方类别
+type Kind string
+
+const (
+	// KindDownload 直播流下载
+	KindDownload Kind = "download"
+	// KindUpload 录播文件上传
+	KindUpload Kind = "upload"
+)
+
+// Consumer 带宽使用方，同一使用方的多个读取共享统计和直播间上限
+type Consumer struct {
+	Kind   Kind
+	LiveID string // 所属直播间，空表示不属于某个直播间
+}
+
+// Limits 带宽上限配置，单位均为 bytes/s，0 表示不限制
+type Limits struct {
+	Global      int64            // 全局上限，下载和上传共享
+	Reserved    int64            // 为控制流量保留的带宽，从全局上限中扣除
+	Upload      int64            // 上传总上限
+	RoomDefault int64            // 直播间下载默认上限
+	Rooms       map[string]int64 // 直播间 ID -> 下载上限，覆盖默认上限
+}
+
+// available 下载和上传可以使用的全局带宽
+func (l Limits) available() int64 {
+	if l.Global <= 0 {
+		return 0
+	}
+	return max(l.Global-l.Reserved, 1)
+}
+
+// roomLimit 直播间下载上限
+func (l Limits) roomLimit(liveID string) int64 {
+	if limit, ok := l.Rooms[liveID]; ok {
+		return limit
+	}
+	return l.RoomDefault
+}
+
+// ConsumerStats 带宽使用方的实时统计
+type ConsumerStats struct {
+	Kind       Kind   `json:"kind"`
+	LiveID     string `json:"live_id,omitempty"`
+	Speed      int64  `json:"speed"`       // 当前速度 bytes/s
+	TotalBytes int64  `json:"total_bytes"` // 累计字节数
+	Limit      int64  `json:"limit"`       // 该使用方单独的上限 bytes/s，0 表示不限制
+	Readers    int    `json:"readers"`     // 进行中的读取数量
+}
+
+// UnlimitedDownload 不受带宽限制的直播流下载
+type UnlimitedDownload struct {
+	LiveID string `json:"live_id"`
+	Reason string `json:"reason"`
+}
+
+// Snapshot 带宽使用情况
+type Snapshot struct {
+	GlobalLimit int64               `json:"global_limit"`
+	Reserved    int64               `json:"reserved"`
+	Available   int64               `json:"available"` // 下载和上传可用的全局带宽，0 表示不限制
+	UploadLimit int64               `json:"upload_limit"`
+	Consumers   []ConsumerStats     `json:"consumers"`
+	Unlimited   []UnlimitedDownload `json:"unlimited"` // 不受带宽限制、也不计入全局上限的下载
+}
+
+const (
+	// speedWindow 计算实时速度的时间窗口
+	speedWindow = time.Second
+	// maxChunk 单次读取的最大字节数，避免一次读取透支过多导致长时间等待
+	maxChunk = 64 * 1024
+)
+
+// consumer 使用方的状态
+type consumer struct {
+	bucket  bucket
+	readers int
+	total   int64
+
+	windowStart time.Time
+	windowBytes int64
+	speed       int64
+}
+
+// record 记录读取的字节数并更新实时速度
+func (c *consumer) record(n int, now time.Time) {
+	c.total += int64(n)
+	c.windowBytes += int64(n)
+	if elapsed := now.Sub(c.windowStart); elapsed >= speedWindow {
+		c.speed = int64(float64(c.windowBytes) / elapsed.Seconds())
+		c.windowStart = now
+		c.windowBytes = 0
+	}
+}
+
+// currentSpeed 返回实时速度，长时间没有数据时为 0
+func (c *consumer) currentSpeed(now time.Time) int64 {
+	if now.Sub(c.windowStart) > 2*speedWindow {
+		return 0
+	}
+	return c.speed
+}
+
+// Manager 带宽管理器
+type Manager struct {
+	mu        sync.Mutex
+	limits    Limits
+	global    bucket
+	upload    bucket
+	consumers map[Consumer]*consumer
+	unlimited map[string]*unlimitedDownload // 直播间 ID -> 不受限制的下载
+}
+
+// unlimitedDownload 直播间不受限制的下载
+type unlimitedDownload struct {
+	reason string
+	count  int
+}
+
+var globalManager = NewManager()
+
+// GetManager 获取全局带宽管理器
+func GetManager() *Manager {
+	return globalManager
+}
+
+// NewManager 创建带宽管理器，默认不限制
+func NewManager() *Manager {
+	now := time.Now()
+	return &Manager{
+		global:    newBucket(0, now),
+		upload:    newBucket(0, now),
+		consumers: make(map[Consumer]*consumer),
+		unlimited: make(map[string]*unlimitedDownload),
+	}
+}
+
+// SetLimits 设置带宽上限，进行中的读取立即生效
+func (m *Manager) SetLimits(limits Limits) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.limits = limits
+	m.global.setRate(limits.available(), now)
+	m.upload.setRate(limits.Upload, now)
+	for key, c := range m.consumers {
+		c.bucket.setRate(m.consumerLimitLocked(key), now)
+	}
+}
+
+// consumerLimitLocked 返回使用方单独的上限，调用方需持有锁
+func (m *Manager) consumerLimitLocked(key Consumer) int64 {
+	if key.Kind != KindDownload || key.LiveID == "" {
+		return 0
+	}
+	return m.limits.roomLimit(key.LiveID)
+}
+
+// NewReader 返回受带宽限制的 Reader，读取的数据计入使用方的统计
+// 关闭返回的 Reader 时会同时关闭实现了 io.Closer 的原 Reader
+func (m *Manager) NewReader(ctx context.Context, key Consumer, r io.Reader) io.ReadCloser {
+	m.mu.Lock()
+	c, ok := m.consumers[key]
+	if !ok {
+		now := time.Now()
+		c = &consumer{bucket: newBucket(m.consumerLimitLocked(key), now), windowStart: now}
+		m.consumers[key] = c
+	}
+	c.readers++
+	m.mu.Unlock()
+	return &reader{ctx: ctx, m: m, key: key, r: r}
+}
+
+// release 结束一个读取，使用方没有进行中的读取时移除
+func (m *Manager) release(key Consumer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.consumers[key]
+	if !ok {
+		return
+	}
+	if c.readers--; c.readers <= 0 {
+		delete(m.consumers, key)
+	}
+}
+
+// MarkUnlimited 记录直播间不经过带宽限制的下载（如 FFmpeg 直接下载 HLS 流），下载结束时调用返回的函数
+func (m *Manager) MarkUnlimited(liveID, reason string) (done func()) {
+	m.mu.Lock()
+	u, ok := m.unlimited[liveID]
+	if !ok {
+		u = &unlimitedDownload{}
+		m.unlimited[liveID] = u
+	}
+	u.reason = reason
+	u.count++
+	m.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if u.count--; u.count <= 0 && m.unlimited[liveID] == u {
+				delete(m.unlimited, liveID)
+			}
+		})
+	}
+}
+
+// wait 记录读取的字节数，并等待到各级上限允许的时间
+func (m *Manager) wait(ctx context.Context, key Consumer, n int) error {
+	m.mu.Lock()
+	now := time.Now()
+	var delay time.Duration
+	if c, ok := m.consumers[key]; ok {
+		c.record(n, now)
+		delay = c.bucket.take(n, now)
+	}
+	if key.Kind == KindUpload {
+		delay = max(delay, m.upload.take(n, now))
+	}
+	delay = max(delay, m.global.take(n, now))
+	m.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Snapshot 返回当前带宽使用情况
+func (m *Manager) Snapshot() Snapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	snapshot := Snapshot{
+		GlobalLimit: m.limits.Global,
+		Reserved:    m.limits.Reserved,
+		Available:   m.limits.available(),
+		UploadLimit: m.limits.Upload,
+		Consumers:   make([]ConsumerStats, 0, len(m.consumers)),
+		Unlimited:   make([]UnlimitedDownload, 0, len(m.unlimited)),
+	}
+	for liveID, u := range m.unlimited {
+		snapshot.Unlimited = append(snapshot.Unlimited, UnlimitedDownload{LiveID: liveID, Reason: u.reason})
+	}
+	sort.Slice(snapshot.Unlimited, func(i, j int) bool {
+		return snapshot.Unlimited[i].LiveID < snapshot.Unlimited[j].LiveID
+	})
+	for key, c := range m.consumers {
+		snapshot.Consumers = append(snapshot.Consumers, ConsumerStats{
+			Kind:       key.Kind,
+			LiveID:     key.LiveID,
+			Speed:      c.currentSpeed(now),
+			TotalBytes: c.total,
+			Limit:      int64(c.bucket.rate),
+			Readers:    c.readers,
+		})
+	}
+	sort.Slice(snapshot.Consumers, func(i, j int) bool {
+		if snapshot.Consumers[i].Kind != snapshot.Consumers[j].Kind {
+			return snapshot.Consumers[i].Kind < snapshot.Consumers[j].Kind
+		}
+		return snapshot.Consumers[i].LiveID < snapshot.Consumers[j].LiveID
+	})
+	return snapshot
+}
@@ -0,0 +1,93 @@
+package bandwidth
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketTake(t *testing.T) {
+	now := time.Now()
+	b := newBucket(1024*1024, now)
+
+	// 桶容量内不需要等待，透支后按速率偿还
+	assert.Zero(t, b.take(512*1024, now))
+	assert.Equal(t, 500*time.Millisecond, b.take(512*1024, now))
+	assert.Equal(t, 250*time.Millisecond, b.take(256*1024, now.Add(500*time.Millisecond)))
+
+	// 不限制时始终不需要等待
+	unlimited := newBucket(0, now)
+	assert.Zero(t, unlimited.take(100*1024*1024, now))
+}
+
+func TestLimitsAvailable(t *testing.T) {
+	assert.Zero(t, Limits{Reserved: 100}.available())
+	assert.Equal(t, int64(900), Limits{Global: 1000, Reserved: 100}.available())
+	assert.Equal(t, int64(1), Limits{Global: 1000, Reserved: 1000}.available())
+}
+
+func TestReaderRoomLimit(t *testing.T) {
+	m := NewManager()
+	m.SetLimits(Limits{Rooms: map[string]int64{"a": 256 * 1024}})
+
+	data := make([]byte, 256*1024)
+	throttled := m.NewReader(context.Background(), Consumer{Kind: KindDownload, LiveID: "a"}, bytes.NewReader(data))
+	unlimited := m.NewReader(context.Background(), Consumer{Kind: KindDownload, LiveID: "b"}, bytes.NewReader(data))
+
+	// 桶容量为 128KB，读取 256KB 需要偿还 128KB，约 0.5 秒
+	start := time.Now()
+	n, err := io.Copy(io.Discard, throttled)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), n)
+	assert.InDelta(t, 500*time.Millisecond, time.Since(start), float64(200*time.Millisecond))
+
+	// 其他直播间不受影响
+	start = time.Now()
+	_, err = io.Copy(io.Discard, unlimited)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+
+	snapshot := m.Snapshot()
+	require.Len(t, snapshot.Consumers, 2)
+	assert.Equal(t, ConsumerStats{Kind: KindDownload, LiveID: "a", Speed: snapshot.Consumers[0].Speed, TotalBytes: int64(len(data)), Limit: 256 * 1024, Readers: 1}, snapshot.Consumers[0])
+	assert.Equal(t, int64(0), snapshot.Consumers[1].Limit)
+
+	// 关闭后移除使用方
+	require.NoError(t, throttled.Close())
+	require.NoError(t, throttled.Close())
+	require.NoError(t, unlimited.Close())
+	assert.Empty(t, m.Snapshot().Consumers)
+}
+
+func TestReaderCancel(t *testing.T) {
+	m := NewManager()
+	m.SetLimits(Limits{Global: 1000, Reserved: 500})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	r := m.NewReader(ctx, Consumer{Kind: KindUpload}, bytes.NewReader(make([]byte, 128*1024)))
+	defer r.Close()
+
+	// 全局可用 500 bytes/s，透支后等待被 context 取消
+	_, err := io.Copy(io.Discard, r)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestMarkUnlimited(t *testing.T) {
+	m := NewManager()
+	done1 := m.MarkUnlimited("1", "hls")
+	done2 := m.MarkUnlimited("1", "hls")
+	assert.Equal(t, []UnlimitedDownload{{LiveID: "1", Reason: "hls"}}, m.Snapshot().Unlimited)
+
+	// 同一直播间的下载全部结束后才移除，重复调用不会多减
+	done1()
+	done1()
+	assert.Len(t, m.Snapshot().Unlimited, 1)
+	done2()
+	assert.Empty(t, m.Snapshot().Unlimited)
+}
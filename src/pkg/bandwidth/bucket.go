@@ -0,0 +1,52 @@
+package bandwidth
+
+import "time"
+
+// burstWindow 令牌桶容量对应的时长，空闲后最多允许突发这段时间的流量
+const burstWindow = 500 * time.Millisecond
+
+// bucket 以字节为单位的令牌桶，令牌可以透支，透支的部分通过等待偿还
+type bucket struct {
+	rate    float64 // bytes/s，0 表示不限制
+	tokens  float64
+	updated time.Time
+}
+
+func newBucket(rate int64, now time.Time) bucket {
+	b := bucket{rate: float64(rate), updated: now}
+	b.tokens = b.burst()
+	return b
+}
+
+// burst 桶容量
+func (b *bucket) burst() float64 {
+	return max(b.rate*burstWindow.Seconds(), maxChunk)
+}
+
+// refill 按经过的时间补充令牌
+func (b *bucket) refill(now time.Time) {
+	if now.After(b.updated) && b.rate > 0 {
+		b.tokens = min(b.tokens+b.rate*now.Sub(b.updated).Seconds(), b.burst())
+	}
+	b.updated = now
+}
+
+// setRate 修改速率，已透支的令牌保留
+func (b *bucket) setRate(rate int64, now time.Time) {
+	b.refill(now)
+	b.rate = float64(rate)
+	b.tokens = min(b.tokens, b.burst())
+}
+
+// take 取出 n 字节的令牌，返回偿还透支需要等待的时间
+func (b *bucket) take(n int, now time.Time) time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+	b.refill(now)
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
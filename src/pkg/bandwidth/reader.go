@@ -0,0 +1,40 @@
+package bandwidth
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// reader 受带宽限制的 Reader
+type reader struct {
+	ctx       context.Context
+	m         *Manager
+	key       Consumer
+	r         io.Reader
+	closeOnce sync.Once
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if len(p) > maxChunk {
+		p = p[:maxChunk]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if waitErr := r.m.wait(r.ctx, r.key, n); waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	return n, err
+}
+
+func (r *reader) Close() error {
+	var err error
+	r.closeOnce.Do(func() {
+		r.m.release(r.key)
+		if closer, ok := r.r.(io.Closer); ok {
+			err = closer.Close()
+		}
+	})
+	return err
+}
@@ -0,0 +1,458 @@
+// Package cookiemonitor 定期检查各平台 Cookie 的登录状态，失效时发送通知，并在平台支持时自动刷新 Cookie
+package cookiemonitor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/instance"
+	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/notify"
+	"github.com/bililive-go/bililive-go/src/pkg/ratelimit"
+	bilisentry "github.com/bililive-go/bililive-go/src/pkg/sentry"
+	"github.com/bililive-go/bililive-go/src/types"
+)
+
+// State Cookie 状态
+type State string
+
+const (
+	StateUnknown     State = "unknown"     // 尚未检查
+	StateValid       State = "valid"       // 已登录
+	StateExpired     State = "expired"     // 已失效
+	StateError       State = "error"       // 检查失败（网络错误等），不代表 Cookie 失效
+	StateUnsupported State = "unsupported" // 该平台没有注册检查器
+)
+
+// checkTimeout 单次检查（含刷新）的超时
+const checkTimeout = 30 * time.Second
+
+// CheckResult 一次登录状态检查的结果
+type CheckResult struct {
+	LoggedIn    bool
+	UserName    string
+	ExpiresAt   time.Time // 零值表示未知
+	NeedRefresh bool      // 平台提示 Cookie 需要刷新
+}
+
+// Checker 平台 Cookie 检查器
+type Checker interface {
+	Check(ctx context.Context, cookie string) (*CheckResult, error)
+}
+
+// Refresher 支持自动刷新 Cookie 的检查器可以额外实现此接口
+type Refresher interface {
+	// Refresh 使用刷新令牌换取新的 Cookie，返回新 Cookie 和新的刷新令牌
+	Refresh(ctx context.Context, cookie, refreshToken string) (newCookie, newRefreshToken string, err error)
+}
+
+// Status 某个 host 或命名账号的 Cookie 状态
+type Status struct {
+	Host        string     `json:"host"`
+	Account     string     `json:"account,omitempty"` // 命名账号名称，空表示该 host 的默认 Cookie
+	State       State      `json:"state"`
+	UserName    string     `json:"user_name,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	NeedRefresh bool       `json:"need_refresh"`
+	CanRefresh  bool       `json:"can_refresh"` // 平台支持自动刷新且已配置刷新令牌
+	LastCheck   *time.Time `json:"last_check,omitempty"`
+	LastRefresh *time.Time `json:"last_refresh,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+var (
+	checkersMu sync.RWMutex
+	checkers   = map[string]Checker{}
+)
+
+// Register 注册某个 host 的 Cookie 检查器，通常在平台包的 init 中调用
+func Register(host string, c Checker) {
+	checkersMu.Lock()
+	defer checkersMu.Unlock()
+	checkers[host] = c
+}
+
+func getChecker(host string) (Checker, bool) {
+	checkersMu.RLock()
+	defer checkersMu.RUnlock()
+	c, ok := checkers[host]
+	return c, ok
+}
+
+// CheckCookie 使用 host 对应的检查器检查一段 Cookie（如命名账号的 Cookie），不记录状态也不发送通知
+func CheckCookie(ctx context.Context, host, cookie string) (*CheckResult, error) {
+	checker, ok := getChecker(host)
+	if !ok {
+		return nil, fmt.Errorf("no cookie checker registered for %s", host)
+	}
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	return check(ctx, checker, host, cookie)
+}
+
+// check 按平台 Cookie 检查的访问频率限制等待后执行检查
+func check(ctx context.Context, checker Checker, host, cookie string) (*CheckResult, error) {
+	if !ratelimit.GetGlobalRateLimiter().Wait(ctx, ratelimit.Request{
+		Platform: configs.GetPlatformKeyFromUrl("https://" + host),
+		Endpoint: ratelimit.EndpointCookie,
+		Room:     host,
+	}) {
+		return nil, ctx.Err()
+	}
+	return checker.Check(ctx, cookie)
+}
+
+// target 一段需要检查的 Cookie：某个 host 的默认 Cookie 或一个命名账号
+type target struct {
+	host         string
+	account      string // 命名账号名称，空表示 host 的默认 Cookie
+	cookie       string
+	refreshToken string
+}
+
+// save 保存刷新后的 Cookie 和刷新令牌
+func (t target) save(cookie, refreshToken string) (*configs.Config, error) {
+	if t.account == "" {
+		return configs.SetCookieWithRefreshToken(t.host, cookie, refreshToken)
+	}
+	return configs.SetAccountCookie(t.account, cookie, refreshToken)
+}
+
+// currentCookie 从配置中读取最新的 Cookie
+func (t target) currentCookie(cfg *configs.Config) string {
+	if t.account == "" {
+		return cfg.Cookies[t.host]
+	}
+	return cfg.Accounts[t.account].Cookie
+}
+
+// Monitor Cookie 健康检查器
+type Monitor struct {
+	mu              sync.RWMutex
+	statuses        map[string]*Status // host -> 默认 Cookie 状态
+	accountStatuses map[string]*Status // 账号名称 -> 命名账号状态
+	trigger         chan struct{}
+	now             func() time.Time
+}
+
+var globalMonitor = NewMonitor()
+
+// GetMonitor 获取全局 Cookie 检查器实例
+func GetMonitor() *Monitor {
+	return globalMonitor
+}
+
+// NewMonitor 创建 Cookie 检查器
+func NewMonitor() *Monitor {
+	return &Monitor{
+		statuses:        make(map[string]*Status),
+		accountStatuses: make(map[string]*Status),
+		trigger:         make(chan struct{}, 1),
+		now:             time.Now,
+	}
+}
+
+// Start 在后台定期检查所有已配置的 Cookie，检查间隔每轮从当前配置读取
+func (m *Monitor) Start(ctx context.Context) {
+	bilisentry.Go(func() {
+		for {
+			cfg := configs.GetCurrentConfig()
+			interval := defaultInterval()
+			if cfg != nil {
+				interval = cfg.CookieMonitor.GetCheckInterval()
+				if cfg.CookieMonitor.Enable {
+					m.CheckAll(ctx)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-m.trigger:
+			case <-time.After(interval):
+			}
+		}
+	})
+}
+
+func defaultInterval() time.Duration {
+	return configs.CookieMonitor{}.GetCheckInterval()
+}
+
+// TriggerCheck 通知后台循环立即进行一轮检查
+func (m *Monitor) TriggerCheck() {
+	select {
+	case m.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// CheckAll 检查所有已配置 Cookie 且注册了检查器的 host，以及所有启用的命名账号
+func (m *Monitor) CheckAll(ctx context.Context) []Status {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		return nil
+	}
+	for host, cookie := range cfg.Cookies {
+		if cookie == "" {
+			continue
+		}
+		if _, ok := getChecker(host); !ok {
+			continue
+		}
+		if _, err := m.CheckHost(ctx, host); err != nil {
+			logrus.WithError(err).WithField("host", host).Debug("cookie check failed")
+		}
+	}
+	for name, account := range cfg.Accounts {
+		if account.Disabled || account.Cookie == "" {
+			continue
+		}
+		if _, ok := getChecker(account.Host); !ok {
+			continue
+		}
+		if _, err := m.CheckAccount(ctx, name); err != nil {
+			logrus.WithError(err).WithField("account", name).Debug("account cookie check failed")
+		}
+	}
+	return m.GetStatuses()
+}
+
+// CheckHost 立即检查指定 host 的 Cookie，必要时自动刷新
+func (m *Monitor) CheckHost(ctx context.Context, host string) (Status, error) {
+	if _, ok := getChecker(host); !ok {
+		return Status{Host: host, State: StateUnsupported}, fmt.Errorf("no cookie checker registered for %s", host)
+	}
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil || cfg.Cookies[host] == "" {
+		return Status{Host: host, State: StateUnknown}, fmt.Errorf("no cookie configured for %s", host)
+	}
+	return m.checkTarget(ctx, cfg, target{
+		host:         host,
+		cookie:       cfg.Cookies[host],
+		refreshToken: cfg.CookieMonitor.RefreshTokens[host],
+	})
+}
+
+// CheckAccount 立即检查指定命名账号的 Cookie，必要时使用账号的刷新令牌自动刷新
+func (m *Monitor) CheckAccount(ctx context.Context, name string) (Status, error) {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		return Status{Account: name, State: StateUnknown}, fmt.Errorf("account %s not found", name)
+	}
+	account, ok := cfg.Accounts[name]
+	if !ok {
+		return Status{Account: name, State: StateUnknown}, fmt.Errorf("account %s not found", name)
+	}
+	if _, ok := getChecker(account.Host); !ok {
+		return Status{Host: account.Host, Account: name, State: StateUnsupported}, fmt.Errorf("no cookie checker registered for %s", account.Host)
+	}
+	if account.Cookie == "" {
+		return Status{Host: account.Host, Account: name, State: StateUnknown}, fmt.Errorf("no cookie configured for account %s", name)
+	}
+	return m.checkTarget(ctx, cfg, target{
+		host:         account.Host,
+		account:      name,
+		cookie:       account.Cookie,
+		refreshToken: account.RefreshToken,
+	})
+}
+
+// checkTarget 检查一段 Cookie，需要刷新且配置了刷新令牌时自动刷新，首次失效时发送通知
+func (m *Monitor) checkTarget(ctx context.Context, cfg *configs.Config, t target) (Status, error) {
+	checker, _ := getChecker(t.host)
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	refresher, canRefresh := checker.(Refresher)
+	canRefresh = canRefresh && t.refreshToken != ""
+
+	now := m.now()
+	status := &Status{
+		Host:       t.host,
+		Account:    t.account,
+		State:      StateUnknown,
+		CanRefresh: canRefresh,
+		LastCheck:  &now,
+	}
+	if prev := m.getStatus(t); prev != nil {
+		status.LastRefresh = prev.LastRefresh
+	}
+
+	res, err := check(ctx, checker, t.host, t.cookie)
+	if err == nil && res.LoggedIn && res.NeedRefresh && canRefresh && cfg.CookieMonitor.AutoRefresh {
+		if refreshErr := m.refresh(ctx, t, refresher); refreshErr != nil {
+			status.LastError = "刷新 Cookie 失败: " + refreshErr.Error()
+		} else {
+			refreshedAt := m.now()
+			status.LastRefresh = &refreshedAt
+			if newCfg := configs.GetCurrentConfig(); newCfg != nil {
+				res, err = check(ctx, checker, t.host, t.currentCookie(newCfg))
+			}
+		}
+	}
+
+	switch {
+	case err != nil:
+		status.State = StateError
+		status.LastError = err.Error()
+	case res.LoggedIn:
+		status.State = StateValid
+		status.UserName = res.UserName
+		status.NeedRefresh = res.NeedRefresh
+		if !res.ExpiresAt.IsZero() {
+			expiresAt := res.ExpiresAt
+			status.ExpiresAt = &expiresAt
+		}
+	default:
+		status.State = StateExpired
+	}
+
+	prev := m.setStatus(status)
+	if status.State == StateExpired && (prev == nil || prev.State != StateExpired) {
+		m.notifyExpired(t)
+	}
+	return *status, err
+}
+
+// refresh 刷新 Cookie 并持久化，然后让使用该 host 的直播间重新加载 Cookie
+func (m *Monitor) refresh(ctx context.Context, t target, r Refresher) error {
+	newCookie, newRefreshToken, err := r.Refresh(ctx, t.cookie, t.refreshToken)
+	if err != nil {
+		return err
+	}
+	newCfg, err := t.save(newCookie, newRefreshToken)
+	if err != nil {
+		return fmt.Errorf("failed to save refreshed cookie: %w", err)
+	}
+	logrus.WithField("host", t.host).WithField("account", t.account).Info("cookie refreshed")
+	ReloadLiveOptions(ctx, newCfg, t.host)
+	return nil
+}
+
+// ReloadLiveOptions 让使用指定 host 的直播间重新解析账号并从配置加载 Cookie
+func ReloadLiveOptions(ctx context.Context, cfg *configs.Config, host string) {
+	inst := instance.GetInstance(ctx)
+	if inst == nil {
+		return
+	}
+	for _, room := range cfg.LiveRooms {
+		u, err := url.Parse(room.Url)
+		if err != nil || u.Host != host {
+			continue
+		}
+		room := room
+		inst.Lives.Range(func(_ types.LiveID, l live.Live) bool {
+			if l.GetRawUrl() == room.Url {
+				if err := l.UpdateLiveOptionsbyConfig(ctx, &room); err != nil {
+					l.GetLogger().WithError(err).Warn("failed to reload cookie")
+				}
+				return false
+			}
+			return true
+		})
+	}
+}
+
+func (m *Monitor) notifyExpired(t target) {
+	platform := configs.GetPlatformKeyFromUrl("https://" + t.host)
+	if cfg := configs.GetCurrentConfig(); cfg != nil {
+		if pc, ok := cfg.PlatformConfigs[platform]; ok && pc.Name != "" {
+			platform = pc.Name
+		}
+	}
+	hostName := t.host
+	detail := "Cookie 已失效，录制可能降级为低画质或失败，请重新设置 Cookie"
+	if t.account != "" {
+		hostName = fmt.Sprintf("%s (账号 %s)", t.host, t.account)
+		detail = fmt.Sprintf("账号 %s 的 Cookie 已失效，使用该账号的直播间录制可能降级为低画质或失败，请重新登录", t.account)
+	}
+	if err := notify.Send(context.Background(), nil, &notify.Message{
+		Event:    notify.EventCookieExpired,
+		HostName: hostName,
+		Platform: platform,
+		Detail:   detail,
+	}); err != nil {
+		logrus.WithError(err).WithField("host", t.host).WithField("account", t.account).Warn("failed to send cookie expired notification")
+	}
+}
+
+func (m *Monitor) getStatus(t target) *Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if t.account != "" {
+		return m.accountStatuses[t.account]
+	}
+	return m.statuses[t.host]
+}
+
+func (m *Monitor) setStatus(status *Status) (prev *Status) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	statuses := m.statuses
+	key := status.Host
+	if status.Account != "" {
+		statuses, key = m.accountStatuses, status.Account
+	}
+	prev = statuses[key]
+	statuses[key] = status
+	return prev
+}
+
+// ResetAccount 清除命名账号的检查状态，账号删除或 Cookie 被手动替换后调用
+func (m *Monitor) ResetAccount(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.accountStatuses, name)
+}
+
+// GetAccountStatus 返回命名账号最近一次检查的状态，尚未检查时返回 nil
+func (m *Monitor) GetAccountStatus(name string) *Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if s, ok := m.accountStatuses[name]; ok {
+		status := *s
+		return &status
+	}
+	return nil
+}
+
+// GetStatuses 返回所有已配置 Cookie 的 host 状态，按 host 排序
+func (m *Monitor) GetStatuses() []Status {
+	cfg := configs.GetCurrentConfig()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]Status, 0)
+	if cfg == nil {
+		return result
+	}
+	for host, cookie := range cfg.Cookies {
+		if cookie == "" {
+			continue
+		}
+		if s, ok := m.statuses[host]; ok {
+			result = append(result, *s)
+			continue
+		}
+		checker, ok := getChecker(host)
+		if !ok {
+			result = append(result, Status{Host: host, State: StateUnsupported})
+			continue
+		}
+		_, isRefresher := checker.(Refresher)
+		result = append(result, Status{
+			Host:       host,
+			State:      StateUnknown,
+			CanRefresh: isRefresher && cfg.CookieMonitor.RefreshTokens[host] != "",
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Host < result[j].Host })
+	return result
+}
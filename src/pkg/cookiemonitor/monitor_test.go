@@ -0,0 +1,168 @@
+package cookiemonitor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/notify"
+	"github.com/bililive-go/bililive-go/src/pkg/ratelimit"
+)
+
+const testHost = "fake.example.com"
+
+// fakeChecker 以 Cookie 字符串判断登录状态："valid" / "stale"（需要刷新） / 其他视为失效
+type fakeChecker struct {
+	refreshed int
+}
+
+func (c *fakeChecker) Check(ctx context.Context, cookie string) (*CheckResult, error) {
+	switch cookie {
+	case "valid":
+		return &CheckResult{LoggedIn: true, UserName: "user"}, nil
+	case "stale":
+		return &CheckResult{LoggedIn: true, UserName: "user", NeedRefresh: true}, nil
+	default:
+		return &CheckResult{LoggedIn: false}, nil
+	}
+}
+
+func (c *fakeChecker) Refresh(ctx context.Context, cookie, refreshToken string) (string, string, error) {
+	c.refreshed++
+	return "valid", refreshToken + "_new", nil
+}
+
+// capture 记录发送到 fake 通知渠道的事件
+var capture struct {
+	sync.Mutex
+	events []string
+}
+
+type captureBuilder struct{}
+
+func (b *captureBuilder) Build(cfg *configs.Notify) (notify.Notifier, error) {
+	return captureNotifier{}, nil
+}
+
+type captureNotifier struct{}
+
+func (captureNotifier) Send(ctx context.Context, msg *notify.Message) error {
+	capture.Lock()
+	defer capture.Unlock()
+	capture.events = append(capture.events, msg.Event)
+	return nil
+}
+
+func capturedEvents() []string {
+	capture.Lock()
+	defer capture.Unlock()
+	return append([]string(nil), capture.events...)
+}
+
+func setup(t *testing.T, cookie, refreshToken string) *fakeChecker {
+	t.Helper()
+	checker := &fakeChecker{}
+	Register(testHost, checker)
+	notify.Register("cookiemonitor_capture", &captureBuilder{})
+
+	cfg := configs.NewConfig()
+	cfg.Cookies = map[string]string{testHost: cookie, "unsupported.example.com": "a=b"}
+	// 放宽 Cookie 检查的访问频率限制，避免测试中多次检查互相等待
+	cfg.RateLimits[string(ratelimit.EndpointCookie)] = configs.RateLimitBucket{IntervalSec: 1, Burst: 20}
+	if refreshToken != "" {
+		cfg.CookieMonitor.RefreshTokens = map[string]string{testHost: refreshToken}
+	}
+	configs.SetCurrentConfig(cfg)
+
+	capture.Lock()
+	capture.events = nil
+	capture.Unlock()
+	return checker
+}
+
+func TestMonitorExpiredNotifiesOnce(t *testing.T) {
+	setup(t, "dead", "")
+	m := NewMonitor()
+
+	status, err := m.CheckHost(context.Background(), testHost)
+	require.NoError(t, err)
+	assert.Equal(t, StateExpired, status.State)
+	assert.NotNil(t, status.LastCheck)
+
+	// 持续失效只通知一次
+	_, err = m.CheckHost(context.Background(), testHost)
+	require.NoError(t, err)
+	assert.Equal(t, []string{notify.EventCookieExpired}, capturedEvents())
+
+	statuses := m.GetStatuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, testHost, statuses[0].Host)
+	assert.Equal(t, StateExpired, statuses[0].State)
+	assert.Equal(t, StateUnsupported, statuses[1].State)
+}
+
+func TestMonitorAutoRefresh(t *testing.T) {
+	checker := setup(t, "stale", "token")
+	m := NewMonitor()
+
+	status, err := m.CheckHost(context.Background(), testHost)
+	require.NoError(t, err)
+	assert.Equal(t, 1, checker.refreshed)
+	assert.Equal(t, StateValid, status.State)
+	assert.True(t, status.CanRefresh)
+	assert.False(t, status.NeedRefresh)
+	assert.NotNil(t, status.LastRefresh)
+
+	cfg := configs.GetCurrentConfig()
+	assert.Equal(t, "valid", cfg.Cookies[testHost])
+	assert.Equal(t, "token_new", cfg.CookieMonitor.RefreshTokens[testHost])
+	assert.Empty(t, capturedEvents())
+}
+
+func TestMonitorNoRefreshWithoutToken(t *testing.T) {
+	checker := setup(t, "stale", "")
+	m := NewMonitor()
+
+	status, err := m.CheckHost(context.Background(), testHost)
+	require.NoError(t, err)
+	assert.Equal(t, 0, checker.refreshed)
+	assert.Equal(t, StateValid, status.State)
+	assert.True(t, status.NeedRefresh)
+	assert.False(t, status.CanRefresh)
+}
+
+func TestMonitorAccountAutoRefresh(t *testing.T) {
+	checker := setup(t, "valid", "")
+	cfg := configs.GetCurrentConfig()
+	cfg.Accounts = map[string]configs.Account{
+		"main":     {Host: testHost, Cookie: "stale", RefreshToken: "token"},
+		"backup":   {Host: testHost, Cookie: "dead"},
+		"disabled": {Host: testHost, Cookie: "dead", Disabled: true},
+	}
+	configs.SetCurrentConfig(cfg)
+	m := NewMonitor()
+
+	m.CheckAll(context.Background())
+	assert.Equal(t, 1, checker.refreshed)
+
+	// 命名账号使用自己的刷新令牌刷新，结果写回账号
+	cfg = configs.GetCurrentConfig()
+	assert.Equal(t, "valid", cfg.Accounts["main"].Cookie)
+	assert.Equal(t, "token_new", cfg.Accounts["main"].RefreshToken)
+	main := m.GetAccountStatus("main")
+	require.NotNil(t, main)
+	assert.Equal(t, StateValid, main.State)
+	assert.NotNil(t, main.LastRefresh)
+
+	// 失效的账号发送通知，停用的账号不检查
+	assert.Equal(t, StateExpired, m.GetAccountStatus("backup").State)
+	assert.Nil(t, m.GetAccountStatus("disabled"))
+	assert.Equal(t, []string{notify.EventCookieExpired}, capturedEvents())
+
+	m.ResetAccount("backup")
+	assert.Nil(t, m.GetAccountStatus("backup"))
+}
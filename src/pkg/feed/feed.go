@@ -0,0 +1,272 @@
+// Package feed 生成录制文件的播客 RSS 和 Atom 订阅源
+package feed
+
+import (
+	"encoding/xml"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mimeTypes 录制文件扩展名对应的 MIME 类型，排在前面的格式在同名文件中优先
+var mimeTypes = []struct {
+	ext  string
+	mime string
+}{
+	{".m4a", "audio/mp4"},
+	{".mp3", "audio/mpeg"},
+	{".aac", "audio/aac"},
+	{".mp4", "video/mp4"},
+	{".mkv", "video/x-matroska"},
+	{".mov", "video/quicktime"},
+	{".flv", "video/x-flv"},
+	{".ts", "video/mp2t"},
+}
+
+// MimeType 返回录制文件的 MIME 类型，不是录制文件时返回空
+func MimeType(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, m := range mimeTypes {
+		if m.ext == ext {
+			return m.mime
+		}
+	}
+	return ""
+}
+
+// Recording 一个录制文件
+type Recording struct {
+	Path     string // 文件路径
+	RelPath  string // 相对于输出根目录的路径，使用 / 分隔，不在输出根目录下时为空
+	Title    string // 不含扩展名的文件名
+	MimeType string
+	Size     int64
+	ModTime  time.Time
+}
+
+// IsAudio 是否为音频文件
+func (r Recording) IsAudio() bool {
+	return strings.HasPrefix(r.MimeType, "audio/")
+}
+
+// Lookup 返回录制文件当前可用的格式
+// 后处理可能把录制文件转换为其他格式并删除原文件，这里按格式优先级查找同名的录制文件，都不存在时返回 false
+func Lookup(path string) (Recording, bool) {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	candidates := make([]string, 0, len(mimeTypes)+1)
+	for _, m := range mimeTypes {
+		candidates = append(candidates, base+m.ext)
+	}
+	// 原文件扩展名大小写不同或不在已知格式中时，最后尝试原路径
+	candidates = append(candidates, path)
+	for _, candidate := range candidates {
+		mime := MimeType(candidate)
+		if mime == "" {
+			continue
+		}
+		info, err := os.Stat(candidate)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		return Recording{
+			Path:     candidate,
+			Title:    filepath.Base(base),
+			MimeType: mime,
+			Size:     info.Size(),
+			ModTime:  info.ModTime(),
+		}, true
+	}
+	return Recording{}, false
+}
+
+// FileURL 返回录制文件通过 /files/ 访问的地址
+func FileURL(baseURL, relPath string) string {
+	segments := strings.Split(relPath, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/files/" + strings.Join(segments, "/")
+}
+
+// Podcast 一个直播间的播客频道
+type Podcast struct {
+	Title       string
+	Link        string // 直播间地址
+	Description string
+	Author      string
+	ImageURL    string
+	Episodes    []Episode
+}
+
+// Episode 播客的一期节目
+type Episode struct {
+	Title     string
+	GUID      string
+	URL       string
+	MimeType  string
+	Size      int64
+	Published time.Time
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Itunes  string     `xml:"xmlns:itunes,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title          string       `xml:"title"`
+	Link           string       `xml:"link"`
+	Description    string       `xml:"description"`
+	LastBuildDate  string       `xml:"lastBuildDate"`
+	ItunesAuthor   string       `xml:"itunes:author,omitempty"`
+	ItunesImage    *itunesImage `xml:"itunes:image,omitempty"`
+	ItunesExplicit string       `xml:"itunes:explicit"`
+	Items          []rssItem    `xml:"item"`
+}
+
+type itunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type rssItem struct {
+	Title     string       `xml:"title"`
+	GUID      rssGUID      `xml:"guid"`
+	PubDate   string       `xml:"pubDate"`
+	Enclosure rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// WriteRSS 输出 RSS 2.0 播客订阅源（带 iTunes 扩展，兼容常见播客客户端）
+func WriteRSS(w io.Writer, p Podcast) error {
+	ch := rssChannel{
+		Title:          p.Title,
+		Link:           p.Link,
+		Description:    p.Description,
+		LastBuildDate:  time.Now().Format(time.RFC1123Z),
+		ItunesAuthor:   p.Author,
+		ItunesExplicit: "false",
+	}
+	if p.ImageURL != "" {
+		ch.ItunesImage = &itunesImage{Href: p.ImageURL}
+	}
+	for _, e := range p.Episodes {
+		ch.Items = append(ch.Items, rssItem{
+			Title:     e.Title,
+			GUID:      rssGUID{Value: e.GUID},
+			PubDate:   e.Published.Format(time.RFC1123Z),
+			Enclosure: rssEnclosure{URL: e.URL, Length: e.Size, Type: e.MimeType},
+		})
+	}
+	return writeXML(w, rss{Version: "2.0", Itunes: "http://www.itunes.com/dtds/podcast-1.0.dtd", Channel: ch})
+}
+
+// Atom 订阅源
+type Atom struct {
+	Title   string
+	ID      string
+	Link    string // 订阅源自身地址
+	Entries []AtomEntry
+}
+
+// AtomEntry 订阅源中的一条录制
+type AtomEntry struct {
+	Title    string
+	ID       string
+	Author   string
+	Summary  string
+	URL      string
+	MimeType string
+	Size     int64
+	Updated  time.Time
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length string `xml:"length,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  *atomAuthor `xml:"author,omitempty"`
+	Summary string      `xml:"summary,omitempty"`
+	Links   []atomLink  `xml:"link"`
+}
+
+// WriteAtom 输出 Atom 订阅源，updated 为最新一条录制的时间
+func WriteAtom(w io.Writer, a Atom) error {
+	updated := time.Unix(0, 0)
+	feed := atomFeed{
+		Xmlns: "http://www.w3.org/2005/Atom",
+		Title: a.Title,
+		ID:    a.ID,
+		Links: []atomLink{{Rel: "self", Href: a.Link}},
+	}
+	for _, e := range a.Entries {
+		if e.Updated.After(updated) {
+			updated = e.Updated
+		}
+		entry := atomEntry{
+			Title:   e.Title,
+			ID:      e.ID,
+			Updated: e.Updated.UTC().Format(time.RFC3339),
+			Summary: e.Summary,
+			Links: []atomLink{
+				{Rel: "alternate", Href: e.URL},
+				{Rel: "enclosure", Href: e.URL, Type: e.MimeType, Length: strconv.FormatInt(e.Size, 10)},
+			},
+		}
+		if e.Author != "" {
+			entry.Author = &atomAuthor{Name: e.Author}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+	return writeXML(w, feed)
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,128 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path string, size int, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestLookup(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "猫耳FM", "主播")
+	now := time.Now().Truncate(time.Second)
+	writeFile(t, filepath.Join(dir, "[2024-01-01][主播][节目].aac"), 10, now.Add(-2*time.Hour))
+	writeFile(t, filepath.Join(dir, "[2024-01-01][主播][节目].m4a"), 8, now.Add(-time.Hour))
+	writeFile(t, filepath.Join(dir, "[2024-01-02][主播][节目 2].mp4"), 20, now)
+
+	// 转换后的音频容器格式优先于原始 aac
+	rec, ok := Lookup(filepath.Join(dir, "[2024-01-01][主播][节目].aac"))
+	require.True(t, ok)
+	assert.Equal(t, "[2024-01-01][主播][节目]", rec.Title)
+	assert.Equal(t, "audio/mp4", rec.MimeType)
+	assert.True(t, rec.IsAudio())
+	assert.Equal(t, int64(8), rec.Size)
+
+	// 原始 flv 已被转换为 mp4 并删除
+	rec, ok = Lookup(filepath.Join(dir, "[2024-01-02][主播][节目 2].flv"))
+	require.True(t, ok)
+	assert.Equal(t, "video/mp4", rec.MimeType)
+	assert.Equal(t, now, rec.ModTime)
+
+	_, ok = Lookup(filepath.Join(dir, "不存在.flv"))
+	assert.False(t, ok)
+}
+
+func TestFileURL(t *testing.T) {
+	assert.Equal(t, "http://host:8080/files/%E7%8C%AB%E8%80%B3FM/a%20b/%5Bx%5D%23.m4a",
+		FileURL("http://host:8080/", "猫耳FM/a b/[x]#.m4a"))
+}
+
+func TestWriteRSS(t *testing.T) {
+	published := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	require.NoError(t, WriteRSS(&buf, Podcast{
+		Title:    "主播 & 朋友",
+		Link:     "https://fm.missevan.com/live/1",
+		Author:   "主播",
+		ImageURL: "https://example.com/cover.jpg",
+		Episodes: []Episode{{
+			Title: "节目", GUID: "a/b.m4a", URL: "http://h/files/a/b.m4a",
+			MimeType: "audio/mp4", Size: 100, Published: published,
+		}},
+	}))
+
+	var doc struct {
+		Channel struct {
+			Title string `xml:"title"`
+			Image struct {
+				Href string `xml:"href,attr"`
+			} `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+			Items []struct {
+				GUID      string `xml:"guid"`
+				PubDate   string `xml:"pubDate"`
+				Enclosure struct {
+					URL    string `xml:"url,attr"`
+					Length int64  `xml:"length,attr"`
+					Type   string `xml:"type,attr"`
+				} `xml:"enclosure"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "主播 & 朋友", doc.Channel.Title)
+	assert.Equal(t, "https://example.com/cover.jpg", doc.Channel.Image.Href)
+	require.Len(t, doc.Channel.Items, 1)
+	item := doc.Channel.Items[0]
+	assert.Equal(t, "a/b.m4a", item.GUID)
+	assert.Equal(t, "Mon, 01 Jan 2024 20:00:00 +0000", item.PubDate)
+	assert.Equal(t, "http://h/files/a/b.m4a", item.Enclosure.URL)
+	assert.Equal(t, int64(100), item.Enclosure.Length)
+	assert.Equal(t, "audio/mp4", item.Enclosure.Type)
+}
+
+func TestWriteAtom(t *testing.T) {
+	newest := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	require.NoError(t, WriteAtom(&buf, Atom{
+		Title: "录制",
+		ID:    "urn:test",
+		Link:  "http://h/api/feed/recordings.atom",
+		Entries: []AtomEntry{
+			{Title: "b", ID: "b", Author: "主播", URL: "http://h/files/b.flv", MimeType: "video/x-flv", Size: 2, Updated: newest},
+			{Title: "a", ID: "a", URL: "http://h/files/a.m4a", MimeType: "audio/mp4", Size: 1, Updated: newest.Add(-time.Hour)},
+		},
+	}))
+
+	var doc struct {
+		Updated string `xml:"updated"`
+		Entries []struct {
+			Title  string `xml:"title"`
+			Author *struct {
+				Name string `xml:"name"`
+			} `xml:"author"`
+			Links []struct {
+				Rel    string `xml:"rel,attr"`
+				Length string `xml:"length,attr"`
+			} `xml:"link"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "2024-01-02T00:00:00Z", doc.Updated)
+	require.Len(t, doc.Entries, 2)
+	assert.Equal(t, "主播", doc.Entries[0].Author.Name)
+	assert.Nil(t, doc.Entries[1].Author)
+	assert.Equal(t, "enclosure", doc.Entries[0].Links[1].Rel)
+	assert.Equal(t, "2", doc.Entries[0].Links[1].Length)
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/bililive-go/bililive-go/src/pkg/bandwidth"
 	bilisentry "github.com/bililive-go/bililive-go/src/pkg/sentry"
 	"github.com/bililive-go/bililive-go/src/pkg/utils"
 	"github.com/sirupsen/logrus"
@@ -134,14 +135,18 @@ func (c *Collector) collect() {
 	recordStats := c.collectRecordStats(timestamp, elapsed)
 	stats = append(stats, recordStats...)
 
-	// 3. 保存统计数据
+	// 3. 收集各带宽使用方的实时带宽（来自带宽管理器）
+	bandwidthStats := c.collectBandwidthStats(timestamp)
+	stats = append(stats, bandwidthStats...)
+
+	// 4. 保存统计数据
 	if len(stats) > 0 {
 		if err := c.store.SaveIOStats(ctx, stats); err != nil {
 			logrus.WithError(err).Error("保存 IO 统计数据失败")
 		}
 	}
 
-	// 4. 收集并保存系统磁盘 I/O 数据
+	// 5. 收集并保存系统磁盘 I/O 数据
 	if c.diskIOCollector != nil {
 		diskIOStats, err := c.diskIOCollector.Collect()
 		if err != nil {
@@ -252,3 +257,44 @@ func (c *Collector) collectRecordStats(timestamp int64, elapsed float64) []*IOSt
 
 	return stats
 }
+
+// collectBandwidthStats 收集带宽管理器中各使用方的实时带宽
+func (c *Collector) collectBandwidthStats(timestamp int64) []*IOStat {
+	snapshot := bandwidth.GetManager().Snapshot()
+	if len(snapshot.Consumers) == 0 {
+		return nil
+	}
+
+	var stats []*IOStat
+	totalSpeed := make(map[StatType]int64)
+	for _, consumer := range snapshot.Consumers {
+		statType := StatTypeBandwidthDownload
+		if consumer.Kind == bandwidth.KindUpload {
+			statType = StatTypeBandwidthUpload
+		}
+		totalSpeed[statType] += consumer.Speed
+
+		// 记录单个使用方的统计，不属于直播间的使用方只计入全局
+		if consumer.LiveID != "" {
+			stats = append(stats, &IOStat{
+				Timestamp:  timestamp,
+				StatType:   statType,
+				LiveID:     consumer.LiveID,
+				Speed:      consumer.Speed,
+				TotalBytes: consumer.TotalBytes,
+			})
+		}
+	}
+
+	// 记录全局带宽
+	for statType, speed := range totalSpeed {
+		stats = append(stats, &IOStat{
+			Timestamp: timestamp,
+			StatType:  statType,
+			LiveID:    "", // 全局
+			Speed:     speed,
+		})
+	}
+
+	return stats
+}
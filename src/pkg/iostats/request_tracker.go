@@ -57,6 +57,16 @@ func (t *RequestTracker) record(liveID, platform string, success bool, errMsg st
 	}
 }
 
+// RecordCooldown 记录平台风控冷却期，冷却升级或结束时以相同开始时间再次调用会更新记录
+func (t *RequestTracker) RecordCooldown(cooldown *PlatformCooldown) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := t.store.SaveCooldown(ctx, cooldown); err != nil {
+		logrus.WithError(err).WithField("platform", cooldown.Platform).Error("保存平台冷却记录失败")
+	}
+}
+
 // 全局请求追踪器实例
 var (
 	globalTracker   *RequestTracker
@@ -90,3 +100,10 @@ func TrackRequestFailure(liveID, platform string, errMsg string) {
 		tracker.RecordFailure(liveID, platform, errMsg)
 	}
 }
+
+// TrackCooldown 便捷方法：记录平台风控冷却期
+func TrackCooldown(cooldown *PlatformCooldown) {
+	if tracker := GetGlobalTracker(); tracker != nil {
+		tracker.RecordCooldown(cooldown)
+	}
+}
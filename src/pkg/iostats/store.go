@@ -35,6 +35,11 @@ type Store interface {
 	// QueryRequestStatusSegments 查询请求状态时间段（用于横条图）
 	QueryRequestStatusSegments(ctx context.Context, query RequestStatusQuery) (*RequestStatusResponse, error)
 
+	// SaveCooldown 保存平台风控冷却期，同一平台同一开始时间的记录会被更新
+	SaveCooldown(ctx context.Context, cooldown *PlatformCooldown) error
+	// QueryCooldowns 查询与时间范围重叠的平台风控冷却期，platform 为空时查询所有平台
+	QueryCooldowns(ctx context.Context, startTime, endTime int64, platform string) ([]PlatformCooldown, error)
+
 	// SaveDiskIOStats 保存磁盘 I/O 统计数据
 	SaveDiskIOStats(ctx context.Context, stats []*DiskIOStat) error
 	// QueryDiskIOStats 查询磁盘 I/O 统计数据
@@ -136,6 +141,22 @@ func (s *SQLiteStore) initTables() error {
 		return fmt.Errorf("failed to create request_status table: %w", err)
 	}
 
+	// 平台风控冷却期表
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS platform_cooldowns (
+			platform TEXT NOT NULL,
+			start_time INTEGER NOT NULL,
+			end_time INTEGER NOT NULL,
+			max_level INTEGER NOT NULL DEFAULT 0,
+			reason TEXT,
+			PRIMARY KEY (platform, start_time)
+		);
+		CREATE INDEX IF NOT EXISTS idx_platform_cooldowns_end ON platform_cooldowns(end_time);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create platform_cooldowns table: %w", err)
+	}
+
 	// 磁盘 I/O 统计表
 	_, err = s.db.Exec(`
 		CREATE TABLE IF NOT EXISTS disk_io_stats (
@@ -420,6 +441,55 @@ func (s *SQLiteStore) QueryRequestStatus(ctx context.Context, query RequestStatu
 	return statuses, rows.Err()
 }
 
+// SaveCooldown 保存平台风控冷却期
+func (s *SQLiteStore) SaveCooldown(ctx context.Context, cooldown *PlatformCooldown) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO platform_cooldowns (platform, start_time, end_time, max_level, reason)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(platform, start_time) DO UPDATE SET
+		 end_time = excluded.end_time, max_level = excluded.max_level, reason = excluded.reason`,
+		cooldown.Platform, cooldown.StartTime, cooldown.EndTime, cooldown.MaxLevel, cooldown.Reason,
+	)
+	return err
+}
+
+// QueryCooldowns 查询平台风控冷却期
+func (s *SQLiteStore) QueryCooldowns(ctx context.Context, startTime, endTime int64, platform string) ([]PlatformCooldown, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sqlQuery := `SELECT platform, start_time, end_time, max_level, reason
+				 FROM platform_cooldowns WHERE start_time <= ? AND end_time >= ?`
+	args := []interface{}{endTime, startTime}
+	if platform != "" {
+		sqlQuery += " AND platform = ?"
+		args = append(args, platform)
+	}
+	sqlQuery += " ORDER BY start_time ASC"
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cooldowns []PlatformCooldown
+	for rows.Next() {
+		var c PlatformCooldown
+		var reason sql.NullString
+		if err := rows.Scan(&c.Platform, &c.StartTime, &c.EndTime, &c.MaxLevel, &reason); err != nil {
+			return nil, err
+		}
+		c.Reason = reason.String
+		cooldowns = append(cooldowns, c)
+	}
+
+	return cooldowns, rows.Err()
+}
+
 // QueryRequestStatusSegments 查询请求状态时间段
 func (s *SQLiteStore) QueryRequestStatusSegments(ctx context.Context, query RequestStatusQuery) (*RequestStatusResponse, error) {
 	statuses, err := s.QueryRequestStatus(ctx, query)
@@ -432,6 +502,17 @@ func (s *SQLiteStore) QueryRequestStatusSegments(ctx context.Context, query Requ
 		GroupedSegments: make(map[string][]RequestStatusSegment),
 	}
 
+	// 平台风控冷却期（按直播间查看时不区分平台）
+	platform := ""
+	if query.ViewMode == ViewModeByPlatform {
+		platform = query.Platform
+	}
+	cooldowns, err := s.QueryCooldowns(ctx, query.StartTime, query.EndTime, platform)
+	if err != nil {
+		return nil, err
+	}
+	response.Cooldowns = cooldowns
+
 	if len(statuses) == 0 {
 		return response, nil
 	}
@@ -572,6 +653,12 @@ func (s *SQLiteStore) Cleanup(ctx context.Context, retentionDays int) error {
 		return fmt.Errorf("failed to cleanup request_status: %w", err)
 	}
 
+	// 清理平台风控冷却期
+	_, err = s.db.ExecContext(ctx, `DELETE FROM platform_cooldowns WHERE end_time < ?`, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup platform_cooldowns: %w", err)
+	}
+
 	// 清理磁盘 I/O 统计数据
 	_, err = s.db.ExecContext(ctx, `DELETE FROM disk_io_stats WHERE timestamp < ?`, cutoff)
 	if err != nil {
@@ -18,6 +18,10 @@ const (
 	StatTypeDiskConvertWrite StatType = "disk_convert_write"
 	// StatTypeDiskSystemIO 系统级磁盘 I/O 统计
 	StatTypeDiskSystemIO StatType = "disk_system_io"
+	// StatTypeBandwidthDownload 带宽管理器统计的下载带宽
+	StatTypeBandwidthDownload StatType = "bandwidth_download"
+	// StatTypeBandwidthUpload 带宽管理器统计的上传带宽
+	StatTypeBandwidthUpload StatType = "bandwidth_upload"
 )
 
 // IOStat IO 统计数据点
@@ -98,6 +102,17 @@ type RequestStatusResponse struct {
 	// GroupedSegments 分组的状态段（用于 by_live 和 by_platform 模式）
 	// key 为 live_id 或 platform
 	GroupedSegments map[string][]RequestStatusSegment `json:"grouped_segments,omitempty"`
+	// Cooldowns 查询时间范围内的平台风控冷却期
+	Cooldowns []PlatformCooldown `json:"cooldowns,omitempty"`
+}
+
+// PlatformCooldown 平台风控冷却期记录
+type PlatformCooldown struct {
+	Platform  string `json:"platform"`         // 平台名称
+	StartTime int64  `json:"start_time"`       // 冷却开始时间 Unix 毫秒
+	EndTime   int64  `json:"end_time"`         // 冷却结束时间 Unix 毫秒，冷却中时为预计结束时间
+	MaxLevel  int    `json:"max_level"`        // 冷却期间达到的最高等级
+	Reason    string `json:"reason,omitempty"` // 最近一次风控原因
 }
 
 // DiskIOStat 系统级磁盘 I/O 统计
@@ -0,0 +1,122 @@
+// Package livepattern 根据历史开播时间学习主播一天内的开播规律，用于预测下次开播时间和调整检测间隔
+package livepattern
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// binWidth 按一天内的时刻统计开播次数的粒度
+	binWidth   = 15 * time.Minute
+	binsPerDay = int(24 * time.Hour / binWidth)
+	// halfLife 历史开播记录的权重半衰期，越近的记录对规律的影响越大
+	halfLife = 30 * 24 * time.Hour
+	// activeRatio 某时刻前后窗口内的开播权重占比达到该值时视为常开播时段
+	activeRatio = 0.2
+	// slowdownFactor 距离预测开播时间越远检测越慢，检测间隔为剩余时间除以该值
+	slowdownFactor = 4
+)
+
+// Model 主播一天内各时刻的开播规律
+type Model struct {
+	bins     [binsPerDay]float64
+	total    float64
+	sessions int
+	loc      *time.Location
+}
+
+// Build 根据开播时间构建规律模型，按 now 所在时区的时刻统计，越早的记录权重越低
+func Build(starts []time.Time, now time.Time) *Model {
+	m := &Model{loc: now.Location()}
+	for _, start := range starts {
+		if start.IsZero() || start.After(now) {
+			continue
+		}
+		weight := math.Pow(0.5, float64(now.Sub(start))/float64(halfLife))
+		m.bins[m.binOf(start)] += weight
+		m.total += weight
+		m.sessions++
+	}
+	return m
+}
+
+// Sessions 参与统计的开播场次
+func (m *Model) Sessions() int {
+	return m.sessions
+}
+
+// binOf 返回时间所在的时刻分组
+func (m *Model) binOf(t time.Time) int {
+	t = t.In(m.loc)
+	return (t.Hour()*60 + t.Minute()) / int(binWidth/time.Minute)
+}
+
+// windowRatio 返回 bin 前后 window 范围内的开播权重占比
+func (m *Model) windowRatio(bin int, window time.Duration) float64 {
+	if m.total == 0 {
+		return 0
+	}
+	span := int(window / binWidth)
+	sum := 0.0
+	for i := -span; i <= span; i++ {
+		sum += m.bins[(bin+i+binsPerDay)%binsPerDay]
+	}
+	return sum / m.total
+}
+
+// isPeak bin 是否为前后 window 范围内开播权重最高的时刻，相同时取最早的
+func (m *Model) isPeak(bin int, window time.Duration) bool {
+	if m.bins[bin] == 0 {
+		return false
+	}
+	span := int(window / binWidth)
+	for i := -span; i <= span; i++ {
+		other := m.bins[(bin+i+binsPerDay)%binsPerDay]
+		if other > m.bins[bin] || (i < 0 && other == m.bins[bin]) {
+			return false
+		}
+	}
+	return true
+}
+
+// NextStart 预测 now 之后的下一次开播时间
+// 正处于常开播时段时返回该时段的预测开播时间（可能略早于 now）；没有明显规律时返回 false
+func (m *Model) NextStart(now time.Time, window time.Duration) (time.Time, bool) {
+	now = now.In(m.loc)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, m.loc)
+	from := now.Add(-window)
+	first := int(from.Sub(day) / binWidth)
+	if from.Before(day) {
+		first--
+	}
+	for i := first; i <= first+binsPerDay; i++ {
+		bin := ((i % binsPerDay) + binsPerDay) % binsPerDay
+		if m.isPeak(bin, window) && m.windowRatio(bin, window) >= activeRatio {
+			return day.Add(time.Duration(i) * binWidth), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Interval 根据规律计算 now 时的检测间隔
+// 预测开播时间前后 window 范围内使用 min，其他时间按距离预测开播时间的远近在 min 和 max 之间放慢；
+// 没有明显规律时返回 base 和 false
+func (m *Model) Interval(now time.Time, base, min, max, window time.Duration) (time.Duration, time.Time, bool) {
+	next, ok := m.NextStart(now, window)
+	if !ok {
+		return base, time.Time{}, false
+	}
+	until := next.Sub(now) - window
+	if until <= 0 {
+		return min, next, true
+	}
+	interval := until / slowdownFactor
+	if interval < min {
+		interval = min
+	}
+	if interval > max {
+		interval = max
+	}
+	return interval, next, true
+}
@@ -0,0 +1,104 @@
+package livepattern
+
+import (
+	"testing"
+	"time"
+)
+
+// eveningStarts 最近 days 天每天 20:00 前后开播
+func eveningStarts(now time.Time, days int) []time.Time {
+	var starts []time.Time
+	for d := 1; d <= days; d++ {
+		day := time.Date(now.Year(), now.Month(), now.Day()-d, 20, 0, 0, 0, now.Location())
+		starts = append(starts, day.Add(time.Duration(d%3-1)*5*time.Minute))
+	}
+	return starts
+}
+
+func TestNextStart(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	m := Build(eveningStarts(now, 10), now)
+	if m.Sessions() != 10 {
+		t.Fatalf("sessions = %d, want 10", m.Sessions())
+	}
+	next, ok := m.NextStart(now, 30*time.Minute)
+	if !ok {
+		t.Fatal("expected predicted start")
+	}
+	want := time.Date(2024, 5, 10, 20, 0, 0, 0, time.UTC)
+	if next.Sub(want).Abs() > 15*time.Minute {
+		t.Errorf("next = %v, want around %v", next, want)
+	}
+
+	// 今天的开播时段过去后预测明天
+	late := time.Date(2024, 5, 10, 22, 0, 0, 0, time.UTC)
+	next, _ = m.NextStart(late, 30*time.Minute)
+	if next.Sub(want.AddDate(0, 0, 1)).Abs() > 15*time.Minute {
+		t.Errorf("next = %v, want around tomorrow 20:00", next)
+	}
+}
+
+func TestInterval(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	m := Build(eveningStarts(now, 10), now)
+	base, min, max, window := 30*time.Second, 10*time.Second, 600*time.Second, 30*time.Minute
+
+	at := func(h, mm int) time.Time { return time.Date(2024, 5, 10, h, mm, 0, 0, time.UTC) }
+	cases := []struct {
+		now  time.Time
+		want time.Duration
+	}{
+		{at(12, 0), max},  // 距离开播很久
+		{at(19, 50), min}, // 开播时段内
+		{at(20, 20), min}, // 开播时段内，已过预测时间
+		{at(19, 10), 0},   // 距离时段开始 20 分钟，按剩余时间放慢
+	}
+	for _, c := range cases {
+		got, _, ok := m.Interval(c.now, base, min, max, window)
+		if !ok {
+			t.Fatalf("%v: expected adaptive interval", c.now)
+		}
+		if c.want == 0 {
+			if got <= min || got >= max {
+				t.Errorf("%v: interval = %v, want between %v and %v", c.now, got, min, max)
+			}
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%v: interval = %v, want %v", c.now, got, c.want)
+		}
+	}
+}
+
+func TestIntervalWithoutPattern(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	// 每次开播时间相差 3 小时，没有集中的时段
+	var starts []time.Time
+	for d := 1; d <= 8; d++ {
+		starts = append(starts, time.Date(2024, 5, 10-d, 3*d%24, 0, 0, 0, time.UTC))
+	}
+	m := Build(starts, now)
+	if got, _, ok := m.Interval(now, 30*time.Second, 10*time.Second, 300*time.Second, 30*time.Minute); ok || got != 30*time.Second {
+		t.Errorf("interval = %v, %v; want base interval", got, ok)
+	}
+	if got, _, ok := Build(nil, now).Interval(now, 30*time.Second, 10*time.Second, 300*time.Second, 30*time.Minute); ok || got != 30*time.Second {
+		t.Errorf("empty model interval = %v, %v; want base interval", got, ok)
+	}
+}
+
+func TestDecay(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	// 早期每天 8:00 开播，最近改为 20:00 开播
+	var starts []time.Time
+	for d := 1; d <= 5; d++ {
+		starts = append(starts, time.Date(2024, 5, 10-d, 20, 0, 0, 0, time.UTC))
+	}
+	for d := 100; d <= 110; d++ {
+		starts = append(starts, time.Date(2024, 5, 10, 8, 0, 0, 0, time.UTC).AddDate(0, 0, -d))
+	}
+	m := Build(starts, now)
+	next, ok := m.NextStart(time.Date(2024, 5, 10, 6, 0, 0, 0, time.UTC), 30*time.Minute)
+	if !ok || next.Hour() != 20 {
+		t.Errorf("next = %v, want 20:00", next)
+	}
+}
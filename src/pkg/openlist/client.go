@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+
+	"github.com/bililive-go/bililive-go/src/pkg/bandwidth"
 )
 
 // Client OpenList API 客户端
@@ -50,11 +52,13 @@ func (c *Client) Upload(ctx context.Context, localPath, remotePath string, onPro
 
 	totalSize := fileInfo.Size()
 
-	// 创建进度追踪 Reader
+	// 创建进度追踪 Reader，上传数据计入带宽限制
 	progressReader := NewProgressReader(file, totalSize, onProgress)
+	body := bandwidth.GetManager().NewReader(ctx, bandwidth.Consumer{Kind: bandwidth.KindUpload}, progressReader)
+	defer body.Close()
 
 	// 构建请求
-	req, err := http.NewRequestWithContext(ctx, "PUT", c.baseURL+"/api/fs/put", progressReader)
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.baseURL+"/api/fs/put", body)
 	if err != nil {
 		return fmt.Errorf("创建请求失败: %w", err)
 	}
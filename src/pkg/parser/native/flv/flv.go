@@ -15,9 +15,13 @@ import (
 	"github.com/bililive-go/bililive-go/src/configs"
 	"github.com/bililive-go/bililive-go/src/instance"
 	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/pkg/bandwidth"
 	"github.com/bililive-go/bililive-go/src/pkg/livelogger"
 	"github.com/bililive-go/bililive-go/src/pkg/parser"
+	"github.com/bililive-go/bililive-go/src/pkg/proxy"
 	"github.com/bililive-go/bililive-go/src/pkg/reader"
+	"github.com/bililive-go/bililive-go/src/pkg/rtmp"
+	"github.com/bililive-go/bililive-go/src/pkg/streamprobe"
 	"github.com/bililive-go/bililive-go/src/pkg/utils"
 )
 
@@ -47,12 +51,13 @@ type builder struct{}
 func (b *builder) Build(cfg map[string]string, logger *livelogger.LiveLogger) (parser.Parser, error) {
 	audioOnly := cfg["audio_only"] == "true"
 	return &Parser{
-		Metadata:  Metadata{},
-		hc:        &http.Client{},
-		stopCh:    make(chan struct{}),
-		closeOnce: new(sync.Once),
-		audioOnly: audioOnly,
-		logger:    logger,
+		Metadata:         Metadata{},
+		hc:               &http.Client{},
+		stopCh:           make(chan struct{}),
+		closeOnce:        new(sync.Once),
+		audioOnly:        audioOnly,
+		bandwidthLimited: cfg["bandwidth_limited"] == "true",
+		logger:           logger,
 	}, nil
 }
 
@@ -73,6 +78,9 @@ type Parser struct {
 	closeOnce *sync.Once
 	audioOnly bool
 	logger    *livelogger.LiveLogger
+
+	// bandwidthLimited 输入已经在流探测代理处限制带宽
+	bandwidthLimited bool
 }
 
 func (p *Parser) ParseLiveStream(ctx context.Context, streamUrlInfo *live.StreamUrlInfo, live live.Live, file string) error {
@@ -84,23 +92,19 @@ func (p *Parser) ParseLiveStream(ctx context.Context, streamUrlInfo *live.Stream
 		}
 	}
 
-	url := streamUrlInfo.Url
 	// init input
-	req, err := http.NewRequest("GET", url.String(), nil)
+	input, err := p.openInput(ctx, streamUrlInfo)
 	if err != nil {
 		return err
 	}
-	req.Header.Add("User-Agent", "Chrome/59.0.3071.115")
-	// add headers for downloader from live
-	for k, v := range streamUrlInfo.HeadersForDownloader {
-		req.Header.Set(k, v)
+	if !p.bandwidthLimited {
+		input = bandwidth.GetManager().NewReader(ctx, bandwidth.Consumer{
+			Kind:   bandwidth.KindDownload,
+			LiveID: string(live.GetLiveId()),
+		}, input)
 	}
-	resp, err := p.hc.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	p.i = reader.New(resp.Body)
+	defer input.Close()
+	p.i = reader.New(input)
 	defer p.i.Free()
 
 	// init output
@@ -115,6 +119,46 @@ func (p *Parser) ParseLiveStream(ctx context.Context, streamUrlInfo *live.Stream
 	return p.doParse(ctx)
 }
 
+// openInput 打开输入流：rtmp 地址使用内置 RTMP 客户端转换为 FLV，其余地址通过 HTTP 拉取
+func (p *Parser) openInput(ctx context.Context, streamUrlInfo *live.StreamUrlInfo) (io.ReadCloser, error) {
+	url := streamUrlInfo.Url
+	if streamprobe.IsStreamRTMP(url) {
+		stream, err := rtmp.OpenFLV(ctx, url, rtmp.FLVOptions{
+			ClientOptions: rtmp.ClientOptions{Dialer: proxy.DownloadDialContext()},
+			OnReconnect: func(attempt int, err error) {
+				p.logger.Warnf("RTMP 连接中断，正在进行第 %d 次重连: %v", attempt, err)
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		// Stop 时关闭连接，使阻塞中的读取立即返回
+		go func() {
+			select {
+			case <-p.stopCh:
+				stream.Close()
+			case <-stream.Done():
+			}
+		}()
+		return stream, nil
+	}
+
+	req, err := http.NewRequest("GET", url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("User-Agent", "Chrome/59.0.3071.115")
+	// add headers for downloader from live
+	for k, v := range streamUrlInfo.HeadersForDownloader {
+		req.Header.Set(k, v)
+	}
+	resp, err := p.hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
 func (p *Parser) Stop() error {
 	p.closeOnce.Do(func() {
 		close(p.stopCh)
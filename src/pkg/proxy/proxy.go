@@ -123,6 +123,16 @@ func CreateSocks5DialContext(proxyURL string) func(ctx context.Context, network,
 	}
 }
 
+// DownloadDialContext 返回非 HTTP 协议（如 RTMP）下载使用的拨号函数
+// 只有 SOCKS5 代理可以转发任意 TCP 连接，未配置代理或使用 HTTP 代理时返回 nil，表示直接连接
+func DownloadDialContext() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	proxyURL := GetDownloadProxyURL()
+	if !isSocks5(proxyURL) {
+		return nil
+	}
+	return CreateSocks5DialContext(proxyURL)
+}
+
 // ApplyProxyToTransport 将通用代理设置应用到 http.Transport
 func ApplyProxyToTransport(transport *http.Transport) {
 	applyProxyURLToTransport(transport, GetProxyURL())
@@ -0,0 +1,432 @@
+// Package pushdetect 通过平台的推送通道（如直播间 websocket）实时获取开播状态变化
+// 同一平台的直播间按平台支持的数量共享连接，连接断开时自动重连，期间由调用方回退为轮询
+// 超过最大连接数的直播间没有推送连接，由调用方继续使用轮询检测开播
+package pushdetect
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	bilisentry "github.com/bililive-go/bililive-go/src/pkg/sentry"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultMaxConns 默认最大连接数
+	DefaultMaxConns = 200
+
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+	// stableDuration 连接保持超过该时长后断开时，重连等待时间从最小值重新计算
+	stableDuration = time.Minute
+	// defaultBatchDelay 新建连接或订阅变化后等待该时长再（重新）连接，期间的订阅变化合并为一次连接
+	defaultBatchDelay = 2 * time.Second
+	// defaultDialInterval 所有连接建立的最小间隔，避免大量直播间同时连接或断网恢复后同时重连
+	defaultDialInterval = 100 * time.Millisecond
+)
+
+// Platform 平台推送通道
+type Platform interface {
+	// Name 平台标识，同一平台的直播间共享连接
+	Name() string
+	// RoomsPerConn 单个连接可以订阅的直播间数量
+	RoomsPerConn() int
+	// Serve 建立连接并订阅 rooms，订阅成功后调用 ready，收到开播或下播消息时调用 emit
+	// 一直阻塞到 ctx 取消或连接断开
+	Serve(ctx context.Context, rooms []string, ready func(), emit func(room string, living bool)) error
+}
+
+// Handler 直播间的订阅回调
+type Handler struct {
+	// OnStatus 收到开播（true）或下播（false）消息，在每个直播间独立的 goroutine 中依次调用，
+	// 处理期间收到的多条消息只保留最新的状态
+	OnStatus func(living bool)
+	// OnConnected 推送通道连接状态变化，断开期间调用方应回退为轮询
+	OnConnected func(connected bool)
+}
+
+// Stats 推送连接状态
+type Stats struct {
+	Conns      int `json:"conns"`      // 连接数
+	Rooms      int `json:"rooms"`      // 订阅的直播间数
+	Connected  int `json:"connected"`  // 推送通道已连接的直播间数
+	Unassigned int `json:"unassigned"` // 超过最大连接数、暂时没有分配连接的直播间数
+}
+
+type roomKey struct {
+	platform string
+	room     string
+}
+
+type room struct {
+	key         roomKey
+	platform    Platform
+	handlers    map[int]Handler
+	nextHandler int
+	conn        *conn
+	connected   bool
+	// status 待分发的开播状态，只保留最新的一条，由 dispatch 在单独的 goroutine 中通知订阅者，
+	// 避免订阅者处理缓慢时阻塞连接的读取
+	status chan bool
+	done   chan struct{}
+}
+
+type conn struct {
+	platform   Platform
+	rooms      map[roomKey]*room
+	ctx        context.Context
+	cancel     context.CancelFunc
+	serveStop  context.CancelFunc // 取消当前的 Serve，用于订阅变化后重新连接
+	restarting bool
+	restart    *time.Timer // 订阅变化后延迟重新连接的定时器，期间的变化合并为一次重连
+}
+
+// Hub 管理所有直播间的推送订阅和连接
+type Hub struct {
+	mu       sync.Mutex
+	maxConns int
+	conns    map[*conn]struct{}
+	rooms    map[roomKey]*room
+
+	batchDelay   time.Duration
+	dialInterval time.Duration
+	nextDialAt   time.Time // 下一次允许建立连接的时间
+	unassigned   int       // 上一次记录日志时没有分配连接的直播间数
+}
+
+var globalHub = NewHub(DefaultMaxConns)
+
+// GetHub 获取全局推送订阅管理器
+func GetHub() *Hub {
+	return globalHub
+}
+
+// NewHub 创建推送订阅管理器，maxConns 为最大连接数
+func NewHub(maxConns int) *Hub {
+	return &Hub{
+		maxConns: maxConns,
+		conns:    make(map[*conn]struct{}),
+		rooms:    make(map[roomKey]*room),
+
+		batchDelay:   defaultBatchDelay,
+		dialInterval: defaultDialInterval,
+	}
+}
+
+// SetMaxConns 设置最大连接数，<= 0 时使用默认值；超出的直播间在有空闲连接时再分配
+func (h *Hub) SetMaxConns(n int) {
+	if n <= 0 {
+		n = DefaultMaxConns
+	}
+	h.mu.Lock()
+	h.maxConns = n
+	h.assignLocked()
+	h.mu.Unlock()
+}
+
+// Subscribe 订阅直播间的推送消息，同一直播间的多个订阅共享一个订阅
+func (h *Hub) Subscribe(p Platform, roomID string, handler Handler) (unsubscribe func()) {
+	key := roomKey{platform: p.Name(), room: roomID}
+	h.mu.Lock()
+	r, ok := h.rooms[key]
+	if !ok {
+		r = &room{
+			key:      key,
+			platform: p,
+			handlers: make(map[int]Handler),
+			status:   make(chan bool, 1),
+			done:     make(chan struct{}),
+		}
+		h.rooms[key] = r
+		bilisentry.Go(func() { h.dispatch(r) })
+	}
+	id := r.nextHandler
+	r.nextHandler++
+	r.handlers[id] = handler
+	connected := r.connected
+	if !ok {
+		h.assignLocked()
+	}
+	h.mu.Unlock()
+
+	if connected && handler.OnConnected != nil {
+		handler.OnConnected(true)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { h.unsubscribe(key, id) })
+	}
+}
+
+func (h *Hub) unsubscribe(key roomKey, id int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r, ok := h.rooms[key]
+	if !ok {
+		return
+	}
+	delete(r.handlers, id)
+	if len(r.handlers) > 0 {
+		return
+	}
+	delete(h.rooms, key)
+	close(r.done)
+	if c := r.conn; c != nil {
+		delete(c.rooms, key)
+		if len(c.rooms) == 0 {
+			c.cancel()
+			if c.restart != nil {
+				c.restart.Stop()
+			}
+			delete(h.conns, c)
+		} else {
+			h.restartLocked(c)
+		}
+	}
+	h.assignLocked()
+}
+
+// MaxConns 返回当前的最大连接数
+func (h *Hub) MaxConns() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.maxConns
+}
+
+// Stats 返回推送连接状态
+func (h *Hub) Stats() Stats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	stats := Stats{Conns: len(h.conns), Rooms: len(h.rooms)}
+	for _, r := range h.rooms {
+		if r.connected {
+			stats.Connected++
+		}
+		if r.conn == nil {
+			stats.Unassigned++
+		}
+	}
+	return stats
+}
+
+// assignLocked 为没有连接的直播间分配连接：优先加入同平台未满的连接，否则在连接数未达上限时新建连接
+func (h *Hub) assignLocked() {
+	unassigned := 0
+	for _, r := range h.rooms {
+		if r.conn != nil {
+			continue
+		}
+		var target *conn
+		for c := range h.conns {
+			if c.platform.Name() == r.key.platform && len(c.rooms) < roomsPerConn(c.platform) {
+				target = c
+				break
+			}
+		}
+		if target == nil {
+			if len(h.conns) >= h.maxConns {
+				unassigned++
+				continue
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			target = &conn{platform: r.platform, rooms: make(map[roomKey]*room), ctx: ctx, cancel: cancel}
+			h.conns[target] = struct{}{}
+			bilisentry.Go(func() { h.run(target) })
+		} else {
+			h.restartLocked(target)
+		}
+		target.rooms[r.key] = r
+		r.conn = target
+	}
+	if unassigned != h.unassigned {
+		if unassigned > 0 {
+			logrus.Warnf("推送连接数已达上限 %d，%d 个直播间使用轮询检测开播", h.maxConns, unassigned)
+		}
+		h.unassigned = unassigned
+	}
+}
+
+// restartLocked 连接订阅的直播间变化后延迟重新连接，延迟期间的变化合并为一次重连
+func (h *Hub) restartLocked(c *conn) {
+	if c.restart != nil {
+		return
+	}
+	c.restart = time.AfterFunc(jitter(h.batchDelay), func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		c.restart = nil
+		// 未在连接中时，下一次连接会使用最新的订阅
+		if c.serveStop != nil {
+			c.restarting = true
+			c.serveStop()
+		}
+	})
+}
+
+// reserveDialLocked 预约一次建立连接的时间，返回需要等待的时长
+func (h *Hub) reserveDialLocked() time.Duration {
+	now := time.Now()
+	at := h.nextDialAt
+	if at.Before(now) {
+		at = now
+	}
+	h.nextDialAt = at.Add(h.dialInterval)
+	return at.Sub(now)
+}
+
+// jitter 在 d 的基础上增加最多 50% 的随机时长，避免大量连接在同一时刻重连
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	return d + rand.N(d/2+1)
+}
+
+// sleep 等待 d 或 ctx 取消，ctx 取消时返回 false
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+func roomsPerConn(p Platform) int {
+	if n := p.RoomsPerConn(); n > 0 {
+		return n
+	}
+	return 1
+}
+
+// run 维持一个连接，断开后按指数退避重连，直到连接不再有订阅
+// 新建的连接先等待一段时间，让同时订阅的直播间合并到同一次连接
+func (h *Hub) run(c *conn) {
+	backoff := minBackoff
+	if !sleep(c.ctx, jitter(h.batchDelay)) {
+		return
+	}
+	for {
+		h.mu.Lock()
+		wait := h.reserveDialLocked()
+		h.mu.Unlock()
+		if !sleep(c.ctx, wait) {
+			return
+		}
+
+		h.mu.Lock()
+		if c.ctx.Err() != nil {
+			h.mu.Unlock()
+			return
+		}
+		rooms := make([]string, 0, len(c.rooms))
+		for key := range c.rooms {
+			rooms = append(rooms, key.room)
+		}
+		ctx, stop := context.WithCancel(c.ctx)
+		c.serveStop = stop
+		c.restarting = false
+		h.mu.Unlock()
+
+		start := time.Now()
+		err := c.platform.Serve(ctx, rooms, func() {
+			h.setConnected(c, rooms, true)
+		}, func(roomID string, living bool) {
+			h.emit(roomKey{platform: c.platform.Name(), room: roomID}, living)
+		})
+		stop()
+
+		h.mu.Lock()
+		restarting := c.restarting
+		c.serveStop = nil
+		h.mu.Unlock()
+		if c.ctx.Err() != nil {
+			h.setConnected(c, rooms, false)
+			return
+		}
+		if restarting {
+			// 订阅变化导致的重连不通知断开，避免调用方频繁切换轮询
+			continue
+		}
+		h.setConnected(c, rooms, false)
+
+		if time.Since(start) > stableDuration {
+			backoff = minBackoff
+		}
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"platform": c.platform.Name(),
+			"rooms":    len(rooms),
+		}).Debugf("推送连接断开，%v 后重连", backoff)
+		if !sleep(c.ctx, jitter(backoff)) {
+			return
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// setConnected 更新直播间的推送连接状态并通知订阅者
+func (h *Hub) setConnected(c *conn, rooms []string, connected bool) {
+	var notify []func(bool)
+	h.mu.Lock()
+	for _, roomID := range rooms {
+		r, ok := h.rooms[roomKey{platform: c.platform.Name(), room: roomID}]
+		if !ok || r.conn != c || r.connected == connected {
+			continue
+		}
+		r.connected = connected
+		for _, handler := range r.handlers {
+			if handler.OnConnected != nil {
+				notify = append(notify, handler.OnConnected)
+			}
+		}
+	}
+	h.mu.Unlock()
+	for _, fn := range notify {
+		fn(connected)
+	}
+}
+
+// emit 提交开播状态消息，不等待订阅者处理；上一条消息还未分发时用最新的状态替换
+func (h *Hub) emit(key roomKey, living bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r, ok := h.rooms[key]
+	if !ok {
+		return
+	}
+	select {
+	case <-r.status:
+	default:
+	}
+	r.status <- living
+}
+
+// dispatch 依次把直播间的开播状态消息分发给订阅者，直到取消订阅
+func (h *Hub) dispatch(r *room) {
+	for {
+		select {
+		case <-r.done:
+			return
+		case living := <-r.status:
+			var notify []func(bool)
+			h.mu.Lock()
+			for _, handler := range r.handlers {
+				if handler.OnStatus != nil {
+					notify = append(notify, handler.OnStatus)
+				}
+			}
+			h.mu.Unlock()
+			for _, fn := range notify {
+				fn(living)
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package pushdetect
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePlatform 测试用推送通道，记录每个连接订阅的直播间，可以主动推送消息或断开连接
+type fakePlatform struct {
+	perConn int
+
+	mu     sync.Mutex
+	serves [][]string
+	emits  map[string]func(string, bool)
+	drops  map[string]chan struct{}
+}
+
+func newFakePlatform(perConn int) *fakePlatform {
+	return &fakePlatform{perConn: perConn, emits: make(map[string]func(string, bool)), drops: make(map[string]chan struct{})}
+}
+
+func (p *fakePlatform) Name() string      { return "fake" }
+func (p *fakePlatform) RoomsPerConn() int { return p.perConn }
+
+func (p *fakePlatform) Serve(ctx context.Context, rooms []string, ready func(), emit func(string, bool)) error {
+	drop := make(chan struct{})
+	p.mu.Lock()
+	p.serves = append(p.serves, rooms)
+	for _, r := range rooms {
+		p.emits[r] = emit
+		p.drops[r] = drop
+	}
+	p.mu.Unlock()
+	ready()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-drop:
+		return errors.New("dropped")
+	}
+}
+
+func (p *fakePlatform) push(room string, living bool) {
+	p.mu.Lock()
+	emit := p.emits[room]
+	p.mu.Unlock()
+	emit(room, living)
+}
+
+func (p *fakePlatform) drop(room string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	close(p.drops[room])
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// newTestHub 缩短合并订阅和建立连接的等待时间
+func newTestHub(maxConns int) *Hub {
+	h := NewHub(maxConns)
+	h.batchDelay = 20 * time.Millisecond
+	h.dialInterval = time.Millisecond
+	return h
+}
+
+func TestHubMultiplexesRooms(t *testing.T) {
+	h := newTestHub(10)
+	p := newFakePlatform(3)
+	var unsubs []func()
+	for _, r := range []string{"1", "2", "3", "4", "5"} {
+		unsubs = append(unsubs, h.Subscribe(p, r, Handler{}))
+	}
+	// 同一直播间的重复订阅共享一个订阅
+	unsubs = append(unsubs, h.Subscribe(p, "1", Handler{}))
+	waitFor(t, func() bool { return h.Stats().Connected == 5 })
+	assert.Equal(t, Stats{Conns: 2, Rooms: 5, Connected: 5}, h.Stats())
+
+	for _, unsub := range unsubs {
+		unsub()
+	}
+	assert.Equal(t, Stats{}, h.Stats())
+}
+
+func TestHubDispatchesStatus(t *testing.T) {
+	h := newTestHub(10)
+	p := newFakePlatform(1)
+	statuses := make(chan bool, 4)
+	unsub := h.Subscribe(p, "1", Handler{OnStatus: func(living bool) { statuses <- living }})
+	defer unsub()
+	waitFor(t, func() bool { return h.Stats().Connected == 1 })
+
+	p.push("1", true)
+	assert.True(t, <-statuses)
+	p.push("1", false)
+	assert.False(t, <-statuses)
+}
+
+func TestHubEmitDoesNotBlockOnSlowHandler(t *testing.T) {
+	h := newTestHub(10)
+	p := newFakePlatform(1)
+	release := make(chan struct{})
+	statuses := make(chan bool, 4)
+	unsub := h.Subscribe(p, "1", Handler{OnStatus: func(living bool) {
+		<-release
+		statuses <- living
+	}})
+	defer unsub()
+	waitFor(t, func() bool { return h.Stats().Connected == 1 })
+
+	// 订阅者处理第一条消息时阻塞，之后的消息不能阻塞连接的读取，并且只保留最新的状态
+	done := make(chan struct{})
+	go func() {
+		p.push("1", true)
+		p.push("1", false)
+		p.push("1", true)
+		p.push("1", false)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("emit blocked by slow handler")
+	}
+	close(release)
+	var got []bool
+	for len(got) == 0 || got[len(got)-1] {
+		select {
+		case living := <-statuses:
+			got = append(got, living)
+		case <-time.After(time.Second):
+			t.Fatalf("latest status not delivered, got %v", got)
+		}
+	}
+	assert.LessOrEqual(t, len(got), 2)
+}
+
+func TestHubReconnectsAfterDrop(t *testing.T) {
+	h := newTestHub(10)
+	p := newFakePlatform(1)
+	var mu sync.Mutex
+	var states []bool
+	unsub := h.Subscribe(p, "1", Handler{OnConnected: func(c bool) {
+		mu.Lock()
+		states = append(states, c)
+		mu.Unlock()
+	}})
+	defer unsub()
+	waitFor(t, func() bool { return h.Stats().Connected == 1 })
+
+	p.drop("1")
+	waitFor(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return len(p.serves) == 2
+	})
+	waitFor(t, func() bool { return h.Stats().Connected == 1 })
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []bool{true, false, true}, states)
+}
+
+func TestHubMaxConns(t *testing.T) {
+	h := newTestHub(1)
+	p := newFakePlatform(1)
+	unsub1 := h.Subscribe(p, "1", Handler{})
+	unsub2 := h.Subscribe(p, "2", Handler{})
+	defer unsub2()
+	waitFor(t, func() bool { return h.Stats().Connected == 1 })
+	assert.Equal(t, 1, h.Stats().Unassigned)
+
+	// 释放连接后分配给等待的直播间
+	unsub1()
+	waitFor(t, func() bool { return h.Stats().Connected == 1 && h.Stats().Unassigned == 0 })
+	assert.Equal(t, 1, h.Stats().Conns)
+}
+
+func TestHubBatchesResubscribe(t *testing.T) {
+	h := newTestHub(10)
+	h.batchDelay = 200 * time.Millisecond
+	p := newFakePlatform(10)
+	unsub := h.Subscribe(p, "1", Handler{})
+	defer unsub()
+	waitFor(t, func() bool { return h.Stats().Connected == 1 })
+
+	// 已连接后陆续加入的直播间合并为一次重连
+	for _, r := range []string{"2", "3", "4", "5"} {
+		defer h.Subscribe(p, r, Handler{})()
+	}
+	waitFor(t, func() bool { return h.Stats().Connected == 5 })
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	assert.Len(t, p.serves, 2)
+	assert.Len(t, p.serves[1], 5)
+}
@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"container/heap"
+	"time"
+)
+
+// tokenBucket 单个平台接口类别的令牌桶
+// 排队的请求使用加权公平排队（WFQ）：每个请求按所属直播间计算虚拟完成时间，
+// 同一直播间连续排队的请求完成时间依次推后，因此请求多的直播间不会挤占其他直播间的份额
+type tokenBucket struct {
+	platform string
+	endpoint Endpoint
+	config   BucketConfig
+
+	tokens    float64
+	updated   time.Time // 上次补充令牌的时间
+	lastGrant time.Time // 上次发放令牌的时间
+
+	vtime    float64            // 虚拟时间：最近一次发放的请求的完成时间
+	roomTags map[string]float64 // 直播间 -> 该直播间最后一个排队请求的完成时间
+	seq      uint64
+	queue    waiterQueue
+	timer    *time.Timer
+}
+
+func newTokenBucket(platform string, endpoint Endpoint, config BucketConfig, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		platform: platform,
+		endpoint: endpoint,
+		config:   config,
+		tokens:   float64(config.Burst),
+		updated:  now,
+		roomTags: make(map[string]float64),
+	}
+}
+
+// unlimited 令牌补充间隔不大于 0 时不限制访问频率
+func (c BucketConfig) unlimited() bool {
+	return c.Interval <= 0
+}
+
+// refill 按经过的时间补充令牌，不限制时始终为满桶
+func (b *tokenBucket) refill(now time.Time) {
+	if b.config.unlimited() {
+		b.tokens = float64(b.config.Burst)
+	} else if now.After(b.updated) {
+		b.tokens = min(b.tokens+float64(now.Sub(b.updated))/float64(b.config.Interval), float64(b.config.Burst))
+	}
+	b.updated = now
+}
+
+// tokenWait 返回距离下一个令牌可用的时间
+func (b *tokenBucket) tokenWait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) * float64(b.config.Interval))
+}
+
+// enqueue 将请求加入队列
+func (b *tokenBucket) enqueue(req Request) *waiter {
+	weight := max(req.Weight, 1)
+	start := max(b.vtime, b.roomTags[req.Room])
+	b.seq++
+	w := &waiter{
+		room:     req.Room,
+		priority: req.Priority,
+		tag:      start + 1/float64(weight),
+		seq:      b.seq,
+		ready:    make(chan struct{}),
+	}
+	b.roomTags[req.Room] = w.tag
+	heap.Push(&b.queue, w)
+	return w
+}
+
+// grant 向请求发放一个令牌
+func (b *tokenBucket) grant(w *waiter, now time.Time) {
+	if !b.config.unlimited() {
+		b.tokens--
+	}
+	b.lastGrant = now
+	b.vtime = max(b.vtime, w.tag)
+	// 该直播间没有更晚排队的请求时清除记录
+	if b.roomTags[w.room] <= w.tag {
+		delete(b.roomTags, w.room)
+	}
+	close(w.ready)
+}
+
+// waiter 排队中的请求
+type waiter struct {
+	room     string
+	priority bool
+	tag      float64 // 虚拟完成时间
+	seq      uint64
+	ready    chan struct{}
+	index    int
+}
+
+// waiterQueue 按优先级、虚拟完成时间、排队顺序排序的请求队列
+type waiterQueue []*waiter
+
+func (q waiterQueue) Len() int { return len(q) }
+
+func (q waiterQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority
+	}
+	if q[i].tag != q[j].tag {
+		return q[i].tag < q[j].tag
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waiterQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waiterQueue) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waiterQueue) Pop() any {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return w
+}
@@ -0,0 +1,218 @@
+package ratelimit
+
+import (
+	"time"
+)
+
+// 风控冷却参数
+// 每次检测到风控冷却等级加一，冷却时长按等级指数增长：1、2、4、8、16 分钟，最长 30 分钟
+// 冷却结束后按等级放慢请求（间隔为 max(最小访问间隔, recoveryBaseInterval) * 2^等级），
+// 每连续成功 recoverySuccesses 次请求降低一级，降到 0 级时恢复正常
+const (
+	baseCooldown         = time.Minute
+	maxCooldown          = 30 * time.Minute
+	maxCooldownLevel     = 6
+	recoveryBaseInterval = 2 * time.Second
+	recoverySuccesses    = 5
+)
+
+// CooldownEventType 冷却状态变化类型
+type CooldownEventType string
+
+const (
+	// CooldownStarted 检测到风控，平台进入冷却
+	CooldownStarted CooldownEventType = "cooldown"
+	// CooldownRecovering 冷却结束，平台开始逐步恢复请求频率
+	CooldownRecovering CooldownEventType = "recovering"
+	// CooldownRecovered 平台恢复正常请求频率
+	CooldownRecovered CooldownEventType = "recovered"
+)
+
+// CooldownState 平台风控冷却状态
+type CooldownState struct {
+	Platform  string    `json:"platform"`
+	Name      string    `json:"name"`       // 平台显示名称
+	Level     int       `json:"level"`      // 当前冷却等级，0 表示未冷却
+	MaxLevel  int       `json:"max_level"`  // 本次冷却期间达到的最高等级
+	Until     time.Time `json:"until"`      // 冷却结束时间
+	StartedAt time.Time `json:"started_at"` // 本次冷却开始时间
+	Reason    string    `json:"reason"`     // 最近一次风控原因
+}
+
+// CooldownEvent 冷却状态变化事件
+type CooldownEvent struct {
+	Type CooldownEventType
+	CooldownState
+}
+
+// platformCooldown 单个平台的风控冷却状态，由 PlatformRateLimiter 的锁保护
+type platformCooldown struct {
+	name       string
+	level      int
+	maxLevel   int
+	until      time.Time
+	startedAt  time.Time
+	reason     string
+	successes  int       // 冷却结束后连续成功的请求数
+	recovering bool      // 是否已进入恢复阶段
+	lastAccess time.Time // 恢复阶段上次访问时间
+}
+
+// cooldownDuration 返回指定等级的冷却时长
+func cooldownDuration(level int) time.Duration {
+	return min(baseCooldown<<(level-1), maxCooldown)
+}
+
+// nextAllowed 返回冷却状态下允许下次访问的时间，interval 为令牌补充间隔，调用方需持有锁
+func (c *platformCooldown) nextAllowed(interval time.Duration) time.Time {
+	if c.level == 0 {
+		return time.Time{}
+	}
+	interval = max(interval, recoveryBaseInterval) << c.level
+	return maxTime(c.until, c.lastAccess.Add(interval))
+}
+
+func (c *platformCooldown) state(platform string) CooldownState {
+	return CooldownState{
+		Platform:  platform,
+		Name:      c.name,
+		Level:     c.level,
+		MaxLevel:  c.maxLevel,
+		Until:     c.until,
+		StartedAt: c.startedAt,
+		Reason:    c.reason,
+	}
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// SetCooldownCallback 设置冷却状态变化回调，回调在锁外调用
+func (prl *PlatformRateLimiter) SetCooldownCallback(fn func(CooldownEvent)) {
+	prl.mu.Lock()
+	defer prl.mu.Unlock()
+	prl.cooldownCallback = fn
+}
+
+// getCooldown 获取平台的冷却状态，不存在时创建，调用方需持有锁
+func (prl *PlatformRateLimiter) getCooldown(platform string) *platformCooldown {
+	c, ok := prl.cooldowns[platform]
+	if !ok {
+		c = &platformCooldown{}
+		prl.cooldowns[platform] = c
+	}
+	return c
+}
+
+// emitCooldownEvent 调用冷却状态变化回调，调用方不能持有锁
+func (prl *PlatformRateLimiter) emitCooldownEvent(fn func(CooldownEvent), events ...CooldownEvent) {
+	if fn == nil {
+		return
+	}
+	for _, e := range events {
+		fn(e)
+	}
+}
+
+// dispatchPlatform 冷却状态变化后重新发放平台各令牌桶的令牌，调用方需持有锁
+func (prl *PlatformRateLimiter) dispatchPlatform(platform string) {
+	now := time.Now()
+	for key, b := range prl.buckets {
+		if key.platform == platform {
+			prl.dispatch(b, now)
+		}
+	}
+}
+
+// ReportRiskControl 报告平台请求被风控拦截，平台进入冷却，冷却等级加一
+// name 为平台显示名称，冷却期间重复报告会被忽略（冷却前发出的请求可能陆续返回风控错误）
+func (prl *PlatformRateLimiter) ReportRiskControl(platform, name, reason string) {
+	if platform == "" {
+		return
+	}
+	now := time.Now()
+	prl.mu.Lock()
+	c := prl.getCooldown(platform)
+	if now.Before(c.until) {
+		prl.mu.Unlock()
+		return
+	}
+	if c.level == 0 {
+		c.startedAt = now
+		c.maxLevel = 0
+	}
+	c.level = min(c.level+1, maxCooldownLevel)
+	c.maxLevel = max(c.maxLevel, c.level)
+	c.until = now.Add(cooldownDuration(c.level))
+	c.name = name
+	c.reason = reason
+	c.successes = 0
+	c.recovering = false
+	event := CooldownEvent{Type: CooldownStarted, CooldownState: c.state(platform)}
+	fn := prl.cooldownCallback
+	prl.mu.Unlock()
+
+	prl.emitCooldownEvent(fn, event)
+}
+
+// ReportSuccess 报告平台请求成功，冷却结束后连续成功足够次数时逐级恢复请求频率
+func (prl *PlatformRateLimiter) ReportSuccess(platform string) {
+	now := time.Now()
+	prl.mu.Lock()
+	c, ok := prl.cooldowns[platform]
+	if !ok || c.level == 0 || now.Before(c.until) {
+		prl.mu.Unlock()
+		return
+	}
+	var events []CooldownEvent
+	if !c.recovering {
+		c.recovering = true
+		events = append(events, CooldownEvent{Type: CooldownRecovering, CooldownState: c.state(platform)})
+	}
+	c.successes++
+	if c.successes >= recoverySuccesses {
+		c.successes = 0
+		c.level--
+		// 降级后请求间隔缩短，重新计算排队请求的等待时间
+		prl.dispatchPlatform(platform)
+		if c.level == 0 {
+			state := c.state(platform)
+			// 记录恢复时间作为冷却期结束时间
+			state.Until = now
+			events = append(events, CooldownEvent{Type: CooldownRecovered, CooldownState: state})
+			c.recovering = false
+		}
+	}
+	fn := prl.cooldownCallback
+	prl.mu.Unlock()
+
+	prl.emitCooldownEvent(fn, events...)
+}
+
+// GetPlatformCooldown 获取平台的风控冷却状态，平台未在冷却或恢复中时返回 false
+func (prl *PlatformRateLimiter) GetPlatformCooldown(platform string) (CooldownState, bool) {
+	prl.mu.Lock()
+	defer prl.mu.Unlock()
+	c, ok := prl.cooldowns[platform]
+	if !ok || c.level == 0 {
+		return CooldownState{}, false
+	}
+	return c.state(platform), true
+}
+
+// GetAllCooldowns 获取所有正在冷却或恢复中的平台状态
+func (prl *PlatformRateLimiter) GetAllCooldowns() []CooldownState {
+	prl.mu.Lock()
+	defer prl.mu.Unlock()
+	var states []CooldownState
+	for platform, c := range prl.cooldowns {
+		if c.level > 0 {
+			states = append(states, c.state(platform))
+		}
+	}
+	return states
+}
@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter() (*PlatformRateLimiter, *[]CooldownEvent) {
+	prl := NewPlatformRateLimiter()
+	var events []CooldownEvent
+	prl.SetCooldownCallback(func(e CooldownEvent) { events = append(events, e) })
+	return prl, &events
+}
+
+// expireCooldown 模拟冷却时间已经结束
+func expireCooldown(prl *PlatformRateLimiter, platform string) {
+	prl.mu.Lock()
+	defer prl.mu.Unlock()
+	c := prl.cooldowns[platform]
+	c.until = time.Now().Add(-time.Second)
+	c.lastAccess = time.Time{}
+}
+
+func TestReportRiskControlStartsCooldown(t *testing.T) {
+	prl, events := newTestLimiter()
+	prl.ReportRiskControl("test", "测试", "code -352")
+	// 冷却期间重复报告被忽略
+	prl.ReportRiskControl("test", "测试", "code -412")
+
+	require.Len(t, *events, 1)
+	e := (*events)[0]
+	assert.Equal(t, CooldownStarted, e.Type)
+	assert.Equal(t, 1, e.Level)
+	assert.Equal(t, "code -352", e.Reason)
+	assert.Equal(t, "测试", e.Name)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), e.Until, time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.False(t, prl.Wait(ctx, Request{Platform: "test"}))
+	// 冷却对平台的所有接口类别生效，其他平台不受影响
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel2()
+	assert.False(t, prl.Wait(ctx2, Request{Platform: "test", Endpoint: EndpointStream}))
+	assert.True(t, prl.Wait(context.Background(), Request{Platform: "other"}))
+
+	info := prl.GetWaitInfo("test", EndpointInfo)
+	assert.Equal(t, 1, info.CooldownLevel)
+	assert.InDelta(t, 60, info.NextRequestInSec, 1)
+}
+
+func TestReportRiskControlEscalates(t *testing.T) {
+	prl, events := newTestLimiter()
+	for range 8 {
+		prl.ReportRiskControl("test", "测试", "risk")
+		expireCooldown(prl, "test")
+	}
+	require.Len(t, *events, 8)
+	for i, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 16 * time.Minute, 30 * time.Minute, 30 * time.Minute} {
+		e := (*events)[i]
+		assert.Equal(t, min(i+1, maxCooldownLevel), e.Level)
+		assert.WithinDuration(t, time.Now().Add(want), e.Until, time.Second)
+		// 冷却期间保持同一个开始时间
+		assert.Equal(t, (*events)[0].StartedAt, e.StartedAt)
+	}
+}
+
+func TestReportSuccessRecoversGradually(t *testing.T) {
+	prl, events := newTestLimiter()
+	prl.ReportRiskControl("test", "测试", "risk")
+	prl.ReportRiskControl("test", "测试", "risk")
+	// 冷却期间的成功不计数
+	prl.ReportSuccess("test")
+	expireCooldown(prl, "test")
+	prl.ReportRiskControl("test", "测试", "risk")
+	expireCooldown(prl, "test")
+
+	state, ok := prl.GetPlatformCooldown("test")
+	require.True(t, ok)
+	assert.Equal(t, 2, state.Level)
+
+	// 恢复阶段按等级放慢请求
+	assert.True(t, prl.Wait(context.Background(), Request{Platform: "test"}))
+	assert.InDelta(t, (recoveryBaseInterval << 2).Seconds(), prl.GetWaitInfo("test", EndpointInfo).NextRequestInSec, 0.5)
+
+	for range recoverySuccesses {
+		prl.ReportSuccess("test")
+	}
+	state, ok = prl.GetPlatformCooldown("test")
+	require.True(t, ok)
+	assert.Equal(t, 1, state.Level)
+	assert.Len(t, prl.GetAllCooldowns(), 1)
+
+	for range recoverySuccesses {
+		prl.ReportSuccess("test")
+	}
+	_, ok = prl.GetPlatformCooldown("test")
+	assert.False(t, ok)
+	assert.Empty(t, prl.GetAllCooldowns())
+	assert.Zero(t, prl.GetWaitInfo("test", EndpointStream).NextRequestInSec)
+
+	var types []CooldownEventType
+	for _, e := range *events {
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []CooldownEventType{CooldownStarted, CooldownStarted, CooldownRecovering, CooldownRecovered}, types)
+	last := (*events)[len(*events)-1]
+	assert.Equal(t, 2, last.MaxLevel)
+	assert.WithinDuration(t, time.Now(), last.Until, time.Second)
+
+	// 恢复后再次触发风控重新从 1 级开始
+	prl.ReportRiskControl("test", "测试", "risk")
+	assert.Equal(t, 1, (*events)[len(*events)-1].Level)
+}
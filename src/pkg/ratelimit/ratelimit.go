@@ -1,216 +1,316 @@
 // Package ratelimit 为每个直播平台提供访问频率限制功能
+// 每个平台按接口类别（直播间信息、直播流地址、Cookie 检查）分别使用令牌桶限制访问频率，
+// 桶容量允许空闲后的突发请求，排队的请求按直播间加权公平排队，优先请求（如刚开播直播间获取直播流）先于其他请求
+// 没有配置的平台接口不限制访问频率，只在风控冷却期间放慢请求
 package ratelimit
 
 import (
+	"container/heap"
 	"context"
 	"sync"
 	"time"
 )
 
+// Endpoint 平台接口类别，每个类别使用独立的令牌桶
+type Endpoint string
+
+const (
+	// EndpointInfo 直播间信息（开播状态检测）
+	EndpointInfo Endpoint = "info"
+	// EndpointStream 直播流地址
+	EndpointStream Endpoint = "stream"
+	// EndpointCookie Cookie 登录状态检查
+	EndpointCookie Endpoint = "cookie"
+)
+
+// Endpoints 所有接口类别
+var Endpoints = []Endpoint{EndpointInfo, EndpointStream, EndpointCookie}
+
+// BucketConfig 令牌桶配置
+type BucketConfig struct {
+	Interval time.Duration // 每隔多久补充一个令牌，即长期平均请求间隔，<= 0 时不限制
+	Burst    int           // 桶容量，空闲后允许连续发出的请求数
+}
+
+// Request 一次访问请求
+type Request struct {
+	Platform string
+	Endpoint Endpoint
+	Room     string // 排队公平性按直播间区分，空字符串视为同一个来源
+	Weight   int    // 直播间权重，权重越大分到的份额越多，默认 1
+	Priority bool   // 优先请求排在所有普通请求之前
+}
+
 // PlatformRateLimiter 管理各个直播平台的访问频率限制
 type PlatformRateLimiter struct {
-	limiters map[string]*PlatformLimiter // 平台名称 -> 限制器
-	mu       sync.RWMutex                // 读写锁保护map
-}
+	mu        sync.Mutex
+	defaults  map[Endpoint]BucketConfig            // 各接口类别的默认配置
+	overrides map[string]map[Endpoint]BucketConfig // 平台名称 -> 覆盖配置
+	buckets   map[bucketKey]*tokenBucket           // 按需创建的令牌桶
+	cooldowns map[string]*platformCooldown         // 平台名称 -> 风控冷却状态，不随限制配置变化清除
 
-// PlatformLimiter 单个平台的频率限制器
-type PlatformLimiter struct {
-	minInterval time.Duration // 最小访问间隔
-	lastAccess  time.Time     // 上次访问时间
-	mu          sync.Mutex    // 保护访问时间的互斥锁
+	cooldownCallback func(CooldownEvent) // 冷却状态变化回调
 }
 
-var globalRateLimiter = &PlatformRateLimiter{
-	limiters: make(map[string]*PlatformLimiter),
+type bucketKey struct {
+	platform string
+	endpoint Endpoint
 }
 
+// defaultBucket 未配置时使用的令牌桶，不限制访问频率
+var defaultBucket = BucketConfig{}
+
+var globalRateLimiter = NewPlatformRateLimiter()
+
 // GetGlobalRateLimiter 获取全局速率限制器实例
 func GetGlobalRateLimiter() *PlatformRateLimiter {
 	return globalRateLimiter
 }
 
-// SetPlatformLimit 设置或更新指定平台的访问频率限制
-func (prl *PlatformRateLimiter) SetPlatformLimit(platform string, intervalSec int) {
-	if intervalSec <= 0 {
-		// 如果间隔为0或负数，移除该平台的限制
-		prl.mu.Lock()
-		delete(prl.limiters, platform)
-		prl.mu.Unlock()
-		return
+// NewPlatformRateLimiter 创建速率限制器
+func NewPlatformRateLimiter() *PlatformRateLimiter {
+	return &PlatformRateLimiter{
+		defaults:  make(map[Endpoint]BucketConfig),
+		overrides: make(map[string]map[Endpoint]BucketConfig),
+		buckets:   make(map[bucketKey]*tokenBucket),
+		cooldowns: make(map[string]*platformCooldown),
 	}
+}
 
-	interval := time.Duration(intervalSec) * time.Second
-
+// SetDefaultBuckets 设置各接口类别的默认令牌桶配置，已创建的令牌桶立即生效
+func (prl *PlatformRateLimiter) SetDefaultBuckets(defaults map[Endpoint]BucketConfig) {
 	prl.mu.Lock()
 	defer prl.mu.Unlock()
+	prl.defaults = make(map[Endpoint]BucketConfig, len(defaults))
+	for endpoint, cfg := range defaults {
+		prl.defaults[endpoint] = cfg
+	}
+	prl.applyConfigs()
+}
 
-	if limiter, exists := prl.limiters[platform]; exists {
-		// 更新现有限制器的间隔
-		limiter.mu.Lock()
-		limiter.minInterval = interval
-		limiter.mu.Unlock()
+// SetPlatformBuckets 设置平台的令牌桶配置，未包含的接口类别使用默认配置，传入 nil 清除覆盖配置
+func (prl *PlatformRateLimiter) SetPlatformBuckets(platform string, buckets map[Endpoint]BucketConfig) {
+	prl.mu.Lock()
+	defer prl.mu.Unlock()
+	if len(buckets) == 0 {
+		delete(prl.overrides, platform)
 	} else {
-		// 创建新的限制器
-		prl.limiters[platform] = &PlatformLimiter{
-			minInterval: interval,
-			lastAccess:  time.Time{}, // 零值时间，首次访问不会被限制
+		overrides := make(map[Endpoint]BucketConfig, len(buckets))
+		for endpoint, cfg := range buckets {
+			overrides[endpoint] = cfg
 		}
+		prl.overrides[platform] = overrides
 	}
+	prl.applyConfigs()
 }
 
-// WaitForPlatform 等待直到允许访问指定平台
-// 如果平台没有设置限制，立即返回
-// 注意：此函数在等待期间不持有锁，以允许 ForceAccess 等操作可以随时执行
-func (prl *PlatformRateLimiter) WaitForPlatform(platform string) {
-	prl.WaitForPlatformWithContext(context.Background(), platform)
-}
-
-// WaitForPlatformWithContext 等待直到允许访问指定平台，支持 context 取消
-// 如果平台没有设置限制，立即返回 true
-// 返回 true 表示成功获取访问权限，false 表示被 context 取消
-func (prl *PlatformRateLimiter) WaitForPlatformWithContext(ctx context.Context, platform string) bool {
-	prl.mu.RLock()
-	limiter, exists := prl.limiters[platform]
-	prl.mu.RUnlock()
-
-	if !exists {
-		// 平台没有设置限制，立即返回
-		return true
+// GetPlatformsWithOverrides 获取设置了覆盖配置的平台
+func (prl *PlatformRateLimiter) GetPlatformsWithOverrides() []string {
+	prl.mu.Lock()
+	defer prl.mu.Unlock()
+	platforms := make([]string, 0, len(prl.overrides))
+	for platform := range prl.overrides {
+		platforms = append(platforms, platform)
 	}
+	return platforms
+}
 
-	for {
-		// 检查 context 是否已取消
-		select {
-		case <-ctx.Done():
-			return false
-		default:
-		}
-
-		// 获取锁，计算等待时间
-		limiter.mu.Lock()
-		now := time.Now()
-		elapsed := now.Sub(limiter.lastAccess)
-
-		if elapsed >= limiter.minInterval {
-			// 已经等待足够长时间，更新访问时间并返回
-			limiter.lastAccess = now
-			limiter.mu.Unlock()
-			return true
+// resolveConfig 返回平台接口类别生效的令牌桶配置，调用方需持有锁
+func (prl *PlatformRateLimiter) resolveConfig(platform string, endpoint Endpoint) BucketConfig {
+	cfg, ok := prl.overrides[platform][endpoint]
+	if !ok {
+		if cfg, ok = prl.defaults[endpoint]; !ok {
+			cfg = defaultBucket
 		}
+	}
+	cfg.Burst = max(cfg.Burst, 1)
+	return cfg
+}
 
-		// 计算需要等待的时间
-		waitTime := limiter.minInterval - elapsed
-		limiter.mu.Unlock() // 释放锁再 sleep，避免阻塞 ForceAccess 等操作
-
-		// 在不持有锁的情况下等待，支持 context 取消
-		timer := time.NewTimer(waitTime)
-		select {
-		case <-ctx.Done():
-			timer.Stop()
-			return false
-		case <-timer.C:
-			// 循环回去重新检查
-		}
+// applyConfigs 将配置变化应用到已创建的令牌桶，调用方需持有锁
+func (prl *PlatformRateLimiter) applyConfigs() {
+	now := time.Now()
+	for key, b := range prl.buckets {
+		b.refill(now)
+		b.config = prl.resolveConfig(key.platform, key.endpoint)
+		b.tokens = min(b.tokens, float64(b.config.Burst))
+		prl.dispatch(b, now)
 	}
 }
 
-// GetPlatformNextAllowedTime 获取平台下次允许访问的时间
-func (prl *PlatformRateLimiter) GetPlatformNextAllowedTime(platform string) time.Time {
-	prl.mu.RLock()
-	limiter, exists := prl.limiters[platform]
-	prl.mu.RUnlock()
+// getBucket 获取令牌桶，不存在时按配置创建（初始为满桶），调用方需持有锁
+func (prl *PlatformRateLimiter) getBucket(platform string, endpoint Endpoint) *tokenBucket {
+	key := bucketKey{platform: platform, endpoint: endpoint}
+	b, ok := prl.buckets[key]
+	if !ok {
+		b = newTokenBucket(platform, endpoint, prl.resolveConfig(platform, endpoint), time.Now())
+		prl.buckets[key] = b
+	}
+	return b
+}
 
-	if !exists {
-		// 没有限制，立即可访问
-		return time.Now()
+// Wait 等待直到允许发出请求，返回 false 表示被 context 取消
+// 平台名称为空时不限制
+func (prl *PlatformRateLimiter) Wait(ctx context.Context, req Request) bool {
+	if req.Platform == "" {
+		return true
+	}
+	if req.Endpoint == "" {
+		req.Endpoint = EndpointInfo
 	}
 
-	limiter.mu.Lock()
-	defer limiter.mu.Unlock()
+	prl.mu.Lock()
+	b := prl.getBucket(req.Platform, req.Endpoint)
+	w := b.enqueue(req)
+	prl.dispatch(b, time.Now())
+	prl.mu.Unlock()
 
-	return limiter.lastAccess.Add(limiter.minInterval)
-}
+	select {
+	case <-w.ready:
+		return true
+	case <-ctx.Done():
+	}
 
-// RemovePlatformLimit 移除指定平台的访问限制
-func (prl *PlatformRateLimiter) RemovePlatformLimit(platform string) {
 	prl.mu.Lock()
 	defer prl.mu.Unlock()
-
-	delete(prl.limiters, platform)
+	select {
+	case <-w.ready:
+		// 取消的同时已经获得令牌，归还给其他请求
+		b.tokens = min(b.tokens+1, float64(b.config.Burst))
+	default:
+		heap.Remove(&b.queue, w.index)
+	}
+	prl.dispatch(b, time.Now())
+	return false
 }
 
-// GetAllPlatformLimits 获取所有平台的当前限制设置
-func (prl *PlatformRateLimiter) GetAllPlatformLimits() map[string]int {
-	prl.mu.RLock()
-	defer prl.mu.RUnlock()
+// ForceAccess 强制访问平台，不排队，但仍消耗令牌（令牌不足时清空）
+func (prl *PlatformRateLimiter) ForceAccess(platform string, endpoint Endpoint) {
+	if platform == "" {
+		return
+	}
+	prl.mu.Lock()
+	defer prl.mu.Unlock()
+	now := time.Now()
+	b := prl.getBucket(platform, endpoint)
+	b.refill(now)
+	if !b.config.unlimited() {
+		b.tokens = max(b.tokens-1, 0)
+	}
+	b.lastGrant = now
+}
 
-	limits := make(map[string]int)
-	for platform, limiter := range prl.limiters {
-		limiter.mu.Lock()
-		limits[platform] = int(limiter.minInterval.Seconds())
-		limiter.mu.Unlock()
+// dispatch 按排队顺序发放可用令牌，剩余请求在下一个令牌可用时再次发放，调用方需持有锁
+func (prl *PlatformRateLimiter) dispatch(b *tokenBucket, now time.Time) {
+	b.refill(now)
+	cooldown := prl.cooldowns[b.platform]
+	for b.queue.Len() > 0 {
+		if b.tokens < 1 {
+			break
+		}
+		if cooldown != nil && now.Before(cooldown.nextAllowed(b.config.Interval)) {
+			break
+		}
+		w := heap.Pop(&b.queue).(*waiter)
+		b.grant(w, now)
+		if cooldown != nil {
+			cooldown.lastAccess = now
+		}
 	}
 
-	return limits
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if b.queue.Len() == 0 {
+		return
+	}
+	wait := b.tokenWait()
+	if cooldown != nil {
+		wait = max(wait, cooldown.nextAllowed(b.config.Interval).Sub(now))
+	}
+	b.timer = time.AfterFunc(wait, func() {
+		prl.mu.Lock()
+		defer prl.mu.Unlock()
+		prl.dispatch(b, time.Now())
+	})
 }
 
 // WaitInfo 包含平台等待状态信息
 type WaitInfo struct {
-	WaitedSeconds    float64 // 自上次请求以来已等待的秒数
-	NextRequestInSec float64 // 预计多少秒后可以发送下一次请求（0 表示立即可以）
-	MinIntervalSec   int     // 平台设置的最小访问间隔
-}
-
-// GetPlatformWaitInfo 获取指定平台的等待状态信息
-func (prl *PlatformRateLimiter) GetPlatformWaitInfo(platform string) WaitInfo {
-	prl.mu.RLock()
-	limiter, exists := prl.limiters[platform]
-	prl.mu.RUnlock()
-
-	if !exists {
-		// 平台没有设置限制
-		return WaitInfo{
-			WaitedSeconds:    0,
-			NextRequestInSec: 0,
-			MinIntervalSec:   0,
-		}
-	}
-
-	limiter.mu.Lock()
-	defer limiter.mu.Unlock()
+	WaitedSeconds    float64   // 自上次请求以来已等待的秒数
+	NextRequestInSec float64   // 预计多少秒后可以发送下一次请求（0 表示立即可以）
+	MinIntervalSec   int       // 令牌补充间隔（秒）
+	Tokens           float64   // 当前可用令牌数
+	Burst            int       // 桶容量
+	Waiting          int       // 排队中的请求数
+	CooldownLevel    int       // 风控冷却等级（0 表示未冷却）
+	CooldownUntil    time.Time // 风控冷却结束时间，冷却结束后仍会按等级放慢请求直到恢复
+}
 
+// GetWaitInfo 获取平台接口类别的等待状态信息
+func (prl *PlatformRateLimiter) GetWaitInfo(platform string, endpoint Endpoint) WaitInfo {
+	prl.mu.Lock()
+	defer prl.mu.Unlock()
+	if platform == "" {
+		return WaitInfo{}
+	}
 	now := time.Now()
-	elapsed := now.Sub(limiter.lastAccess)
+	b := prl.getBucket(platform, endpoint)
+	b.refill(now)
 
 	info := WaitInfo{
-		WaitedSeconds:  elapsed.Seconds(),
-		MinIntervalSec: int(limiter.minInterval.Seconds()),
+		MinIntervalSec: int(b.config.Interval.Seconds()),
+		Tokens:         b.tokens,
+		Burst:          b.config.Burst,
+		Waiting:        b.queue.Len(),
 	}
-
-	if elapsed < limiter.minInterval {
-		// 还需要等待
-		info.NextRequestInSec = (limiter.minInterval - elapsed).Seconds()
+	if !b.lastGrant.IsZero() {
+		info.WaitedSeconds = now.Sub(b.lastGrant).Seconds()
+	}
+	if b.tokens < 1 {
+		info.NextRequestInSec = b.tokenWait().Seconds()
+	}
+	if c, ok := prl.cooldowns[platform]; ok {
+		info.CooldownLevel = c.level
+		if c.level > 0 {
+			info.CooldownUntil = c.until
+		}
+		if next := c.nextAllowed(b.config.Interval); now.Before(next) {
+			info.NextRequestInSec = max(info.NextRequestInSec, next.Sub(now).Seconds())
+		}
 	}
-
 	return info
 }
 
-// ForceAccess 强制访问平台，忽略频率限制
-// 返回距离上次访问的时间间隔
-func (prl *PlatformRateLimiter) ForceAccess(platform string) time.Duration {
-	prl.mu.RLock()
-	limiter, exists := prl.limiters[platform]
-	prl.mu.RUnlock()
-
-	if !exists {
-		return 0
-	}
-
-	limiter.mu.Lock()
-	defer limiter.mu.Unlock()
+// BucketState 令牌桶状态
+type BucketState struct {
+	Platform    string    `json:"platform"`
+	Endpoint    Endpoint  `json:"endpoint"`
+	IntervalSec float64   `json:"interval_sec"`
+	Burst       int       `json:"burst"`
+	Tokens      float64   `json:"tokens"`
+	Waiting     int       `json:"waiting"`
+	LastAccess  time.Time `json:"last_access"`
+}
 
+// GetBucketStates 获取所有已创建令牌桶的状态
+func (prl *PlatformRateLimiter) GetBucketStates() []BucketState {
+	prl.mu.Lock()
+	defer prl.mu.Unlock()
 	now := time.Now()
-	elapsed := now.Sub(limiter.lastAccess)
-	limiter.lastAccess = now
-	return elapsed
+	states := make([]BucketState, 0, len(prl.buckets))
+	for _, b := range prl.buckets {
+		b.refill(now)
+		states = append(states, BucketState{
+			Platform:    b.platform,
+			Endpoint:    b.endpoint,
+			IntervalSec: b.config.Interval.Seconds(),
+			Burst:       b.config.Burst,
+			Tokens:      b.tokens,
+			Waiting:     b.queue.Len(),
+			LastAccess:  b.lastGrant,
+		})
+	}
+	return states
 }
@@ -1,73 +1,171 @@
 package ratelimit
 
 import (
+	"context"
+	"sync"
 	"testing"
 	"time"
-)
 
-func TestPlatformRateLimiter(t *testing.T) {
-	limiter := GetGlobalRateLimiter()
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
 
-	// 设置测试平台限制：2秒间隔
-	limiter.SetPlatformLimit("test_platform", 2)
+const testInterval = 50 * time.Millisecond
 
-	// 第一次访问应该立即通过
-	start := time.Now()
-	limiter.WaitForPlatform("test_platform")
-	elapsed1 := time.Since(start)
+func newBucketLimiter(burst int) *PlatformRateLimiter {
+	prl := NewPlatformRateLimiter()
+	prl.SetDefaultBuckets(map[Endpoint]BucketConfig{
+		EndpointInfo: {Interval: testInterval, Burst: burst},
+	})
+	return prl
+}
 
-	if elapsed1 > 100*time.Millisecond {
-		t.Errorf("First access should be immediate, took %v", elapsed1)
-	}
+func TestTokenBucketBurst(t *testing.T) {
+	prl := newBucketLimiter(3)
 
-	// 第二次访问应该等待约2秒
-	start = time.Now()
-	limiter.WaitForPlatform("test_platform")
-	elapsed2 := time.Since(start)
-
-	if elapsed2 < 1900*time.Millisecond || elapsed2 > 2100*time.Millisecond {
-		t.Errorf("Second access should wait ~2s, took %v", elapsed2)
+	// 满桶时可以连续发出 burst 个请求
+	start := time.Now()
+	for range 3 {
+		require.True(t, prl.Wait(context.Background(), Request{Platform: "test"}))
 	}
+	assert.Less(t, time.Since(start), testInterval/2)
 
-	// 测试没有限制的平台应该立即通过
+	// 之后按补充间隔发出
 	start = time.Now()
-	limiter.WaitForPlatform("unlimited_platform")
-	elapsed3 := time.Since(start)
+	require.True(t, prl.Wait(context.Background(), Request{Platform: "test"}))
+	assert.InDelta(t, testInterval, time.Since(start), float64(testInterval)/2)
 
-	if elapsed3 > 100*time.Millisecond {
-		t.Errorf("Unlimited platform access should be immediate, took %v", elapsed3)
-	}
+	info := prl.GetWaitInfo("test", EndpointInfo)
+	assert.Equal(t, 3, info.Burst)
+	assert.Greater(t, info.NextRequestInSec, 0.0)
 
-	// 清理
-	limiter.RemovePlatformLimit("test_platform")
+	// 平台名称为空时不限制
+	assert.True(t, prl.Wait(context.Background(), Request{}))
 }
 
-func TestPlatformRateLimiterUpdate(t *testing.T) {
-	limiter := GetGlobalRateLimiter()
+func TestTokenBucketEndpointsAreIndependent(t *testing.T) {
+	prl := newBucketLimiter(1)
+	prl.SetPlatformBuckets("test", map[Endpoint]BucketConfig{
+		EndpointStream: {Interval: time.Hour, Burst: 1},
+	})
+	require.True(t, prl.Wait(context.Background(), Request{Platform: "test", Endpoint: EndpointStream}))
 
-	// 设置初始限制
-	limiter.SetPlatformLimit("update_test", 3)
+	// 直播流令牌用完不影响直播间信息请求
+	start := time.Now()
+	require.True(t, prl.Wait(context.Background(), Request{Platform: "test", Endpoint: EndpointInfo}))
+	assert.Less(t, time.Since(start), testInterval/2)
 
-	// 更新限制
-	limiter.SetPlatformLimit("update_test", 1)
+	ctx, cancel := context.WithTimeout(context.Background(), testInterval)
+	defer cancel()
+	assert.False(t, prl.Wait(ctx, Request{Platform: "test", Endpoint: EndpointStream}))
+	assert.Zero(t, prl.GetWaitInfo("test", EndpointStream).Waiting, "取消的请求应移出队列")
 
-	// 第一次访问应该立即通过
-	limiter.WaitForPlatform("update_test")
+	// 清除覆盖配置后使用默认配置（未设置默认配置的接口类别不限制）
+	prl.SetPlatformBuckets("test", nil)
+	assert.Empty(t, prl.GetPlatformsWithOverrides())
+	start = time.Now()
+	for range 5 {
+		require.True(t, prl.Wait(context.Background(), Request{Platform: "test", Endpoint: EndpointStream}))
+	}
+	assert.Less(t, time.Since(start), testInterval/2)
+}
 
-	// 验证新的限制生效
+func TestUnconfiguredIsUnlimited(t *testing.T) {
+	prl := NewPlatformRateLimiter()
 	start := time.Now()
-	limiter.WaitForPlatform("update_test")
-	elapsed := time.Since(start)
+	for range 10 {
+		require.True(t, prl.Wait(context.Background(), Request{Platform: "test"}))
+	}
+	prl.ForceAccess("test", EndpointInfo)
+	require.True(t, prl.Wait(context.Background(), Request{Platform: "test"}))
+	assert.Less(t, time.Since(start), testInterval/2)
+	assert.Zero(t, prl.GetWaitInfo("test", EndpointInfo).NextRequestInSec)
+
+	// 不限制频率的平台触发风控后仍然需要冷却
+	prl.ReportRiskControl("test", "测试", "blocked")
+	ctx, cancel := context.WithTimeout(context.Background(), testInterval)
+	defer cancel()
+	assert.False(t, prl.Wait(ctx, Request{Platform: "test"}))
+}
 
-	if elapsed < 900*time.Millisecond || elapsed > 1100*time.Millisecond {
-		t.Errorf("Updated limit should wait ~1s, took %v", elapsed)
+// waitOrder 让请求依次排队，返回获得令牌的顺序
+func waitOrder(t *testing.T, prl *PlatformRateLimiter, reqs []Request) []int {
+	t.Helper()
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if prl.Wait(context.Background(), req) {
+				mu.Lock()
+				order = append(order, i)
+				mu.Unlock()
+			}
+		}()
+		// 等待请求进入队列，保证排队顺序
+		require.Eventually(t, func() bool {
+			return prl.GetWaitInfo(req.Platform, req.Endpoint).Waiting == i+1
+		}, time.Second, time.Millisecond)
 	}
+	wg.Wait()
+	return order
+}
 
-	// 清理
-	limiter.RemovePlatformLimit("update_test")
+func TestTokenBucketFairQueueing(t *testing.T) {
+	prl := newBucketLimiter(1)
+	require.True(t, prl.Wait(context.Background(), Request{Platform: "test"}))
+
+	// 直播间 a 先排了三个请求，b 和 c 随后排队，不需要等 a 的请求全部完成
+	order := waitOrder(t, prl, []Request{
+		{Platform: "test", Endpoint: EndpointInfo, Room: "a"},
+		{Platform: "test", Endpoint: EndpointInfo, Room: "a"},
+		{Platform: "test", Endpoint: EndpointInfo, Room: "a"},
+		{Platform: "test", Endpoint: EndpointInfo, Room: "b"},
+		{Platform: "test", Endpoint: EndpointInfo, Room: "c", Priority: true},
+	})
+	assert.Equal(t, []int{4, 0, 3, 1, 2}, order)
+}
+
+func TestTokenBucketWeight(t *testing.T) {
+	prl := newBucketLimiter(1)
+	require.True(t, prl.Wait(context.Background(), Request{Platform: "test"}))
+
+	// 权重为 2 的直播间获得两倍份额
+	order := waitOrder(t, prl, []Request{
+		{Platform: "test", Endpoint: EndpointInfo, Room: "a"},
+		{Platform: "test", Endpoint: EndpointInfo, Room: "a"},
+		{Platform: "test", Endpoint: EndpointInfo, Room: "b", Weight: 2},
+		{Platform: "test", Endpoint: EndpointInfo, Room: "b", Weight: 2},
+	})
+	assert.Equal(t, []int{2, 0, 3, 1}, order)
+}
+
+func TestTokenBucketUpdateConfig(t *testing.T) {
+	prl := newBucketLimiter(1)
+	prl.SetPlatformBuckets("test", map[Endpoint]BucketConfig{EndpointInfo: {Interval: time.Hour, Burst: 1}})
+	require.True(t, prl.Wait(context.Background(), Request{Platform: "test"}))
+
+	done := make(chan bool)
+	go func() { done <- prl.Wait(context.Background(), Request{Platform: "test"}) }()
+	require.Eventually(t, func() bool { return prl.GetWaitInfo("test", EndpointInfo).Waiting == 1 }, time.Second, time.Millisecond)
+
+	// 缩短间隔后排队的请求按新配置发放
+	prl.SetPlatformBuckets("test", map[Endpoint]BucketConfig{EndpointInfo: {Interval: testInterval, Burst: 1}})
+	select {
+	case ok := <-done:
+		assert.True(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("queued request was not granted after config update")
+	}
 }
 
-func TestConfigSyncRateLimits(t *testing.T) {
-	// 这个测试需要配置系统的支持，暂时跳过具体实现
-	t.Skip("Config sync test requires full config system")
+func TestForceAccess(t *testing.T) {
+	prl := newBucketLimiter(1)
+	prl.ForceAccess("test", EndpointInfo)
+	info := prl.GetWaitInfo("test", EndpointInfo)
+	assert.Less(t, info.Tokens, 1.0)
+	assert.Greater(t, info.NextRequestInSec, 0.0)
+	assert.Len(t, prl.GetBucketStates(), 1)
 }
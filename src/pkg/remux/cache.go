@@ -0,0 +1,144 @@
+package remux
+
+import (
+	"container/list"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// defaultCacheEntries 内存中最多缓存的索引数量
+const defaultCacheEntries = 64
+
+// Cache 关键帧索引缓存：内存 LRU + 磁盘 JSON
+// 文件大小和修改时间不变时直接使用缓存；文件变长时在旧索引的基础上增量扫描
+type Cache struct {
+	dir string
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	max     int
+
+	// locks 同一文件的索引建立串行执行
+	locks sync.Map
+}
+
+type cacheEntry struct {
+	path string
+	idx  *Index
+}
+
+// NewCache 创建索引缓存，dir 为空时只使用内存缓存
+func NewCache(dir string) *Cache {
+	return &Cache{
+		dir:     dir,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		max:     defaultCacheEntries,
+	}
+}
+
+// Get 返回文件的关键帧索引，必要时建立或更新
+func (c *Cache) Get(path string) (*Index, error) {
+	mu, _ := c.locks.LoadOrStore(path, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	modTime := stat.ModTime().UnixNano()
+
+	prev := c.load(path)
+	if prev != nil && prev.Size == stat.Size() && prev.ModTime == modTime {
+		return prev, nil
+	}
+	if prev != nil && prev.Size > stat.Size() {
+		prev = nil // 文件被截断或替换，重新扫描
+	}
+	idx, err := BuildIndex(f, stat.Size(), prev)
+	if err != nil {
+		return nil, err
+	}
+	idx.ModTime = modTime
+	c.store(path, idx)
+	return idx, nil
+}
+
+// load 依次从内存和磁盘读取缓存的索引
+func (c *Cache) load(path string) *Index {
+	c.mu.Lock()
+	if e, ok := c.entries[path]; ok {
+		c.lru.MoveToFront(e)
+		c.mu.Unlock()
+		return e.Value.(*cacheEntry).idx
+	}
+	c.mu.Unlock()
+
+	if c.dir == "" {
+		return nil
+	}
+	b, err := os.ReadFile(c.file(path))
+	if err != nil {
+		return nil
+	}
+	idx := new(Index)
+	if err := json.Unmarshal(b, idx); err != nil || idx.Version != indexVersion {
+		return nil
+	}
+	return idx
+}
+
+// store 写入内存和磁盘缓存，磁盘写入失败不影响使用
+func (c *Cache) store(path string, idx *Index) {
+	c.mu.Lock()
+	if e, ok := c.entries[path]; ok {
+		e.Value.(*cacheEntry).idx = idx
+		c.lru.MoveToFront(e)
+	} else {
+		c.entries[path] = c.lru.PushFront(&cacheEntry{path: path, idx: idx})
+		for c.lru.Len() > c.max {
+			oldest := c.lru.Back()
+			c.lru.Remove(oldest)
+			delete(c.entries, oldest.Value.(*cacheEntry).path)
+		}
+	}
+	c.mu.Unlock()
+
+	if c.dir == "" {
+		return
+	}
+	b, err := json.Marshal(idx)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return
+	}
+	tmp := c.file(path) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, c.file(path)); err != nil {
+		os.Remove(tmp)
+	}
+}
+
+// file 缓存文件名使用路径的 sha1，避免路径中的特殊字符
+func (c *Cache) file(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	sum := sha1.Sum([]byte(path))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,249 @@
+package remux
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// 支持的编码
+const (
+	CodecAVC  = "avc"
+	CodecHEVC = "hevc"
+	CodecAAC  = "aac"
+)
+
+// ascInfo AudioSpecificConfig 中的基本信息
+type ascInfo struct {
+	objectType int
+	sampleRate int
+	channels   int
+}
+
+var aacSampleRates = []int{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+
+// parseASC 解析 AAC AudioSpecificConfig，无法解析时返回 44.1kHz 双声道
+func parseASC(asc []byte) ascInfo {
+	info := ascInfo{objectType: 2, sampleRate: 44100, channels: 2}
+	if len(asc) < 2 {
+		return info
+	}
+	info.objectType = int(asc[0] >> 3)
+	freqIndex := int(asc[0]&0x07)<<1 | int(asc[1]>>7)
+	if freqIndex < len(aacSampleRates) {
+		info.sampleRate = aacSampleRates[freqIndex]
+	}
+	if ch := int(asc[1]>>3) & 0x0f; ch > 0 {
+		info.channels = ch
+	}
+	return info
+}
+
+// codecString 返回 RFC 6381 编码字符串，用于 MSE 的 MIME 类型
+func (t *Track) codecString() string {
+	switch t.Codec {
+	case CodecAVC:
+		if len(t.Config) >= 4 {
+			return fmt.Sprintf("avc1.%02x%02x%02x", t.Config[1], t.Config[2], t.Config[3])
+		}
+		return "avc1.640028"
+	case CodecHEVC:
+		return hevcCodecString(t.Config)
+	case CodecAAC:
+		return fmt.Sprintf("mp4a.40.%d", parseASC(t.Config).objectType)
+	}
+	return ""
+}
+
+// hevcCodecString 按 ISO/IEC 14496-15 附录 E 从 hvcC 生成编码字符串，例如 hvc1.1.6.L120.90
+func hevcCodecString(hvcc []byte) string {
+	if len(hvcc) < 13 {
+		return "hvc1.1.6.L120.90"
+	}
+	space := []string{"", "A", "B", "C"}[hvcc[1]>>6]
+	tier := "L"
+	if hvcc[1]&0x20 != 0 {
+		tier = "H"
+	}
+	compat := bits.Reverse32(uint32(hvcc[2])<<24 | uint32(hvcc[3])<<16 | uint32(hvcc[4])<<8 | uint32(hvcc[5]))
+	s := fmt.Sprintf("hvc1.%s%d.%X.%s%d", space, hvcc[1]&0x1f, compat, tier, hvcc[12])
+	constraints := hvcc[6:12]
+	n := len(constraints)
+	for n > 0 && constraints[n-1] == 0 {
+		n--
+	}
+	for _, c := range constraints[:n] {
+		s += fmt.Sprintf(".%X", c)
+	}
+	return s
+}
+
+// MimeType 返回 MSE 可用的 MIME 类型
+func (idx *Index) MimeType() string {
+	var codecs []string
+	for _, t := range []*Track{idx.Video, idx.Audio} {
+		if t != nil {
+			codecs = append(codecs, t.codecString())
+		}
+	}
+	kind := "video"
+	if idx.Video == nil {
+		kind = "audio"
+	}
+	return fmt.Sprintf(`%s/mp4; codecs="%s"`, kind, strings.Join(codecs, ","))
+}
+
+// bitReader 读取 H.264 RBSP 的位读取器
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) bit() uint32 {
+	if r.pos >= len(r.data)*8 {
+		r.pos++
+		return 0
+	}
+	b := r.data[r.pos/8] >> (7 - r.pos%8) & 1
+	r.pos++
+	return uint32(b)
+}
+
+func (r *bitReader) bitsN(n int) uint32 {
+	var v uint32
+	for i := 0; i < n; i++ {
+		v = v<<1 | r.bit()
+	}
+	return v
+}
+
+// ue 无符号指数哥伦布码
+func (r *bitReader) ue() uint32 {
+	zeros := 0
+	for r.bit() == 0 {
+		zeros++
+		if zeros > 31 || r.pos > len(r.data)*8 {
+			return 0
+		}
+	}
+	return (1<<zeros - 1) + r.bitsN(zeros)
+}
+
+func (r *bitReader) se() int32 {
+	v := r.ue()
+	if v&1 == 1 {
+		return int32((v + 1) / 2)
+	}
+	return -int32(v / 2)
+}
+
+func (r *bitReader) overrun() bool {
+	return r.pos > len(r.data)*8
+}
+
+// unescapeRBSP 去除防竞争字节 00 00 03
+func unescapeRBSP(nal []byte) []byte {
+	out := make([]byte, 0, len(nal))
+	zeros := 0
+	for _, b := range nal {
+		if zeros >= 2 && b == 3 {
+			zeros = 0
+			continue
+		}
+		out = append(out, b)
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+	}
+	return out
+}
+
+// avcDimensions 从 avcC 中第一个 SPS 解析画面尺寸
+func avcDimensions(avcc []byte) (width, height int, ok bool) {
+	if len(avcc) < 8 || avcc[5]&0x1f == 0 {
+		return 0, 0, false
+	}
+	n := int(avcc[6])<<8 | int(avcc[7])
+	if n < 4 || len(avcc) < 8+n {
+		return 0, 0, false
+	}
+	r := &bitReader{data: unescapeRBSP(avcc[8+1 : 8+n])} // 跳过 NAL 头
+	profile := r.bitsN(8)
+	r.bitsN(16) // constraint flags + level
+	r.ue()      // seq_parameter_set_id
+	chroma := uint32(1)
+	switch profile {
+	case 100, 110, 122, 244, 44, 83, 86, 118, 128, 138, 139, 134, 135:
+		chroma = r.ue()
+		if chroma == 3 {
+			r.bit() // separate_colour_plane_flag
+		}
+		r.ue()  // bit_depth_luma_minus8
+		r.ue()  // bit_depth_chroma_minus8
+		r.bit() // qpprime_y_zero_transform_bypass_flag
+		if r.bit() == 1 {
+			count := 8
+			if chroma == 3 {
+				count = 12
+			}
+			for i := 0; i < count; i++ {
+				if r.bit() == 0 {
+					continue
+				}
+				size := 16
+				if i >= 6 {
+					size = 64
+				}
+				last, next := int32(8), int32(8)
+				for j := 0; j < size; j++ {
+					if next != 0 {
+						next = (last + r.se() + 256) % 256
+					}
+					if next != 0 {
+						last = next
+					}
+				}
+			}
+		}
+	}
+	r.ue() // log2_max_frame_num_minus4
+	switch r.ue() {
+	case 0:
+		r.ue()
+	case 1:
+		r.bit()
+		r.se()
+		r.se()
+		for n := r.ue(); n > 0; n-- {
+			r.se()
+		}
+	}
+	r.ue()  // max_num_ref_frames
+	r.bit() // gaps_in_frame_num_value_allowed_flag
+	widthMbs := int(r.ue()) + 1
+	heightMaps := int(r.ue()) + 1
+	frameMbsOnly := int(r.bit())
+	if frameMbsOnly == 0 {
+		r.bit() // mb_adaptive_frame_field_flag
+	}
+	r.bit() // direct_8x8_inference_flag
+	var cropLeft, cropRight, cropTop, cropBottom int
+	if r.bit() == 1 {
+		cropLeft, cropRight, cropTop, cropBottom = int(r.ue()), int(r.ue()), int(r.ue()), int(r.ue())
+	}
+	if r.overrun() {
+		return 0, 0, false
+	}
+	cropX, cropY := 1, 2-frameMbsOnly
+	switch chroma {
+	case 1:
+		cropX, cropY = 2, 2*(2-frameMbsOnly)
+	case 2:
+		cropX = 2
+	}
+	width = widthMbs*16 - (cropLeft+cropRight)*cropX
+	height = (2-frameMbsOnly)*heightMaps*16 - (cropTop+cropBottom)*cropY
+	return width, height, width > 0 && height > 0
+}
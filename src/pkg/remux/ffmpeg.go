@@ -0,0 +1,50 @@
+package remux
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+)
+
+// FFmpeg 使用 ffmpeg 将非 FLV 文件（ts / mkv 等）无损转封装为分段 MP4 写入 w
+// start 为起始时间（秒），ffmpeg 在 -c copy 时会从之前最近的关键帧开始
+func FFmpeg(ctx context.Context, ffmpegPath, input string, start float64, w io.Writer) error {
+	args := []string{"-hide_banner", "-loglevel", "error", "-nostdin"}
+	if start > 0 {
+		args = append(args, "-ss", strconv.FormatFloat(start, 'f', 3, 64))
+	}
+	args = append(args,
+		"-i", input,
+		"-map", "0:v:0?", "-map", "0:a:0?",
+		"-c", "copy",
+		"-movflags", "frag_keyframe+empty_moov+default_base_moof",
+		"-f", "mp4", "pipe:1",
+	)
+	cmd := exec.CommandContext(ctx, ffmpegPath, args...)
+	cmd.Stdout = w
+	stderr := &tailBuffer{max: 4 << 10}
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("ffmpeg remux failed: %w: %s", err, stderr.buf)
+	}
+	return nil
+}
+
+// tailBuffer 只保留最后 max 字节的输出
+type tailBuffer struct {
+	buf []byte
+	max int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.max {
+		b.buf = b.buf[len(b.buf)-b.max:]
+	}
+	return len(p), nil
+}
@@ -0,0 +1,184 @@
+package remux
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/bililive-go/bililive-go/src/pkg/rtmp"
+)
+
+const (
+	flvTagHeaderSize = 11
+
+	tagAudio  = 8
+	tagVideo  = 9
+	tagScript = 18
+
+	// FLV 视频编码 ID
+	flvCodecAVC  = 7
+	flvCodecHEVC = 12
+	// FLV 音频格式
+	flvSoundAAC = 10
+
+	// Enhanced RTMP 扩展视频头中的包类型
+	exPacketSequenceStart = 0
+	exPacketCodedFrames   = 1
+	exPacketCodedFramesX  = 3
+)
+
+// ErrNotFLV 文件不是 FLV 格式
+var ErrNotFLV = errors.New("not a flv file")
+
+// tagHeader FLV tag 头
+type tagHeader struct {
+	typ       uint8
+	size      int
+	timestamp int64
+}
+
+func parseTagHeader(h []byte) tagHeader {
+	return tagHeader{
+		typ:       h[0] & 0x1f,
+		size:      int(h[1])<<16 | int(h[2])<<8 | int(h[3]),
+		timestamp: int64(uint32(h[7])<<24 | uint32(h[4])<<16 | uint32(h[5])<<8 | uint32(h[6])),
+	}
+}
+
+// videoPacket 解析后的视频 tag
+type videoPacket struct {
+	codec    string
+	header   bool // 序列头（avcC / hvcC）
+	keyframe bool
+	cts      int32
+	data     []byte // 序列头时为配置记录，否则为 AVCC/HVCC 格式的 NALU
+}
+
+// parseVideo 解析视频 tag，支持传统 FLV 的 H.264 / H.265 和 Enhanced RTMP 的 avc1 / hvc1
+// 不支持的编码返回 nil
+func parseVideo(p []byte) *videoPacket {
+	if len(p) < 5 {
+		return nil
+	}
+	if p[0]&0x80 != 0 {
+		// Enhanced RTMP: [IsExHeader:1][FrameType:3][PacketType:4][FourCC:32]
+		pkt := &videoPacket{keyframe: (p[0]>>4)&0x07 == 1}
+		switch string(p[1:5]) {
+		case "avc1":
+			pkt.codec = CodecAVC
+		case "hvc1":
+			pkt.codec = CodecHEVC
+		default:
+			return nil
+		}
+		switch p[0] & 0x0f {
+		case exPacketSequenceStart:
+			pkt.header = true
+			pkt.data = p[5:]
+		case exPacketCodedFrames:
+			if len(p) < 8 {
+				return nil
+			}
+			pkt.cts = int32(uint32(p[5])<<16|uint32(p[6])<<8|uint32(p[7])) << 8 >> 8
+			pkt.data = p[8:]
+		case exPacketCodedFramesX:
+			pkt.data = p[5:]
+		default:
+			return nil
+		}
+		return pkt
+	}
+	pkt := &videoPacket{keyframe: p[0]>>4 == 1}
+	switch p[0] & 0x0f {
+	case flvCodecAVC:
+		pkt.codec = CodecAVC
+	case flvCodecHEVC:
+		pkt.codec = CodecHEVC
+	default:
+		return nil
+	}
+	switch p[1] {
+	case 0:
+		pkt.header = true
+	case 1:
+	default:
+		return nil // end of sequence
+	}
+	// 24 位有符号的 composition time
+	pkt.cts = int32(uint32(p[2])<<16|uint32(p[3])<<8|uint32(p[4])) << 8 >> 8
+	pkt.data = p[5:]
+	return pkt
+}
+
+// audioPacket 解析后的 AAC tag
+type audioPacket struct {
+	header bool
+	data   []byte
+}
+
+// parseAudio 解析音频 tag，只支持 AAC
+func parseAudio(p []byte) *audioPacket {
+	if len(p) < 2 || p[0]>>4 != flvSoundAAC {
+		return nil
+	}
+	return &audioPacket{header: p[1] == 0, data: p[2:]}
+}
+
+// parseMetadata 从 onMetaData 读取画面尺寸
+func parseMetadata(p []byte) (width, height int) {
+	values, err := rtmp.DecodeAMF0(p)
+	if err != nil {
+		return 0, 0
+	}
+	for _, v := range values {
+		obj, ok := v.(rtmp.Object)
+		if !ok {
+			continue
+		}
+		w, _ := obj["width"].(float64)
+		h, _ := obj["height"].(float64)
+		return int(w), int(h)
+	}
+	return 0, 0
+}
+
+// dataOffset 校验 FLV 文件头，返回第一个 tag 的偏移
+func dataOffset(r io.ReaderAt) (int64, error) {
+	var h [9]byte
+	if _, err := r.ReadAt(h[:], 0); err != nil {
+		return 0, ErrNotFLV
+	}
+	if string(h[:3]) != "FLV" {
+		return 0, ErrNotFLV
+	}
+	return int64(binary.BigEndian.Uint32(h[5:9])) + 4, nil
+}
+
+// tagReader 顺序读取 FLV tag
+type tagReader struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+func newTagReader(r io.Reader) *tagReader {
+	return &tagReader{r: bufio.NewReaderSize(r, 256<<10)}
+}
+
+// next 读取下一个 tag，返回的 payload 在下次调用前有效
+// 文件末尾不完整的 tag（例如仍在录制）视为 io.EOF
+func (t *tagReader) next() (tagHeader, []byte, error) {
+	var h [flvTagHeaderSize]byte
+	if _, err := io.ReadFull(t.r, h[:]); err != nil {
+		return tagHeader{}, nil, io.EOF
+	}
+	th := parseTagHeader(h[:])
+	if cap(t.buf) < th.size+4 {
+		t.buf = make([]byte, th.size+4)
+	}
+	t.buf = t.buf[:th.size+4]
+	if _, err := io.ReadFull(t.r, t.buf); err != nil {
+		return tagHeader{}, nil, io.EOF
+	}
+	return th, t.buf[:th.size], nil
+}
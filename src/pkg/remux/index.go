@@ -0,0 +1,223 @@
+package remux
+
+import (
+	"errors"
+	"io"
+	"sort"
+)
+
+const (
+	indexVersion = 1
+
+	// audioSyncInterval 纯音频文件每隔多久记录一个可跳转位置（毫秒）
+	audioSyncInterval = 1000
+)
+
+// Track 轨道信息
+type Track struct {
+	Codec  string `json:"codec"`
+	Config []byte `json:"config"` // avcC / hvcC 配置记录或 AAC AudioSpecificConfig
+}
+
+// Keyframe 可跳转的位置：视频关键帧，纯音频文件为固定间隔的音频帧
+type Keyframe struct {
+	Time   int64 `json:"t"` // 时间戳（毫秒）
+	Offset int64 `json:"o"` // tag 在文件中的偏移
+}
+
+// Index FLV 文件的关键帧索引
+type Index struct {
+	Version   int        `json:"version"`
+	Size      int64      `json:"size"`     // 建立索引时的文件大小
+	ModTime   int64      `json:"mod_time"` // 建立索引时的修改时间（UnixNano）
+	Start     int64      `json:"start"`    // 第一个音视频 tag 的时间戳
+	End       int64      `json:"end"`      // 最后一个音视频 tag 的时间戳
+	Width     int        `json:"width"`
+	Height    int        `json:"height"`
+	Video     *Track     `json:"video,omitempty"`
+	Audio     *Track     `json:"audio,omitempty"`
+	Keyframes []Keyframe `json:"keyframes"`
+	// ScanEnd 下一个未扫描 tag 的偏移，文件增长后从这里继续扫描
+	ScanEnd int64 `json:"scan_end"`
+}
+
+// Duration 时长（毫秒）
+func (idx *Index) Duration() int64 {
+	return idx.End - idx.Start
+}
+
+func (idx *Index) trackIDs() []uint32 {
+	var ids []uint32
+	if idx.Video != nil {
+		ids = append(ids, videoTrackID)
+	}
+	if idx.Audio != nil {
+		ids = append(ids, audioTrackID)
+	}
+	return ids
+}
+
+// KeyframeAt 返回 offset（毫秒，相对文件开头）处或之前最近的可跳转位置
+func (idx *Index) KeyframeAt(offset int64) Keyframe {
+	t := idx.Start + offset
+	i := sort.Search(len(idx.Keyframes), func(i int) bool { return idx.Keyframes[i].Time > t })
+	if i == 0 {
+		return idx.Keyframes[0]
+	}
+	return idx.Keyframes[i-1]
+}
+
+// BuildIndex 扫描 FLV 文件建立关键帧索引，只读取 tag 头和必要的负载
+// prev 不为空且文件只是变长时（例如仍在录制），从上次扫描结束的位置继续
+func BuildIndex(r io.ReaderAt, size int64, prev *Index) (*Index, error) {
+	idx := &Index{Version: indexVersion, Start: -1}
+	if prev != nil && prev.Version == indexVersion && prev.ScanEnd > 0 && prev.ScanEnd <= size && prev.Size <= size {
+		cp := *prev
+		cp.Keyframes = append([]Keyframe(nil), prev.Keyframes...)
+		idx = &cp
+	} else {
+		off, err := dataOffset(r)
+		if err != nil {
+			return nil, err
+		}
+		idx.ScanEnd = off
+	}
+
+	cr := &chunkReader{r: r, size: size}
+	lastAudioSync := int64(-1)
+	if n := len(idx.Keyframes); n > 0 && idx.Video == nil {
+		lastAudioSync = idx.Keyframes[n-1].Time
+	}
+	var h [flvTagHeaderSize]byte
+	for off := idx.ScanEnd; off+flvTagHeaderSize <= size; {
+		if err := cr.readAt(h[:], off); err != nil {
+			break
+		}
+		th := parseTagHeader(h[:])
+		next := off + flvTagHeaderSize + int64(th.size) + 4
+		if next > size {
+			break // 不完整的 tag
+		}
+		payloadOff := off + flvTagHeaderSize
+
+		switch th.typ {
+		case tagScript:
+			if idx.Width == 0 && th.size < 64<<10 {
+				p := make([]byte, th.size)
+				if cr.readAt(p, payloadOff) == nil {
+					idx.Width, idx.Height = parseMetadata(p)
+				}
+			}
+		case tagVideo:
+			head := make([]byte, min(th.size, 8))
+			if cr.readAt(head, payloadOff) != nil {
+				break
+			}
+			pkt := parseVideo(head)
+			if pkt == nil {
+				break
+			}
+			if pkt.header {
+				if idx.Video == nil {
+					p := make([]byte, th.size)
+					if cr.readAt(p, payloadOff) == nil {
+						if full := parseVideo(p); full != nil {
+							idx.Video = &Track{Codec: full.codec, Config: append([]byte(nil), full.data...)}
+							// 之前记录的音频跳转点作废，改用视频关键帧
+							idx.Keyframes = idx.Keyframes[:0]
+						}
+					}
+				}
+				break
+			}
+			idx.observe(th.timestamp)
+			if pkt.keyframe && idx.Video != nil {
+				idx.Keyframes = append(idx.Keyframes, Keyframe{Time: th.timestamp, Offset: off})
+			}
+		case tagAudio:
+			head := make([]byte, min(th.size, 2))
+			if cr.readAt(head, payloadOff) != nil {
+				break
+			}
+			pkt := parseAudio(head)
+			if pkt == nil {
+				break
+			}
+			if pkt.header {
+				if idx.Audio == nil {
+					p := make([]byte, th.size)
+					if cr.readAt(p, payloadOff) == nil {
+						idx.Audio = &Track{Codec: CodecAAC, Config: append([]byte(nil), p[2:]...)}
+					}
+				}
+				break
+			}
+			idx.observe(th.timestamp)
+			if idx.Video == nil && (lastAudioSync < 0 || th.timestamp-lastAudioSync >= audioSyncInterval) {
+				idx.Keyframes = append(idx.Keyframes, Keyframe{Time: th.timestamp, Offset: off})
+				lastAudioSync = th.timestamp
+			}
+		}
+		off = next
+		idx.ScanEnd = off
+	}
+	idx.Size = size
+
+	if idx.Video == nil && idx.Audio == nil {
+		return nil, errors.New("no supported audio or video stream (H.264/H.265/AAC)")
+	}
+	if len(idx.Keyframes) == 0 {
+		return nil, errors.New("no keyframe found")
+	}
+	if idx.Video != nil && idx.Video.Codec == CodecAVC && (idx.Width == 0 || idx.Height == 0) {
+		if w, h, ok := avcDimensions(idx.Video.Config); ok {
+			idx.Width, idx.Height = w, h
+		}
+	}
+	return idx, nil
+}
+
+// observe 记录音视频时间戳范围
+func (idx *Index) observe(ts int64) {
+	if idx.Start < 0 || ts < idx.Start {
+		idx.Start = ts
+	}
+	if ts > idx.End {
+		idx.End = ts
+	}
+}
+
+// chunkReader 按 64KB 块缓存的 ReaderAt，扫描大量小 tag 时减少系统调用
+type chunkReader struct {
+	r     io.ReaderAt
+	size  int64
+	start int64
+	buf   []byte
+}
+
+const chunkSize = 64 << 10
+
+func (c *chunkReader) readAt(p []byte, off int64) error {
+	if off+int64(len(p)) > c.size {
+		return io.ErrUnexpectedEOF
+	}
+	// 大块数据直接读取
+	if len(p) > chunkSize/2 {
+		_, err := c.r.ReadAt(p, off)
+		return err
+	}
+	if off < c.start || off+int64(len(p)) > c.start+int64(len(c.buf)) {
+		n := min(int64(chunkSize), c.size-off)
+		if cap(c.buf) < chunkSize {
+			c.buf = make([]byte, chunkSize)
+		}
+		c.buf = c.buf[:n]
+		if _, err := c.r.ReadAt(c.buf, off); err != nil && !errors.Is(err, io.EOF) {
+			c.buf = c.buf[:0]
+			return err
+		}
+		c.start = off
+	}
+	copy(p, c.buf[off-c.start:])
+	return nil
+}
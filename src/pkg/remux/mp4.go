@@ -0,0 +1,331 @@
+package remux
+
+import (
+	"encoding/binary"
+)
+
+// timescale 音视频轨道统一使用毫秒时间刻度，与 FLV 时间戳一致，不引入换算误差
+const timescale = 1000
+
+// 轨道 ID
+const (
+	videoTrackID = 1
+	audioTrackID = 2
+)
+
+// trun 中的 sample flags
+const (
+	sampleFlagsSync    = 0x02000000 // sample_depends_on=2，不依赖其他帧
+	sampleFlagsNonSync = 0x01010000 // sample_depends_on=1，sample_is_non_sync_sample=1
+)
+
+// box 追加式的 ISO BMFF box 构造器
+type box struct {
+	buf []byte
+}
+
+// start 开始一个 box，返回用于 end 的位置
+func (b *box) start(typ string) int {
+	pos := len(b.buf)
+	b.buf = append(b.buf, 0, 0, 0, 0)
+	b.buf = append(b.buf, typ...)
+	return pos
+}
+
+// startFull 开始一个带 version 和 flags 的 full box
+func (b *box) startFull(typ string, version uint8, flags uint32) int {
+	pos := b.start(typ)
+	b.u32(uint32(version)<<24 | flags&0xffffff)
+	return pos
+}
+
+func (b *box) end(pos int) {
+	binary.BigEndian.PutUint32(b.buf[pos:], uint32(len(b.buf)-pos))
+}
+
+func (b *box) u8(v uint8)     { b.buf = append(b.buf, v) }
+func (b *box) u16(v uint16)   { b.buf = binary.BigEndian.AppendUint16(b.buf, v) }
+func (b *box) u32(v uint32)   { b.buf = binary.BigEndian.AppendUint32(b.buf, v) }
+func (b *box) u64(v uint64)   { b.buf = binary.BigEndian.AppendUint64(b.buf, v) }
+func (b *box) bytes(v []byte) { b.buf = append(b.buf, v...) }
+func (b *box) zeros(n int)    { b.buf = append(b.buf, make([]byte, n)...) }
+func (b *box) str(s string)   { b.buf = append(b.buf, s...) }
+func (b *box) matrix() {
+	b.u32(0x00010000)
+	b.zeros(12)
+	b.u32(0x00010000)
+	b.zeros(12)
+	b.u32(0x40000000)
+}
+func (b *box) leaf(typ string, payload func()) {
+	pos := b.start(typ)
+	payload()
+	b.end(pos)
+}
+
+// initSegment 生成 ftyp + moov 初始化分段
+func initSegment(idx *Index) []byte {
+	b := &box{}
+	pos := b.start("ftyp")
+	b.str("isom")
+	b.u32(0x200)
+	b.str("isomiso5iso6mp41")
+	b.end(pos)
+
+	moov := b.start("moov")
+	mvhd := b.startFull("mvhd", 0, 0)
+	b.zeros(8) // creation / modification time
+	b.u32(timescale)
+	b.u32(0) // duration，分段模式下未知
+	b.u32(0x00010000)
+	b.u16(0x0100)
+	b.zeros(10)
+	b.matrix()
+	b.zeros(24)
+	b.u32(audioTrackID + 1)
+	b.end(mvhd)
+
+	if idx.Video != nil {
+		writeTrak(b, idx, idx.Video, videoTrackID)
+	}
+	if idx.Audio != nil {
+		writeTrak(b, idx, idx.Audio, audioTrackID)
+	}
+
+	mvex := b.start("mvex")
+	for _, id := range idx.trackIDs() {
+		trex := b.startFull("trex", 0, 0)
+		b.u32(id)
+		b.u32(1) // default_sample_description_index
+		b.zeros(12)
+		b.end(trex)
+	}
+	b.end(mvex)
+	b.end(moov)
+	return b.buf
+}
+
+func writeTrak(b *box, idx *Index, t *Track, trackID uint32) {
+	isVideo := trackID == videoTrackID
+	trak := b.start("trak")
+
+	tkhd := b.startFull("tkhd", 0, 3) // enabled | in_movie
+	b.zeros(8)
+	b.u32(trackID)
+	b.zeros(4)
+	b.u32(0) // duration
+	b.zeros(8)
+	b.u16(0) // layer
+	b.u16(0) // alternate_group
+	if isVideo {
+		b.u16(0)
+	} else {
+		b.u16(0x0100)
+	}
+	b.zeros(2)
+	b.matrix()
+	if isVideo {
+		b.u32(uint32(idx.Width) << 16)
+		b.u32(uint32(idx.Height) << 16)
+	} else {
+		b.zeros(8)
+	}
+	b.end(tkhd)
+
+	mdia := b.start("mdia")
+	mdhd := b.startFull("mdhd", 0, 0)
+	b.zeros(8)
+	b.u32(timescale)
+	b.u32(0)
+	b.u16(0x55c4) // und
+	b.u16(0)
+	b.end(mdhd)
+
+	hdlr := b.startFull("hdlr", 0, 0)
+	b.u32(0)
+	if isVideo {
+		b.str("vide")
+		b.zeros(12)
+		b.str("VideoHandler\x00")
+	} else {
+		b.str("soun")
+		b.zeros(12)
+		b.str("SoundHandler\x00")
+	}
+	b.end(hdlr)
+
+	minf := b.start("minf")
+	if isVideo {
+		vmhd := b.startFull("vmhd", 0, 1)
+		b.zeros(8)
+		b.end(vmhd)
+	} else {
+		smhd := b.startFull("smhd", 0, 0)
+		b.zeros(4)
+		b.end(smhd)
+	}
+	dinf := b.start("dinf")
+	dref := b.startFull("dref", 0, 0)
+	b.u32(1)
+	url := b.startFull("url ", 0, 1) // 数据在同一文件中
+	b.end(url)
+	b.end(dref)
+	b.end(dinf)
+
+	stbl := b.start("stbl")
+	stsd := b.startFull("stsd", 0, 0)
+	b.u32(1)
+	if isVideo {
+		writeVisualSampleEntry(b, idx, t)
+	} else {
+		writeAudioSampleEntry(b, t)
+	}
+	b.end(stsd)
+	for _, typ := range []string{"stts", "stsc", "stco"} {
+		p := b.startFull(typ, 0, 0)
+		b.u32(0)
+		b.end(p)
+	}
+	stsz := b.startFull("stsz", 0, 0)
+	b.zeros(8)
+	b.end(stsz)
+	b.end(stbl)
+	b.end(minf)
+	b.end(mdia)
+	b.end(trak)
+}
+
+func writeVisualSampleEntry(b *box, idx *Index, t *Track) {
+	entry, config := "avc1", "avcC"
+	if t.Codec == CodecHEVC {
+		entry, config = "hvc1", "hvcC"
+	}
+	pos := b.start(entry)
+	b.zeros(6)
+	b.u16(1) // data_reference_index
+	b.zeros(16)
+	b.u16(uint16(idx.Width))
+	b.u16(uint16(idx.Height))
+	b.u32(0x00480000) // 72 dpi
+	b.u32(0x00480000)
+	b.zeros(4)
+	b.u16(1) // frame_count
+	b.zeros(32)
+	b.u16(0x0018)
+	b.u16(0xffff)
+	b.leaf(config, func() { b.bytes(t.Config) })
+	b.end(pos)
+}
+
+func writeAudioSampleEntry(b *box, t *Track) {
+	asc := parseASC(t.Config)
+	pos := b.start("mp4a")
+	b.zeros(6)
+	b.u16(1)
+	b.zeros(8)
+	b.u16(uint16(asc.channels))
+	b.u16(16)
+	b.zeros(4)
+	b.u32(uint32(min(asc.sampleRate, 0xffff)) << 16)
+
+	esds := b.startFull("esds", 0, 0)
+	// ES_Descriptor > DecoderConfigDescriptor > DecoderSpecificInfo，以及 SLConfigDescriptor
+	dsi := descriptor(0x05, t.Config)
+	dcd := descriptor(0x04, append([]byte{0x40, 0x15, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, dsi...))
+	sl := descriptor(0x06, []byte{0x02})
+	b.bytes(descriptor(0x03, append(append([]byte{0, 0, 0}, dcd...), sl...)))
+	b.end(esds)
+	b.end(pos)
+}
+
+// descriptor MPEG-4 描述符，长度固定使用 4 字节编码
+func descriptor(tag byte, payload []byte) []byte {
+	n := len(payload)
+	out := []byte{tag, byte(n>>21) | 0x80, byte(n>>14) | 0x80, byte(n>>7) | 0x80, byte(n) & 0x7f}
+	return append(out, payload...)
+}
+
+// sample 一个音频或视频帧
+type sample struct {
+	dts      int64 // 解码时间（毫秒，已减去起点）
+	cts      int32 // 显示时间偏移
+	keyframe bool
+	data     []byte
+	duration uint32
+}
+
+// fragmentWriter 将样本写为 moof + mdat 分段
+type fragmentWriter struct {
+	seq uint32
+}
+
+// fragment 生成一个分段，每个轨道一个 traf，样本数据依次放在同一个 mdat 中
+func (f *fragmentWriter) fragment(video, audio []sample) []byte {
+	f.seq++
+	b := &box{}
+	moof := b.start("moof")
+	mfhd := b.startFull("mfhd", 0, 0)
+	b.u32(f.seq)
+	b.end(mfhd)
+
+	type patch struct {
+		pos    int
+		offset int
+	}
+	var patches []patch
+	dataLen := 0
+	for _, tr := range []struct {
+		id      uint32
+		samples []sample
+	}{{videoTrackID, video}, {audioTrackID, audio}} {
+		if len(tr.samples) == 0 {
+			continue
+		}
+		traf := b.start("traf")
+		tfhd := b.startFull("tfhd", 0, 0x020000) // default-base-is-moof
+		b.u32(tr.id)
+		b.end(tfhd)
+		tfdt := b.startFull("tfdt", 1, 0)
+		b.u64(uint64(tr.samples[0].dts))
+		b.end(tfdt)
+
+		isVideo := tr.id == videoTrackID
+		flags := uint32(0x000001 | 0x000100 | 0x000200 | 0x000400) // data offset, duration, size, flags
+		if isVideo {
+			flags |= 0x000800 // composition time offset
+		}
+		trun := b.startFull("trun", 1, flags)
+		b.u32(uint32(len(tr.samples)))
+		patches = append(patches, patch{pos: len(b.buf), offset: dataLen})
+		b.u32(0)
+		for _, s := range tr.samples {
+			b.u32(s.duration)
+			b.u32(uint32(len(s.data)))
+			if s.keyframe || !isVideo {
+				b.u32(sampleFlagsSync)
+			} else {
+				b.u32(sampleFlagsNonSync)
+			}
+			if isVideo {
+				b.u32(uint32(s.cts))
+			}
+			dataLen += len(s.data)
+		}
+		b.end(trun)
+		b.end(traf)
+	}
+	b.end(moof)
+
+	moofSize := len(b.buf)
+	for _, p := range patches {
+		binary.BigEndian.PutUint32(b.buf[p.pos:], uint32(moofSize+8+p.offset))
+	}
+	b.u32(uint32(8 + dataLen))
+	b.str("mdat")
+	for _, samples := range [][]sample{video, audio} {
+		for _, s := range samples {
+			b.bytes(s.data)
+		}
+	}
+	return b.buf
+}
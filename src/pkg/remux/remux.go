@@ -0,0 +1,136 @@
+// Package remux 将录制的 FLV 文件无损转封装为分段 MP4（fMP4），供浏览器直接播放
+// 使用纯 Go 的 FLV 解析和 MP4 封装，不需要为每个请求启动 ffmpeg；
+// 通过关键帧索引按时间跳转，索引在内存和 app data 中缓存，仍在录制的文件会增量更新索引
+package remux
+
+import (
+	"context"
+	"io"
+)
+
+const (
+	// maxFragmentBytes 单个分段的数据上限，GOP 过长时提前输出，避免占用过多内存
+	maxFragmentBytes = 16 << 20
+	// audioFragmentDuration 纯音频文件每个分段的时长（毫秒）
+	audioFragmentDuration = 1000
+	// defaultVideoDuration / defaultAudioDuration 无法推算时最后一帧使用的时长（毫秒）
+	defaultVideoDuration = 33
+	defaultAudioDuration = 23
+)
+
+// InitSegment 返回 ftyp + moov 初始化分段，可用于 MSE 的 SourceBuffer
+func InitSegment(idx *Index) []byte {
+	return initSegment(idx)
+}
+
+// Remux 从可跳转位置 kf 开始将 FLV 转封装为分段 MP4 写入 w，时间轴以 kf 为零点
+// 读到文件末尾时返回 nil
+func Remux(ctx context.Context, w io.Writer, r io.ReadSeeker, idx *Index, kf Keyframe) error {
+	if _, err := w.Write(initSegment(idx)); err != nil {
+		return err
+	}
+	if _, err := r.Seek(kf.Offset, io.SeekStart); err != nil {
+		return err
+	}
+	m := &muxer{w: w, idx: idx, base: kf.Time}
+	tr := newTagReader(r)
+	for {
+		th, p, err := tr.next()
+		if err != nil {
+			return m.flush(-1)
+		}
+		dts := th.timestamp - m.base
+		if dts < 0 {
+			continue
+		}
+		switch th.typ {
+		case tagVideo:
+			if idx.Video == nil {
+				continue
+			}
+			pkt := parseVideo(p)
+			if pkt == nil || pkt.header || pkt.codec != idx.Video.Codec {
+				continue
+			}
+			if pkt.keyframe && len(m.video) > 0 || m.pending > maxFragmentBytes {
+				if err := m.flush(dts); err != nil {
+					return err
+				}
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+			m.add(&m.video, sample{dts: dts, cts: pkt.cts, keyframe: pkt.keyframe, data: append([]byte(nil), pkt.data...)})
+		case tagAudio:
+			if idx.Audio == nil {
+				continue
+			}
+			pkt := parseAudio(p)
+			if pkt == nil || pkt.header {
+				continue
+			}
+			if idx.Video == nil && len(m.audio) > 0 && dts-m.audio[0].dts >= audioFragmentDuration {
+				if err := m.flush(-1); err != nil {
+					return err
+				}
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+			m.add(&m.audio, sample{dts: dts, keyframe: true, data: append([]byte(nil), pkt.data...)})
+		}
+	}
+}
+
+// muxer 缓存一个分段的样本
+type muxer struct {
+	w       io.Writer
+	idx     *Index
+	base    int64
+	fw      fragmentWriter
+	video   []sample
+	audio   []sample
+	pending int
+	// 上一个分段最后一帧的时长，用于推算无法确定时长的最后一帧
+	lastVideoDur uint32
+	lastAudioDur uint32
+}
+
+func (m *muxer) add(samples *[]sample, s sample) {
+	*samples = append(*samples, s)
+	m.pending += len(s.data)
+}
+
+// flush 输出缓存的样本，nextVideoDTS 为下一分段第一个视频帧的时间，未知时为 -1
+func (m *muxer) flush(nextVideoDTS int64) error {
+	if len(m.video) == 0 && len(m.audio) == 0 {
+		return nil
+	}
+	m.lastVideoDur = setDurations(m.video, nextVideoDTS, m.lastVideoDur, defaultVideoDuration)
+	m.lastAudioDur = setDurations(m.audio, -1, m.lastAudioDur, defaultAudioDuration)
+	_, err := m.w.Write(m.fw.fragment(m.video, m.audio))
+	m.video, m.audio, m.pending = m.video[:0], m.audio[:0], 0
+	return err
+}
+
+// setDurations 由相邻帧的时间差计算每帧时长，返回最后一帧的时长
+func setDurations(samples []sample, next int64, last, fallback uint32) uint32 {
+	if len(samples) == 0 {
+		return last
+	}
+	for i := 0; i < len(samples)-1; i++ {
+		samples[i].duration = uint32(max(samples[i+1].dts-samples[i].dts, 0))
+	}
+	n := len(samples) - 1
+	switch {
+	case next > samples[n].dts:
+		samples[n].duration = uint32(next - samples[n].dts)
+	case n > 0:
+		samples[n].duration = samples[n-1].duration
+	case last > 0:
+		samples[n].duration = last
+	default:
+		samples[n].duration = fallback
+	}
+	return samples[n].duration
+}
@@ -0,0 +1,213 @@
+package remux
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// bitWriter 测试中用于构造 SPS
+type bitWriter struct {
+	buf  []byte
+	nbit int
+}
+
+func (w *bitWriter) bits(v uint32, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if w.nbit%8 == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		if v>>i&1 == 1 {
+			w.buf[len(w.buf)-1] |= 1 << (7 - w.nbit%8)
+		}
+		w.nbit++
+	}
+}
+
+func (w *bitWriter) ue(v uint32) {
+	v++
+	n := 0
+	for t := v; t > 1; t >>= 1 {
+		n++
+	}
+	w.bits(0, n)
+	w.bits(v, n+1)
+}
+
+// testAVCC 构造 1280x720 baseline profile 的 avcC
+func testAVCC() []byte {
+	w := &bitWriter{}
+	w.bits(66, 8) // profile_idc
+	w.bits(0, 8)
+	w.bits(31, 8) // level_idc
+	w.ue(0)       // seq_parameter_set_id
+	w.ue(0)       // log2_max_frame_num_minus4
+	w.ue(2)       // pic_order_cnt_type
+	w.ue(1)       // max_num_ref_frames
+	w.bits(0, 1)
+	w.ue(79) // pic_width_in_mbs_minus1
+	w.ue(44) // pic_height_in_map_units_minus1
+	w.bits(1, 1)
+	w.bits(1, 1)
+	w.bits(0, 1) // frame_cropping_flag
+	w.bits(0, 1) // vui_parameters_present_flag
+	w.bits(1, 1) // rbsp_stop_one_bit
+	sps := append([]byte{0x67}, w.buf...)
+	pps := []byte{0x68, 0xce, 0x38, 0x80}
+	avcc := []byte{1, 66, 0, 31, 0xff, 0xe1}
+	avcc = binary.BigEndian.AppendUint16(avcc, uint16(len(sps)))
+	avcc = append(avcc, sps...)
+	avcc = append(avcc, 1)
+	avcc = binary.BigEndian.AppendUint16(avcc, uint16(len(pps)))
+	return append(avcc, pps...)
+}
+
+func appendTag(buf []byte, typ uint8, ts uint32, payload []byte) []byte {
+	n := len(payload)
+	buf = append(buf, typ, byte(n>>16), byte(n>>8), byte(n), byte(ts>>16), byte(ts>>8), byte(ts), byte(ts>>24), 0, 0, 0)
+	buf = append(buf, payload...)
+	return binary.BigEndian.AppendUint32(buf, uint32(11+n))
+}
+
+// buildFLV 构造 3 个 GOP（每 2 秒一个关键帧）、25fps 视频和 AAC 音频的 FLV
+func buildFLV() []byte {
+	buf := []byte{'F', 'L', 'V', 1, 5, 0, 0, 0, 9, 0, 0, 0, 0}
+	buf = appendTag(buf, tagVideo, 0, append([]byte{0x17, 0, 0, 0, 0}, testAVCC()...))
+	buf = appendTag(buf, tagAudio, 0, []byte{0xaf, 0, 0x12, 0x10})
+	for ts := uint32(0); ts < 6000; ts += 40 {
+		frame := []byte{0x27, 1, 0, 0, 40, 0, 0, 0, 2, 0x41, 0x9a}
+		if ts%2000 == 0 {
+			frame = []byte{0x17, 1, 0, 0, 0, 0, 0, 0, 2, 0x65, 0x88}
+		}
+		buf = appendTag(buf, tagVideo, ts, frame)
+		buf = appendTag(buf, tagAudio, ts+10, []byte{0xaf, 1, 0x21, 0x10})
+	}
+	return buf
+}
+
+func TestBuildIndex(t *testing.T) {
+	data := buildFLV()
+	idx, err := BuildIndex(bytes.NewReader(data), int64(len(data)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.Video == nil || idx.Video.Codec != CodecAVC || idx.Audio == nil {
+		t.Fatalf("unexpected tracks: %+v %+v", idx.Video, idx.Audio)
+	}
+	if idx.Width != 1280 || idx.Height != 720 {
+		t.Errorf("dimensions = %dx%d, want 1280x720", idx.Width, idx.Height)
+	}
+	if len(idx.Keyframes) != 3 {
+		t.Fatalf("keyframes = %d, want 3", len(idx.Keyframes))
+	}
+	if idx.Duration() != 5970 {
+		t.Errorf("duration = %d, want 5970", idx.Duration())
+	}
+	if got := idx.MimeType(); got != `video/mp4; codecs="avc1.42001f,mp4a.40.2"` {
+		t.Errorf("mime = %s", got)
+	}
+	if kf := idx.KeyframeAt(3500); kf.Time != 2000 {
+		t.Errorf("KeyframeAt(3500) = %d, want 2000", kf.Time)
+	}
+	if kf := idx.KeyframeAt(0); kf.Time != 0 {
+		t.Errorf("KeyframeAt(0) = %d, want 0", kf.Time)
+	}
+}
+
+func TestBuildIndexIncremental(t *testing.T) {
+	data := buildFLV()
+	half := len(data) / 2
+	prev, err := BuildIndex(bytes.NewReader(data[:half]), int64(half), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := BuildIndex(bytes.NewReader(data), int64(len(data)), prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, _ := BuildIndex(bytes.NewReader(data), int64(len(data)), nil)
+	if len(idx.Keyframes) != len(full.Keyframes) || idx.End != full.End || idx.ScanEnd != full.ScanEnd {
+		t.Errorf("incremental index differs: %+v vs %+v", idx, full)
+	}
+}
+
+// topBoxes 返回顶层 box 类型
+func topBoxes(t *testing.T, data []byte) []string {
+	var types []string
+	for len(data) > 0 {
+		if len(data) < 8 {
+			t.Fatalf("truncated box")
+		}
+		size := binary.BigEndian.Uint32(data)
+		if size < 8 || int(size) > len(data) {
+			t.Fatalf("invalid box size %d", size)
+		}
+		types = append(types, string(data[4:8]))
+		data = data[size:]
+	}
+	return types
+}
+
+func TestRemux(t *testing.T) {
+	data := buildFLV()
+	r := bytes.NewReader(data)
+	idx, err := BuildIndex(r, int64(len(data)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := Remux(context.Background(), &out, r, idx, idx.KeyframeAt(2500)); err != nil {
+		t.Fatal(err)
+	}
+	got := topBoxes(t, out.Bytes())
+	want := []string{"ftyp", "moov", "moof", "mdat", "moof", "mdat"}
+	if len(got) != len(want) {
+		t.Fatalf("boxes = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("boxes = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSetDurations(t *testing.T) {
+	s := []sample{{dts: 0}, {dts: 40}, {dts: 80}}
+	if last := setDurations(s, 130, 0, defaultVideoDuration); last != 50 {
+		t.Errorf("last = %d, want 50", last)
+	}
+	if s[0].duration != 40 || s[1].duration != 40 {
+		t.Errorf("durations = %d %d", s[0].duration, s[1].duration)
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.flv")
+	data := buildFLV()
+	if err := os.WriteFile(path, data[:len(data)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := NewCache(filepath.Join(dir, "index"))
+	first, err := c.Get(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := c.Get(path); again != first {
+		t.Error("expected cached index")
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 新的缓存实例从磁盘读取旧索引后增量扫描
+	grown, err := NewCache(filepath.Join(dir, "index")).Get(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grown.Keyframes) != 3 || grown.Size != int64(len(data)) {
+		t.Errorf("keyframes = %d size = %d", len(grown.Keyframes), grown.Size)
+	}
+}
@@ -0,0 +1,263 @@
+// Package restream 将录制中的 FLV 流同时转推到一个或多个 RTMP 服务器
+// Relay 挂在 streamprobe 代理的数据路径上，作为 io.Writer 接收转发给下载器的 FLV 字节流，
+// 不需要再次拉取上游。每个目标独立连接，断开后按退避时间自动重连，重连后从下一个关键帧开始推送
+package restream
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bililive-go/bililive-go/src/pkg/livelogger"
+	"github.com/bililive-go/bililive-go/src/pkg/rtmp"
+)
+
+const (
+	// DefaultMinBackoff 首次重连的等待时间
+	DefaultMinBackoff = time.Second
+	// DefaultMaxBackoff 重连等待时间的上限
+	DefaultMaxBackoff = 30 * time.Second
+
+	// queueSize 每个目标的消息队列长度，目标跟不上时丢弃消息并从下一个关键帧继续
+	queueSize = 2048
+
+	flvHeaderSize    = 9
+	flvTagHeaderSize = 11
+)
+
+// 转推目标的状态
+const (
+	StateConnecting = "connecting"
+	StatePublishing = "publishing"
+	StateRetrying   = "retrying"
+	StateStopped    = "stopped"
+)
+
+// Target 转推目标
+type Target struct {
+	Name string
+	URL  string
+}
+
+// Options 转推选项
+type Options struct {
+	Logger *livelogger.LiveLogger
+	// Dialer 自定义拨号函数，为空时直连
+	Dialer func(ctx context.Context, network, addr string) (net.Conn, error)
+	// MinBackoff / MaxBackoff 重连退避时间，每次失败翻倍，成功推流后重置
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+func (o Options) minBackoff() time.Duration {
+	if o.MinBackoff > 0 {
+		return o.MinBackoff
+	}
+	return DefaultMinBackoff
+}
+
+func (o Options) maxBackoff() time.Duration {
+	if o.MaxBackoff > 0 {
+		return o.MaxBackoff
+	}
+	return DefaultMaxBackoff
+}
+
+// TargetStatus 转推目标的状态
+type TargetStatus struct {
+	Name       string    `json:"name"`
+	Url        string    `json:"url"` // 隐藏了推流密钥的地址
+	State      string    `json:"state"`
+	Error      string    `json:"error,omitempty"`
+	Reconnects int       `json:"reconnects"`
+	BytesSent  uint64    `json:"bytes_sent"`
+	Dropped    uint64    `json:"dropped"`
+	Since      time.Time `json:"since"`
+}
+
+// Relay 一次录制的转推
+type Relay struct {
+	opts    Options
+	targets []*target
+
+	// demux 状态，只在 Write 中访问
+	buf        []byte
+	headerDone bool
+	invalid    bool
+
+	mu          sync.Mutex
+	meta        *rtmp.Message
+	videoHeader *rtmp.Message
+	audioHeader *rtmp.Message
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New 创建转推，地址无效的目标直接标记为已停止
+func New(targets []Target, opts Options) *Relay {
+	if opts.Logger == nil {
+		opts.Logger = livelogger.New(0, nil)
+	}
+	r := &Relay{opts: opts}
+	for _, t := range targets {
+		tt := &target{
+			relay: r,
+			name:  t.Name,
+			queue: make(chan *rtmp.Message, queueSize),
+		}
+		u, err := url.Parse(t.URL)
+		if err == nil {
+			tt.u = u
+			_, err = rtmp.ParseURL(u)
+		}
+		tt.status = TargetStatus{Name: t.Name, Url: maskURL(t.URL), State: StateConnecting, Since: time.Now()}
+		if err != nil {
+			tt.u = nil
+			tt.status.State = StateStopped
+			tt.status.Error = err.Error()
+		}
+		if tt.status.Name == "" && u != nil {
+			tt.status.Name = u.Host
+			tt.name = u.Host
+		}
+		r.targets = append(r.targets, tt)
+	}
+	return r
+}
+
+// Start 为每个目标启动推流，ctx 结束或调用 Stop 后停止
+func (r *Relay) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	for _, t := range r.targets {
+		if t.u == nil {
+			continue
+		}
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			t.run(ctx)
+		}()
+	}
+}
+
+// Stop 停止所有目标并等待连接关闭
+func (r *Relay) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
+
+// Status 返回所有目标的状态
+func (r *Relay) Status() []TargetStatus {
+	out := make([]TargetStatus, 0, len(r.targets))
+	for _, t := range r.targets {
+		out = append(out, t.getStatus())
+	}
+	return out
+}
+
+// Write 接收 FLV 字节流，拆分为 tag 后分发给各目标，不会阻塞
+// 非 FLV 数据直接丢弃
+func (r *Relay) Write(p []byte) (int, error) {
+	if r.invalid {
+		return len(p), nil
+	}
+	r.buf = append(r.buf, p...)
+	off := 0
+	for {
+		data := r.buf[off:]
+		if !r.headerDone {
+			if len(data) < flvHeaderSize {
+				break
+			}
+			if string(data[:3]) != "FLV" {
+				r.invalid = true
+				r.buf = nil
+				r.opts.Logger.Warn("转推: 不是 FLV 流，停止转推")
+				return len(p), nil
+			}
+			skip := int(binary.BigEndian.Uint32(data[5:9])) + 4
+			if len(data) < skip {
+				break
+			}
+			off += skip
+			r.headerDone = true
+			continue
+		}
+		if len(data) < flvTagHeaderSize {
+			break
+		}
+		size := int(data[1])<<16 | int(data[2])<<8 | int(data[3])
+		if len(data) < flvTagHeaderSize+size+4 {
+			break
+		}
+		ts := uint32(data[7])<<24 | uint32(data[4])<<16 | uint32(data[5])<<8 | uint32(data[6])
+		payload := make([]byte, size)
+		copy(payload, data[flvTagHeaderSize:flvTagHeaderSize+size])
+		r.dispatch(&rtmp.Message{Type: data[0] & 0x1f, Timestamp: ts, Payload: payload})
+		off += flvTagHeaderSize + size + 4
+	}
+	r.buf = append(r.buf[:0], r.buf[off:]...)
+	return len(p), nil
+}
+
+// dispatch 缓存元数据和序列头，并将消息放入各目标的队列
+func (r *Relay) dispatch(msg *rtmp.Message) {
+	switch msg.Type {
+	case rtmp.TypeDataAMF0:
+		r.mu.Lock()
+		r.meta = msg
+		r.mu.Unlock()
+	case rtmp.TypeVideo:
+		if rtmp.IsVideoSequenceHeader(msg.Payload) {
+			r.mu.Lock()
+			r.videoHeader = msg
+			r.mu.Unlock()
+		}
+	case rtmp.TypeAudio:
+		if rtmp.IsAudioSequenceHeader(msg.Payload) {
+			r.mu.Lock()
+			r.audioHeader = msg
+			r.mu.Unlock()
+		}
+	default:
+		return
+	}
+	for _, t := range r.targets {
+		if t.u != nil {
+			t.enqueue(msg)
+		}
+	}
+}
+
+// headers 返回新连接需要先发送的元数据和序列头
+func (r *Relay) headers() (headers []*rtmp.Message, hasVideo bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range []*rtmp.Message{r.meta, r.videoHeader, r.audioHeader} {
+		if m != nil {
+			headers = append(headers, m)
+		}
+	}
+	return headers, r.videoHeader != nil
+}
+
+// maskURL 隐藏推流地址中的推流密钥和查询参数，只保留服务器地址和应用名
+func maskURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	app, rest, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	masked := u.Scheme + "://" + u.Host + "/" + app
+	if rest != "" || u.RawQuery != "" {
+		masked += "/***"
+	}
+	return masked
+}
@@ -0,0 +1,164 @@
+package restream
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bililive-go/bililive-go/src/pkg/rtmp"
+)
+
+// sink 记录收到的推流消息，kick 后下一个消息会断开推流端
+type sink struct {
+	mu       sync.Mutex
+	sessions [][]*rtmp.Message
+	streams  []string
+	kick     atomic.Bool
+}
+
+type sinkPublisher struct {
+	s     *sink
+	index int
+}
+
+func (p *sinkPublisher) OnMessage(msg *rtmp.Message) error {
+	if p.s.kick.Swap(false) {
+		return errors.New("kicked")
+	}
+	p.s.mu.Lock()
+	defer p.s.mu.Unlock()
+	p.s.sessions[p.index] = append(p.s.sessions[p.index], msg)
+	return nil
+}
+
+func (p *sinkPublisher) Close() {}
+
+func (s *sink) onPublish(req *rtmp.PublishRequest) (rtmp.Publisher, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = append(s.sessions, nil)
+	s.streams = append(s.streams, req.Stream+"?"+req.Query.Encode())
+	return &sinkPublisher{s: s, index: len(s.sessions) - 1}, nil
+}
+
+func (s *sink) messages(session int) []*rtmp.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session >= len(s.sessions) {
+		return nil
+	}
+	return append([]*rtmp.Message(nil), s.sessions[session]...)
+}
+
+// writeSplit 将数据拆成小块写入，模拟代理转发时任意的分块
+func writeSplit(t *testing.T, r *Relay, data []byte) {
+	t.Helper()
+	for len(data) > 0 {
+		n := min(7, len(data))
+		_, err := r.Write(data[:n])
+		require.NoError(t, err)
+		data = data[n:]
+	}
+}
+
+func waitState(t *testing.T, r *Relay, index int, state string) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return r.Status()[index].State == state
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRelay(t *testing.T) {
+	s := &sink{}
+	server := &rtmp.Server{OnPublish: s.onPublish}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	r := New([]Target{
+		{Name: "backup", URL: "rtmp://" + listener.Addr().String() + "/live/key?token=1"},
+		{URL: "http://example.com/live/key"},
+	}, Options{MinBackoff: 20 * time.Millisecond, MaxBackoff: 50 * time.Millisecond})
+	r.Start(t.Context())
+	defer r.Stop()
+
+	status := r.Status()
+	assert.Equal(t, StateStopped, status[1].State)
+	assert.NotEmpty(t, status[1].Error)
+	assert.Equal(t, "example.com", status[1].Name)
+	waitState(t, r, 0, StatePublishing)
+	assert.Equal(t, "rtmp://"+listener.Addr().String()+"/live/***", r.Status()[0].Url)
+
+	meta, err := rtmp.EncodeAMF0("onMetaData", rtmp.Object{"width": 1280.0})
+	require.NoError(t, err)
+	stream := append([]byte(nil), rtmp.FLVHeader...)
+	for _, tag := range []struct {
+		typeID  uint8
+		ts      uint32
+		payload []byte
+	}{
+		{rtmp.TypeDataAMF0, 0, meta},
+		{rtmp.TypeVideo, 1000, []byte{0x17, 0, 1}},
+		{rtmp.TypeAudio, 1000, []byte{0xaf, 0, 2}},
+		// 关键帧之前的普通帧不会转推
+		{rtmp.TypeVideo, 1000, []byte{0x27, 1, 3}},
+		{rtmp.TypeVideo, 1040, []byte{0x17, 1, 4}},
+		{rtmp.TypeAudio, 1060, []byte{0xaf, 1, 5}},
+	} {
+		stream = rtmp.AppendFLVTag(stream, tag.typeID, tag.ts, tag.payload)
+	}
+	writeSplit(t, r, stream)
+
+	require.Eventually(t, func() bool { return len(s.messages(0)) == 5 }, 5*time.Second, 10*time.Millisecond)
+	got := s.messages(0)
+	values, err := rtmp.DecodeAMF0(got[0].Payload)
+	require.NoError(t, err)
+	assert.Equal(t, "@setDataFrame", values[0])
+	assert.Equal(t, "onMetaData", values[1])
+	for i, want := range []struct {
+		ts   uint32
+		last byte
+	}{{0, 1}, {0, 2}, {0, 4}, {20, 5}} {
+		assert.Equal(t, want.ts, got[i+1].Timestamp, i)
+		assert.Equal(t, want.last, got[i+1].Payload[2], i)
+	}
+	assert.Equal(t, "key?token=1", s.streams[0])
+
+	// 目标断开后自动重连，重连后先发送缓存的元数据和序列头，再从下一个关键帧开始
+	s.kick.Store(true)
+	writeSplit(t, r, rtmp.AppendFLVTag(nil, rtmp.TypeVideo, 1080, []byte{0x27, 1, 6}))
+	require.Eventually(t, func() bool {
+		status := r.Status()[0]
+		return status.State == StatePublishing && status.Reconnects == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	var next []byte
+	next = rtmp.AppendFLVTag(next, rtmp.TypeVideo, 2000, []byte{0x27, 1, 7})
+	next = rtmp.AppendFLVTag(next, rtmp.TypeVideo, 2040, []byte{0x17, 1, 8})
+	next = rtmp.AppendFLVTag(next, rtmp.TypeVideo, 2080, []byte{0x27, 1, 9})
+	writeSplit(t, r, next)
+
+	require.Eventually(t, func() bool { return len(s.messages(1)) == 5 }, 5*time.Second, 10*time.Millisecond)
+	got = s.messages(1)
+	assert.Equal(t, uint8(rtmp.TypeDataAMF0), got[0].Type)
+	assert.Equal(t, byte(1), got[1].Payload[2])
+	assert.Equal(t, byte(2), got[2].Payload[2])
+	assert.Equal(t, []uint32{0, 40}, []uint32{got[3].Timestamp, got[4].Timestamp})
+	assert.Equal(t, []byte{8, 9}, []byte{got[3].Payload[2], got[4].Payload[2]})
+	assert.NotZero(t, r.Status()[0].BytesSent)
+
+	r.Stop()
+	assert.Equal(t, StateStopped, r.Status()[0].State)
+}
+
+func TestMaskURL(t *testing.T) {
+	assert.Equal(t, "rtmp://a.com/live/***", maskURL("rtmp://a.com/live/secret"))
+	assert.Equal(t, "rtmp://a.com/live/***", maskURL("rtmp://a.com/live?key=1"))
+	assert.Equal(t, "rtmps://a.com:443/app", maskURL("rtmps://a.com:443/app"))
+}
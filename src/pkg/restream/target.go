@@ -0,0 +1,185 @@
+package restream
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bililive-go/bililive-go/src/pkg/rtmp"
+)
+
+// target 一个转推目标的连接和状态
+type target struct {
+	relay *Relay
+	name  string
+	u     *url.URL
+	queue chan *rtmp.Message
+	// overflow 队列已满丢弃过消息，需要从下一个关键帧重新开始
+	overflow atomic.Bool
+	dropped  atomic.Uint64
+	sent     atomic.Uint64
+
+	mu     sync.Mutex
+	status TargetStatus
+}
+
+// enqueue 放入消息队列，队列已满时丢弃
+func (t *target) enqueue(msg *rtmp.Message) {
+	select {
+	case t.queue <- msg:
+	default:
+		t.dropped.Add(1)
+		t.overflow.Store(true)
+	}
+}
+
+func (t *target) setState(state string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.status.State != state {
+		t.status.Since = time.Now()
+	}
+	t.status.State = state
+	if err != nil {
+		t.status.Error = err.Error()
+	} else if state == StatePublishing {
+		t.status.Error = ""
+	}
+	if state == StateRetrying {
+		t.status.Reconnects++
+	}
+}
+
+func (t *target) getStatus() TargetStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status := t.status
+	status.BytesSent = t.sent.Load()
+	status.Dropped = t.dropped.Load()
+	return status
+}
+
+// run 推流直到 ctx 结束，连接失败或断开后按退避时间重连
+func (t *target) run(ctx context.Context) {
+	opts := t.relay.opts
+	backoff := opts.minBackoff()
+	for {
+		t.setState(StateConnecting, nil)
+		published, err := t.publishOnce(ctx)
+		if ctx.Err() != nil {
+			t.setState(StateStopped, nil)
+			return
+		}
+		if published {
+			backoff = opts.minBackoff()
+		}
+		t.setState(StateRetrying, err)
+		opts.Logger.Warnf("转推到 %s 中断，%v 后重连: %v", t.name, backoff, err)
+		select {
+		case <-ctx.Done():
+			t.setState(StateStopped, nil)
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, opts.maxBackoff())
+	}
+}
+
+// publishOnce 建立一次推流连接并持续发送，返回是否成功开始推流以及断开的原因
+func (t *target) publishOnce(ctx context.Context) (bool, error) {
+	client, err := rtmp.Dial(ctx, t.u, rtmp.ClientOptions{Dialer: t.relay.opts.Dialer})
+	if err != nil {
+		return false, err
+	}
+	defer client.Close()
+	if err := client.Publish(ctx); err != nil {
+		return false, err
+	}
+	stop := context.AfterFunc(ctx, func() { client.Close() })
+	defer stop()
+	drainErr := make(chan error, 1)
+	go func() {
+		drainErr <- client.Drain()
+	}()
+
+	t.setState(StatePublishing, nil)
+	t.relay.opts.Logger.Infof("开始转推到 %s", t.name)
+
+	// 丢弃连接前积压的消息，先发送元数据和序列头，再从下一个关键帧开始发送
+	for len(t.queue) > 0 {
+		<-t.queue
+	}
+	t.overflow.Store(false)
+	headers, hasVideo := t.relay.headers()
+	s := &session{client: client, target: t, base: -1, waitKeyFrame: true, hasVideo: hasVideo}
+	for _, msg := range headers {
+		if err := s.write(msg); err != nil {
+			return true, err
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case err := <-drainErr:
+			return true, err
+		case msg := <-t.queue:
+			if t.overflow.Swap(false) {
+				s.waitKeyFrame = true
+			}
+			if err := s.forward(msg); err != nil {
+				return true, err
+			}
+		}
+	}
+}
+
+// session 一次推流连接内的时间戳和关键帧状态
+type session struct {
+	client       *rtmp.Client
+	target       *target
+	base         int64
+	waitKeyFrame bool
+	hasVideo     bool
+}
+
+// forward 发送一个消息，等待关键帧期间丢弃普通音视频帧
+func (s *session) forward(msg *rtmp.Message) error {
+	isHeader := msg.Type == rtmp.TypeDataAMF0 ||
+		(msg.Type == rtmp.TypeVideo && rtmp.IsVideoSequenceHeader(msg.Payload)) ||
+		(msg.Type == rtmp.TypeAudio && rtmp.IsAudioSequenceHeader(msg.Payload))
+	if msg.Type == rtmp.TypeVideo && rtmp.IsVideoSequenceHeader(msg.Payload) {
+		s.hasVideo = true
+	}
+	if !isHeader && s.waitKeyFrame {
+		// 纯音频流没有关键帧，直接开始发送
+		if s.hasVideo && !(msg.Type == rtmp.TypeVideo && rtmp.IsKeyFrame(msg.Payload)) {
+			return nil
+		}
+		s.waitKeyFrame = false
+	}
+	if !isHeader && s.base < 0 {
+		s.base = int64(msg.Timestamp)
+	}
+	return s.write(msg)
+}
+
+// write 以本次连接的第一个音视频帧为时间戳起点发送消息，元数据恢复 @setDataFrame 前缀
+func (s *session) write(msg *rtmp.Message) error {
+	var ts uint32
+	if s.base >= 0 && int64(msg.Timestamp) > s.base {
+		ts = uint32(int64(msg.Timestamp) - s.base)
+	}
+	payload := msg.Payload
+	if msg.Type == rtmp.TypeDataAMF0 {
+		prefix, _ := rtmp.EncodeAMF0("@setDataFrame")
+		payload = append(prefix, payload...)
+	}
+	if err := s.client.WriteMedia(&rtmp.Message{Type: msg.Type, Timestamp: ts, Payload: payload}); err != nil {
+		return err
+	}
+	s.target.sent.Add(uint64(len(payload)))
+	return nil
+}
@@ -0,0 +1,238 @@
+package rtmp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// AMF0 类型标记
+const (
+	amf0Number      = 0x00
+	amf0Boolean     = 0x01
+	amf0String      = 0x02
+	amf0Object      = 0x03
+	amf0Null        = 0x05
+	amf0Undefined   = 0x06
+	amf0ECMAArray   = 0x08
+	amf0ObjectEnd   = 0x09
+	amf0StrictArray = 0x0a
+	amf0Date        = 0x0b
+	amf0LongString  = 0x0c
+)
+
+// Object AMF0 对象，编码时按键名排序以保证输出稳定
+type Object map[string]any
+
+var errAMFTruncated = errors.New("amf0: truncated data")
+
+// EncodeAMF0 依次编码多个值
+// 支持 float64 / int / uint32 / bool / string / Object / map[string]any / []any / nil
+func EncodeAMF0(values ...any) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, v := range values {
+		if err := encodeAMF0Value(&buf, v); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeAMF0Value(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(amf0Null)
+	case float64:
+		buf.WriteByte(amf0Number)
+		_ = binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case int:
+		return encodeAMF0Value(buf, float64(v))
+	case uint32:
+		return encodeAMF0Value(buf, float64(v))
+	case bool:
+		buf.WriteByte(amf0Boolean)
+		if v {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	case string:
+		if len(v) > math.MaxUint16 {
+			buf.WriteByte(amf0LongString)
+			_ = binary.Write(buf, binary.BigEndian, uint32(len(v)))
+		} else {
+			buf.WriteByte(amf0String)
+			_ = binary.Write(buf, binary.BigEndian, uint16(len(v)))
+		}
+		buf.WriteString(v)
+	case Object:
+		buf.WriteByte(amf0Object)
+		return encodeAMF0Properties(buf, v)
+	case map[string]any:
+		return encodeAMF0Value(buf, Object(v))
+	case []any:
+		buf.WriteByte(amf0StrictArray)
+		_ = binary.Write(buf, binary.BigEndian, uint32(len(v)))
+		for _, item := range v {
+			if err := encodeAMF0Value(buf, item); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("amf0: unsupported type %T", v)
+	}
+	return nil
+}
+
+func encodeAMF0Properties(buf *bytes.Buffer, obj Object) error {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		_ = binary.Write(buf, binary.BigEndian, uint16(len(k)))
+		buf.WriteString(k)
+		if err := encodeAMF0Value(buf, obj[k]); err != nil {
+			return err
+		}
+	}
+	buf.Write([]byte{0, 0, amf0ObjectEnd})
+	return nil
+}
+
+// DecodeAMF0 解码数据中的全部值
+// 对象和 ECMA 数组解码为 Object，严格数组解码为 []any，null / undefined 解码为 nil
+func DecodeAMF0(data []byte) ([]any, error) {
+	r := bytes.NewReader(data)
+	values := make([]any, 0, 4)
+	for r.Len() > 0 {
+		v, err := decodeAMF0Value(r)
+		if err != nil {
+			return values, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func decodeAMF0Value(r *bytes.Reader) (any, error) {
+	marker, err := r.ReadByte()
+	if err != nil {
+		return nil, errAMFTruncated
+	}
+	switch marker {
+	case amf0Number:
+		var bits uint64
+		if err := binary.Read(r, binary.BigEndian, &bits); err != nil {
+			return nil, errAMFTruncated
+		}
+		return math.Float64frombits(bits), nil
+	case amf0Boolean:
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, errAMFTruncated
+		}
+		return b != 0, nil
+	case amf0String:
+		return readAMF0String(r, false)
+	case amf0LongString:
+		return readAMF0String(r, true)
+	case amf0Object:
+		return decodeAMF0Properties(r)
+	case amf0ECMAArray:
+		if _, err := r.Seek(4, io.SeekCurrent); err != nil {
+			return nil, errAMFTruncated
+		}
+		return decodeAMF0Properties(r)
+	case amf0StrictArray:
+		var n uint32
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+			return nil, errAMFTruncated
+		}
+		if int(n) > r.Len() {
+			return nil, errAMFTruncated
+		}
+		items := make([]any, 0, n)
+		for i := uint32(0); i < n; i++ {
+			v, err := decodeAMF0Value(r)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+	case amf0Date:
+		var bits uint64
+		if err := binary.Read(r, binary.BigEndian, &bits); err != nil {
+			return nil, errAMFTruncated
+		}
+		// 忽略时区字段
+		if _, err := r.Seek(2, io.SeekCurrent); err != nil {
+			return nil, errAMFTruncated
+		}
+		return math.Float64frombits(bits), nil
+	case amf0Null, amf0Undefined:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("amf0: unsupported marker 0x%02x", marker)
+	}
+}
+
+func readAMF0String(r *bytes.Reader, long bool) (string, error) {
+	var n int
+	if long {
+		var l uint32
+		if err := binary.Read(r, binary.BigEndian, &l); err != nil {
+			return "", errAMFTruncated
+		}
+		n = int(l)
+	} else {
+		var l uint16
+		if err := binary.Read(r, binary.BigEndian, &l); err != nil {
+			return "", errAMFTruncated
+		}
+		n = int(l)
+	}
+	if n > r.Len() {
+		return "", errAMFTruncated
+	}
+	b := make([]byte, n)
+	_, _ = io.ReadFull(r, b)
+	return string(b), nil
+}
+
+func decodeAMF0Properties(r *bytes.Reader) (Object, error) {
+	obj := Object{}
+	for {
+		key, err := readAMF0String(r, false)
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			marker, err := r.ReadByte()
+			if err != nil {
+				return nil, errAMFTruncated
+			}
+			if marker == amf0ObjectEnd {
+				return obj, nil
+			}
+			_ = r.UnreadByte()
+		}
+		v, err := decodeAMF0Value(r)
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = v
+	}
+}
+
+// String 读取对象中的字符串字段
+func (o Object) String(key string) string {
+	s, _ := o[key].(string)
+	return s
+}
@@ -0,0 +1,432 @@
+package rtmp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultDialTimeout 建立连接、握手和 connect / play 命令的默认超时
+	DefaultDialTimeout = 10 * time.Second
+	// DefaultReadTimeout 默认读超时，超过此时间没有收到任何数据视为连接中断
+	DefaultReadTimeout = 20 * time.Second
+)
+
+var (
+	// ErrStreamNotFound 服务器上没有该流（未开播）
+	ErrStreamNotFound = errors.New("rtmp: stream not found")
+	// ErrStreamEnded 服务器通知流已结束
+	ErrStreamEnded = errors.New("rtmp: stream ended")
+)
+
+// StatusError 服务器返回的错误状态
+type StatusError struct {
+	Code        string
+	Description string
+}
+
+func (e *StatusError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("rtmp: %s: %s", e.Code, e.Description)
+	}
+	return "rtmp: " + e.Code
+}
+
+// Target 从 rtmp URL 中解析出的连接参数
+type Target struct {
+	Addr     string // host:port
+	TLS      bool
+	App      string
+	TcUrl    string
+	Stream   string // 播放或推流使用的流名称，包含查询参数
+	HostName string
+}
+
+// ParseURL 解析 rtmp://host[:port]/app/stream?query
+// app 为路径的第一段，其余部分（以及查询参数）作为流名称
+func ParseURL(u *url.URL) (*Target, error) {
+	t := &Target{HostName: u.Hostname()}
+	switch strings.ToLower(u.Scheme) {
+	case "rtmp":
+		t.Addr = hostPort(u, "1935")
+	case "rtmps":
+		t.Addr = hostPort(u, "443")
+		t.TLS = true
+	default:
+		return nil, fmt.Errorf("rtmp: unsupported scheme %s", u.Scheme)
+	}
+	path := strings.TrimPrefix(u.Path, "/")
+	app, stream, _ := strings.Cut(path, "/")
+	if app == "" {
+		return nil, fmt.Errorf("rtmp: missing app in %s", u.Redacted())
+	}
+	if u.RawQuery != "" {
+		stream += "?" + u.RawQuery
+	}
+	t.App = app
+	t.Stream = stream
+	t.TcUrl = fmt.Sprintf("%s://%s/%s", strings.ToLower(u.Scheme), u.Host, app)
+	return t, nil
+}
+
+func hostPort(u *url.URL, defaultPort string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), defaultPort)
+}
+
+// ClientOptions 客户端连接选项
+type ClientOptions struct {
+	DialTimeout time.Duration
+	ReadTimeout time.Duration
+	// Dialer 自定义拨号方法，例如通过代理连接；为空时直接连接
+	Dialer func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+func (o ClientOptions) dialTimeout() time.Duration {
+	if o.DialTimeout > 0 {
+		return o.DialTimeout
+	}
+	return DefaultDialTimeout
+}
+
+func (o ClientOptions) readTimeout() time.Duration {
+	if o.ReadTimeout > 0 {
+		return o.ReadTimeout
+	}
+	return DefaultReadTimeout
+}
+
+// Client RTMP 客户端连接
+type Client struct {
+	*Conn
+	target   *Target
+	streamID uint32
+	txn      float64
+	opts     ClientOptions
+	// pending 等待命令响应期间收到的媒体消息
+	pending []*Message
+}
+
+// Dial 连接服务器并完成握手和 connect 命令
+func Dial(ctx context.Context, u *url.URL, opts ClientOptions) (*Client, error) {
+	target, err := ParseURL(u)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, opts.dialTimeout())
+	defer cancel()
+
+	dial := opts.Dialer
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	nc, err := dial(ctx, "tcp", target.Addr)
+	if err != nil {
+		return nil, err
+	}
+	if target.TLS {
+		tlsConn := tls.Client(nc, &tls.Config{ServerName: target.HostName})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			nc.Close()
+			return nil, err
+		}
+		nc = tlsConn
+	}
+
+	c := &Client{
+		Conn:   newConn(nc, opts.dialTimeout()),
+		target: target,
+		opts:   opts,
+	}
+	// ctx 取消时中断握手和命令交互
+	stop := context.AfterFunc(ctx, func() { _ = nc.SetDeadline(time.Now()) })
+	err = c.connect()
+	if !stop() || err != nil {
+		nc.Close()
+		if err == nil {
+			err = ctx.Err()
+		}
+		return nil, err
+	}
+	_ = nc.SetDeadline(time.Time{})
+	return c, nil
+}
+
+func (c *Client) connect() error {
+	if err := c.clientHandshake(); err != nil {
+		return err
+	}
+	if err := c.writeUint32Control(TypeWindowAckSize, defaultWindowAck); err != nil {
+		return err
+	}
+	if err := c.SetWriteChunkSize(outChunkSize); err != nil {
+		return err
+	}
+	_, err := c.call("connect", Object{
+		"app":            c.target.App,
+		"flashVer":       "LNX 9,0,124,2",
+		"tcUrl":          c.target.TcUrl,
+		"fpad":           false,
+		"capabilities":   15.0,
+		"audioCodecs":    3191.0,
+		"videoCodecs":    252.0,
+		"videoFunction":  1.0,
+		"objectEncoding": 0.0,
+	})
+	return err
+}
+
+// call 发送命令并等待对应的 _result / _error
+func (c *Client) call(name string, args ...any) ([]any, error) {
+	c.txn++
+	txn := c.txn
+	values := append([]any{name, txn}, args...)
+	if err := c.WriteCommand(csidCommand, 0, values...); err != nil {
+		return nil, err
+	}
+	for {
+		msg, err := c.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		if msg.Type != TypeCommandAMF0 && msg.Type != TypeCommandAMF3 {
+			continue
+		}
+		cmd, respTxn, rest, err := decodeCommand(msg)
+		if err != nil || respTxn != txn {
+			continue
+		}
+		switch cmd {
+		case "_result":
+			return rest, nil
+		case "_error":
+			return nil, statusFromArgs(rest, name+" failed")
+		}
+	}
+}
+
+// statusFromArgs 从 onStatus / _error 的参数中取出状态对象
+func statusFromArgs(args []any, fallback string) *StatusError {
+	for _, a := range args {
+		if obj, ok := a.(Object); ok {
+			if code := obj.String("code"); code != "" {
+				return &StatusError{Code: code, Description: obj.String("description")}
+			}
+		}
+	}
+	return &StatusError{Code: fallback}
+}
+
+func (c *Client) createStream() error {
+	rest, err := c.call("createStream", nil)
+	if err != nil {
+		return err
+	}
+	for _, v := range rest {
+		if id, ok := v.(float64); ok {
+			c.streamID = uint32(id)
+			return nil
+		}
+	}
+	return errors.New("rtmp: createStream returned no stream id")
+}
+
+// Play 创建流并开始播放，等待服务器确认或收到第一个媒体消息
+func (c *Client) Play(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.dialTimeout())
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() { _ = c.nc.SetDeadline(time.Now()) })
+	err := c.play()
+	if !stop() && err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+	_ = c.nc.SetDeadline(time.Time{})
+	c.Timeout = c.opts.readTimeout()
+	return nil
+}
+
+func (c *Client) play() error {
+	if err := c.createStream(); err != nil {
+		return err
+	}
+	if err := c.WriteCommand(csidStreamCommand, c.streamID, "play", 0.0, nil, c.target.Stream, -2.0); err != nil {
+		return err
+	}
+	if err := c.writeUserControl(eventSetBufferLen, c.streamID, 3000); err != nil {
+		return err
+	}
+	for {
+		msg, err := c.ReadMessage()
+		if err != nil {
+			return err
+		}
+		switch msg.Type {
+		case TypeAudio, TypeVideo, TypeDataAMF0, TypeAggregate:
+			c.pending = append(c.pending, msg)
+			return nil
+		case TypeCommandAMF0, TypeCommandAMF3:
+			if err := checkStatus(msg); err != nil {
+				return err
+			}
+			cmd, _, rest, _ := decodeCommand(msg)
+			if cmd == "onStatus" {
+				if code := statusFromArgs(rest, "").Code; code == "NetStream.Play.Start" {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// checkStatus 检查 onStatus 中的错误和流结束通知
+func checkStatus(msg *Message) error {
+	cmd, _, rest, err := decodeCommand(msg)
+	if err != nil || cmd != "onStatus" {
+		return nil
+	}
+	status := statusFromArgs(rest, "")
+	switch status.Code {
+	case "NetStream.Play.StreamNotFound":
+		return fmt.Errorf("%w: %s", ErrStreamNotFound, status.Description)
+	case "NetStream.Play.Stop", "NetStream.Play.UnpublishNotify", "NetStream.Play.Complete":
+		return ErrStreamEnded
+	case "NetStream.Play.Failed", "NetStream.Play.BadName", "NetConnection.Connect.Rejected":
+		return status
+	}
+	return nil
+}
+
+// ReadMediaMessage 读取下一个音视频、元数据或聚合消息
+// 流结束或服务器报告错误时返回错误
+func (c *Client) ReadMediaMessage() (*Message, error) {
+	if len(c.pending) > 0 {
+		msg := c.pending[0]
+		c.pending = c.pending[1:]
+		return msg, nil
+	}
+	for {
+		msg, err := c.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		switch msg.Type {
+		case TypeAudio, TypeVideo, TypeDataAMF0, TypeAggregate:
+			return msg, nil
+		case TypeDataAMF3:
+			if len(msg.Payload) > 0 {
+				msg.Payload = msg.Payload[1:]
+			}
+			msg.Type = TypeDataAMF0
+			return msg, nil
+		case TypeCommandAMF0, TypeCommandAMF3:
+			if err := checkStatus(msg); err != nil {
+				return nil, err
+			}
+		case TypeUserControl:
+			if len(msg.Payload) >= 2 && uint16(msg.Payload[0])<<8|uint16(msg.Payload[1]) == eventStreamEOF {
+				return nil, ErrStreamEnded
+			}
+		}
+	}
+}
+
+// Publish 创建流并开始推流，等待服务器确认
+func (c *Client) Publish(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.dialTimeout())
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() { _ = c.nc.SetDeadline(time.Now()) })
+	err := c.publish()
+	if !stop() && err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+	_ = c.nc.SetDeadline(time.Time{})
+	// 推流期间服务器只会偶尔发送确认，读取不设置超时，写入超时用于发现连接中断
+	c.Timeout = c.opts.readTimeout()
+	c.noReadTimeout = true
+	return nil
+}
+
+// Drain 持续读取并丢弃服务器发送的消息（确认、Ping 等由 ReadMessage 内部处理），直到连接出错
+// 推流期间应在单独的 goroutine 中调用，服务器发送的错误状态会作为错误返回
+func (c *Client) Drain() error {
+	for {
+		msg, err := c.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if msg.Type == TypeCommandAMF0 || msg.Type == TypeCommandAMF3 {
+			cmd, _, rest, _ := decodeCommand(msg)
+			if cmd != "onStatus" {
+				continue
+			}
+			for _, a := range rest {
+				if obj, ok := a.(Object); ok && obj.String("level") == "error" {
+					return statusFromArgs(rest, "")
+				}
+			}
+		}
+	}
+}
+
+func (c *Client) publish() error {
+	if err := c.WriteCommand(csidCommand, 0, "releaseStream", 0.0, nil, c.target.Stream); err != nil {
+		return err
+	}
+	if err := c.WriteCommand(csidCommand, 0, "FCPublish", 0.0, nil, c.target.Stream); err != nil {
+		return err
+	}
+	if err := c.createStream(); err != nil {
+		return err
+	}
+	if err := c.WriteCommand(csidStreamCommand, c.streamID, "publish", 0.0, nil, c.target.Stream, "live"); err != nil {
+		return err
+	}
+	for {
+		msg, err := c.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if msg.Type != TypeCommandAMF0 && msg.Type != TypeCommandAMF3 {
+			continue
+		}
+		cmd, _, rest, _ := decodeCommand(msg)
+		if cmd != "onStatus" {
+			continue
+		}
+		status := statusFromArgs(rest, "")
+		switch {
+		case status.Code == "NetStream.Publish.Start":
+			return nil
+		case strings.HasPrefix(status.Code, "NetStream.Publish."), strings.HasPrefix(status.Code, "NetConnection."):
+			return status
+		}
+	}
+}
+
+// WriteMedia 在推流的流上发送音视频、元数据或聚合消息
+func (c *Client) WriteMedia(msg *Message) error {
+	csid := uint32(csidData)
+	switch msg.Type {
+	case TypeAudio:
+		csid = csidAudio
+	case TypeVideo:
+		csid = csidVideo
+	}
+	out := *msg
+	out.StreamID = c.streamID
+	return c.WriteMessage(csid, &out)
+}
@@ -0,0 +1,492 @@
+package rtmp
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// 消息类型
+const (
+	TypeSetChunkSize     uint8 = 1
+	TypeAbort            uint8 = 2
+	TypeAck              uint8 = 3
+	TypeUserControl      uint8 = 4
+	TypeWindowAckSize    uint8 = 5
+	TypeSetPeerBandwidth uint8 = 6
+	TypeAudio            uint8 = 8
+	TypeVideo            uint8 = 9
+	TypeDataAMF3         uint8 = 15
+	TypeCommandAMF3      uint8 = 17
+	TypeDataAMF0         uint8 = 18
+	TypeCommandAMF0      uint8 = 20
+	TypeAggregate        uint8 = 22
+)
+
+// 用户控制事件
+const (
+	eventStreamBegin  uint16 = 0
+	eventStreamEOF    uint16 = 1
+	eventSetBufferLen uint16 = 3
+	eventPingRequest  uint16 = 6
+	eventPingResponse uint16 = 7
+)
+
+const (
+	rtmpVersion       byte = 3
+	handshakeSize          = 1536
+	defaultChunkSize       = 128
+	outChunkSize           = 4096
+	defaultWindowAck       = 2500000
+	maxMessageSize         = 16 << 20
+	extendedTimestamp      = 0xffffff
+
+	csidProtocolControl = 2
+	csidCommand         = 3
+	csidAudio           = 4
+	csidData            = 5
+	csidVideo           = 6
+	csidStreamCommand   = 8
+)
+
+// Message 一个完整的 RTMP 消息
+type Message struct {
+	Type      uint8
+	StreamID  uint32
+	Timestamp uint32
+	Payload   []byte
+}
+
+// chunkStream 一个 chunk stream 的接收状态
+type chunkStream struct {
+	timestamp uint32
+	delta     uint32
+	length    uint32
+	typeID    uint8
+	streamID  uint32
+	extended  bool
+	buf       []byte
+}
+
+// Conn RTMP 连接的 chunk 层，客户端和服务端共用
+// 读取端自动处理 Set Chunk Size、Window Ack Size 和 Ping，不会返回协议控制消息
+type Conn struct {
+	nc net.Conn
+	br *bufio.Reader
+	bw *bufio.Writer
+
+	// Timeout 单次读写的超时，为 0 时不设置
+	Timeout time.Duration
+	// noReadTimeout 读取不设置超时，用于推流时服务器可能长时间不发送数据的场景
+	noReadTimeout bool
+
+	readChunkSize  uint32
+	writeChunkSize uint32
+	windowAckSize  uint32
+	counter        *countingReader
+	lastAck        uint64
+	streams        map[uint32]*chunkStream
+
+	writeMu sync.Mutex
+}
+
+type countingReader struct {
+	r io.Reader
+	n uint64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += uint64(n)
+	return n, err
+}
+
+func newConn(nc net.Conn, timeout time.Duration) *Conn {
+	counter := &countingReader{r: nc}
+	return &Conn{
+		nc:             nc,
+		br:             bufio.NewReaderSize(counter, 64*1024),
+		bw:             bufio.NewWriterSize(nc, 64*1024),
+		Timeout:        timeout,
+		readChunkSize:  defaultChunkSize,
+		writeChunkSize: defaultChunkSize,
+		windowAckSize:  defaultWindowAck,
+		counter:        counter,
+		streams:        make(map[uint32]*chunkStream),
+	}
+}
+
+// Close 关闭底层连接
+func (c *Conn) Close() error {
+	return c.nc.Close()
+}
+
+// RemoteAddr 返回对端地址
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.nc.RemoteAddr()
+}
+
+func (c *Conn) setReadDeadline() {
+	if c.Timeout > 0 && !c.noReadTimeout {
+		_ = c.nc.SetReadDeadline(time.Now().Add(c.Timeout))
+	}
+}
+
+func (c *Conn) setWriteDeadline() {
+	if c.Timeout > 0 {
+		_ = c.nc.SetWriteDeadline(time.Now().Add(c.Timeout))
+	}
+}
+
+// clientHandshake 简单握手：C0+C1 -> S0+S1+S2 -> C2
+func (c *Conn) clientHandshake() error {
+	c1 := make([]byte, 1+handshakeSize)
+	c1[0] = rtmpVersion
+	binary.BigEndian.PutUint32(c1[1:5], uint32(time.Now().Unix()))
+	if _, err := rand.Read(c1[9:]); err != nil {
+		return err
+	}
+	c.setWriteDeadline()
+	if _, err := c.nc.Write(c1); err != nil {
+		return err
+	}
+	s := make([]byte, 1+2*handshakeSize)
+	c.setReadDeadline()
+	if _, err := io.ReadFull(c.br, s); err != nil {
+		return fmt.Errorf("rtmp handshake: %w", err)
+	}
+	if s[0] != rtmpVersion {
+		return fmt.Errorf("rtmp handshake: unsupported version %d", s[0])
+	}
+	c.setWriteDeadline()
+	_, err := c.nc.Write(s[1 : 1+handshakeSize])
+	return err
+}
+
+// serverHandshake 服务端握手：C0+C1 -> S0+S1+S2 -> C2
+func (c *Conn) serverHandshake() error {
+	c1 := make([]byte, 1+handshakeSize)
+	c.setReadDeadline()
+	if _, err := io.ReadFull(c.br, c1); err != nil {
+		return fmt.Errorf("rtmp handshake: %w", err)
+	}
+	if c1[0] != rtmpVersion {
+		return fmt.Errorf("rtmp handshake: unsupported version %d", c1[0])
+	}
+	s := make([]byte, 1+2*handshakeSize)
+	s[0] = rtmpVersion
+	binary.BigEndian.PutUint32(s[1:5], uint32(time.Now().Unix()))
+	if _, err := rand.Read(s[9 : 1+handshakeSize]); err != nil {
+		return err
+	}
+	copy(s[1+handshakeSize:], c1[1:])
+	c.setWriteDeadline()
+	if _, err := c.nc.Write(s); err != nil {
+		return err
+	}
+	c2 := make([]byte, handshakeSize)
+	c.setReadDeadline()
+	if _, err := io.ReadFull(c.br, c2); err != nil {
+		return fmt.Errorf("rtmp handshake: %w", err)
+	}
+	return nil
+}
+
+// ReadMessage 读取下一个完整消息
+func (c *Conn) ReadMessage() (*Message, error) {
+	for {
+		msg, err := c.readChunk()
+		if err != nil {
+			return nil, err
+		}
+		if msg == nil {
+			continue
+		}
+		handled, err := c.handleControl(msg)
+		if err != nil {
+			return nil, err
+		}
+		if !handled {
+			return msg, nil
+		}
+	}
+}
+
+// readChunk 读取一个 chunk，消息完整时返回消息
+func (c *Conn) readChunk() (*Message, error) {
+	c.setReadDeadline()
+	b, err := c.br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	format := b >> 6
+	csid := uint32(b & 0x3f)
+	switch csid {
+	case 0:
+		b1, err := c.br.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		csid = uint32(b1) + 64
+	case 1:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return nil, err
+		}
+		csid = uint32(ext[0]) + uint32(ext[1])*256 + 64
+	}
+
+	cs, ok := c.streams[csid]
+	if !ok {
+		if format != 0 {
+			return nil, fmt.Errorf("rtmp: chunk stream %d starts with format %d", csid, format)
+		}
+		cs = &chunkStream{}
+		c.streams[csid] = cs
+	}
+
+	var header [11]byte
+	headerSize := [4]int{11, 7, 3, 0}[format]
+	if _, err := io.ReadFull(c.br, header[:headerSize]); err != nil {
+		return nil, err
+	}
+	var ts uint32
+	if format < 3 {
+		ts = uint32(header[0])<<16 | uint32(header[1])<<8 | uint32(header[2])
+		cs.extended = ts == extendedTimestamp
+	}
+	if format < 2 {
+		cs.length = uint32(header[3])<<16 | uint32(header[4])<<8 | uint32(header[5])
+		cs.typeID = header[6]
+		if cs.length > maxMessageSize {
+			return nil, fmt.Errorf("rtmp: message too large (%d bytes)", cs.length)
+		}
+	}
+	if format == 0 {
+		cs.streamID = binary.LittleEndian.Uint32(header[7:11])
+	}
+	if cs.extended {
+		var ext [4]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return nil, err
+		}
+		if format < 3 {
+			ts = binary.BigEndian.Uint32(ext[:])
+		}
+	}
+
+	// 新消息的第一个 chunk 才更新时间戳
+	if len(cs.buf) == 0 {
+		switch format {
+		case 0:
+			cs.timestamp = ts
+			cs.delta = ts
+		case 1, 2:
+			cs.delta = ts
+			cs.timestamp += ts
+		case 3:
+			cs.timestamp += cs.delta
+		}
+		if cap(cs.buf) < int(cs.length) {
+			cs.buf = make([]byte, 0, cs.length)
+		}
+	}
+
+	n := cs.length - uint32(len(cs.buf))
+	if n > c.readChunkSize {
+		n = c.readChunkSize
+	}
+	start := len(cs.buf)
+	cs.buf = cs.buf[:start+int(n)]
+	if _, err := io.ReadFull(c.br, cs.buf[start:]); err != nil {
+		return nil, err
+	}
+	if uint32(len(cs.buf)) < cs.length {
+		return nil, nil
+	}
+
+	payload := make([]byte, len(cs.buf))
+	copy(payload, cs.buf)
+	cs.buf = cs.buf[:0]
+	return &Message{
+		Type:      cs.typeID,
+		StreamID:  cs.streamID,
+		Timestamp: cs.timestamp,
+		Payload:   payload,
+	}, nil
+}
+
+// handleControl 处理协议控制消息，返回 true 表示已处理不需要交给上层
+func (c *Conn) handleControl(msg *Message) (bool, error) {
+	if err := c.maybeAck(); err != nil {
+		return false, err
+	}
+	switch msg.Type {
+	case TypeSetChunkSize:
+		if len(msg.Payload) < 4 {
+			return true, errors.New("rtmp: invalid set chunk size")
+		}
+		size := binary.BigEndian.Uint32(msg.Payload) & 0x7fffffff
+		if size == 0 || size > maxMessageSize {
+			return true, fmt.Errorf("rtmp: invalid chunk size %d", size)
+		}
+		c.readChunkSize = size
+		return true, nil
+	case TypeWindowAckSize:
+		if len(msg.Payload) >= 4 {
+			c.windowAckSize = binary.BigEndian.Uint32(msg.Payload)
+		}
+		return true, nil
+	case TypeAbort:
+		if len(msg.Payload) >= 4 {
+			if cs, ok := c.streams[binary.BigEndian.Uint32(msg.Payload)]; ok {
+				cs.buf = cs.buf[:0]
+			}
+		}
+		return true, nil
+	case TypeAck, TypeSetPeerBandwidth:
+		return true, nil
+	case TypeUserControl:
+		if len(msg.Payload) >= 6 && binary.BigEndian.Uint16(msg.Payload) == eventPingRequest {
+			pong := make([]byte, 6)
+			binary.BigEndian.PutUint16(pong, eventPingResponse)
+			copy(pong[2:], msg.Payload[2:6])
+			return true, c.WriteMessage(csidProtocolControl, &Message{Type: TypeUserControl, Payload: pong})
+		}
+		// 其余用户控制事件（StreamBegin / StreamEOF 等）交给上层
+		return false, nil
+	}
+	return false, nil
+}
+
+// maybeAck 接收字节数超过窗口大小时发送确认
+func (c *Conn) maybeAck() error {
+	if c.windowAckSize == 0 || c.counter.n-c.lastAck < uint64(c.windowAckSize) {
+		return nil
+	}
+	c.lastAck = c.counter.n
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint32(payload, uint32(c.counter.n))
+	return c.WriteMessage(csidProtocolControl, &Message{Type: TypeAck, Payload: payload})
+}
+
+// WriteMessage 将消息按 chunk 写出，第一个 chunk 使用完整头部，后续 chunk 使用 format 3
+func (c *Conn) WriteMessage(csid uint32, msg *Message) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.setWriteDeadline()
+
+	ts := msg.Timestamp
+	extended := ts >= extendedTimestamp
+	header := make([]byte, 0, 18)
+	header = appendBasicHeader(header, 0, csid)
+	if extended {
+		header = append(header, 0xff, 0xff, 0xff)
+	} else {
+		header = append(header, byte(ts>>16), byte(ts>>8), byte(ts))
+	}
+	length := len(msg.Payload)
+	header = append(header, byte(length>>16), byte(length>>8), byte(length), msg.Type)
+	header = binary.LittleEndian.AppendUint32(header, msg.StreamID)
+	if extended {
+		header = binary.BigEndian.AppendUint32(header, ts)
+	}
+	if _, err := c.bw.Write(header); err != nil {
+		return err
+	}
+
+	payload := msg.Payload
+	for {
+		n := len(payload)
+		if n > int(c.writeChunkSize) {
+			n = int(c.writeChunkSize)
+		}
+		if _, err := c.bw.Write(payload[:n]); err != nil {
+			return err
+		}
+		payload = payload[n:]
+		if len(payload) == 0 {
+			break
+		}
+		cont := appendBasicHeader(nil, 3, csid)
+		if extended {
+			cont = binary.BigEndian.AppendUint32(cont, ts)
+		}
+		if _, err := c.bw.Write(cont); err != nil {
+			return err
+		}
+	}
+	return c.bw.Flush()
+}
+
+func appendBasicHeader(b []byte, format byte, csid uint32) []byte {
+	switch {
+	case csid < 64:
+		return append(b, format<<6|byte(csid))
+	case csid < 320:
+		return append(b, format<<6, byte(csid-64))
+	default:
+		return append(b, format<<6|1, byte((csid-64)&0xff), byte((csid-64)>>8))
+	}
+}
+
+// SetWriteChunkSize 通知对端并修改发送端的 chunk 大小
+func (c *Conn) SetWriteChunkSize(size uint32) error {
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint32(payload, size)
+	if err := c.WriteMessage(csidProtocolControl, &Message{Type: TypeSetChunkSize, Payload: payload}); err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	c.writeChunkSize = size
+	c.writeMu.Unlock()
+	return nil
+}
+
+func (c *Conn) writeUint32Control(typeID uint8, v uint32, extra ...byte) error {
+	payload := binary.BigEndian.AppendUint32(nil, v)
+	payload = append(payload, extra...)
+	return c.WriteMessage(csidProtocolControl, &Message{Type: typeID, Payload: payload})
+}
+
+// writeUserControl 发送用户控制事件
+func (c *Conn) writeUserControl(event uint16, args ...uint32) error {
+	payload := binary.BigEndian.AppendUint16(nil, event)
+	for _, a := range args {
+		payload = binary.BigEndian.AppendUint32(payload, a)
+	}
+	return c.WriteMessage(csidProtocolControl, &Message{Type: TypeUserControl, Payload: payload})
+}
+
+// WriteCommand 以 AMF0 编码发送命令消息
+func (c *Conn) WriteCommand(csid, streamID uint32, values ...any) error {
+	payload, err := EncodeAMF0(values...)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(csid, &Message{Type: TypeCommandAMF0, StreamID: streamID, Payload: payload})
+}
+
+// decodeCommand 解码命令消息，AMF3 命令的第一个字节为格式标记需要跳过
+func decodeCommand(msg *Message) (string, float64, []any, error) {
+	payload := msg.Payload
+	if msg.Type == TypeCommandAMF3 && len(payload) > 0 {
+		payload = payload[1:]
+	}
+	values, err := DecodeAMF0(payload)
+	if err != nil && len(values) < 2 {
+		return "", 0, nil, err
+	}
+	if len(values) < 2 {
+		return "", 0, nil, errors.New("rtmp: invalid command")
+	}
+	name, _ := values[0].(string)
+	txn, _ := values[1].(float64)
+	return name, txn, values[2:], nil
+}
@@ -0,0 +1,323 @@
+package rtmp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	flvTagHeaderSize = 11
+
+	// DefaultMaxReconnects 默认最大连续重连次数
+	DefaultMaxReconnects = 3
+	// DefaultReconnectBackoff 默认第一次重连前的等待时间，之后每次翻倍
+	DefaultReconnectBackoff = time.Second
+)
+
+// FLVHeader 同时包含音视频的 FLV 文件头（包括第一个 PreviousTagSize）
+var FLVHeader = []byte{'F', 'L', 'V', 0x01, 0x05, 0, 0, 0, 9, 0, 0, 0, 0}
+
+// FLVOptions 拉流选项
+type FLVOptions struct {
+	ClientOptions
+	// MaxReconnects 连接中断后最多连续重连的次数，成功收到数据后重新计数；为负数时不重连
+	MaxReconnects int
+	// ReconnectBackoff 第一次重连前的等待时间，之后每次翻倍
+	ReconnectBackoff time.Duration
+	// OnReconnect 每次重连前调用，可用于记录日志
+	OnReconnect func(attempt int, err error)
+}
+
+// FLVStream 将 RTMP 播放流转换为 FLV 字节流
+// 连接中断时自动重连，重连后时间戳接在中断前之后，下游看到的是一条连续的 FLV 流；
+// 服务器明确通知流结束或流不存在时返回错误，不再重连
+type FLVStream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	url    *url.URL
+	opts   FLVOptions
+
+	mu     sync.Mutex
+	client *Client
+	closed bool
+
+	buf      bytes.Buffer
+	started  bool
+	received bool
+	// 时间戳修正：offset 为当前连接的时间戳偏移，base 为当前连接第一个消息的时间戳
+	offset   uint32
+	base     int64
+	lastTime uint32
+}
+
+// OpenFLV 连接并开始播放，返回 FLV 字节流
+func OpenFLV(ctx context.Context, u *url.URL, opts FLVOptions) (*FLVStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &FLVStream{
+		ctx:    ctx,
+		cancel: cancel,
+		url:    u,
+		opts:   opts,
+		base:   -1,
+	}
+	client, err := s.dial()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	s.client = client
+	return s, nil
+}
+
+func (s *FLVStream) dial() (*Client, error) {
+	client, err := Dial(s.ctx, s.url, s.opts.ClientOptions)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Play(s.ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// Read 实现 io.Reader
+func (s *FLVStream) Read(p []byte) (int, error) {
+	if !s.started {
+		s.started = true
+		s.buf.Write(FLVHeader)
+	}
+	for s.buf.Len() == 0 {
+		if err := s.fill(); err != nil {
+			return 0, err
+		}
+	}
+	return s.buf.Read(p)
+}
+
+// Done 返回在流关闭后关闭的 channel
+func (s *FLVStream) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Close 关闭连接，正在进行的 Read 会返回错误
+func (s *FLVStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	s.cancel()
+	if s.client != nil {
+		return s.client.Close()
+	}
+	return nil
+}
+
+// fill 读取下一个消息并写入缓冲区，连接中断时重连
+func (s *FLVStream) fill() error {
+	s.mu.Lock()
+	client := s.client
+	s.mu.Unlock()
+	if client == nil {
+		return io.ErrClosedPipe
+	}
+	msg, err := client.ReadMediaMessage()
+	if err == nil {
+		s.received = true
+		s.writeMessage(msg)
+		return nil
+	}
+	if s.ctx.Err() != nil {
+		return io.EOF
+	}
+	if errors.Is(err, ErrStreamEnded) {
+		return io.EOF
+	}
+	return s.reconnect(err)
+}
+
+func (s *FLVStream) reconnect(cause error) error {
+	s.mu.Lock()
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+	}
+	s.mu.Unlock()
+
+	max := s.opts.MaxReconnects
+	if max == 0 {
+		max = DefaultMaxReconnects
+	}
+	backoff := s.opts.ReconnectBackoff
+	if backoff <= 0 {
+		backoff = DefaultReconnectBackoff
+	}
+	// 上一次连接一直没有收到数据时不再重连，避免对不可用的地址反复重试
+	if !s.received {
+		return cause
+	}
+	s.received = false
+
+	err := cause
+	for attempt := 1; attempt <= max; attempt++ {
+		if s.opts.OnReconnect != nil {
+			s.opts.OnReconnect(attempt, err)
+		}
+		select {
+		case <-s.ctx.Done():
+			return io.EOF
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+
+		var client *Client
+		client, err = s.dial()
+		if err != nil {
+			if errors.Is(err, ErrStreamNotFound) || s.ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			client.Close()
+			return io.EOF
+		}
+		s.client = client
+		s.mu.Unlock()
+		// 新连接的时间戳从中断前的最后时间戳继续
+		s.offset = s.lastTime
+		s.base = -1
+		return nil
+	}
+	return err
+}
+
+// writeMessage 将消息转换为 FLV tag 写入缓冲区
+func (s *FLVStream) writeMessage(msg *Message) {
+	switch msg.Type {
+	case TypeAggregate:
+		s.writeAggregate(msg)
+	case TypeDataAMF0:
+		payload := StripSetDataFrame(msg.Payload)
+		// 元数据的时间戳通常为 0，不作为时间戳起点
+		ts := s.offset
+		if s.base >= 0 {
+			ts = s.timestamp(msg.Timestamp)
+		}
+		s.writeTag(TypeDataAMF0, ts, payload)
+	default:
+		s.writeTag(msg.Type, s.timestamp(msg.Timestamp), msg.Payload)
+	}
+}
+
+// writeAggregate 聚合消息拆分为多个 tag 写入
+func (s *FLVStream) writeAggregate(msg *Message) {
+	for _, sub := range SplitAggregate(msg) {
+		s.writeTag(sub.Type, s.timestamp(sub.Timestamp), sub.Payload)
+	}
+}
+
+// SplitAggregate 拆分聚合消息，聚合消息的内容是若干个 FLV tag，时间戳相对于第一个子 tag
+func SplitAggregate(msg *Message) []*Message {
+	var out []*Message
+	data := msg.Payload
+	first := int64(-1)
+	for len(data) >= flvTagHeaderSize {
+		size := int(data[1])<<16 | int(data[2])<<8 | int(data[3])
+		if len(data) < flvTagHeaderSize+size {
+			break
+		}
+		ts := uint32(data[7])<<24 | uint32(data[4])<<16 | uint32(data[5])<<8 | uint32(data[6])
+		if first < 0 {
+			first = int64(ts)
+		}
+		out = append(out, &Message{
+			Type:      data[0] & 0x1f,
+			StreamID:  msg.StreamID,
+			Timestamp: msg.Timestamp + uint32(int64(ts)-first),
+			Payload:   data[flvTagHeaderSize : flvTagHeaderSize+size],
+		})
+		data = data[flvTagHeaderSize+size:]
+		if len(data) >= 4 {
+			data = data[4:]
+		}
+	}
+	return out
+}
+
+// timestamp 计算写入文件的时间戳，重连后接续之前的时间戳
+func (s *FLVStream) timestamp(ts uint32) uint32 {
+	if s.base < 0 {
+		s.base = int64(ts)
+	}
+	out := s.offset
+	if int64(ts) > s.base {
+		out += uint32(int64(ts) - s.base)
+	}
+	if out > s.lastTime {
+		s.lastTime = out
+	}
+	return out
+}
+
+func (s *FLVStream) writeTag(typeID uint8, ts uint32, data []byte) {
+	s.buf.Write(AppendFLVTag(nil, typeID, ts, data))
+}
+
+// AppendFLVTag 将一个 FLV tag（包括末尾的 PreviousTagSize）追加到 b
+func AppendFLVTag(b []byte, typeID uint8, ts uint32, data []byte) []byte {
+	size := len(data)
+	b = append(b,
+		typeID,
+		byte(size>>16), byte(size>>8), byte(size),
+		byte(ts>>16), byte(ts>>8), byte(ts), byte(ts>>24),
+		0, 0, 0,
+	)
+	b = append(b, data...)
+	return binary.BigEndian.AppendUint32(b, uint32(flvTagHeaderSize+size))
+}
+
+// StripSetDataFrame 去掉推流端元数据消息的 @setDataFrame 前缀，写入文件的元数据只包含 onMetaData
+func StripSetDataFrame(payload []byte) []byte {
+	values, err := DecodeAMF0(payload)
+	if err != nil || len(values) < 2 || values[0] != "@setDataFrame" {
+		return payload
+	}
+	stripped, err := EncodeAMF0(values[1:]...)
+	if err != nil {
+		return payload
+	}
+	return stripped
+}
+
+// IsVideoSequenceHeader 判断视频数据是否为 AVC / HEVC 序列头或 Enhanced RTMP 的 SequenceStart
+func IsVideoSequenceHeader(p []byte) bool {
+	if len(p) < 2 {
+		return false
+	}
+	if p[0]&0x80 != 0 {
+		return p[0]&0x0f == 0
+	}
+	codec := p[0] & 0x0f
+	return (codec == 7 || codec == 12) && p[1] == 0
+}
+
+// IsKeyFrame 判断视频数据是否为关键帧
+func IsKeyFrame(p []byte) bool {
+	return len(p) > 0 && (p[0]>>4)&0x07 == 1
+}
+
+// IsAudioSequenceHeader 判断音频数据是否为 AAC 序列头
+func IsAudioSequenceHeader(p []byte) bool {
+	return len(p) >= 2 && p[0]>>4 == 10 && p[1] == 0
+}
@@ -0,0 +1,426 @@
+package rtmp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testServer 进程内的 RTMP 播放服务器
+// 每个连接依次发送 sessions 中对应的消息，发送完后根据 end 的设置断开或通知流结束
+type testServer struct {
+	t        *testing.T
+	listener net.Listener
+	sessions [][]*Message
+	// endWithStatus 最后一个会话结束时发送 UnpublishNotify，而不是直接断开
+	endWithStatus bool
+	// notFound 播放时返回 StreamNotFound
+	notFound bool
+	// stall 发送完消息后保持连接但不再发送数据
+	stall bool
+
+	connections atomic.Int32
+	mu          sync.Mutex
+	app, stream string
+	wg          sync.WaitGroup
+}
+
+func newTestServer(t *testing.T) *testServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &testServer{t: t, listener: listener}
+	t.Cleanup(func() {
+		listener.Close()
+		s.wg.Wait()
+	})
+	return s
+}
+
+func (s *testServer) url(path string) *url.URL {
+	return &url.URL{Scheme: "rtmp", Host: s.listener.Addr().String(), Path: path}
+}
+
+func (s *testServer) serve() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			nc, err := s.listener.Accept()
+			if err != nil {
+				return
+			}
+			index := int(s.connections.Add(1)) - 1
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				defer nc.Close()
+				_ = s.handle(newConn(nc, 5*time.Second), index)
+			}()
+		}
+	}()
+}
+
+func (s *testServer) handle(c *Conn, index int) error {
+	if err := c.serverHandshake(); err != nil {
+		return err
+	}
+	// 使用较小的 chunk 大小，覆盖 chunk 重组
+	if err := c.SetWriteChunkSize(64); err != nil {
+		return err
+	}
+	for {
+		msg, err := c.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if msg.Type != TypeCommandAMF0 {
+			continue
+		}
+		cmd, txn, args, err := decodeCommand(msg)
+		if err != nil {
+			return err
+		}
+		switch cmd {
+		case "connect":
+			obj, _ := args[0].(Object)
+			s.mu.Lock()
+			s.app = obj.String("app")
+			s.mu.Unlock()
+			if err := c.WriteCommand(csidCommand, 0, "_result", txn, Object{"fmsVer": "test"},
+				Object{"level": "status", "code": "NetConnection.Connect.Success"}); err != nil {
+				return err
+			}
+		case "createStream":
+			if err := c.WriteCommand(csidCommand, 0, "_result", txn, nil, 1.0); err != nil {
+				return err
+			}
+		case "play":
+			s.mu.Lock()
+			s.stream, _ = args[1].(string)
+			s.mu.Unlock()
+			if s.notFound {
+				return c.WriteCommand(csidStreamCommand, 1, "onStatus", 0.0, nil,
+					Object{"level": "error", "code": "NetStream.Play.StreamNotFound"})
+			}
+			if err := c.WriteCommand(csidStreamCommand, 1, "onStatus", 0.0, nil,
+				Object{"level": "status", "code": "NetStream.Play.Start"}); err != nil {
+				return err
+			}
+			return s.sendSession(c, index)
+		}
+	}
+}
+
+func (s *testServer) sendSession(c *Conn, index int) error {
+	if index >= len(s.sessions) {
+		return nil
+	}
+	// 在媒体消息之间插入 ping，客户端需要回应后继续
+	if err := c.writeUserControl(eventPingRequest, 1234); err != nil {
+		return err
+	}
+	for _, msg := range s.sessions[index] {
+		csid := uint32(csidVideo)
+		if msg.Type == TypeAudio {
+			csid = csidAudio
+		}
+		if err := c.WriteMessage(csid, msg); err != nil {
+			return err
+		}
+	}
+	if s.stall {
+		time.Sleep(time.Second)
+		return nil
+	}
+	if s.endWithStatus && index == len(s.sessions)-1 {
+		return c.WriteCommand(csidStreamCommand, 1, "onStatus", 0.0, nil,
+			Object{"level": "status", "code": "NetStream.Play.UnpublishNotify"})
+	}
+	return nil
+}
+
+type flvTag struct {
+	typeID    uint8
+	timestamp uint32
+	data      []byte
+}
+
+func readFLVTags(t *testing.T, data []byte) []flvTag {
+	t.Helper()
+	require.GreaterOrEqual(t, len(data), 13)
+	require.Equal(t, []byte("FLV"), data[:3])
+	data = data[13:]
+	var tags []flvTag
+	for len(data) > 0 {
+		require.GreaterOrEqual(t, len(data), flvTagHeaderSize)
+		size := int(data[1])<<16 | int(data[2])<<8 | int(data[3])
+		ts := uint32(data[7])<<24 | uint32(data[4])<<16 | uint32(data[5])<<8 | uint32(data[6])
+		require.GreaterOrEqual(t, len(data), flvTagHeaderSize+size+4)
+		tags = append(tags, flvTag{data[0], ts, data[flvTagHeaderSize : flvTagHeaderSize+size]})
+		assert.Equal(t, uint32(flvTagHeaderSize+size), binary.BigEndian.Uint32(data[flvTagHeaderSize+size:]))
+		data = data[flvTagHeaderSize+size+4:]
+	}
+	return tags
+}
+
+func mediaSession(start uint32, count int) []*Message {
+	msgs := make([]*Message, 0, count)
+	for i := 0; i < count; i++ {
+		typeID := TypeVideo
+		if i%2 == 1 {
+			typeID = TypeAudio
+		}
+		// 视频消息大于 chunk 大小，需要拆分为多个 chunk
+		payload := bytes.Repeat([]byte{byte(i)}, 50+i*40)
+		msgs = append(msgs, &Message{Type: typeID, StreamID: 1, Timestamp: start + uint32(i*20), Payload: payload})
+	}
+	return msgs
+}
+
+func TestFLVStreamPlay(t *testing.T) {
+	server := newTestServer(t)
+	meta, err := EncodeAMF0("@setDataFrame", "onMetaData", Object{"width": 1280.0})
+	require.NoError(t, err)
+	session := append([]*Message{{Type: TypeDataAMF0, StreamID: 1, Payload: meta}}, mediaSession(1000, 6)...)
+	server.sessions = [][]*Message{session}
+	server.endWithStatus = true
+	server.serve()
+
+	stream, err := OpenFLV(t.Context(), server.url("/live/room1?token=abc"), FLVOptions{})
+	require.NoError(t, err)
+	defer stream.Close()
+	data, err := io.ReadAll(stream)
+	require.NoError(t, err)
+
+	assert.Equal(t, "live", server.app)
+	assert.Equal(t, "room1?token=abc", server.stream)
+
+	tags := readFLVTags(t, data)
+	require.Len(t, tags, 7)
+	values, err := DecodeAMF0(tags[0].data)
+	require.NoError(t, err)
+	assert.Equal(t, "onMetaData", values[0])
+	assert.Equal(t, 1280.0, values[1].(Object)["width"])
+	for i, tag := range tags[1:] {
+		assert.Equal(t, session[i+1].Type, tag.typeID)
+		assert.Equal(t, uint32(i*20), tag.timestamp)
+		assert.Equal(t, session[i+1].Payload, tag.data)
+	}
+}
+
+func TestFLVStreamReconnect(t *testing.T) {
+	server := newTestServer(t)
+	// 第一次连接发送 3 个消息后断开，重连后时间戳从 0 重新开始
+	server.sessions = [][]*Message{mediaSession(500, 3), mediaSession(0, 3)}
+	server.endWithStatus = true
+	server.serve()
+
+	var reconnects atomic.Int32
+	stream, err := OpenFLV(t.Context(), server.url("/live/room"), FLVOptions{
+		ReconnectBackoff: 10 * time.Millisecond,
+		OnReconnect:      func(int, error) { reconnects.Add(1) },
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+	data, err := io.ReadAll(stream)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(1), reconnects.Load())
+	assert.Equal(t, int32(2), server.connections.Load())
+	tags := readFLVTags(t, data)
+	require.Len(t, tags, 6)
+	var timestamps []uint32
+	for _, tag := range tags {
+		timestamps = append(timestamps, tag.timestamp)
+	}
+	assert.Equal(t, []uint32{0, 20, 40, 40, 60, 80}, timestamps)
+}
+
+func TestFLVStreamErrors(t *testing.T) {
+	server := newTestServer(t)
+	server.notFound = true
+	server.serve()
+	_, err := OpenFLV(t.Context(), server.url("/live/missing"), FLVOptions{})
+	assert.True(t, errors.Is(err, ErrStreamNotFound))
+
+	// 读超时：服务器不再发送数据
+	stalled := newTestServer(t)
+	stalled.sessions = [][]*Message{mediaSession(0, 1)}
+	stalled.stall = true
+	stalled.serve()
+	stream, err := OpenFLV(t.Context(), stalled.url("/live/room"), FLVOptions{
+		ClientOptions:    ClientOptions{ReadTimeout: 100 * time.Millisecond},
+		MaxReconnects:    -1,
+		ReconnectBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+	_, err = io.ReadAll(stream)
+	var netErr net.Error
+	assert.True(t, errors.As(err, &netErr) && netErr.Timeout(), "%v", err)
+
+	// 连接被拒绝
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+	_, err = OpenFLV(t.Context(), &url.URL{Scheme: "rtmp", Host: addr, Path: "/live/a"}, FLVOptions{})
+	assert.Error(t, err)
+
+	// 关闭后读取立即返回
+	ctx, cancel := context.WithCancel(t.Context())
+	stream, err = OpenFLV(ctx, stalled.url("/live/room"), FLVOptions{})
+	require.NoError(t, err)
+	cancel()
+	stream.Close()
+	_, err = io.ReadAll(stream)
+	assert.NoError(t, err)
+}
+
+func TestReadChunkHeaderFormats(t *testing.T) {
+	// 手工构造使用 format 1 / 2 / 3 头部和扩展时间戳的 chunk
+	var raw bytes.Buffer
+	// format 0, csid 6, ts 扩展, len 4, video, stream 1
+	raw.Write([]byte{0x06, 0xff, 0xff, 0xff, 0, 0, 4, TypeVideo, 1, 0, 0, 0})
+	raw.Write([]byte{0x01, 0x00, 0x00, 0x00})
+	raw.Write([]byte{1, 2, 3, 4})
+	// format 1, ts delta 10, len 2, audio
+	raw.Write([]byte{0x46, 0, 0, 10, 0, 0, 2, TypeAudio})
+	raw.Write([]byte{5, 6})
+	// format 2, ts delta 30
+	raw.Write([]byte{0x86, 0, 0, 30})
+	raw.Write([]byte{7, 8})
+	// format 3：沿用上一次的 delta
+	raw.Write([]byte{0xc6})
+	raw.Write([]byte{9, 10})
+
+	client, server := net.Pipe()
+	defer client.Close()
+	go func() {
+		_, _ = server.Write(raw.Bytes())
+		server.Close()
+	}()
+	c := newConn(client, time.Second)
+	expected := []Message{
+		{Type: TypeVideo, StreamID: 1, Timestamp: 0x01000000, Payload: []byte{1, 2, 3, 4}},
+		{Type: TypeAudio, StreamID: 1, Timestamp: 0x0100000a, Payload: []byte{5, 6}},
+		{Type: TypeAudio, StreamID: 1, Timestamp: 0x01000028, Payload: []byte{7, 8}},
+		{Type: TypeAudio, StreamID: 1, Timestamp: 0x01000046, Payload: []byte{9, 10}},
+	}
+	for _, want := range expected {
+		msg, err := c.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, want, *msg)
+	}
+}
+
+func TestAMF0RoundTrip(t *testing.T) {
+	data, err := EncodeAMF0("connect", 1.0, Object{"app": "live", "fpad": false, "list": []any{1.0, "a"}}, nil)
+	require.NoError(t, err)
+	values, err := DecodeAMF0(data)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"connect", 1.0, Object{"app": "live", "fpad": false, "list": []any{1.0, "a"}}, nil}, values)
+
+	_, err = DecodeAMF0(data[:len(data)-3])
+	assert.Error(t, err)
+}
+
+func TestParseURL(t *testing.T) {
+	u, _ := url.Parse("rtmp://live.example.com/live/room_1?auth=x")
+	target, err := ParseURL(u)
+	require.NoError(t, err)
+	assert.Equal(t, "live.example.com:1935", target.Addr)
+	assert.Equal(t, "live", target.App)
+	assert.Equal(t, "room_1?auth=x", target.Stream)
+	assert.Equal(t, "rtmp://live.example.com/live", target.TcUrl)
+
+	u, _ = url.Parse("rtmps://live.example.com/app")
+	target, err = ParseURL(u)
+	require.NoError(t, err)
+	assert.True(t, target.TLS)
+	assert.Equal(t, "live.example.com:443", target.Addr)
+
+	_, err = ParseURL(&url.URL{Scheme: "http", Host: "a.com", Path: "/live/a"})
+	assert.Error(t, err)
+}
+
+type recordingPublisher struct {
+	mu       sync.Mutex
+	messages []*Message
+	closed   chan struct{}
+}
+
+func (p *recordingPublisher) OnMessage(msg *Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *recordingPublisher) Close() {
+	close(p.closed)
+}
+
+func TestServerPublish(t *testing.T) {
+	publisher := &recordingPublisher{closed: make(chan struct{})}
+	var request *PublishRequest
+	server := &Server{
+		OnPublish: func(req *PublishRequest) (Publisher, error) {
+			if req.Query.Get("secret") != "s3cret" {
+				return nil, errors.New("bad secret")
+			}
+			request = req
+			return publisher, nil
+		},
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+	base := "rtmp://" + listener.Addr().String()
+
+	// 密码错误时拒绝推流
+	u, _ := url.Parse(base + "/live/key1?secret=wrong")
+	client, err := Dial(t.Context(), u, ClientOptions{})
+	require.NoError(t, err)
+	err = client.Publish(t.Context())
+	var status *StatusError
+	require.ErrorAs(t, err, &status)
+	assert.Equal(t, "NetStream.Publish.BadName", status.Code)
+	client.Close()
+
+	u, _ = url.Parse(base + "/live/key1?secret=s3cret")
+	client, err = Dial(t.Context(), u, ClientOptions{})
+	require.NoError(t, err)
+	require.NoError(t, client.Publish(t.Context()))
+	sent := mediaSession(0, 5)
+	for _, msg := range sent {
+		require.NoError(t, client.WriteMedia(msg))
+	}
+	client.Close()
+
+	select {
+	case <-publisher.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publisher not closed")
+	}
+	assert.Equal(t, "live", request.App)
+	assert.Equal(t, "key1", request.Stream)
+	require.Len(t, publisher.messages, len(sent))
+	for i, msg := range publisher.messages {
+		assert.Equal(t, sent[i].Type, msg.Type)
+		assert.Equal(t, sent[i].Timestamp, msg.Timestamp)
+		assert.Equal(t, sent[i].Payload, msg.Payload)
+	}
+}
@@ -0,0 +1,260 @@
+package rtmp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultServerTimeout 服务端默认读写超时，推流端超过此时间没有发送数据视为断开
+const DefaultServerTimeout = 30 * time.Second
+
+// PublishRequest 推流请求
+type PublishRequest struct {
+	App    string
+	Stream string     // 流名称，不包含查询参数
+	Query  url.Values // 流名称和 app 中的查询参数
+	Remote net.Addr
+}
+
+// Publisher 接收一次推流的媒体消息
+type Publisher interface {
+	// OnMessage 收到音视频、元数据或聚合消息，返回错误时断开推流端
+	OnMessage(msg *Message) error
+	// Close 推流结束时调用
+	Close()
+}
+
+// Server 只接受推流的 RTMP 服务器
+type Server struct {
+	// OnPublish 推流端发起推流时调用，返回错误时拒绝推流
+	OnPublish func(req *PublishRequest) (Publisher, error)
+	// Timeout 单次读写的超时，为 0 时使用 DefaultServerTimeout
+	Timeout time.Duration
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[*Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// ErrServerClosed Serve 在服务器关闭后返回的错误
+var ErrServerClosed = errors.New("rtmp: server closed")
+
+// ListenAndServe 监听地址并处理连接
+func (s *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve 在 listener 上接受连接，直到服务器关闭
+func (s *Server) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		listener.Close()
+		return ErrServerClosed
+	}
+	if s.listeners == nil {
+		s.listeners = make(map[net.Listener]struct{})
+		s.conns = make(map[*Conn]struct{})
+	}
+	s.listeners[listener] = struct{}{}
+	s.mu.Unlock()
+
+	for {
+		nc, err := listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			delete(s.listeners, listener)
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		timeout := s.Timeout
+		if timeout <= 0 {
+			timeout = DefaultServerTimeout
+		}
+		c := newConn(nc, timeout)
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			nc.Close()
+			return ErrServerClosed
+		}
+		s.conns[c] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, c)
+				s.mu.Unlock()
+				c.Close()
+			}()
+			_ = s.serveConn(c)
+		}()
+	}
+}
+
+// Close 关闭所有监听和连接，并等待连接处理结束
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+// serverSession 一个推流连接的状态
+type serverSession struct {
+	*Conn
+	server    *Server
+	app       string
+	appQuery  url.Values
+	publisher Publisher
+}
+
+func (s *Server) serveConn(c *Conn) error {
+	if err := c.serverHandshake(); err != nil {
+		return err
+	}
+	sess := &serverSession{Conn: c, server: s}
+	defer func() {
+		if sess.publisher != nil {
+			sess.publisher.Close()
+		}
+	}()
+	for {
+		msg, err := c.ReadMessage()
+		if err != nil {
+			return err
+		}
+		switch msg.Type {
+		case TypeCommandAMF0, TypeCommandAMF3:
+			if err := sess.handleCommand(msg); err != nil {
+				return err
+			}
+		case TypeAudio, TypeVideo, TypeDataAMF0, TypeAggregate:
+			if sess.publisher == nil {
+				continue
+			}
+			if err := sess.publisher.OnMessage(msg); err != nil {
+				return err
+			}
+		case TypeDataAMF3:
+			if sess.publisher == nil || len(msg.Payload) == 0 {
+				continue
+			}
+			msg.Type = TypeDataAMF0
+			msg.Payload = msg.Payload[1:]
+			if err := sess.publisher.OnMessage(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// errPublishEnded 推流端主动结束推流
+var errPublishEnded = errors.New("rtmp: publish ended")
+
+func (sess *serverSession) handleCommand(msg *Message) error {
+	cmd, txn, args, err := decodeCommand(msg)
+	if err != nil {
+		return err
+	}
+	switch cmd {
+	case "connect":
+		var obj Object
+		if len(args) > 0 {
+			obj, _ = args[0].(Object)
+		}
+		app, rawQuery, _ := strings.Cut(obj.String("app"), "?")
+		sess.app = strings.TrimSuffix(app, "/")
+		sess.appQuery, _ = url.ParseQuery(rawQuery)
+		if err := sess.writeUint32Control(TypeWindowAckSize, defaultWindowAck); err != nil {
+			return err
+		}
+		if err := sess.writeUint32Control(TypeSetPeerBandwidth, defaultWindowAck, 2); err != nil {
+			return err
+		}
+		if err := sess.SetWriteChunkSize(outChunkSize); err != nil {
+			return err
+		}
+		return sess.WriteCommand(csidCommand, 0, "_result", txn,
+			Object{"fmsVer": "FMS/3,0,1,123", "capabilities": 31.0},
+			Object{"level": "status", "code": "NetConnection.Connect.Success", "description": "Connection succeeded.", "objectEncoding": 0.0})
+	case "createStream":
+		return sess.WriteCommand(csidCommand, 0, "_result", txn, nil, 1.0)
+	case "releaseStream", "FCPublish", "FCUnpublish", "getStreamLength":
+		if txn == 0 {
+			return nil
+		}
+		return sess.WriteCommand(csidCommand, 0, "_result", txn, nil)
+	case "publish":
+		return sess.handlePublish(msg.StreamID, args)
+	case "play":
+		_ = sess.WriteCommand(csidStreamCommand, msg.StreamID, "onStatus", 0.0, nil,
+			Object{"level": "error", "code": "NetStream.Play.Failed", "description": "play is not supported"})
+		return errors.New("rtmp: play is not supported")
+	case "deleteStream", "closeStream":
+		if sess.publisher != nil {
+			return errPublishEnded
+		}
+	}
+	return nil
+}
+
+func (sess *serverSession) handlePublish(streamID uint32, args []any) error {
+	if sess.publisher != nil {
+		return errors.New("rtmp: already publishing")
+	}
+	var name string
+	if len(args) > 1 {
+		name, _ = args[1].(string)
+	}
+	stream, rawQuery, _ := strings.Cut(name, "?")
+	query, _ := url.ParseQuery(rawQuery)
+	for k, vs := range sess.appQuery {
+		for _, v := range vs {
+			query.Add(k, v)
+		}
+	}
+	req := &PublishRequest{App: sess.app, Stream: stream, Query: query, Remote: sess.RemoteAddr()}
+
+	var publisher Publisher
+	err := errors.New("rtmp: publish is not accepted")
+	if sess.server.OnPublish != nil {
+		publisher, err = sess.server.OnPublish(req)
+	}
+	if err != nil {
+		_ = sess.WriteCommand(csidStreamCommand, streamID, "onStatus", 0.0, nil,
+			Object{"level": "error", "code": "NetStream.Publish.BadName", "description": err.Error()})
+		return fmt.Errorf("rtmp: publish %s/%s rejected: %w", req.App, req.Stream, err)
+	}
+	sess.publisher = publisher
+	if err := sess.writeUserControl(eventStreamBegin, streamID); err != nil {
+		return err
+	}
+	return sess.WriteCommand(csidStreamCommand, streamID, "onStatus", 0.0, nil,
+		Object{"level": "status", "code": "NetStream.Publish.Start", "description": "Start publishing"})
+}
@@ -0,0 +1,486 @@
+// Package scheduler 直播间轮询的集中调度器
+// 所有任务的下一次执行时间保存在一个最小堆中，由单个调度 goroutine 按时间取出，
+// 交给对应平台的有界 worker 池执行；没有到期任务时调度 goroutine 只等待定时器，不会空转
+package scheduler
+
+import (
+	"container/heap"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bilisentry "github.com/bililive-go/bililive-go/src/pkg/sentry"
+)
+
+const (
+	// DefaultWorkers 每个平台默认的 worker 数量
+	DefaultWorkers = 4
+	// DefaultBatchWindow 合并批量执行时，最多把多久之后才到期的同组任务提前执行
+	DefaultBatchWindow = 10 * time.Second
+)
+
+// Job 一个周期性任务
+// 同一个任务不会并发执行；每次执行结束后调用 next 计算下一次执行时间，返回 false 表示暂停，
+// 之后可通过 Scheduler.Ensure 重新加入队列
+type Job struct {
+	// Value 任务关联的对象，批量执行时用于找回每个任务的调用方
+	Value any
+
+	platform string
+	run      func()
+	next     func() (time.Time, bool)
+	batch    *Batch
+
+	// 以下字段由 Scheduler.mu 保护
+	at       time.Time
+	index    int    // 在堆中的位置，-1 表示不在队列中
+	batchKey string // 入队时计算的批量分组键
+	running  bool
+	removed  bool
+}
+
+// Batch 任务的批量执行方式
+// 一个任务到期时，同一分组中在 BatchWindow 内到期的其他任务会被提前取出，合并为一次 Run
+type Batch struct {
+	// Key 返回分组键，入队时计算；空字符串表示单独执行
+	Key func() string
+	// Max 单次合并的最大任务数，<= 0 表示不限制
+	Max int
+	// Run 执行一组任务，jobs 中第一个为到期的任务
+	Run func(jobs []*Job)
+}
+
+// SetBatch 设置任务的批量执行方式，需要在任务第一次加入调度器之前调用
+func (j *Job) SetBatch(b *Batch) *Job {
+	j.batch = b
+	return j
+}
+
+// NewJob 创建任务，platform 用于选择 worker 池
+func NewJob(platform string, run func(), next func() (time.Time, bool)) *Job {
+	return &Job{platform: platform, run: run, next: next, index: -1}
+}
+
+// Platform 任务所属平台
+func (j *Job) Platform() string {
+	return j.platform
+}
+
+// Stats 调度器运行状态
+type Stats struct {
+	Queued   int    `json:"queued"`   // 等待执行的任务数
+	Running  int    `json:"running"`  // 正在执行或等待 worker 的任务数
+	Workers  int    `json:"workers"`  // 已启动的 worker 数
+	Executed uint64 `json:"executed"` // 累计执行次数
+	Batches  uint64 `json:"batches"`  // 累计合并执行的批次数
+}
+
+// Scheduler 集中调度器
+type Scheduler struct {
+	mu      sync.Mutex
+	queue   jobHeap
+	pools   map[string]*pool
+	workers map[string]int // 平台单独设置的 worker 数量
+	def     int
+	running int
+	groups  map[string]map[*Job]struct{} // 批量分组键 -> 队列中的任务
+	window  time.Duration
+
+	executed atomic.Uint64
+	batches  atomic.Uint64
+	started  sync.Once
+	wake     chan struct{}
+	stop     chan struct{}
+	closed   bool
+}
+
+// New 创建调度器，workers 为每个平台默认的 worker 数量
+func New(workers int) *Scheduler {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	return &Scheduler{
+		pools:   make(map[string]*pool),
+		workers: make(map[string]int),
+		def:     workers,
+		groups:  make(map[string]map[*Job]struct{}),
+		window:  DefaultBatchWindow,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+}
+
+var defaultScheduler = New(DefaultWorkers)
+
+// GetScheduler 获取全局调度器实例
+func GetScheduler() *Scheduler {
+	return defaultScheduler
+}
+
+// SetPlatformWorkers 设置指定平台的 worker 数量，n <= 0 时恢复默认值
+// 已启动的 worker 不会减少，新的上限在需要启动 worker 时生效
+func (s *Scheduler) SetPlatformWorkers(platform string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n <= 0 {
+		delete(s.workers, platform)
+	} else {
+		s.workers[platform] = n
+	}
+	if p, ok := s.pools[platform]; ok {
+		p.setSize(s.workerLimit(platform))
+	}
+}
+
+func (s *Scheduler) workerLimit(platform string) int {
+	if n, ok := s.workers[platform]; ok {
+		return n
+	}
+	return s.def
+}
+
+// SetBatchWindow 设置合并批量执行的时间窗口，<= 0 时只合并已经到期的任务
+func (s *Scheduler) SetBatchWindow(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.window = max(d, 0)
+}
+
+// Ensure 任务既不在队列中也没有在执行时，按 next 计算的时间加入队列
+// 任务正在执行时不做处理，执行结束后会重新调用 next
+func (s *Scheduler) Ensure(j *Job) {
+	s.mu.Lock()
+	if s.closed || j.removed || j.running || j.index >= 0 {
+		s.mu.Unlock()
+		return
+	}
+	at, ok := j.next()
+	if ok {
+		s.push(j, at)
+	}
+	s.mu.Unlock()
+	if ok {
+		s.start()
+	}
+}
+
+// Reschedule 将已在队列中的任务改为 at 执行，例如配置的间隔发生变化
+func (s *Scheduler) Reschedule(j *Job, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j.index < 0 {
+		return
+	}
+	j.at = at
+	heap.Fix(&s.queue, j.index)
+	s.notify()
+}
+
+// Remove 移除任务，正在执行的任务结束后不再加入队列
+func (s *Scheduler) Remove(j *Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.removed = true
+	if j.index >= 0 {
+		s.dequeue(j)
+	}
+}
+
+// NextRunAt 返回任务在队列中的执行时间，不在队列中时返回 false
+func (s *Scheduler) NextRunAt(j *Job) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j.index < 0 {
+		return time.Time{}, false
+	}
+	return j.at, true
+}
+
+// IsScheduled 任务是否在队列中或正在执行
+func (s *Scheduler) IsScheduled(j *Job) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return j.index >= 0 || j.running
+}
+
+// Stats 返回调度器运行状态
+func (s *Scheduler) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := Stats{Queued: len(s.queue), Running: s.running, Executed: s.executed.Load(), Batches: s.batches.Load()}
+	for _, p := range s.pools {
+		stats.Workers += p.workerCount()
+	}
+	return stats
+}
+
+// Close 停止调度器和所有 worker，正在执行的任务会执行完
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.stop)
+	for _, p := range s.pools {
+		p.close()
+	}
+}
+
+// push 加入队列，调用方持有 s.mu
+func (s *Scheduler) push(j *Job, at time.Time) {
+	j.at = at
+	j.batchKey = ""
+	if j.batch != nil && j.batch.Key != nil {
+		j.batchKey = j.batch.Key()
+	}
+	if j.batchKey != "" {
+		group, ok := s.groups[j.batchKey]
+		if !ok {
+			group = make(map[*Job]struct{})
+			s.groups[j.batchKey] = group
+		}
+		group[j] = struct{}{}
+	}
+	heap.Push(&s.queue, j)
+	if j.index == 0 {
+		s.notify()
+	}
+}
+
+// dequeue 从队列和批量分组中移除任务，调用方持有 s.mu
+func (s *Scheduler) dequeue(j *Job) {
+	heap.Remove(&s.queue, j.index)
+	s.leaveGroup(j)
+}
+
+func (s *Scheduler) leaveGroup(j *Job) {
+	if j.batchKey == "" {
+		return
+	}
+	if group, ok := s.groups[j.batchKey]; ok {
+		delete(group, j)
+		if len(group) == 0 {
+			delete(s.groups, j.batchKey)
+		}
+	}
+}
+
+// collect 取出到期任务 j 以及同一分组中在时间窗口内到期的任务，调用方持有 s.mu
+func (s *Scheduler) collect(j *Job, now time.Time) []*Job {
+	s.leaveGroup(j)
+	jobs := []*Job{j}
+	if j.batchKey == "" {
+		return jobs
+	}
+	deadline := now.Add(s.window)
+	for other := range s.groups[j.batchKey] {
+		if j.batch.Max > 0 && len(jobs) >= j.batch.Max {
+			break
+		}
+		if other.at.After(deadline) {
+			continue
+		}
+		s.dequeue(other)
+		jobs = append(jobs, other)
+	}
+	return jobs
+}
+
+// notify 唤醒调度 goroutine 重新计算等待时间
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) start() {
+	s.started.Do(func() {
+		bilisentry.Go(s.dispatch)
+	})
+}
+
+// dispatch 调度循环：取出所有到期的任务交给 worker 池，然后等待到下一个任务的时间
+func (s *Scheduler) dispatch() {
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	for {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return
+		}
+		now := time.Now()
+		for len(s.queue) > 0 && !s.queue[0].at.After(now) {
+			jobs := s.collect(heap.Pop(&s.queue).(*Job), now)
+			for _, j := range jobs {
+				j.running = true
+			}
+			s.running += len(jobs)
+			s.poolFor(jobs[0].platform).push(jobs)
+		}
+		wait := time.Duration(-1)
+		if len(s.queue) > 0 {
+			wait = s.queue[0].at.Sub(now)
+		}
+		s.mu.Unlock()
+
+		if wait < 0 {
+			select {
+			case <-s.wake:
+			case <-s.stop:
+				return
+			}
+			continue
+		}
+		timer.Reset(wait)
+		select {
+		case <-timer.C:
+		case <-s.wake:
+			timer.Stop()
+		case <-s.stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// poolFor 返回平台的 worker 池，调用方持有 s.mu
+func (s *Scheduler) poolFor(platform string) *pool {
+	p, ok := s.pools[platform]
+	if !ok {
+		p = newPool(s, s.workerLimit(platform))
+		s.pools[platform] = p
+	}
+	return p
+}
+
+// execute 在 worker 中执行任务，结束后重新加入队列
+func (s *Scheduler) execute(jobs []*Job) {
+	defer func() {
+		for _, j := range jobs {
+			s.finish(j)
+		}
+	}()
+	defer bilisentry.Recover()
+	if len(jobs) > 1 || jobs[0].batchKey != "" {
+		s.batches.Add(1)
+		jobs[0].batch.Run(jobs)
+		return
+	}
+	jobs[0].run()
+}
+
+func (s *Scheduler) finish(j *Job) {
+	s.executed.Add(1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.running = false
+	s.running--
+	if s.closed || j.removed {
+		return
+	}
+	if at, ok := j.next(); ok {
+		s.push(j, at)
+	}
+}
+
+// pool 单个平台的有界 worker 池，worker 按需启动，最多 size 个
+type pool struct {
+	s       *Scheduler
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   [][]*Job
+	size    int
+	workers int
+	idle    int
+	closed  bool
+}
+
+func newPool(s *Scheduler, size int) *pool {
+	p := &pool{s: s, size: size}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+func (p *pool) setSize(n int) {
+	p.mu.Lock()
+	p.size = n
+	p.mu.Unlock()
+}
+
+func (p *pool) workerCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.workers
+}
+
+func (p *pool) push(jobs []*Job) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.queue = append(p.queue, jobs)
+	if len(p.queue) > p.idle && p.workers < p.size {
+		p.workers++
+		bilisentry.Go(p.work)
+		return
+	}
+	p.cond.Signal()
+}
+
+func (p *pool) close() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	p.cond.Broadcast()
+}
+
+func (p *pool) work() {
+	p.mu.Lock()
+	for {
+		for len(p.queue) == 0 && !p.closed {
+			p.idle++
+			p.cond.Wait()
+			p.idle--
+		}
+		if p.closed {
+			p.workers--
+			p.mu.Unlock()
+			return
+		}
+		jobs := p.queue[0]
+		p.queue[0] = nil
+		p.queue = p.queue[1:]
+		p.mu.Unlock()
+		p.s.execute(jobs)
+		p.mu.Lock()
+	}
+}
+
+// jobHeap 按执行时间排序的最小堆
+type jobHeap []*Job
+
+func (h jobHeap) Len() int           { return len(h) }
+func (h jobHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+func (h jobHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *jobHeap) Push(x any) {
+	j := x.(*Job)
+	j.index = len(*h)
+	*h = append(*h, j)
+}
+
+func (h *jobHeap) Pop() any {
+	old := *h
+	n := len(old)
+	j := old[n-1]
+	old[n-1] = nil
+	j.index = -1
+	*h = old[:n-1]
+	return j
+}
@@ -0,0 +1,271 @@
+package scheduler
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerRunsInTimeOrder(t *testing.T) {
+	s := New(1)
+	defer s.Close()
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	now := time.Now()
+	for i, delay := range []time.Duration{30, 10, 20} {
+		wg.Add(1)
+		at := now.Add(delay * time.Millisecond)
+		once := false
+		s.Ensure(NewJob("p", func() {
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			wg.Done()
+		}, func() (time.Time, bool) {
+			if once {
+				return time.Time{}, false
+			}
+			once = true
+			return at, true
+		}))
+	}
+	wg.Wait()
+	assert.Equal(t, []int{1, 2, 0}, order)
+}
+
+func TestSchedulerRepeatsAndRemoves(t *testing.T) {
+	s := New(2)
+	defer s.Close()
+
+	var runs atomic.Int32
+	done := make(chan struct{})
+	var j *Job
+	j = NewJob("p", func() {
+		if runs.Add(1) == 3 {
+			s.Remove(j)
+			close(done)
+		}
+	}, func() (time.Time, bool) {
+		return time.Now().Add(time.Millisecond), true
+	})
+	s.Ensure(j)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not run")
+	}
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(3), runs.Load())
+	assert.False(t, s.IsScheduled(j))
+}
+
+func TestSchedulerPausedJobCanBeResumed(t *testing.T) {
+	s := New(1)
+	defer s.Close()
+
+	var active atomic.Bool
+	ran := make(chan struct{}, 1)
+	j := NewJob("p", func() { ran <- struct{}{} }, func() (time.Time, bool) {
+		return time.Now(), active.Load()
+	})
+	s.Ensure(j)
+	assert.False(t, s.IsScheduled(j))
+
+	active.Store(true)
+	s.Ensure(j)
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not run")
+	}
+	active.Store(false)
+}
+
+func TestSchedulerWorkerLimit(t *testing.T) {
+	s := New(2)
+	defer s.Close()
+
+	var current, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		once := false
+		s.Ensure(NewJob("p", func() {
+			n := current.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			current.Add(-1)
+			wg.Done()
+		}, func() (time.Time, bool) {
+			if once {
+				return time.Time{}, false
+			}
+			once = true
+			return time.Now(), true
+		}))
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+	assert.Equal(t, 2, s.Stats().Workers)
+}
+
+func TestSchedulerBatch(t *testing.T) {
+	s := New(1)
+	defer s.Close()
+	s.SetBatchWindow(time.Hour)
+
+	var mu sync.Mutex
+	var batches [][]any
+	var wg sync.WaitGroup
+	batch := &Batch{
+		Key: func() string { return "k" },
+		Max: 3,
+		Run: func(jobs []*Job) {
+			mu.Lock()
+			defer mu.Unlock()
+			var values []any
+			for _, j := range jobs {
+				values = append(values, j.Value)
+				wg.Done()
+			}
+			batches = append(batches, values)
+		},
+	}
+	// 5 个任务：第一个先到期，其余在窗口内，按每批最多 3 个合并
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		at := now.Add(100*time.Millisecond + time.Duration(i)*10*time.Millisecond)
+		once := false
+		j := NewJob("p", func() { t.Error("single run should not be used") }, func() (time.Time, bool) {
+			if once {
+				return time.Time{}, false
+			}
+			once = true
+			return at, true
+		}).SetBatch(batch)
+		j.Value = i
+		s.Ensure(j)
+	}
+	wg.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	if assert.Len(t, batches, 2) {
+		assert.Len(t, batches[0], 3)
+		assert.Equal(t, 0, batches[0][0])
+		assert.Len(t, batches[1], 2)
+	}
+	assert.Equal(t, uint64(2), s.Stats().Batches)
+}
+
+// benchmarkRooms 基准测试使用的直播间数量和平台数量
+const (
+	benchmarkRooms     = 2000
+	benchmarkPlatforms = 10
+)
+
+// BenchmarkScheduler 集中调度：所有直播间共享一个调度 goroutine 和每个平台的有界 worker 池
+// 每次操作为一次轮询，goroutines 为运行中的 goroutine 数量
+func BenchmarkScheduler(b *testing.B) {
+	s := New(DefaultWorkers)
+	defer s.Close()
+
+	var remaining atomic.Int64
+	remaining.Store(int64(b.N))
+	done := make(chan struct{})
+	base := runtime.NumGoroutine()
+	b.ResetTimer()
+	for i := 0; i < benchmarkRooms; i++ {
+		s.Ensure(NewJob(fmt.Sprintf("p%d", i%benchmarkPlatforms), func() {
+			if remaining.Add(-1) == 0 {
+				close(done)
+			}
+		}, func() (time.Time, bool) {
+			return time.Now().Add(time.Millisecond), remaining.Load() > 0
+		}))
+	}
+	<-done
+	b.ReportMetric(float64(runtime.NumGoroutine()-base), "goroutines")
+}
+
+// BenchmarkPerRoomGoroutines 旧的方式：每个直播间一个调度 goroutine，没有等待者时每 100ms 唤醒检查一次
+func BenchmarkPerRoomGoroutines(b *testing.B) {
+	var remaining atomic.Int64
+	remaining.Store(int64(b.N))
+	done := make(chan struct{})
+	stop := make(chan struct{})
+	var once sync.Once
+	base := runtime.NumGoroutine()
+	b.ResetTimer()
+	for i := 0; i < benchmarkRooms; i++ {
+		go func() {
+			for {
+				select {
+				case <-stop:
+					return
+				case <-time.After(time.Millisecond):
+				}
+				if remaining.Add(-1) == 0 {
+					once.Do(func() { close(done) })
+				}
+			}
+		}()
+	}
+	<-done
+	b.ReportMetric(float64(runtime.NumGoroutine()-base), "goroutines")
+	close(stop)
+}
+
+// BenchmarkIdleWakeups 没有到期任务时的唤醒开销：集中调度在等待期间不会被唤醒，
+// 旧的方式每个直播间每 100ms 唤醒一次；每次操作为 100ms 的空闲时间，wakeups 为期间的唤醒次数
+func BenchmarkIdleWakeups(b *testing.B) {
+	b.Run("scheduler", func(b *testing.B) {
+		s := New(DefaultWorkers)
+		defer s.Close()
+		var wakeups atomic.Int64
+		for i := 0; i < benchmarkRooms; i++ {
+			s.Ensure(NewJob("p", func() { wakeups.Add(1) }, func() (time.Time, bool) {
+				return time.Now().Add(time.Hour), true
+			}))
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			time.Sleep(100 * time.Millisecond)
+		}
+		b.ReportMetric(float64(wakeups.Load())/float64(b.N), "wakeups/op")
+	})
+	b.Run("per-room", func(b *testing.B) {
+		var wakeups atomic.Int64
+		stop := make(chan struct{})
+		for i := 0; i < benchmarkRooms; i++ {
+			go func() {
+				for {
+					select {
+					case <-stop:
+						return
+					case <-time.After(100 * time.Millisecond):
+						wakeups.Add(1)
+					}
+				}
+			}()
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			time.Sleep(100 * time.Millisecond)
+		}
+		b.ReportMetric(float64(wakeups.Load())/float64(b.N), "wakeups/op")
+		close(stop)
+	})
+}
@@ -0,0 +1,331 @@
+// Package storyboard 为录制文件生成故事板雪碧图、WebVTT 缩略图轨道和音频波形
+// 雪碧图按固定间隔截取画面并拼接为网格，网页播放器拖动进度条时按 WebVTT 中的坐标显示对应的缩略图
+package storyboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ManifestFile 故事板描述文件
+	ManifestFile = "storyboard.json"
+	// VTTFile 使用相对路径引用雪碧图的 WebVTT 缩略图轨道，便于本地播放器直接使用
+	VTTFile = "thumbnails.vtt"
+	// WaveformFile 音频波形文件
+	WaveformFile = "waveform.json"
+
+	// siblingDirName 视频所在目录下存放故事板的隐藏目录
+	siblingDirName = ".storyboard"
+
+	manifestVersion = 1
+)
+
+// Options 生成选项，零值使用默认值
+type Options struct {
+	// Interval 截图间隔，视频过长时会自动加大以满足 MaxFrames
+	Interval time.Duration
+	// MaxFrames 最多截取的画面数
+	MaxFrames int
+	// TileWidth / TileHeight 每个缩略图的尺寸，画面按比例缩放后居中填充
+	TileWidth  int
+	TileHeight int
+	// Columns / Rows 每张雪碧图的网格
+	Columns int
+	Rows    int
+	// PeaksPerSecond 波形每秒的采样点数，音频过长时会自动降低以满足 MaxPeaks
+	PeaksPerSecond int
+	MaxPeaks       int
+}
+
+func (o Options) withDefaults() Options {
+	if o.Interval <= 0 {
+		o.Interval = 10 * time.Second
+	}
+	if o.MaxFrames <= 0 {
+		o.MaxFrames = 1000
+	}
+	if o.TileWidth <= 0 || o.TileHeight <= 0 {
+		o.TileWidth, o.TileHeight = 160, 90
+	}
+	if o.Columns <= 0 || o.Rows <= 0 {
+		o.Columns, o.Rows = 10, 10
+	}
+	if o.PeaksPerSecond <= 0 {
+		o.PeaksPerSecond = 10
+	}
+	if o.MaxPeaks <= 0 {
+		o.MaxPeaks = 20000
+	}
+	return o
+}
+
+// Manifest 故事板描述
+type Manifest struct {
+	Version    int       `json:"version"`
+	Duration   float64   `json:"duration"` // 视频时长（秒）
+	Interval   float64   `json:"interval"` // 截图间隔（秒）
+	TileWidth  int       `json:"tile_width"`
+	TileHeight int       `json:"tile_height"`
+	Columns    int       `json:"columns"`
+	Rows       int       `json:"rows"`
+	Count      int       `json:"count"`   // 缩略图数量
+	Sprites    []string  `json:"sprites"` // 雪碧图文件名，纯音频文件为空
+	Waveform   string    `json:"waveform,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	// SourceModTime 生成时视频文件的修改时间，视频之后再被修改时故事板过期
+	SourceModTime time.Time `json:"source_mod_time,omitempty"`
+}
+
+// IsFresh 判断故事板是否与修改时间为 modTime 的视频一致
+func (m *Manifest) IsFresh(modTime time.Time) bool {
+	if m.SourceModTime.IsZero() {
+		// 旧版本的故事板没有记录视频的修改时间
+		return !modTime.After(m.CreatedAt)
+	}
+	return !modTime.After(m.SourceModTime)
+}
+
+// Tile 返回第 i 个缩略图所在的雪碧图序号和坐标
+func (m *Manifest) Tile(i int) (sheet, x, y int) {
+	perSheet := m.Columns * m.Rows
+	sheet = i / perSheet
+	pos := i % perSheet
+	return sheet, (pos % m.Columns) * m.TileWidth, (pos / m.Columns) * m.TileHeight
+}
+
+// WriteVTT 输出 WebVTT 缩略图轨道，spriteURL 返回第 n 张雪碧图的地址
+func (m *Manifest) WriteVTT(w io.Writer, spriteURL func(sheet int) string) error {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	for i := 0; i < m.Count; i++ {
+		start := float64(i) * m.Interval
+		end := math.Min(start+m.Interval, m.Duration)
+		if end <= start {
+			break
+		}
+		sheet, x, y := m.Tile(i)
+		fmt.Fprintf(&b, "\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n",
+			vttTime(start), vttTime(end), spriteURL(sheet), x, y, m.TileWidth, m.TileHeight)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func vttTime(sec float64) string {
+	ms := int64(math.Round(sec * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// SiblingDir 视频旁边的故事板目录：同目录下 .storyboard/<文件名>
+func SiblingDir(videoPath string) string {
+	return filepath.Join(filepath.Dir(videoPath), siblingDirName, filepath.Base(videoPath))
+}
+
+// Load 读取目录中的故事板描述，不存在时返回 os.ErrNotExist
+func Load(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+var (
+	durationRe = regexp.MustCompile(`Duration: (\d+):(\d{2}):(\d{2}(?:\.\d+)?)`)
+	videoRe    = regexp.MustCompile(`Stream #\S+.*: Video: `)
+	audioRe    = regexp.MustCompile(`Stream #\S+.*: Audio: `)
+)
+
+// probeResult ffmpeg 输出中解析出的媒体信息
+type probeResult struct {
+	duration float64
+	hasVideo bool
+	hasAudio bool
+}
+
+// parseProbe 解析 ffmpeg -i 输出的时长和流信息
+func parseProbe(out string) probeResult {
+	var r probeResult
+	if m := durationRe.FindStringSubmatch(out); m != nil {
+		h, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		sec, _ := strconv.ParseFloat(m[3], 64)
+		r.duration = float64(h*3600+minute*60) + sec
+	}
+	r.hasVideo = videoRe.MatchString(out)
+	r.hasAudio = audioRe.MatchString(out)
+	return r
+}
+
+func probe(ctx context.Context, ffmpegPath, input string) (probeResult, error) {
+	// 没有指定输出时 ffmpeg 会以错误退出，只需要它打印的媒体信息
+	out, _ := exec.CommandContext(ctx, ffmpegPath, "-hide_banner", "-i", input).CombinedOutput()
+	r := parseProbe(string(out))
+	if r.duration <= 0 {
+		return r, fmt.Errorf("failed to get duration of %s", input)
+	}
+	return r, nil
+}
+
+// locks 同一目录的生成串行执行，避免并发请求重复生成
+var locks sync.Map
+
+// Ensure 返回 dir 中与视频一致的故事板，不存在或视频在生成之后又被修改时重新生成
+// 获取锁之后再检查一次，并发请求等待同一次生成完成，视频每次修改最多生成一次
+func Ensure(ctx context.Context, ffmpegPath, input, dir string, opts Options) (*Manifest, error) {
+	mu, _ := locks.LoadOrStore(dir, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+	stat, err := os.Stat(input)
+	if err != nil {
+		return nil, err
+	}
+	if m, err := Load(dir); err == nil && m.IsFresh(stat.ModTime()) {
+		return m, nil
+	}
+	return generate(ctx, ffmpegPath, input, dir, opts)
+}
+
+// Generate 生成故事板到 dir，已有内容会被替换
+func Generate(ctx context.Context, ffmpegPath, input, dir string, opts Options) (*Manifest, error) {
+	mu, _ := locks.LoadOrStore(dir, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+	return generate(ctx, ffmpegPath, input, dir, opts)
+}
+
+// generate 先写入临时目录，全部完成后再替换 dir，中途失败不会留下不完整的故事板
+func generate(ctx context.Context, ffmpegPath, input, dir string, opts Options) (*Manifest, error) {
+	opts = opts.withDefaults()
+	// 在读取视频之前记录修改时间，生成期间的写入会让故事板在下次请求时过期
+	stat, err := os.Stat(input)
+	if err != nil {
+		return nil, err
+	}
+	info, err := probe(ctx, ffmpegPath, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), "."+filepath.Base(dir)+".tmp-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	if err := os.Chmod(tmp, 0755); err != nil {
+		return nil, err
+	}
+
+	interval := math.Max(math.Ceil(opts.Interval.Seconds()), math.Ceil(info.duration/float64(opts.MaxFrames)))
+	m := &Manifest{
+		Version:    manifestVersion,
+		Duration:   info.duration,
+		Interval:   interval,
+		TileWidth:  opts.TileWidth,
+		TileHeight: opts.TileHeight,
+		Columns:    opts.Columns,
+		Rows:       opts.Rows,
+		Sprites:    []string{},
+		CreatedAt:  time.Now(),
+
+		SourceModTime: stat.ModTime(),
+	}
+
+	if info.hasVideo {
+		if err := generateSprites(ctx, ffmpegPath, input, tmp, m); err != nil {
+			return nil, err
+		}
+	}
+	if info.hasAudio {
+		samplesPerPixel := max(waveformSampleRate/opts.PeaksPerSecond,
+			int(math.Ceil(info.duration*waveformSampleRate/float64(opts.MaxPeaks))))
+		if err := generateWaveform(ctx, ffmpegPath, input, filepath.Join(tmp, WaveformFile), samplesPerPixel); err != nil {
+			return nil, err
+		}
+		m.Waveform = WaveformFile
+	}
+	if len(m.Sprites) == 0 && m.Waveform == "" {
+		return nil, fmt.Errorf("no video or audio stream in %s", input)
+	}
+
+	if len(m.Sprites) > 0 {
+		f, err := os.Create(filepath.Join(tmp, VTTFile))
+		if err != nil {
+			return nil, err
+		}
+		err = m.WriteVTT(f, func(sheet int) string { return m.Sprites[sheet] })
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(tmp, ManifestFile), data, 0644); err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// generateSprites 按间隔截取关键帧并拼接为雪碧图
+// 只解码关键帧，长时间录制也能较快完成，截图时间与间隔的误差在一个 GOP 以内
+func generateSprites(ctx context.Context, ffmpegPath, input, dir string, m *Manifest) error {
+	w, h := m.TileWidth, m.TileHeight
+	filter := fmt.Sprintf("fps=1/%d,scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,tile=%dx%d",
+		int(m.Interval), w, h, w, h, m.Columns, m.Rows)
+	cmd := exec.CommandContext(ctx, ffmpegPath,
+		"-hide_banner", "-skip_frame", "nokey", "-i", input,
+		"-an", "-sn", "-vf", filter, "-q:v", "5", "-start_number", "0",
+		"-y", filepath.Join(dir, "sprite_%03d.jpg"),
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg sprite generation failed: %w: %s", err, lastLine(string(out)))
+	}
+	sprites, err := filepath.Glob(filepath.Join(dir, "sprite_*.jpg"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(sprites)
+	for _, s := range sprites {
+		m.Sprites = append(m.Sprites, filepath.Base(s))
+	}
+	m.Count = min(int(math.Ceil(m.Duration/m.Interval)), len(m.Sprites)*m.Columns*m.Rows)
+	return nil
+}
+
+func lastLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
@@ -0,0 +1,117 @@
+package storyboard
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProbe(t *testing.T) {
+	out := `Input #0, flv, from 'a.flv':
+  Duration: 01:02:03.45, start: 0.000000, bitrate: 2000 kb/s
+  Stream #0:0: Video: h264 (High), yuv420p, 1920x1080, 30 fps
+  Stream #0:1: Audio: aac (LC), 48000 Hz, stereo, fltp
+At least one output file must be specified`
+	r := parseProbe(out)
+	assert.InDelta(t, 3723.45, r.duration, 1e-9)
+	assert.True(t, r.hasVideo)
+	assert.True(t, r.hasAudio)
+
+	r = parseProbe("  Duration: 00:00:10.00, start: 0\n  Stream #0:0(und): Audio: aac (LC)\n")
+	assert.InDelta(t, 10, r.duration, 1e-9)
+	assert.False(t, r.hasVideo)
+	assert.True(t, r.hasAudio)
+}
+
+func TestManifestVTT(t *testing.T) {
+	m := &Manifest{Duration: 25, Interval: 10, TileWidth: 160, TileHeight: 90, Columns: 2, Rows: 1, Count: 3}
+	sheet, x, y := m.Tile(1)
+	assert.Equal(t, []int{0, 160, 0}, []int{sheet, x, y})
+	sheet, x, y = m.Tile(2)
+	assert.Equal(t, []int{1, 0, 0}, []int{sheet, x, y})
+
+	var buf bytes.Buffer
+	require.NoError(t, m.WriteVTT(&buf, func(sheet int) string { return "/s/" + string(rune('a'+sheet)) }))
+	assert.Equal(t, `WEBVTT
+
+00:00:00.000 --> 00:00:10.000
+/s/a#xywh=0,0,160,90
+
+00:00:10.000 --> 00:00:20.000
+/s/a#xywh=160,0,160,90
+
+00:00:20.000 --> 00:00:25.000
+/s/b#xywh=0,0,160,90
+`, buf.String())
+	assert.Equal(t, "01:01:01.500", vttTime(3661.5))
+}
+
+func TestComputeWaveform(t *testing.T) {
+	var pcm bytes.Buffer
+	for _, s := range []int16{0, 1000, -32768, 256, 32767, -512, 100} {
+		require.NoError(t, binary.Write(&pcm, binary.LittleEndian, s))
+	}
+	pcm.WriteByte(1) // 不完整的采样被忽略
+
+	w, err := ComputeWaveform(&pcm, 8000, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, w.Length)
+	assert.Equal(t, []int8{-128, 3, -2, 127, 0, 0}, w.Data)
+
+	_, err = ComputeWaveform(strings.NewReader(""), 8000, 0)
+	assert.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	video := filepath.Join(t.TempDir(), "a.flv")
+	dir := SiblingDir(video)
+	assert.Equal(t, filepath.Join(filepath.Dir(video), ".storyboard", "a.flv"), dir)
+
+	_, err := Load(dir)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestFile), []byte(`{"version":1,"count":2,"sprites":["sprite_000.jpg"]}`), 0644))
+	m, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, m.Count)
+	assert.Equal(t, []string{"sprite_000.jpg"}, m.Sprites)
+}
+
+func TestEnsureFresh(t *testing.T) {
+	video := filepath.Join(t.TempDir(), "a.flv")
+	require.NoError(t, os.WriteFile(video, []byte("flv"), 0644))
+	stat, err := os.Stat(video)
+	require.NoError(t, err)
+	dir := SiblingDir(video)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	data, err := json.Marshal(&Manifest{Version: manifestVersion, Count: 1, SourceModTime: stat.ModTime()})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestFile), data, 0644))
+
+	// 视频没有变化时直接使用已有的故事板，不调用 ffmpeg
+	m, err := Ensure(context.Background(), "/nonexistent/ffmpeg", video, dir, Options{})
+	require.NoError(t, err)
+	assert.True(t, m.IsFresh(stat.ModTime()))
+
+	// 视频被修改后需要重新生成
+	later := stat.ModTime().Add(time.Minute)
+	require.NoError(t, os.Chtimes(video, later, later))
+	assert.False(t, m.IsFresh(later))
+	_, err = Ensure(context.Background(), "/nonexistent/ffmpeg", video, dir, Options{})
+	assert.Error(t, err)
+
+	// 旧版本的故事板按生成时间判断
+	old := &Manifest{CreatedAt: later}
+	assert.True(t, old.IsFresh(later))
+	assert.False(t, old.IsFresh(later.Add(time.Second)))
+}
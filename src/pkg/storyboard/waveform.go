@@ -0,0 +1,118 @@
+package storyboard
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// waveformSampleRate 计算波形时的重采样率，只用于显示，不需要更高的采样率
+const waveformSampleRate = 8000
+
+// Waveform 音频波形，格式与 audiowaveform 的 JSON 输出一致，可直接用于 peaks.js 等前端组件
+// Data 依次为每个像素的最小值和最大值
+type Waveform struct {
+	Version         int    `json:"version"`
+	Channels        int    `json:"channels"`
+	SampleRate      int    `json:"sample_rate"`
+	SamplesPerPixel int    `json:"samples_per_pixel"`
+	Bits            int    `json:"bits"`
+	Length          int    `json:"length"`
+	Data            []int8 `json:"data"`
+}
+
+// ComputeWaveform 从单声道 16 位小端 PCM 数据计算波形，每 samplesPerPixel 个采样输出一对最小值和最大值
+func ComputeWaveform(r io.Reader, sampleRate, samplesPerPixel int) (*Waveform, error) {
+	if samplesPerPixel <= 0 {
+		return nil, errors.New("samples per pixel must be positive")
+	}
+	w := &Waveform{
+		Version:         2,
+		Channels:        1,
+		SampleRate:      sampleRate,
+		SamplesPerPixel: samplesPerPixel,
+		Bits:            8,
+		Data:            []int8{},
+	}
+	br := bufio.NewReaderSize(r, 64<<10)
+	var buf [2]byte
+	var lo, hi int16
+	n := 0
+	flush := func() {
+		w.Data = append(w.Data, int8(lo>>8), int8(hi>>8))
+		w.Length++
+		n = 0
+	}
+	for {
+		if _, err := io.ReadFull(br, buf[:]); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return nil, err
+		}
+		s := int16(binary.LittleEndian.Uint16(buf[:]))
+		if n == 0 {
+			lo, hi = s, s
+		} else {
+			lo, hi = min(lo, s), max(hi, s)
+		}
+		n++
+		if n == samplesPerPixel {
+			flush()
+		}
+	}
+	if n > 0 {
+		flush()
+	}
+	return w, nil
+}
+
+// generateWaveform 使用 ffmpeg 解码为单声道 PCM 并计算波形
+func generateWaveform(ctx context.Context, ffmpegPath, input, output string, samplesPerPixel int) error {
+	cmd := exec.CommandContext(ctx, ffmpegPath,
+		"-hide_banner", "-loglevel", "error", "-i", input,
+		"-vn", "-sn", "-ac", "1", "-ar", strconv.Itoa(waveformSampleRate),
+		"-f", "s16le", "-acodec", "pcm_s16le", "pipe:1",
+	)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr limitedBuffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	w, err := ComputeWaveform(stdout, waveformSampleRate, samplesPerPixel)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("ffmpeg waveform generation failed: %w: %s", err, lastLine(string(stderr)))
+	}
+	data, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, data, 0644)
+}
+
+// limitedBuffer 只保留最后 4KB 的 ffmpeg 错误输出
+type limitedBuffer []byte
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	*b = append(*b, p...)
+	if len(*b) > 4096 {
+		*b = (*b)[len(*b)-4096:]
+	}
+	return len(p), nil
+}
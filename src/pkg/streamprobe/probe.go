@@ -13,8 +13,10 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/bililive-go/bililive-go/src/pkg/bandwidth"
 	"github.com/bililive-go/bililive-go/src/pkg/livelogger"
 	"github.com/bililive-go/bililive-go/src/pkg/proxy"
+	"github.com/bililive-go/bililive-go/src/pkg/rtmp"
 )
 
 const (
@@ -40,6 +42,12 @@ type Config struct {
 
 	// Logger 日志记录器
 	Logger *livelogger.LiveLogger
+
+	// Tee 旁路输出（如转推），转发给下载器的数据会同时写入，写入不应阻塞，返回的错误会被忽略
+	Tee io.Writer
+
+	// LiveID 所属直播间，上游数据按该直播间计入带宽限制和统计
+	LiveID string
 }
 
 // StreamProbe 直播流探测代理
@@ -95,6 +103,11 @@ func (p *StreamProbe) Start(ctx context.Context) error {
 		p.cancel()
 		return fmt.Errorf("连接上游流失败: %w", err)
 	}
+	// 下载器和转推都从代理读取，在上游统一限制带宽
+	p.upstreamBody = bandwidth.GetManager().NewReader(p.ctx, bandwidth.Consumer{
+		Kind:   bandwidth.KindDownload,
+		LiveID: p.config.LiveID,
+	}, p.upstreamBody)
 
 	// 2. 探测流头信息
 	p.probeStreamHeader()
@@ -129,6 +142,10 @@ func (p *StreamProbe) GetHeaderInfo() *StreamHeaderInfo {
 
 // connectUpstream 连接上游直播流
 func (p *StreamProbe) connectUpstream() error {
+	if IsStreamRTMP(p.config.UpstreamURL) {
+		return p.connectRTMPUpstream()
+	}
+
 	// 创建带下载代理的 HTTP 客户端
 	transport := &http.Transport{
 		DialContext: (&net.Dialer{
@@ -171,6 +188,23 @@ func (p *StreamProbe) connectUpstream() error {
 	return nil
 }
 
+// connectRTMPUpstream 通过内置 RTMP 客户端拉流，转换为 FLV 字节流后与 HTTP-FLV 走相同的探测和转发流程
+func (p *StreamProbe) connectRTMPUpstream() error {
+	stream, err := rtmp.OpenFLV(p.ctx, p.config.UpstreamURL, rtmp.FLVOptions{
+		ClientOptions: rtmp.ClientOptions{Dialer: proxy.DownloadDialContext()},
+		OnReconnect: func(attempt int, err error) {
+			if p.config.Logger != nil {
+				p.config.Logger.Warnf("RTMP 连接中断，正在进行第 %d 次重连: %v", attempt, err)
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+	p.upstreamBody = stream
+	return nil
+}
+
 // probeStreamHeader 探测 FLV 流头信息
 func (p *StreamProbe) probeStreamHeader() {
 	// 判断是否为 FLV 流
@@ -220,8 +254,7 @@ func (p *StreamProbe) probeHLSStream() *StreamHeaderInfo {
 
 // isFLVStream 判断上游是否为 FLV 流
 func (p *StreamProbe) isFLVStream() bool {
-	urlPath := strings.ToLower(p.config.UpstreamURL.Path)
-	if strings.Contains(urlPath, ".flv") {
+	if IsStreamFLV(p.config.UpstreamURL) {
 		return true
 	}
 	// 检查 Content-Type
@@ -304,6 +337,9 @@ func (p *StreamProbe) handleStreamRequest(w http.ResponseWriter, r *http.Request
 			}
 		}
 	}
+	if p.upstreamResp == nil && p.isFLVStream() {
+		w.Header().Set("Content-Type", "video/x-flv")
+	}
 	w.Header().Set("Connection", "close")
 
 	// 设置 Flusher 以实时推送数据
@@ -321,6 +357,7 @@ func (p *StreamProbe) handleStreamRequest(w http.ResponseWriter, r *http.Request
 		if hasFlusher {
 			flusher.Flush()
 		}
+		p.tee(buffered)
 	}
 
 	// 2. 转发上游后续数据
@@ -346,6 +383,7 @@ func (p *StreamProbe) handleStreamRequest(w http.ResponseWriter, r *http.Request
 			if hasFlusher {
 				flusher.Flush()
 			}
+			p.tee(buf[:n])
 		}
 		if err != nil {
 			if !errors.Is(err, io.EOF) && !errors.Is(err, context.Canceled) {
@@ -356,6 +394,13 @@ func (p *StreamProbe) handleStreamRequest(w http.ResponseWriter, r *http.Request
 	}
 }
 
+// tee 将转发给下载器的数据写入旁路输出
+func (p *StreamProbe) tee(data []byte) {
+	if p.config.Tee != nil {
+		_, _ = p.config.Tee.Write(data)
+	}
+}
+
 // cleanup 清理资源
 func (p *StreamProbe) cleanup() {
 	if p.server != nil {
@@ -385,8 +430,15 @@ func (p *StreamProbe) notifyError(err error, msg string) {
 
 // IsStreamFLV 判断给定的 URL 是否看起来像 FLV 流
 // 这是一个工具函数，供外部判断是否应该使用 StreamProbe
+// RTMP 流由内置客户端转换为 FLV，同样视为 FLV 流
 func IsStreamFLV(u *url.URL) bool {
-	return strings.Contains(strings.ToLower(u.Path), ".flv")
+	return strings.Contains(strings.ToLower(u.Path), ".flv") || IsStreamRTMP(u)
+}
+
+// IsStreamRTMP 判断给定的 URL 是否为 RTMP 流
+func IsStreamRTMP(u *url.URL) bool {
+	scheme := strings.ToLower(u.Scheme)
+	return scheme == "rtmp" || scheme == "rtmps"
 }
 
 // IsStreamHLS 判断给定的 URL 是否看起来像 HLS 流
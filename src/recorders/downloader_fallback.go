@@ -0,0 +1,157 @@
+package recorders
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/types"
+)
+
+// DownloaderSwitch 一次下载器切换记录
+type DownloaderSwitch struct {
+	From       configs.DownloaderType `json:"from"`
+	To         configs.DownloaderType `json:"to"`
+	Failures   int                    `json:"failures"` // 切换前连续快速失败的次数
+	Reason     string                 `json:"reason"`   // 最后一次快速失败的原因
+	SwitchedAt time.Time              `json:"switched_at"`
+}
+
+// DownloaderSwitchedParam 下载器切换事件参数
+type DownloaderSwitchedParam struct {
+	Live live.Live
+	DownloaderSwitch
+}
+
+// GetLiveId 返回切换下载器的直播间 ID，用于 SSE 广播
+func (p DownloaderSwitchedParam) GetLiveId() types.LiveID {
+	return p.Live.GetLiveId()
+}
+
+// downloadAttempt 一次下载器运行的结果
+type downloadAttempt struct {
+	downloader configs.DownloaderType
+	feature    configs.Feature
+	duration   time.Duration
+	written    bool
+	err        error
+	// upstreamOffline 下载结束时直播间处于宽限期或上游报告未开播，失败与下载器无关
+	upstreamOffline bool
+}
+
+// fastFailureReason 返回快速失败的原因，不是快速失败时返回空字符串
+func (a *downloadAttempt) fastFailureReason() string {
+	var reason string
+	switch {
+	case !a.written:
+		reason = "没有写入数据"
+	case a.duration < a.feature.GetDownloaderFastFailureWindow():
+		reason = fmt.Sprintf("%.0f 秒内退出", a.duration.Seconds())
+	default:
+		return ""
+	}
+	if a.err != nil {
+		reason += ": " + a.err.Error()
+	}
+	return reason
+}
+
+// downloaderFallback 录制器的下载器回退状态
+// 录制器在直播会话结束时移除，状态随之重置；同一会话内重启录制器时由新录制器继承
+type downloaderFallback struct {
+	mu       sync.Mutex
+	index    int // 当前下载器在回退链中的位置
+	failures int // 当前下载器连续快速失败的次数
+	switches []DownloaderSwitch
+}
+
+// current 返回当前应使用的下载器
+func (f *downloaderFallback) current(chain []configs.DownloaderType) configs.DownloaderType {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return chain[f.index%len(chain)]
+}
+
+// record 记录一次下载结果，连续快速失败达到次数后切换到回退链中的下一个下载器，返回发生的切换
+func (f *downloaderFallback) record(attempt *downloadAttempt, now time.Time) *DownloaderSwitch {
+	chain := attempt.feature.GetDownloaderChain()
+	reason := attempt.fastFailureReason()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if attempt.upstreamOffline {
+		// 直播中断时所有下载器都会失败，既不计入也不重置连续失败次数
+		return nil
+	}
+	if reason == "" {
+		f.failures = 0
+		return nil
+	}
+	f.failures++
+	if len(chain) < 2 || f.failures < attempt.feature.GetDownloaderFallbackFailures() {
+		return nil
+	}
+
+	// 配置可能在录制期间变化，以实际使用的下载器确定下一个
+	if i := slices.Index(chain, attempt.downloader); i >= 0 {
+		f.index = i
+	}
+	f.index = (f.index + 1) % len(chain)
+	sw := DownloaderSwitch{
+		From:       attempt.downloader,
+		To:         chain[f.index],
+		Failures:   f.failures,
+		Reason:     reason,
+		SwitchedAt: now,
+	}
+	f.failures = 0
+	f.switches = append(f.switches, sw)
+	return &sw
+}
+
+// inherit 继承同一会话中上一个录制器的回退状态
+func (f *downloaderFallback) inherit(prev *downloaderFallback) {
+	prev.mu.Lock()
+	index, failures, switches := prev.index, prev.failures, slices.Clone(prev.switches)
+	prev.mu.Unlock()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.index, f.failures, f.switches = index, failures, switches
+}
+
+// status 返回当前连续快速失败次数和切换记录
+func (f *downloaderFallback) status() (int, []DownloaderSwitch) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.failures, slices.Clone(f.switches)
+}
+
+// inheritDownloaderFallback 重启录制器时，新录制器继承上一个录制器的回退状态
+func inheritDownloaderFallback(next, prev Recorder) {
+	nextRecorder, ok := next.(*recorder)
+	if !ok {
+		return
+	}
+	if prevRecorder, ok := prev.(*recorder); ok {
+		nextRecorder.fallback.inherit(&prevRecorder.fallback)
+	}
+}
+
+// outputWritten 检查下载器是否写入了数据，录播姬会输出带 _PART 后缀的分段文件
+func outputWritten(fileName string, downloader configs.DownloaderType) bool {
+	files := []string{fileName}
+	if downloader == configs.DownloaderBililiveRecorder {
+		files = append(files, findBililiveRecorderOutputFiles(fileName)...)
+	}
+	for _, file := range files {
+		if stat, err := os.Stat(file); err == nil && stat.Size() > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package recorders
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/live"
+	livemock "github.com/bililive-go/bililive-go/src/live/mock"
+)
+
+func TestDownloaderFallback(t *testing.T) {
+	feature := configs.Feature{
+		DownloaderType:             configs.DownloaderFFmpeg,
+		DownloaderFallback:         []configs.DownloaderType{configs.DownloaderNative},
+		DownloaderFallbackFailures: 2,
+	}
+	chain := feature.GetDownloaderChain()
+	f := &downloaderFallback{}
+	now := time.Now()
+	fastFailure := func(d configs.DownloaderType) *downloadAttempt {
+		return &downloadAttempt{downloader: d, feature: feature, duration: time.Second, written: true, err: errors.New("exit status 1")}
+	}
+
+	// 正常录制后重置连续失败次数
+	assert.Nil(t, f.record(fastFailure(configs.DownloaderFFmpeg), now))
+	assert.Nil(t, f.record(&downloadAttempt{downloader: configs.DownloaderFFmpeg, feature: feature, duration: time.Minute, written: true}, now))
+	assert.Nil(t, f.record(fastFailure(configs.DownloaderFFmpeg), now))
+	assert.Equal(t, configs.DownloaderFFmpeg, f.current(chain))
+
+	// 连续快速失败达到次数后切换
+	sw := f.record(fastFailure(configs.DownloaderFFmpeg), now)
+	require.NotNil(t, sw)
+	assert.Equal(t, configs.DownloaderFFmpeg, sw.From)
+	assert.Equal(t, configs.DownloaderNative, sw.To)
+	assert.Equal(t, 2, sw.Failures)
+	assert.Equal(t, "1 秒内退出: exit status 1", sw.Reason)
+	assert.Equal(t, configs.DownloaderNative, f.current(chain))
+
+	// 长时间运行但没有写入数据也视为快速失败，回退链用完后回到首选下载器
+	noData := &downloadAttempt{downloader: configs.DownloaderNative, feature: feature, duration: time.Hour}
+	assert.Nil(t, f.record(noData, now))
+	sw = f.record(noData, now)
+	require.NotNil(t, sw)
+	assert.Equal(t, configs.DownloaderFFmpeg, sw.To)
+	assert.Equal(t, "没有写入数据", sw.Reason)
+
+	// 重启录制器时继承回退状态
+	failures, switches := f.status()
+	assert.Zero(t, failures)
+	assert.Len(t, switches, 2)
+	next := &downloaderFallback{}
+	next.inherit(f)
+	_, inherited := next.status()
+	assert.Equal(t, switches, inherited)
+
+	// 没有备用下载器时不切换
+	single := configs.Feature{DownloaderType: configs.DownloaderFFmpeg, DownloaderFallbackFailures: 1}
+	assert.Nil(t, (&downloaderFallback{}).record(&downloadAttempt{downloader: configs.DownloaderFFmpeg, feature: single}, now))
+}
+
+func TestDownloaderFallbackUpstreamOffline(t *testing.T) {
+	feature := configs.Feature{
+		DownloaderType:             configs.DownloaderFFmpeg,
+		DownloaderFallback:         []configs.DownloaderType{configs.DownloaderNative},
+		DownloaderFallbackFailures: 2,
+	}
+	f := &downloaderFallback{}
+	now := time.Now()
+	failure := func(offline bool) *downloadAttempt {
+		return &downloadAttempt{downloader: configs.DownloaderFFmpeg, feature: feature, duration: time.Second, upstreamOffline: offline}
+	}
+
+	// 直播中断期间的失败不计入连续快速失败
+	assert.Nil(t, f.record(failure(false), now))
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, f.record(failure(true), now))
+	}
+	failures, _ := f.status()
+	assert.Equal(t, 1, failures)
+	assert.NotNil(t, f.record(failure(false), now))
+}
+
+// graceLive 处于宽限期的直播间
+type graceLive struct {
+	*livemock.MockLive
+	inGrace bool
+}
+
+func (l *graceLive) SetGracePeriod(time.Time) {}
+func (l *graceLive) InGracePeriod() bool      { return l.inGrace }
+
+func TestRecorderUpstreamOffline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cache := gcache.New(4).LRU().Build()
+	l := &graceLive{MockLive: livemock.NewMockLive(ctrl)}
+	r := &recorder{Live: l, cache: cache}
+
+	// 没有直播间信息时按正常失败处理
+	assert.False(t, r.upstreamOffline())
+
+	require.NoError(t, cache.Set(l, &live.Info{Status: true}))
+	assert.False(t, r.upstreamOffline())
+
+	l.inGrace = true
+	assert.True(t, r.upstreamOffline())
+
+	l.inGrace = false
+	require.NoError(t, cache.Set(l, &live.Info{Status: false}))
+	assert.True(t, r.upstreamOffline())
+}
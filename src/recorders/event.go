@@ -6,4 +6,8 @@ const (
 	RecorderStart   events.EventType = "RecorderStart"
 	RecorderStop    events.EventType = "RecorderStop"
 	RecorderRestart events.EventType = "RecorderRestart"
+	// RecorderPreempted 录制被优先级更高的直播间抢占，事件对象为 PreemptedParam
+	RecorderPreempted events.EventType = "RecorderPreempted"
+	// RecorderDownloaderSwitched 下载器连续快速失败后切换到回退链中的下一个，事件对象为 DownloaderSwitchedParam
+	RecorderDownloaderSwitched events.EventType = "RecorderDownloaderSwitched"
 )
@@ -21,11 +21,16 @@ type BroadcastRecorderStatusFunc func(liveId types.LiveID, status map[string]int
 // OnRecordingEndFunc 是录制结束时的回调函数类型
 type OnRecordingEndFunc func(ctx context.Context)
 
+// BroadcastRecordingQueueFunc 是用于广播录制排队状态的回调函数类型
+type BroadcastRecordingQueueFunc func(status RecordingQueueStatus)
+
 var (
 	// broadcastRecorderStatusFunc 全局广播函数，由 servers 包设置
 	broadcastRecorderStatusFunc BroadcastRecorderStatusFunc
 	// onRecordingEndFunc 录制结束时的回调函数，用于触发优雅更新检查
 	onRecordingEndFunc OnRecordingEndFunc
+	// broadcastRecordingQueueFunc 录制排队状态变化时的广播函数，由 servers 包设置
+	broadcastRecordingQueueFunc BroadcastRecordingQueueFunc
 )
 
 // SetBroadcastRecorderStatusFunc 设置录制器状态广播函数
@@ -38,9 +43,15 @@ func SetOnRecordingEndFunc(fn OnRecordingEndFunc) {
 	onRecordingEndFunc = fn
 }
 
+// SetBroadcastRecordingQueueFunc 设置录制排队状态广播函数
+func SetBroadcastRecordingQueueFunc(fn BroadcastRecordingQueueFunc) {
+	broadcastRecordingQueueFunc = fn
+}
+
 func NewManager(ctx context.Context) Manager {
 	rm := &manager{
 		savers:       make(map[types.LiveID]Recorder),
+		slots:        make(map[types.LiveID]slot),
 		statusStopCh: make(chan struct{}),
 	}
 	instance.GetInstance(ctx).RecorderManager = rm
@@ -62,6 +73,8 @@ type Manager interface {
 	GetRecorderStatus(ctx context.Context, liveId types.LiveID) (map[string]interface{}, error)
 	// GetActiveRecordingsCount 获取当前活跃的录制数量
 	GetActiveRecordingsCount() int
+	// GetRecordingQueue 获取同时录制数量限制和排队中的直播间
+	GetRecordingQueue() RecordingQueueStatus
 }
 
 // for test
@@ -72,6 +85,9 @@ var (
 type manager struct {
 	lock         sync.RWMutex
 	savers       map[types.LiveID]Recorder
+	slots        map[types.LiveID]slot // 正在录制的直播间，抢占和排队时使用
+	queue        []*queuedRecorder     // 因达到同时录制上限排队的直播间
+	ed           events.Dispatcher
 	statusTicker *time.Ticker
 	statusStopCh chan struct{}
 	statusWg     sync.WaitGroup // 用于等待广播 goroutine 退出
@@ -85,6 +101,17 @@ func (m *manager) registryListener(ctx context.Context, ed events.Dispatcher) {
 		}
 	}))
 
+	// 宽限期内录制器会一直重试，恢复时通常仍在；录制器已被移除时重新添加
+	ed.AddEventListener(listeners.LiveResumed, events.NewEventListener(func(event *events.Event) {
+		live := event.Object.(live.Live)
+		if m.HasRecorder(ctx, live.GetLiveId()) {
+			return
+		}
+		if err := m.AddRecorder(ctx, live); err != nil {
+			live.GetLogger().Errorf("failed to add recorder, err: %v", err)
+		}
+	}))
+
 	ed.AddEventListener(listeners.RoomNameChanged, events.NewEventListener(func(event *events.Event) {
 		live := event.Object.(live.Live)
 		if !m.HasRecorder(ctx, live.GetLiveId()) {
@@ -97,6 +124,9 @@ func (m *manager) registryListener(ctx context.Context, ed events.Dispatcher) {
 
 	removeEvtListener := events.NewEventListener(func(event *events.Event) {
 		live := event.Object.(live.Live)
+		if m.dequeue(live.GetLiveId()) {
+			return
+		}
 		if !m.HasRecorder(ctx, live.GetLiveId()) {
 			return
 		}
@@ -113,7 +143,8 @@ func (m *manager) Start(ctx context.Context) error {
 	if cfg := configs.GetCurrentConfig(); (cfg != nil && cfg.RPC.Enable) || inst.Lives.Len() > 0 {
 		inst.WaitGroup.Add(1)
 	}
-	m.registryListener(ctx, inst.EventDispatcher.(events.Dispatcher))
+	m.ed = inst.EventDispatcher.(events.Dispatcher)
+	m.registryListener(ctx, m.ed)
 
 	// 启动定期广播录制器状态的 goroutine
 	m.startStatusBroadcaster(ctx)
@@ -137,22 +168,81 @@ func (m *manager) Close(ctx context.Context) {
 	for id, recorder := range m.savers {
 		recorder.Close()
 		delete(m.savers, id)
+		delete(m.slots, id)
 	}
+	m.queue = nil
 	inst := instance.GetInstance(ctx)
 	inst.WaitGroup.Done()
 }
 
+// AddRecorder 为开播的直播间添加录制器
+// 达到同时录制上限时，优先级更高的直播间抢占优先级最低的录制（开启 preempt 时），否则排队等待空位
 func (m *manager) AddRecorder(ctx context.Context, live live.Live) error {
 	m.lock.Lock()
-	defer m.lock.Unlock()
 	if _, ok := m.savers[live.GetLiveId()]; ok {
+		m.lock.Unlock()
 		return ErrRecorderExist
 	}
+	if m.queuedLocked(live.GetLiveId()) {
+		m.lock.Unlock()
+		return nil
+	}
+	limit := recordingLimit()
+
+	var preempted *PreemptedParam
+	if limit.MaxConcurrent > 0 && len(m.savers) >= limit.MaxConcurrent {
+		priority := recordingPriority(live)
+		victim, victimPriority, ok := m.lowestPriorityLocked()
+		if !limit.Preempt || !ok || victimPriority >= priority {
+			m.enqueueLocked(ctx, live, priority, false)
+			m.lock.Unlock()
+			live.GetLogger().WithField("priority", priority).Info("recording queued, max concurrent recordings reached")
+			m.broadcastQueue()
+			return nil
+		}
+		m.removeLocked(victim.live.GetLiveId())
+		m.enqueueLocked(victim.ctx, victim.live, victimPriority, true)
+		preempted = &PreemptedParam{Live: victim.live, Priority: victimPriority, By: live, ByPriority: priority}
+	}
+	err := m.startLocked(ctx, live, nil)
+	started := false
+	if err != nil {
+		started = m.fillSlotsLocked()
+	}
+	m.lock.Unlock()
+	if started {
+		m.broadcastQueue()
+	}
+
+	if preempted != nil {
+		preempted.Live.GetLogger().
+			WithField("priority", preempted.Priority).
+			WithField("preempted_by", preempted.By.GetRawUrl()).
+			WithField("preempted_by_priority", preempted.ByPriority).
+			Warn("recording preempted by a higher priority room, queued")
+		if m.ed != nil {
+			m.ed.DispatchEvent(events.NewEvent(RecorderPreempted, *preempted))
+		}
+		m.broadcastQueue()
+	}
+	return err
+}
+
+// startLocked 创建并启动录制器，重启时新录制器继承 prev 的下载器回退状态，调用方需持有锁
+// 启动失败时不占用录制位置
+func (m *manager) startLocked(ctx context.Context, live live.Live, prev Recorder) error {
 	recorder, err := newRecorder(ctx, live)
 	if err != nil {
 		return err
 	}
+	inheritDownloaderFallback(recorder, prev)
 	m.savers[live.GetLiveId()] = recorder
+	m.slots[live.GetLiveId()] = slot{ctx: ctx, live: live}
+	if err := recorder.Start(ctx); err != nil {
+		// 启动失败时释放录制位置，调用方负责把空位交给排队的直播间
+		m.removeLocked(live.GetLiveId())
+		return err
+	}
 
 	cfg := configs.GetCurrentConfig()
 	if cfg != nil {
@@ -160,7 +250,19 @@ func (m *manager) AddRecorder(ctx context.Context, live live.Live) error {
 			bilisentry.GoWithContext(ctx, func(ctx context.Context) { m.cronRestart(ctx, live) })
 		}
 	}
-	return recorder.Start(ctx)
+	return nil
+}
+
+// removeLocked 关闭并移除录制器，调用方需持有锁
+func (m *manager) removeLocked(liveId types.LiveID) bool {
+	recorder, ok := m.savers[liveId]
+	if !ok {
+		return false
+	}
+	recorder.Close()
+	delete(m.savers, liveId)
+	delete(m.slots, liveId)
+	return true
 }
 
 func (m *manager) cronRestart(ctx context.Context, live live.Live) {
@@ -183,25 +285,37 @@ func (m *manager) cronRestart(ctx context.Context, live live.Live) {
 	}
 }
 
+// RestartRecorder 重启录制器，保留原来的录制位置，不会被排队的直播间占用
 func (m *manager) RestartRecorder(ctx context.Context, live live.Live) error {
-	if err := m.RemoveRecorder(ctx, live.GetLiveId()); err != nil {
-		return err
+	m.lock.Lock()
+	prev, ok := m.savers[live.GetLiveId()]
+	if !ok || !m.removeLocked(live.GetLiveId()) {
+		m.lock.Unlock()
+		return ErrRecorderNotExist
 	}
-	if err := m.AddRecorder(ctx, live); err != nil {
-		return err
+	err := m.startLocked(ctx, live, prev)
+	started := false
+	if err != nil {
+		started = m.fillSlotsLocked()
 	}
-	return nil
+	m.lock.Unlock()
+	if started {
+		m.broadcastQueue()
+	}
+	return err
 }
 
 func (m *manager) RemoveRecorder(ctx context.Context, liveId types.LiveID) error {
 	m.lock.Lock()
-	defer m.lock.Unlock()
-	recorder, ok := m.savers[liveId]
-	if !ok {
+	if !m.removeLocked(liveId) {
+		m.lock.Unlock()
 		return ErrRecorderNotExist
 	}
-	recorder.Close()
-	delete(m.savers, liveId)
+	started := m.fillSlotsLocked()
+	m.lock.Unlock()
+	if started {
+		m.broadcastQueue()
+	}
 
 	// 录制结束后，检查是否有等待中的优雅更新
 	if onRecordingEndFunc != nil {
@@ -244,6 +358,13 @@ func (m *manager) startStatusBroadcaster(ctx context.Context) {
 				return
 			case <-m.statusTicker.C:
 				m.broadcastAllRecorderStatus(ctx)
+				// 同时录制上限调大后启动排队中的直播间
+				m.lock.Lock()
+				started := m.fillSlotsLocked()
+				m.lock.Unlock()
+				if started {
+					m.broadcastQueue()
+				}
 			}
 		}
 	})
@@ -298,3 +419,110 @@ func (m *manager) GetActiveRecordingsCount() int {
 	defer m.lock.RUnlock()
 	return len(m.savers)
 }
+
+// GetRecordingQueue 获取同时录制数量限制和排队中的直播间
+func (m *manager) GetRecordingQueue() RecordingQueueStatus {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.queueStatusLocked()
+}
+
+// queueStatusLocked 返回排队状态，调用方需持有锁
+func (m *manager) queueStatusLocked() RecordingQueueStatus {
+	status := RecordingQueueStatus{
+		MaxConcurrent: recordingLimit().MaxConcurrent,
+		Active:        len(m.savers),
+		Queue:         make([]QueuedRecording, 0, len(m.queue)),
+	}
+	for _, q := range m.queue {
+		status.Queue = append(status.Queue, q.QueuedRecording)
+	}
+	return status
+}
+
+// broadcastQueue 广播排队状态变化
+func (m *manager) broadcastQueue() {
+	if broadcastRecordingQueueFunc == nil {
+		return
+	}
+	broadcastRecordingQueueFunc(m.GetRecordingQueue())
+}
+
+// queuedLocked 直播间是否在排队，调用方需持有锁
+func (m *manager) queuedLocked(liveId types.LiveID) bool {
+	for _, q := range m.queue {
+		if q.LiveID == liveId {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueueLocked 将直播间加入排队，调用方需持有锁
+func (m *manager) enqueueLocked(ctx context.Context, live live.Live, priority int, preempted bool) {
+	m.queue = append(m.queue, &queuedRecorder{
+		slot: slot{ctx: ctx, live: live},
+		QueuedRecording: QueuedRecording{
+			LiveID:    live.GetLiveId(),
+			Priority:  priority,
+			QueuedAt:  time.Now(),
+			Preempted: preempted,
+		},
+	})
+	sortQueue(m.queue)
+}
+
+// dequeue 将下播或停止监控的直播间移出排队，返回直播间是否在排队
+func (m *manager) dequeue(liveId types.LiveID) bool {
+	m.lock.Lock()
+	removed := false
+	for i, q := range m.queue {
+		if q.LiveID == liveId {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			removed = true
+			break
+		}
+	}
+	m.lock.Unlock()
+	if removed {
+		m.broadcastQueue()
+	}
+	return removed
+}
+
+// lowestPriorityLocked 返回优先级最低的录制及其优先级，优先级相同时抢占最晚开始的，调用方需持有锁
+func (m *manager) lowestPriorityLocked() (slot, int, bool) {
+	var (
+		victim         slot
+		victimPriority int
+		victimAt       time.Time
+		found          bool
+	)
+	for liveId, s := range m.slots {
+		priority := recordingPriority(s.live)
+		startAt := m.savers[liveId].StartTime()
+		if !found || priority < victimPriority || (priority == victimPriority && startAt.After(victimAt)) {
+			victim, victimPriority, victimAt, found = s, priority, startAt, true
+		}
+	}
+	return victim, victimPriority, found
+}
+
+// fillSlotsLocked 有空位时按优先级启动排队中的直播间，返回是否启动了录制，调用方需持有锁
+func (m *manager) fillSlotsLocked() bool {
+	started := false
+	for len(m.queue) > 0 {
+		if limit := recordingLimit().MaxConcurrent; limit > 0 && len(m.savers) >= limit {
+			break
+		}
+		next := m.queue[0]
+		m.queue = m.queue[1:]
+		if err := m.startLocked(next.ctx, next.live, nil); err != nil {
+			next.live.GetLogger().Errorf("failed to start queued recorder, err: %v", err)
+			continue
+		}
+		next.live.GetLogger().WithField("priority", next.Priority).Info("queued recording started")
+		started = true
+	}
+	return started
+}
@@ -2,7 +2,9 @@ package recorders
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	gomock "go.uber.org/mock/gomock"
@@ -11,6 +13,7 @@ import (
 	"github.com/bililive-go/bililive-go/src/instance"
 	"github.com/bililive-go/bililive-go/src/live"
 	livemock "github.com/bililive-go/bililive-go/src/live/mock"
+	"github.com/bililive-go/bililive-go/src/pkg/livelogger"
 	"github.com/bililive-go/bililive-go/src/types"
 )
 
@@ -44,3 +47,106 @@ func TestManagerAddAndRemoveRecorder(t *testing.T) {
 	assert.Equal(t, ErrRecorderNotExist, err)
 	assert.False(t, m.HasRecorder(context.Background(), "test"))
 }
+
+func TestManagerRecordingQueueAndPreempt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	high := 5
+	cfg := configs.NewConfig()
+	cfg.RecordingLimit = configs.RecordingLimit{MaxConcurrent: 1, Preempt: true}
+	cfg.LiveRooms = []configs.LiveRoom{
+		{Url: "https://live.example.com/a"},
+		{Url: "https://live.example.com/b", OverridableConfig: configs.OverridableConfig{RecordingPriority: &high}},
+		{Url: "https://live.example.com/c"},
+	}
+	configs.SetCurrentConfig(cfg)
+	ctx := context.WithValue(context.Background(), instance.Key, &instance.Instance{})
+	m := NewManager(ctx)
+	backup := newRecorder
+	newRecorder = func(ctx context.Context, live live.Live) (Recorder, error) {
+		r := NewMockRecorder(ctrl)
+		r.EXPECT().Start(gomock.Any()).Return(nil)
+		r.EXPECT().Close().AnyTimes()
+		r.EXPECT().StartTime().Return(time.Now()).AnyTimes()
+		return r, nil
+	}
+	defer func() { newRecorder = backup }()
+	newLive := func(id string) *livemock.MockLive {
+		l := livemock.NewMockLive(ctrl)
+		l.EXPECT().GetLiveId().Return(types.LiveID(id)).AnyTimes()
+		l.EXPECT().GetRawUrl().Return("https://live.example.com/" + id).AnyTimes()
+		l.EXPECT().GetLogger().Return(livelogger.New(10, nil)).AnyTimes()
+		return l
+	}
+	queuedIds := func() []types.LiveID {
+		var ids []types.LiveID
+		for _, q := range m.GetRecordingQueue().Queue {
+			ids = append(ids, q.LiveID)
+		}
+		return ids
+	}
+
+	assert.NoError(t, m.AddRecorder(context.Background(), newLive("a")))
+	assert.NoError(t, m.AddRecorder(context.Background(), newLive("c")))
+	assert.False(t, m.HasRecorder(context.Background(), "c"))
+	assert.Equal(t, []types.LiveID{"c"}, queuedIds())
+
+	// b 优先级更高，抢占 a，a 重新排队并排在同优先级的 c 之后
+	assert.NoError(t, m.AddRecorder(context.Background(), newLive("b")))
+	assert.True(t, m.HasRecorder(context.Background(), "b"))
+	assert.False(t, m.HasRecorder(context.Background(), "a"))
+	assert.Equal(t, []types.LiveID{"c", "a"}, queuedIds())
+	assert.True(t, m.GetRecordingQueue().Queue[1].Preempted)
+
+	assert.NoError(t, m.RemoveRecorder(context.Background(), "b"))
+	assert.True(t, m.HasRecorder(context.Background(), "c"))
+	assert.Equal(t, []types.LiveID{"a"}, queuedIds())
+
+	m.(*manager).dequeue("a")
+	assert.Empty(t, queuedIds())
+	assert.Equal(t, 1, m.GetRecordingQueue().Active)
+}
+
+func TestManagerFailedStartReleasesSlot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := configs.NewConfig()
+	cfg.RecordingLimit = configs.RecordingLimit{MaxConcurrent: 1}
+	configs.SetCurrentConfig(cfg)
+	ctx := context.WithValue(context.Background(), instance.Key, &instance.Instance{})
+	m := NewManager(ctx)
+	backup := newRecorder
+	newRecorder = func(ctx context.Context, l live.Live) (Recorder, error) {
+		r := NewMockRecorder(ctrl)
+		if l.GetLiveId() == "b" {
+			r.EXPECT().Start(gomock.Any()).Return(errors.New("start failed"))
+		} else {
+			r.EXPECT().Start(gomock.Any()).Return(nil)
+		}
+		r.EXPECT().Close().AnyTimes()
+		r.EXPECT().StartTime().Return(time.Now()).AnyTimes()
+		return r, nil
+	}
+	defer func() { newRecorder = backup }()
+	newLive := func(id string) *livemock.MockLive {
+		l := livemock.NewMockLive(ctrl)
+		l.EXPECT().GetLiveId().Return(types.LiveID(id)).AnyTimes()
+		l.EXPECT().GetRawUrl().Return("https://live.example.com/" + id).AnyTimes()
+		l.EXPECT().GetLogger().Return(livelogger.New(10, nil)).AnyTimes()
+		return l
+	}
+
+	assert.NoError(t, m.AddRecorder(context.Background(), newLive("a")))
+	assert.NoError(t, m.AddRecorder(context.Background(), newLive("b")))
+	assert.NoError(t, m.AddRecorder(context.Background(), newLive("c")))
+	assert.Len(t, m.GetRecordingQueue().Queue, 2)
+
+	// b 启动失败时不能占用录制位置，空位交给排在后面的 c
+	assert.NoError(t, m.RemoveRecorder(context.Background(), "a"))
+	assert.False(t, m.HasRecorder(context.Background(), "b"))
+	assert.True(t, m.HasRecorder(context.Background(), "c"))
+	assert.Empty(t, m.GetRecordingQueue().Queue)
+	assert.Equal(t, 1, m.GetRecordingQueue().Active)
+}
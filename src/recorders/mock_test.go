@@ -262,6 +262,20 @@ func (mr *MockManagerMockRecorder) GetRecorderStatus(ctx, liveId any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecorderStatus", reflect.TypeOf((*MockManager)(nil).GetRecorderStatus), ctx, liveId)
 }
 
+// GetRecordingQueue mocks base method.
+func (m *MockManager) GetRecordingQueue() RecordingQueueStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecordingQueue")
+	ret0, _ := ret[0].(RecordingQueueStatus)
+	return ret0
+}
+
+// GetRecordingQueue indicates an expected call of GetRecordingQueue.
+func (mr *MockManagerMockRecorder) GetRecordingQueue() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecordingQueue", reflect.TypeOf((*MockManager)(nil).GetRecordingQueue))
+}
+
 // HasRecorder mocks base method.
 func (m *MockManager) HasRecorder(ctx context.Context, liveId types.LiveID) bool {
 	m.ctrl.T.Helper()
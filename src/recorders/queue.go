@@ -0,0 +1,82 @@
+package recorders
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/types"
+)
+
+// QueuedRecording 因达到同时录制上限而排队的直播间
+type QueuedRecording struct {
+	LiveID    types.LiveID `json:"live_id"`
+	Priority  int          `json:"priority"`
+	QueuedAt  time.Time    `json:"queued_at"`
+	Preempted bool         `json:"preempted"` // 是否因被优先级更高的直播间抢占而排队
+}
+
+// RecordingQueueStatus 同时录制数量限制和排队状态
+type RecordingQueueStatus struct {
+	MaxConcurrent int               `json:"max_concurrent"` // 0 表示不限制
+	Active        int               `json:"active"`
+	Queue         []QueuedRecording `json:"queue"` // 按开始录制的先后顺序排列
+}
+
+// PreemptedParam 录制被抢占事件的参数
+type PreemptedParam struct {
+	Live       live.Live // 被抢占的直播间
+	Priority   int
+	By         live.Live // 抢占录制位置的直播间
+	ByPriority int
+}
+
+// GetLiveId 返回被抢占的直播间 ID
+func (p PreemptedParam) GetLiveId() types.LiveID {
+	return p.Live.GetLiveId()
+}
+
+// slot 占用录制位置的直播间，优先级在需要抢占时按当前配置解析
+type slot struct {
+	ctx  context.Context
+	live live.Live
+}
+
+// queuedRecorder 排队中的直播间
+type queuedRecorder struct {
+	slot
+	QueuedRecording
+}
+
+// sortQueue 按优先级从高到低、排队时间从早到晚排序
+func sortQueue(queue []*queuedRecorder) {
+	sort.SliceStable(queue, func(i, j int) bool {
+		if queue[i].Priority != queue[j].Priority {
+			return queue[i].Priority > queue[j].Priority
+		}
+		return queue[i].QueuedAt.Before(queue[j].QueuedAt)
+	})
+}
+
+// recordingPriority 获取直播间的录制优先级（直播间 -> 平台 -> 全局层级解析）
+func recordingPriority(l live.Live) int {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		return 0
+	}
+	room, err := cfg.GetLiveRoomByUrl(l.GetRawUrl())
+	if err != nil {
+		room = &configs.LiveRoom{Url: l.GetRawUrl()}
+	}
+	return cfg.ResolveConfigForRoom(room, configs.GetPlatformKeyFromUrl(l.GetRawUrl())).RecordingPriority
+}
+
+// recordingLimit 获取当前的同时录制数量限制
+func recordingLimit() configs.RecordingLimit {
+	if cfg := configs.GetCurrentConfig(); cfg != nil {
+		return cfg.RecordingLimit
+	}
+	return configs.RecordingLimit{}
+}
@@ -23,13 +23,17 @@ import (
 	"github.com/bililive-go/bililive-go/src/configs"
 	"github.com/bililive-go/bililive-go/src/instance"
 	"github.com/bililive-go/bililive-go/src/live"
+	"github.com/bililive-go/bililive-go/src/notify"
 	"github.com/bililive-go/bililive-go/src/pipeline"
+	"github.com/bililive-go/bililive-go/src/pkg/bandwidth"
 	"github.com/bililive-go/bililive-go/src/pkg/events"
 	"github.com/bililive-go/bililive-go/src/pkg/livelogger"
 	"github.com/bililive-go/bililive-go/src/pkg/parser"
 	"github.com/bililive-go/bililive-go/src/pkg/parser/bililive_recorder"
 	"github.com/bililive-go/bililive-go/src/pkg/parser/ffmpeg"
 	"github.com/bililive-go/bililive-go/src/pkg/parser/native/flv"
+	"github.com/bililive-go/bililive-go/src/pkg/proxy"
+	"github.com/bililive-go/bililive-go/src/pkg/restream"
 	bilisentry "github.com/bililive-go/bililive-go/src/pkg/sentry"
 	"github.com/bililive-go/bililive-go/src/pkg/streamprobe"
 	"github.com/bililive-go/bililive-go/src/pkg/utils"
@@ -47,8 +51,8 @@ var (
 	// newParser 根据配置的下载器类型创建 parser，并实现回退逻辑：
 	// bililive-recorder -> ffmpeg -> native
 	newParser = func(u *url.URL, downloaderType configs.DownloaderType, cfg map[string]string, logger *livelogger.LiveLogger) (parser.Parser, error) {
-		// 判断是否为 FLV 流
-		isFLV := strings.Contains(u.Path, ".flv")
+		// 判断是否为 FLV 流（RTMP 流由内置客户端转换为 FLV）
+		isFLV := streamprobe.IsStreamFLV(u)
 
 		// 根据下载器类型选择 parser，并实现回退逻辑
 		parserName := resolveParserName(downloaderType, isFLV, logger)
@@ -214,6 +218,15 @@ type recorder struct {
 
 	// 实际流头部信息（来自 StreamProbe 探测）
 	actualStreamInfo atomic.Pointer[streamprobe.StreamHeaderInfo]
+
+	// relay 当前录制的转推（来自直播间的 restream 配置）
+	relay atomic.Pointer[restream.Relay]
+
+	// failureNotified 连续失败期间只发送一次录制失败通知，录制成功后重置
+	failureNotified atomic.Bool
+
+	// fallback 下载器连续快速失败时按回退链切换
+	fallback downloaderFallback
 }
 
 func NewRecorder(ctx context.Context, live live.Live) (Recorder, error) {
@@ -230,7 +243,26 @@ func NewRecorder(ctx context.Context, live live.Live) (Recorder, error) {
 	}, nil
 }
 
-func (r *recorder) tryRecord(ctx context.Context) {
+// notifyRecordingFailed 发送录制失败通知，连续的失败只通知一次
+func (r *recorder) notifyRecordingFailed(ctx context.Context, info *live.Info, err error) {
+	if ctx.Err() != nil || !r.failureNotified.CompareAndSwap(false, true) {
+		return
+	}
+	if err := notify.Send(context.Background(), r.getLogger(), &notify.Message{
+		Event:    notify.EventRecordingFailed,
+		HostName: info.HostName,
+		RoomName: info.RoomName,
+		Platform: r.Live.GetPlatformCNName(),
+		LiveURL:  r.Live.GetRawUrl(),
+		CoverURL: info.Cover,
+		Detail:   err.Error(),
+	}); err != nil {
+		r.getLogger().WithError(err).Warn("failed to send recording failed notification")
+	}
+}
+
+// tryRecord 进行一次录制，返回下载器的运行结果，下载器没有运行时返回 nil
+func (r *recorder) tryRecord(ctx context.Context) (attempt *downloadAttempt) {
 	// 每次重试前重置探测状态，避免上次录制的旧数据残留
 	// （例如上次探测成功但本次流分辨率已变化）
 	r.actualStreamInfo.Store(nil)
@@ -319,8 +351,8 @@ func (r *recorder) tryRecord(ctx context.Context) {
 		"timeout_in_us": strconv.Itoa(resolvedConfig.TimeoutInUs),
 		"audio_only":    strconv.FormatBool(info.AudioOnly),
 	}
-	// 使用层级配置的下载器类型
-	downloaderType := resolvedConfig.Feature.GetEffectiveDownloaderType()
+	// 使用层级配置的下载器回退链中当前的下载器
+	downloaderType := r.fallback.current(resolvedConfig.Feature.GetDownloaderChain())
 
 	// 如果启用了 FLV 代理分段且使用 FFmpeg 下载器，传递配置
 	if resolvedConfig.Feature.EnableFlvProxySegment && downloaderType == configs.DownloaderFFmpeg {
@@ -335,6 +367,12 @@ func (r *recorder) tryRecord(ctx context.Context) {
 	// 如果用代理 URL 判断，所有 FLV 流都会被误判为"非 FLV"，导致 Native/录播姬下载器回退到 ffmpeg。
 	originalURL := url
 	isFLV := streamprobe.IsStreamFLV(url)
+	// bandwidthLimited 下载是否经过探测代理的带宽限制
+	bandwidthLimited := false
+	restreamTargets := room.EnabledRestreamTargets()
+	if len(restreamTargets) > 0 && !isFLV {
+		r.getLogger().Warn("转推只支持 FLV / RTMP 流，本次录制不转推")
+	}
 	if isFLV {
 		// FLV 流：启动探测代理
 		probeConfig := streamprobe.Config{
@@ -358,6 +396,13 @@ func (r *recorder) tryRecord(ctx context.Context) {
 				})
 			},
 			Logger: r.getLogger(),
+			LiveID: string(r.Live.GetLiveId()),
+		}
+		// 转推挂在探测代理的数据路径上，与下载器共用同一路上游连接
+		var relay *restream.Relay
+		if len(restreamTargets) > 0 {
+			relay = newRelay(restreamTargets, r.getLogger())
+			probeConfig.Tee = relay
 		}
 
 		probe := streamprobe.New(probeConfig)
@@ -368,9 +413,23 @@ func (r *recorder) tryRecord(ctx context.Context) {
 				Unsupported:    true,
 				UnsupportedMsg: fmt.Sprintf("流探测代理启动失败: %v", probeErr),
 			})
+			if relay != nil {
+				r.getLogger().Warn("转推依赖流探测代理，本次录制不转推")
+			}
 		} else {
 			// 代理启动成功，用代理 URL 替换原始 URL
 			defer probe.Stop()
+			// 带宽已在代理的上游限制，原生下载器不再重复限制
+			parserCfg["bandwidth_limited"] = "true"
+			bandwidthLimited = true
+			if relay != nil {
+				relay.Start(ctx)
+				r.relay.Store(relay)
+				defer func() {
+					relay.Stop()
+					r.relay.Store(nil)
+				}()
+			}
 			streamInfo = &live.StreamUrlInfo{
 				Url:                  probe.LocalURL(),
 				HeadersForDownloader: nil, // 本地代理不需要 headers
@@ -421,30 +480,52 @@ func (r *recorder) tryRecord(ctx context.Context) {
 	p, err := newParser(originalURL, downloaderType, parserCfg, r.getLogger())
 	if err != nil {
 		r.getLogger().WithError(err).Error("failed to init parse")
+		r.notifyRecordingFailed(ctx, info, err)
 		return
 	}
 	r.setAndCloseParser(p)
 	r.startTime = time.Now()
+	// 原生 FLV 下载器自行限制带宽，其他下载器只有经过探测代理时才受限制
+	if !bandwidthLimited && resolveParserName(downloaderType, isFLV, nil) != flv.Name {
+		reason := "非 FLV 流（如 HLS）由 FFmpeg 直接下载"
+		if isFLV {
+			reason = "流探测代理未启动，下载器直接连接上游"
+		}
+		done := bandwidth.GetManager().MarkUnlimited(string(r.Live.GetLiveId()), reason)
+		defer done()
+	}
 
 	// 设置当前录制文件路径
 	r.setCurrentFilePath(fileName)
 
 	r.getLogger().Debugln("Start ParseLiveStream(" + url.String() + ", " + fileName + ")")
 	err = r.parser.ParseLiveStream(ctx, streamInfo, r.Live, fileName)
+	attempt = &downloadAttempt{
+		downloader: downloaderType,
+		feature:    resolvedConfig.Feature,
+		duration:   time.Since(r.startTime),
+		written:    outputWritten(fileName, downloaderType),
+		err:        err,
+	}
 
 	// 清除当前录制文件路径
 	r.setCurrentFilePath("")
 
 	if err != nil {
 		r.getLogger().WithError(err).Error("failed to parse live stream")
+		r.notifyRecordingFailed(ctx, info, err)
 		return
 	}
+	r.failureNotified.Store(false)
 	r.getLogger().Debugln("End ParseLiveStream(" + url.String() + ", " + fileName + ")")
 	removeEmptyFile(fileName)
 
 	// 使用层级配置的 OnRecordFinished
 	cmdStr := strings.Trim(resolvedConfig.OnRecordFinished.CustomCommandline, "")
 	if len(cmdStr) > 0 {
+		if _, statErr := os.Stat(fileName); statErr == nil {
+			r.recordSessionFiles(instance.GetInstance(ctx), []string{fileName})
+		}
 		ffmpegPath, ffmpegErr := utils.GetFFmpegPathForLive(ctx, r.Live)
 		if ffmpegErr != nil {
 			r.getLogger().WithError(ffmpegErr).Error("failed to find ffmpeg")
@@ -538,6 +619,7 @@ func (r *recorder) tryRecord(ctx context.Context) {
 			r.getLogger().Warn("没有找到任何输出文件，跳过后处理")
 			return
 		}
+		r.recordSessionFiles(inst, outputFiles)
 
 		// 获取 PipelineManager
 		pipelineManager := pipeline.GetManager(inst)
@@ -556,12 +638,49 @@ func (r *recorder) tryRecord(ctx context.Context) {
 		}
 
 		// 入队 Pipeline 任务
-		if err := pipelineManager.EnqueueRecordingTask(info, pipelineConfig, outputFiles); err != nil {
+		recordInfo := pipeline.NewRecordInfo(info)
+		recordInfo.StartTime = r.startTime
+		recordInfo.RoomTitles = r.roomTitlesSince(inst, r.startTime, info.RoomName)
+		if err := pipelineManager.EnqueueRecordingTask(recordInfo, pipelineConfig, outputFiles); err != nil {
 			r.getLogger().WithError(err).Error("failed to enqueue pipeline task")
 		} else {
 			r.getLogger().Infof("pipeline task enqueued: %d files, %d stages", len(outputFiles), len(pipelineConfig.Stages))
 		}
 	}
+	return attempt
+}
+
+// roomTitlesSince 获取录制期间使用过的直播间标题，没有状态持久化或期间未改名时只返回当前标题
+func (r *recorder) roomTitlesSince(inst *instance.Instance, since time.Time, current string) []string {
+	// 使用接口断言访问 livestate.Manager，避免循环导入
+	type roomTitleGetter interface {
+		GetRoomTitlesSince(liveID string, since time.Time) []string
+	}
+	if getter, ok := inst.LiveStateManager.(roomTitleGetter); ok {
+		if titles := getter.GetRoomTitlesSince(string(r.Live.GetLiveId()), since); len(titles) > 0 {
+			return titles
+		}
+	}
+	if current == "" {
+		return nil
+	}
+	return []string{current}
+}
+
+// recordSessionFiles 将录制完成的文件关联到直播会话，宽限期内中断前后的文件属于同一个会话
+func (r *recorder) recordSessionFiles(inst *instance.Instance, files []string) {
+	// 使用接口断言访问 livestate.Manager，避免循环导入
+	type sessionFileRecorder interface {
+		RecordSessionFile(liveID, filePath string)
+	}
+	if inst == nil {
+		return
+	}
+	if recorder, ok := inst.LiveStateManager.(sessionFileRecorder); ok {
+		for _, file := range files {
+			recorder.RecordSessionFile(string(r.Live.GetLiveId()), file)
+		}
+	}
 }
 
 func (r *recorder) selectPreferredStream(streamInfos []*live.StreamUrlInfo) (ret *live.StreamUrlInfo) {
@@ -626,8 +745,11 @@ func (r *recorder) run(ctx context.Context) {
 			// tryRecord 返回时 cancel 会停止所有异步操作（如 HLS 探测 goroutine）
 			tryCtx, tryCancel := context.WithCancel(ctx)
 			start := time.Now()
-			r.tryRecord(tryCtx)
+			attempt := r.tryRecord(tryCtx)
 			tryCancel()
+			if attempt != nil {
+				r.checkDownloaderFallback(ctx, attempt)
+			}
 
 			// 确保两次 tryRecord 之间至少间隔 minRetryInterval
 			// 防止快速失败（如 FFmpeg 秒退 404）导致紧密循环
@@ -645,6 +767,49 @@ func (r *recorder) run(ctx context.Context) {
 	}
 }
 
+// checkDownloaderFallback 记录下载器运行结果，连续快速失败时切换到回退链中的下一个下载器
+func (r *recorder) checkDownloaderFallback(ctx context.Context, attempt *downloadAttempt) {
+	// 录制被主动停止时下载器的退出不算失败
+	select {
+	case <-r.stop:
+		return
+	default:
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	attempt.upstreamOffline = r.upstreamOffline()
+	sw := r.fallback.record(attempt, time.Now())
+	if sw == nil {
+		return
+	}
+	r.getLogger().
+		WithField("failures", sw.Failures).
+		WithField("reason", sw.Reason).
+		Warnf("下载器 %s 连续快速失败，切换到 %s", sw.From.DisplayName(), sw.To.DisplayName())
+	r.ed.DispatchEvent(events.NewEvent(RecorderDownloaderSwitched, DownloaderSwitchedParam{
+		Live:             r.Live,
+		DownloaderSwitch: *sw,
+	}))
+}
+
+// upstreamOffline 直播间是否处于宽限期或最近一次获取的信息为未开播
+func (r *recorder) upstreamOffline() bool {
+	if poller, ok := r.Live.(live.GracePoller); ok && poller.InGracePeriod() {
+		return true
+	}
+	if r.cache == nil {
+		return false
+	}
+	obj, err := r.cache.Get(r.Live)
+	if err != nil {
+		return false
+	}
+	info, ok := obj.(*live.Info)
+	return ok && !info.Status
+}
+
 func (r *recorder) getParser() parser.Parser {
 	r.parserLock.RLock()
 	defer r.parserLock.RUnlock()
@@ -717,6 +882,18 @@ func (r *recorder) setCurrentFilePath(path string) {
 	r.currentFilePath = path
 }
 
+// newRelay 按直播间的转推配置创建转推，转推连接使用下载代理
+func newRelay(targets []configs.RestreamTarget, logger *livelogger.LiveLogger) *restream.Relay {
+	relayTargets := make([]restream.Target, 0, len(targets))
+	for _, t := range targets {
+		relayTargets = append(relayTargets, restream.Target{Name: t.GetName(), URL: t.Url})
+	}
+	return restream.New(relayTargets, restream.Options{
+		Logger: logger,
+		Dialer: proxy.DownloadDialContext(),
+	})
+}
+
 // getCurrentFilePath 获取当前正在录制的文件路径
 func (r *recorder) getCurrentFilePath() string {
 	r.currentFileLock.RLock()
@@ -810,6 +987,11 @@ func (r *recorder) GetStatus() (map[string]interface{}, error) {
 		status["probe_status"] = "pending"
 	}
 
+	// 添加转推目标状态
+	if relay := r.relay.Load(); relay != nil {
+		status["restream"] = relay.Status()
+	}
+
 	// 添加原始流 URL 和 Headers（供前端调试展示）
 	// 对敏感 Header（如 Cookie、Authorization）进行脱敏处理，
 	// 避免在 WebUI 无鉴权或被反代到公网时泄露凭据
@@ -822,6 +1004,21 @@ func (r *recorder) GetStatus() (map[string]interface{}, error) {
 	}
 	r.currentFileLock.RUnlock()
 
+	// 添加下载器回退状态
+	if cfg := configs.GetCurrentConfig(); cfg != nil {
+		feature := cfg.GetEffectiveConfigForRoom(r.Live.GetRawUrl()).Feature
+		chain := feature.GetDownloaderChain()
+		status["downloader"] = r.fallback.current(chain)
+		if len(chain) > 1 {
+			status["downloader_chain"] = chain
+		}
+	}
+	failures, switches := r.fallback.status()
+	status["downloader_fast_failures"] = failures
+	if len(switches) > 0 {
+		status["downloader_switches"] = switches
+	}
+
 	return status, nil
 }
 
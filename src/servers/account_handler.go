@@ -0,0 +1,311 @@
+package servers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	applog "github.com/bililive-go/bililive-go/src/log"
+	"github.com/bililive-go/bililive-go/src/pkg/accounts"
+	"github.com/bililive-go/bililive-go/src/pkg/cookiemonitor"
+)
+
+// errAccountInUse 删除仍被直播间引用的账号时返回
+var errAccountInUse = errors.New("账号仍被直播间引用")
+
+// bilibiliLoginHost B站扫码登录得到的账号所属 host
+const bilibiliLoginHost = "live.bilibili.com"
+
+// cookieFormat Cookie 至少包含一个 key=value
+var cookieFormat = regexp.MustCompile(".*=.*")
+
+// accountInfo 账号及其引用情况
+type accountInfo struct {
+	configs.Account
+	Rooms  []string              `json:"rooms"`
+	Status *cookiemonitor.Status `json:"status,omitempty"` // Cookie 检查器最近一次检查的结果
+}
+
+// getAccounts 获取所有命名账号及其引用情况
+func getAccounts(writer http.ResponseWriter, r *http.Request) {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		writeJsonWithStatusCode(writer, http.StatusInternalServerError, commonResp{
+			ErrNo:  http.StatusInternalServerError,
+			ErrMsg: "配置未初始化",
+		})
+		return
+	}
+
+	result := make(map[string]accountInfo, len(cfg.Accounts))
+	for name, account := range cfg.Accounts {
+		result[name] = accountInfo{
+			Account: account,
+			Rooms:   cfg.GetAccountUsage(name),
+			Status:  cookiemonitor.GetMonitor().GetAccountStatus(name),
+		}
+	}
+	writeJSON(writer, result)
+}
+
+// getAccountRiskStatus 获取触发过风控的账号及其冷却状态
+func getAccountRiskStatus(writer http.ResponseWriter, r *http.Request) {
+	writeJSON(writer, accounts.GetPool().GetStatuses())
+}
+
+// updateAccount 创建或更新命名账号
+// 更新后使用该平台的直播间立即重新加载 Cookie
+func updateAccount(writer http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(mux.Vars(r)["name"])
+	if name == "" {
+		writeJsonWithStatusCode(writer, http.StatusBadRequest, commonResp{
+			ErrNo:  http.StatusBadRequest,
+			ErrMsg: "账号名称不能为空",
+		})
+		return
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJsonWithStatusCode(writer, http.StatusBadRequest, commonResp{
+			ErrNo:  http.StatusBadRequest,
+			ErrMsg: err.Error(),
+		})
+		return
+	}
+	var updates map[string]interface{}
+	if err := json.Unmarshal(b, &updates); err != nil {
+		writeJsonWithStatusCode(writer, http.StatusBadRequest, commonResp{
+			ErrNo:  http.StatusBadRequest,
+			ErrMsg: "无效的JSON格式: " + err.Error(),
+		})
+		return
+	}
+	if cookie, ok := updates["cookie"].(string); ok && cookie != "" {
+		if !cookieFormat.MatchString(cookie) {
+			writeJsonWithStatusCode(writer, http.StatusBadRequest, commonResp{
+				ErrNo:  http.StatusBadRequest,
+				ErrMsg: "cookie格式错误",
+			})
+			return
+		}
+	}
+
+	var oldHost string
+	newCfg, err := configs.UpdateWithRetry(func(c *configs.Config) error {
+		if c.Accounts == nil {
+			c.Accounts = make(map[string]configs.Account)
+		}
+		account := c.Accounts[name]
+		oldHost = account.Host
+		if host, ok := updates["host"].(string); ok {
+			account.Host = host
+		}
+		if cookie, ok := updates["cookie"].(string); ok {
+			account.Cookie = cookie
+		}
+		if refreshToken, ok := updates["refresh_token"].(string); ok {
+			account.RefreshToken = refreshToken
+		}
+		if userName, ok := updates["user_name"].(string); ok {
+			account.UserName = userName
+		}
+		if uid, ok := updates["uid"].(string); ok {
+			account.UID = uid
+		}
+		if note, ok := updates["note"].(string); ok {
+			account.Note = note
+		}
+		if disabled, ok := updates["disabled"].(bool); ok {
+			account.Disabled = disabled
+		}
+		c.Accounts[name] = account
+		return c.ValidateAccounts()
+	}, 3, 10*time.Millisecond)
+	if err != nil {
+		writeJsonWithStatusCode(writer, http.StatusInternalServerError, commonResp{
+			ErrNo:  http.StatusInternalServerError,
+			ErrMsg: "更新账号失败: " + err.Error(),
+		})
+		return
+	}
+
+	host := newCfg.Accounts[name].Host
+	if _, ok := updates["cookie"]; ok {
+		accounts.GetPool().Reset(host, name)
+		cookiemonitor.GetMonitor().ResetAccount(name)
+	}
+	cookiemonitor.ReloadLiveOptions(r.Context(), newCfg, host)
+	if oldHost != "" && oldHost != host {
+		cookiemonitor.ReloadLiveOptions(r.Context(), newCfg, oldHost)
+	}
+	writeJSON(writer, commonResp{
+		Data: "OK",
+	})
+}
+
+// deleteAccount 删除命名账号
+// 账号仍被直播间引用时默认拒绝删除，传入 force=true 时会同时清除这些直播间的账号选择
+func deleteAccount(writer http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	force := r.URL.Query().Get("force") == "true"
+
+	var host string
+	newCfg, err := configs.UpdateWithRetry(func(c *configs.Config) error {
+		account, ok := c.Accounts[name]
+		if !ok {
+			return nil
+		}
+		host = account.Host
+		if rooms := c.GetAccountUsage(name); len(rooms) > 0 {
+			if !force {
+				return fmt.Errorf("%w: %d 个直播间", errAccountInUse, len(rooms))
+			}
+			for i := range c.LiveRooms {
+				if c.LiveRooms[i].Account == name {
+					c.LiveRooms[i].Account = ""
+				}
+			}
+		}
+		delete(c.Accounts, name)
+		return nil
+	}, 3, 10*time.Millisecond)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, errAccountInUse) {
+			code = http.StatusConflict
+		}
+		writeJsonWithStatusCode(writer, code, commonResp{
+			ErrNo:  code,
+			ErrMsg: "删除账号失败: " + err.Error(),
+		})
+		return
+	}
+
+	if host != "" {
+		accounts.GetPool().Reset(host, name)
+		cookiemonitor.GetMonitor().ResetAccount(name)
+		cookiemonitor.ReloadLiveOptions(r.Context(), newCfg, host)
+	}
+	writeJSON(writer, commonResp{
+		Data: "OK",
+	})
+}
+
+// checkAccount 检查账号的登录状态，登录有效时更新账号昵称
+func checkAccount(writer http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	cfg := configs.GetCurrentConfig()
+	account, ok := cfg.Accounts[name]
+	if !ok {
+		writeJsonWithStatusCode(writer, http.StatusNotFound, commonResp{
+			ErrNo:  http.StatusNotFound,
+			ErrMsg: "账号不存在",
+		})
+		return
+	}
+
+	res, err := cookiemonitor.CheckCookie(r.Context(), account.Host, account.Cookie)
+	if err != nil {
+		writeJsonWithStatusCode(writer, http.StatusBadRequest, commonResp{
+			ErrNo:  http.StatusBadRequest,
+			ErrMsg: err.Error(),
+		})
+		return
+	}
+	if res.LoggedIn && res.UserName != "" && res.UserName != account.UserName {
+		_, err := configs.UpdateWithRetry(func(c *configs.Config) error {
+			if a, ok := c.Accounts[name]; ok {
+				a.UserName = res.UserName
+				c.Accounts[name] = a
+			}
+			return nil
+		}, 3, 10*time.Millisecond)
+		if err != nil {
+			applog.GetLogger().Error("failed to save account user name: " + err.Error())
+		}
+	}
+	writeJSON(writer, commonResp{Data: res})
+}
+
+// saveBilibiliLoginAccount 将B站扫码登录结果保存为命名账号
+// loginUrl 为扫码登录成功后返回的跳转地址，Cookie 以查询参数形式附带在其中
+func saveBilibiliLoginAccount(ctx context.Context, name string, loginUrl *url.URL, refreshToken string) error {
+	cookie, err := bilibiliLoginCookie(loginUrl)
+	if err != nil {
+		return err
+	}
+	q := loginUrl.Query()
+
+	account := configs.Account{
+		Host:         bilibiliLoginHost,
+		Cookie:       cookie,
+		RefreshToken: refreshToken,
+		UID:          q.Get("DedeUserID"),
+	}
+	if cfg := configs.GetCurrentConfig(); cfg != nil {
+		if old, ok := cfg.Accounts[name]; ok {
+			account.Note = old.Note
+			account.Disabled = old.Disabled
+			account.UserName = old.UserName
+		}
+	}
+	if res, err := cookiemonitor.CheckCookie(ctx, bilibiliLoginHost, cookie); err == nil && res.LoggedIn {
+		account.UserName = res.UserName
+	}
+
+	newCfg, err := configs.SetAccount(name, account)
+	if err != nil {
+		return err
+	}
+	accounts.GetPool().Reset(bilibiliLoginHost, name)
+	cookiemonitor.GetMonitor().ResetAccount(name)
+	cookiemonitor.ReloadLiveOptions(ctx, newCfg, bilibiliLoginHost)
+	return nil
+}
+
+// saveBilibiliLoginCookie 将扫码登录结果保存为B站的全局 Cookie，同时保存刷新令牌用于 Cookie 自动刷新
+func saveBilibiliLoginCookie(ctx context.Context, loginUrl *url.URL, refreshToken string) error {
+	cookie, err := bilibiliLoginCookie(loginUrl)
+	if err != nil {
+		return err
+	}
+	newCfg, err := configs.SetCookieWithRefreshToken(bilibiliLoginHost, cookie, refreshToken)
+	if err != nil {
+		return err
+	}
+	cookiemonitor.ReloadLiveOptions(ctx, newCfg, bilibiliLoginHost)
+	return nil
+}
+
+// bilibiliLoginCookie 从扫码登录的回调地址中提取 Cookie
+func bilibiliLoginCookie(loginUrl *url.URL) (string, error) {
+	q := loginUrl.Query()
+	if q.Get("SESSDATA") == "" {
+		return "", errors.New("登录结果中缺少 SESSDATA")
+	}
+	parts := make([]string, 0, 5)
+	for _, key := range []string{"DedeUserID", "DedeUserID__ckMd5", "SESSDATA", "bili_jct", "sid"} {
+		if v := q.Get(key); v != "" {
+			parts = append(parts, key+"="+v)
+		}
+	}
+	return strings.Join(parts, "; "), nil
+}
+
+// applyRoomAccountUpdates 处理直播间账号选择的更新，传入空字符串表示使用平台默认 Cookie
+func applyRoomAccountUpdates(room *configs.LiveRoom, updates map[string]interface{}) {
+	if account, ok := updates["account"].(string); ok {
+		room.Account = account
+	}
+}
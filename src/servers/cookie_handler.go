@@ -0,0 +1,93 @@
+package servers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/pkg/cookiemonitor"
+)
+
+// getCookieStatus 获取各平台 Cookie 的健康状态（登录状态、过期时间、上次检查/刷新时间）
+func getCookieStatus(writer http.ResponseWriter, r *http.Request) {
+	writeJSON(writer, cookiemonitor.GetMonitor().GetStatuses())
+}
+
+// checkCookieStatus 立即检查 Cookie 状态
+// 请求体可选 {"host": "live.bilibili.com"}，不指定 host 时检查所有已配置的 Cookie
+func checkCookieStatus(writer http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Host string `json:"host"`
+	}
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJsonWithStatusCode(writer, http.StatusBadRequest, commonResp{
+			ErrNo:  http.StatusBadRequest,
+			ErrMsg: err.Error(),
+		})
+		return
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &req); err != nil {
+			writeJsonWithStatusCode(writer, http.StatusBadRequest, commonResp{
+				ErrNo:  http.StatusBadRequest,
+				ErrMsg: "无效的请求体",
+			})
+			return
+		}
+	}
+
+	monitor := cookiemonitor.GetMonitor()
+	if req.Host == "" {
+		writeJSON(writer, commonResp{Data: monitor.CheckAll(r.Context())})
+		return
+	}
+	status, err := monitor.CheckHost(r.Context(), req.Host)
+	if err != nil && status.State != cookiemonitor.StateError {
+		writeJsonWithStatusCode(writer, http.StatusBadRequest, commonResp{
+			ErrNo:  http.StatusBadRequest,
+			ErrMsg: err.Error(),
+		})
+		return
+	}
+	writeJSON(writer, commonResp{Data: status})
+}
+
+// putCookieRefreshToken 设置某个 host 用于自动刷新 Cookie 的令牌
+// 请求体 {"host": "live.bilibili.com", "refresh_token": "..."}，refresh_token 为空表示删除
+func putCookieRefreshToken(writer http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Host         string `json:"host"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Host == "" {
+		writeJsonWithStatusCode(writer, http.StatusBadRequest, commonResp{
+			ErrNo:  http.StatusBadRequest,
+			ErrMsg: "无效的请求体",
+		})
+		return
+	}
+
+	_, err := configs.UpdateWithRetry(func(c *configs.Config) error {
+		if req.RefreshToken == "" {
+			delete(c.CookieMonitor.RefreshTokens, req.Host)
+			return nil
+		}
+		if c.CookieMonitor.RefreshTokens == nil {
+			c.CookieMonitor.RefreshTokens = make(map[string]string)
+		}
+		c.CookieMonitor.RefreshTokens[req.Host] = req.RefreshToken
+		return nil
+	}, 3, 10*time.Millisecond)
+	if err != nil {
+		writeJsonWithStatusCode(writer, http.StatusInternalServerError, commonResp{
+			ErrNo:  http.StatusInternalServerError,
+			ErrMsg: err.Error(),
+		})
+		return
+	}
+	cookiemonitor.GetMonitor().TriggerCheck()
+	writeJSON(writer, commonResp{Data: "OK"})
+}
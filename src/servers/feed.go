@@ -0,0 +1,230 @@
+package servers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/instance"
+	"github.com/bililive-go/bililive-go/src/livestate"
+	"github.com/bililive-go/bililive-go/src/pkg/feed"
+	"github.com/bililive-go/bililive-go/src/types"
+)
+
+const (
+	// maxPodcastEpisodes 单个直播间播客订阅源的最大节目数
+	maxPodcastEpisodes = 500
+	// defaultFeedEntries 全部直播间 Atom 订阅源默认返回的录制数
+	defaultFeedEntries = 50
+	maxFeedEntries     = 500
+
+	contentTypeRSS  = "application/rss+xml; charset=utf-8"
+	contentTypeAtom = "application/atom+xml; charset=utf-8"
+)
+
+// requestBaseURL 根据请求推断外部访问地址
+// 只有开启 rpc.trust_proxy_headers 时才使用反向代理设置的 X-Forwarded-Proto / X-Forwarded-Host，
+// 否则客户端可以伪造这些请求头，让订阅源中的链接指向任意地址
+func requestBaseURL(cfg *configs.Config, r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	host := r.Host
+	if cfg.RPC.TrustProxyHeaders {
+		if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+			scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+		}
+		if fwd := r.Header.Get("X-Forwarded-Host"); fwd != "" {
+			host = strings.TrimSpace(strings.Split(fwd, ",")[0])
+		}
+	}
+	return scheme + "://" + host
+}
+
+// outputRoot 返回 /files/ 对应的输出根目录
+func outputRoot(cfg *configs.Config) string {
+	if cfg.OutPutPath == "" {
+		return "./"
+	}
+	return cfg.OutPutPath
+}
+
+// feedRecording 订阅源中的一个录制文件
+type feedRecording struct {
+	feed.Recording
+	ID       string // 订阅源条目的唯一标识
+	URL      string
+	HostName string
+}
+
+// getLiveStateManager 获取直播间状态管理器，未启用状态持久化时返回 nil
+func getLiveStateManager(r *http.Request) *livestate.Manager {
+	inst := instance.GetInstance(r.Context())
+	if inst == nil {
+		return nil
+	}
+	manager, _ := inst.LiveStateManager.(*livestate.Manager)
+	return manager
+}
+
+// sessionRecordings 从直播会话记录的录制文件生成订阅源条目，liveID 为空时包含所有直播间
+// 录制文件按实际路径读取，不依赖输出模板；后处理转换后的文件优先于原始文件，已删除的文件会被跳过
+func sessionRecordings(cfg *configs.Config, manager *livestate.Manager, baseURL, liveID string, limit int) []feedRecording {
+	if manager == nil {
+		return nil
+	}
+	absRoot, err := filepath.Abs(outputRoot(cfg))
+	if err != nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var recordings []feedRecording
+	for _, file := range manager.GetRecentSessionFiles(liveID, limit) {
+		rec, ok := feed.Lookup(file.FilePath)
+		if !ok {
+			continue
+		}
+		absPath, err := filepath.Abs(rec.Path)
+		if err != nil || seen[absPath] {
+			continue
+		}
+		seen[absPath] = true
+
+		item := feedRecording{Recording: rec, HostName: file.HostName}
+		// 输出根目录下的文件通过 /files/ 访问，其他位置（如直播间单独配置的输出目录）通过录制文件记录访问
+		if rel, err := filepath.Rel(absRoot, absPath); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			item.RelPath = filepath.ToSlash(rel)
+			item.URL = feed.FileURL(baseURL, item.RelPath)
+			item.ID = item.RelPath
+		} else {
+			item.URL = fmt.Sprintf("%s/api/feed/files/%d/%s", baseURL, file.ID, url.PathEscape(filepath.Base(rec.Path)))
+			item.ID = item.URL
+		}
+		recordings = append(recordings, item)
+	}
+	return recordings
+}
+
+// getFeedFile 提供不在输出根目录下的录制文件，只能访问直播会话记录过的录制文件
+func getFeedFile(writer http.ResponseWriter, r *http.Request) {
+	manager := getLiveStateManager(r)
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if manager == nil || err != nil {
+		http.NotFound(writer, r)
+		return
+	}
+	file, err := manager.GetSessionFile(id)
+	if err != nil {
+		http.NotFound(writer, r)
+		return
+	}
+	rec, ok := feed.Lookup(file.FilePath)
+	if !ok {
+		http.NotFound(writer, r)
+		return
+	}
+	writer.Header().Set(contentType, rec.MimeType)
+	http.ServeFile(writer, r, rec.Path)
+}
+
+// getLivePodcast 直播间的播客 RSS 订阅源，每个录制文件作为一期节目
+// 音频直播间转换后的 m4a/mp3 优先于原始 aac 文件
+func getLivePodcast(writer http.ResponseWriter, r *http.Request) {
+	inst := instance.GetInstance(r.Context())
+	vars := mux.Vars(r)
+	liveObj, ok := inst.Lives.Get(types.LiveID(vars["id"]))
+	if !ok {
+		writeJsonWithStatusCode(writer, http.StatusNotFound, commonResp{
+			ErrNo:  http.StatusNotFound,
+			ErrMsg: fmt.Sprintf("live id: %s can not find", vars["id"]),
+		})
+		return
+	}
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		writeJsonWithStatusCode(writer, http.StatusInternalServerError, commonResp{
+			ErrNo:  http.StatusInternalServerError,
+			ErrMsg: "配置未加载",
+		})
+		return
+	}
+
+	info := parseInfo(r.Context(), liveObj)
+	baseURL := requestBaseURL(cfg, r)
+	recordings := sessionRecordings(cfg, getLiveStateManager(r), baseURL, string(liveObj.GetLiveId()), maxPodcastEpisodes)
+	podcast := feed.Podcast{
+		Title:       fmt.Sprintf("%s - %s", info.HostName, liveObj.GetPlatformCNName()),
+		Link:        liveObj.GetRawUrl(),
+		Description: fmt.Sprintf("%s 的直播录制", info.HostName),
+		Author:      info.HostName,
+		ImageURL:    info.Cover,
+	}
+	for _, rec := range recordings {
+		podcast.Episodes = append(podcast.Episodes, feed.Episode{
+			Title:     rec.Title,
+			GUID:      rec.ID,
+			URL:       rec.URL,
+			MimeType:  rec.MimeType,
+			Size:      rec.Size,
+			Published: rec.ModTime,
+		})
+	}
+
+	writer.Header().Set(contentType, contentTypeRSS)
+	if err := feed.WriteRSS(writer, podcast); err != nil {
+		logrus.WithError(err).Debug("write podcast feed failed")
+	}
+}
+
+// getRecordingsFeed 全部直播间最新录制的 Atom 订阅源，limit 参数控制条数
+func getRecordingsFeed(writer http.ResponseWriter, r *http.Request) {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		writeJsonWithStatusCode(writer, http.StatusInternalServerError, commonResp{
+			ErrNo:  http.StatusInternalServerError,
+			ErrMsg: "配置未加载",
+		})
+		return
+	}
+	limit := defaultFeedEntries
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 {
+		limit = min(v, maxFeedEntries)
+	}
+
+	baseURL := requestBaseURL(cfg, r)
+	recordings := sessionRecordings(cfg, getLiveStateManager(r), baseURL, "", limit)
+	atom := feed.Atom{
+		Title: "bililive-go 录制",
+		ID:    baseURL + "/api/feed/recordings.atom",
+		Link:  baseURL + r.URL.RequestURI(),
+	}
+	for _, rec := range recordings {
+		summary := rec.RelPath
+		if summary == "" {
+			summary = filepath.Base(rec.Path)
+		}
+		atom.Entries = append(atom.Entries, feed.AtomEntry{
+			Title:    rec.Title,
+			ID:       rec.URL,
+			Author:   rec.HostName,
+			Summary:  summary,
+			URL:      rec.URL,
+			MimeType: rec.MimeType,
+			Size:     rec.Size,
+			Updated:  rec.ModTime,
+		})
+	}
+
+	writer.Header().Set(contentType, contentTypeAtom)
+	if err := feed.WriteAtom(writer, atom); err != nil {
+		logrus.WithError(err).Debug("write recordings feed failed")
+	}
+}
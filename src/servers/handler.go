@@ -32,9 +32,11 @@ import (
 	"github.com/bililive-go/bililive-go/src/live"
 	"github.com/bililive-go/bililive-go/src/livestate"
 	applog "github.com/bililive-go/bililive-go/src/log"
+	"github.com/bililive-go/bililive-go/src/pkg/cookiemonitor"
 	"github.com/bililive-go/bililive-go/src/pkg/livelogger"
 	"github.com/bililive-go/bililive-go/src/pkg/memstats"
 	"github.com/bililive-go/bililive-go/src/pkg/ratelimit"
+	"github.com/bililive-go/bililive-go/src/pkg/storyboard"
 	"github.com/bililive-go/bililive-go/src/pkg/utils"
 	"github.com/bililive-go/bililive-go/src/recorders"
 	"github.com/bililive-go/bililive-go/src/tools"
@@ -72,6 +74,7 @@ func parseInfo(ctx context.Context, l live.Live) *live.Info {
 	// 否则前一次调用的残留值会导致 recording=true + recording_preparing=true 同时返回
 	info.Recording = false
 	info.RecordingPreparing = false
+	info.RecordingQueuePosition = 0
 	recorderMgr := inst.RecorderManager.(recorders.Manager)
 	if recorderMgr.HasRecorder(ctx, l.GetLiveId()) {
 		if recorder, err := recorderMgr.GetRecorder(ctx, l.GetLiveId()); err == nil && recorder.IsRecording() {
@@ -80,6 +83,14 @@ func parseInfo(ctx context.Context, l live.Live) *live.Info {
 			// 有 recorder 但尚未真正开始录制（例如流 URL 404 导致不断重试）
 			info.RecordingPreparing = true
 		}
+	} else {
+		// 达到同时录制上限时在排队等待
+		for i, q := range recorderMgr.GetRecordingQueue().Queue {
+			if q.LiveID == l.GetLiveId() {
+				info.RecordingQueuePosition = i + 1
+				break
+			}
+		}
 	}
 	if info.HostName == "" {
 		info.HostName = "获取失败"
@@ -87,6 +98,13 @@ func parseInfo(ctx context.Context, l live.Live) *live.Info {
 	if info.RoomName == "" {
 		info.RoomName = l.GetRawUrl()
 	}
+	info.EffectiveIntervalSeconds = 0
+	info.PredictedNextStartAt = time.Time{}
+	if provider, ok := l.(live.SchedulerStatusProvider); ok {
+		status := provider.GetSchedulerStatus()
+		info.EffectiveIntervalSeconds = status.EffectiveIntervalSeconds
+		info.PredictedNextStartAt = status.PredictedNextStartAt
+	}
 	return info
 }
 
@@ -174,7 +192,7 @@ func getLive(writer http.ResponseWriter, r *http.Request) {
 	}
 
 	// 获取平台等待状态信息
-	waitInfo := ratelimit.GetGlobalRateLimiter().GetPlatformWaitInfo(platformKey)
+	waitInfo := ratelimit.GetGlobalRateLimiter().GetWaitInfo(platformKey, ratelimit.EndpointInfo)
 
 	// 获取调度器状态信息
 	var schedulerStatus *live.SchedulerStatus
@@ -217,6 +235,12 @@ func getLive(writer http.ResponseWriter, r *http.Request) {
 		liveStartTime = lastStartTime.Format("2006-01-02 15:04:05")
 	}
 
+	// 获取当前使用的账号
+	var activeAccount string
+	if opts := liveObj.GetOptions(); opts != nil {
+		activeAccount = opts.Account
+	}
+
 	// 构造详细响应
 	detailedInfo := map[string]interface{}{
 		// 基本信息
@@ -241,19 +265,26 @@ func getLive(writer http.ResponseWriter, r *http.Request) {
 		// 平台访问限制
 		"platform_rate_limit": cfg.GetPlatformMinAccessInterval(platformKey),
 
-		// 平台等待状态
-		"rate_limit_info": map[string]interface{}{
-			"waited_seconds":      waitInfo.WaitedSeconds,
-			"next_request_in_sec": waitInfo.NextRequestInSec,
-			"min_interval_sec":    waitInfo.MinIntervalSec,
-		},
+		// 平台等待状态（开播状态检测），rate_limit_buckets 为各接口类别的令牌桶状态
+		"rate_limit_info":    rateLimitInfo(waitInfo),
+		"rate_limit_buckets": rateLimitBuckets(platformKey),
 
-		// 配置来源信息
+		// 配置来源信息（global / platform / room / profile:<模板名> / tag:<标签名>）
 		"config_sources": map[string]string{
-			"interval":     getConfigSource(cfg, *room, platformKey, "interval"),
-			"out_put_path": getConfigSource(cfg, *room, platformKey, "out_put_path"),
-			"ffmpeg_path":  getConfigSource(cfg, *room, platformKey, "ffmpeg_path"),
+			"interval":               getConfigSource(&resolvedConfig, "interval"),
+			"out_put_path":           getConfigSource(&resolvedConfig, "out_put_path"),
+			"ffmpeg_path":            getConfigSource(&resolvedConfig, "ffmpeg_path"),
+			"out_put_tmpl":           getConfigSource(&resolvedConfig, "out_put_tmpl"),
+			"feature":                getConfigSource(&resolvedConfig, "feature"),
+			"video_split_strategies": getConfigSource(&resolvedConfig, "video_split_strategies"),
+			"on_record_finished":     getConfigSource(&resolvedConfig, "on_record_finished"),
+			"stream_preference":      getConfigSource(&resolvedConfig, "stream_preference"),
+			"notify_rules":           getConfigSource(&resolvedConfig, "notify_rules"),
 		},
+		"profiles": room.Profiles,
+		"tags":     room.Tags,
+		// 当前使用的账号（为空表示使用 cookies 中该平台的 Cookie，开启轮换时可能与 room_config.account 不同）
+		"account": activeAccount,
 
 		// 运行时信息 - 连接统计
 		"conn_stats": connStats,
@@ -313,43 +344,9 @@ func getLive(writer http.ResponseWriter, r *http.Request) {
 }
 
 // getConfigSource 获取配置项的来源级别
-func getConfigSource(config *configs.Config, room configs.LiveRoom, platformKey, configKey string) string {
-	// 检查房间级配置
-	switch configKey {
-	case "interval":
-		if room.Interval != nil {
-			return "room"
-		}
-	case "out_put_path":
-		if room.OutPutPath != nil {
-			return "room"
-		}
-	case "ffmpeg_path":
-		if room.FfmpegPath != nil {
-			return "room"
-		}
-	}
-
-	// 检查平台级配置
-	if platformConfig, exists := config.PlatformConfigs[platformKey]; exists {
-		switch configKey {
-		case "interval":
-			if platformConfig.Interval != nil {
-				return "platform"
-			}
-		case "out_put_path":
-			if platformConfig.OutPutPath != nil {
-				return "platform"
-			}
-		case "ffmpeg_path":
-			if platformConfig.FfmpegPath != nil {
-				return "platform"
-			}
-		}
-	}
-
-	// 默认为全局配置
-	return "global"
+// 返回 global / platform / room，来自配置模板时返回 profile:<模板名>
+func getConfigSource(resolved *configs.ResolvedConfig, configKey string) string {
+	return resolved.SourceOf(configKey)
 }
 
 func getLiveLogs(writer http.ResponseWriter, r *http.Request) {
@@ -458,7 +455,7 @@ func parseLiveAction(writer http.ResponseWriter, r *http.Request) {
 	case "forceRefresh":
 		// 强制刷新：忽略平台访问频率限制，立即获取最新信息
 		platformKey := configs.GetPlatformKeyFromUrl(live.GetRawUrl())
-		ratelimit.GetGlobalRateLimiter().ForceAccess(platformKey)
+		ratelimit.GetGlobalRateLimiter().ForceAccess(platformKey, ratelimit.EndpointInfo)
 
 		// 手动调用 GetInfo 获取最新信息
 		info, err := live.GetInfo()
@@ -470,12 +467,8 @@ func parseLiveAction(writer http.ResponseWriter, r *http.Request) {
 		}
 
 		// 广播频率限制更新事件，通知前端更新倒计时
-		waitInfo := ratelimit.GetGlobalRateLimiter().GetPlatformWaitInfo(platformKey)
-		GetSSEHub().BroadcastRateLimitUpdate(live.GetLiveId(), map[string]interface{}{
-			"waited_seconds":      waitInfo.WaitedSeconds,
-			"next_request_in_sec": waitInfo.NextRequestInSec,
-			"min_interval_sec":    waitInfo.MinIntervalSec,
-		})
+		waitInfo := ratelimit.GetGlobalRateLimiter().GetWaitInfo(platformKey, ratelimit.EndpointInfo)
+		GetSSEHub().BroadcastRateLimitUpdate(live.GetLiveId(), rateLimitInfo(waitInfo))
 
 		// 返回刷新后的信息
 		writeJSON(writer, map[string]interface{}{
@@ -693,14 +686,30 @@ func addLives(writer http.ResponseWriter, r *http.Request) {
 	writeJSON(writer, info)
 }
 
+// errFileRoomNotAllowed 通过接口添加或修改为 file:// 直播间时返回
+// file:// 直播间会读取任意本地路径，只允许在配置文件中手动添加
+var errFileRoomNotAllowed = errors.New("file:// 直播间只能在配置文件中添加")
+
+// checkRoomUrlAllowed 检查通过接口提交的直播间地址是否允许使用
+func checkRoomUrlAllowed(u *url.URL) error {
+	if strings.EqualFold(u.Scheme, "file") {
+		return errFileRoomNotAllowed
+	}
+	return nil
+}
+
 func addLiveImpl(ctx context.Context, urlStr string, isListen bool) (info *live.Info, err error) {
-	if !strings.HasPrefix(urlStr, "http://") && !strings.HasPrefix(urlStr, "https://") {
+	// rtmp:// 直链等带有协议的地址保持原样
+	if !strings.Contains(urlStr, "://") {
 		urlStr = "https://" + urlStr
 	}
 	u, err := url.Parse(urlStr)
 	if err != nil {
 		return nil, errors.New("can't parse url: " + urlStr)
 	}
+	if err := checkRoomUrlAllowed(u); err != nil {
+		return nil, err
+	}
 	inst := instance.GetInstance(ctx)
 	needAppend := false
 	liveRoom, err := configs.GetCurrentConfig().GetLiveRoomByUrl(u.String())
@@ -1184,6 +1193,9 @@ type VideoFileInfo struct {
 	RelPath  string `json:"rel_path"`  // 相对于 output_path 的路径（用于缩略图和播放）
 	Size     int64  `json:"size"`      // 字节数
 	ModTime  int64  `json:"mod_time"`  // Unix 时间戳
+	// HasStoryboard / HasWaveform 是否已有故事板缓存（雪碧图 + WebVTT）和音频波形，可通过 /api/storyboard/{rel_path} 获取
+	HasStoryboard bool `json:"has_storyboard"`
+	HasWaveform   bool `json:"has_waveform"`
 }
 
 // getVideoFiles 列出指定文件夹路径（相对 output_path）下的所有视频文件
@@ -1226,12 +1238,19 @@ func getVideoFiles(writer http.ResponseWriter, r *http.Request) {
 			return nil
 		}
 		rel, _ := filepath.Rel(rootPath, path)
-		files = append(files, VideoFileInfo{
+		file := VideoFileInfo{
 			Name:    d.Name(),
 			RelPath: filepath.ToSlash(rel),
 			Size:    info.Size(),
 			ModTime: info.ModTime().Unix(),
-		})
+		}
+		if dir := cachedStoryboardDir(cfg, file.RelPath, path); dir != "" {
+			if m, err := storyboard.Load(dir); err == nil {
+				file.HasStoryboard = len(m.Sprites) > 0
+				file.HasWaveform = m.Waveform != ""
+			}
+		}
+		files = append(files, file)
 		return nil
 	})
 	if err != nil {
@@ -1433,6 +1452,9 @@ type EffectiveConfigResponse struct {
 	DownloaderAvailability tools.DownloaderAvailability `json:"downloader_availability"`
 	// 可用的下载器类型列表
 	AvailableDownloaders []string `json:"available_downloaders"`
+
+	// 指定 room 参数时返回该直播间的解析结果，Sources 中标明每个值来自哪一层级或配置模板
+	RoomEffectiveConfig *configs.ResolvedConfig `json:"room_effective_config,omitempty"`
 }
 
 // getEffectiveConfig 获取实际生效的配置值（用于GUI模式显示）
@@ -1515,6 +1537,12 @@ func getEffectiveConfig(writer http.ResponseWriter, r *http.Request) {
 		AvailableDownloaders:     availableDownloaders,
 	}
 
+	// 可选：解析指定直播间的生效配置及来源
+	if roomUrl := r.URL.Query().Get("room"); roomUrl != "" {
+		resolved := cfg.GetEffectiveConfigForRoom(roomUrl)
+		response.RoomEffectiveConfig = &resolved
+	}
+
 	writeJSON(writer, response)
 }
 
@@ -1597,11 +1625,12 @@ func getPlatformStats(writer http.ResponseWriter, r *http.Request) {
 			actualAccessInterval = float64(interval) / float64(listeningCount)
 		}
 
-		// 检查是否低于最小访问间隔
+		// 检查是否低于开播状态检测的平均请求间隔
 		warningMessage := ""
-		if listeningCount > 0 && platformConfig.MinAccessIntervalSec > 0 && actualAccessInterval < float64(platformConfig.MinAccessIntervalSec) {
-			effectiveInterval := float64(platformConfig.MinAccessIntervalSec) * float64(listeningCount)
-			warningMessage = fmt.Sprintf("当前设置下实际每个直播间的检测间隔约为 %.1f 秒（受最小访问间隔限制）", effectiveInterval)
+		infoLimit, limited := cfg.GetPlatformRateLimit(platformKey, ratelimit.EndpointInfo)
+		if limited && listeningCount > 0 && actualAccessInterval < infoLimit.IntervalSec {
+			effectiveInterval := infoLimit.IntervalSec * float64(listeningCount)
+			warningMessage = fmt.Sprintf("当前设置下实际每个直播间的检测间隔约为 %.1f 秒（受访问频率限制）", effectiveInterval)
 		}
 
 		stats = append(stats, map[string]interface{}{
@@ -1612,7 +1641,8 @@ func getPlatformStats(writer http.ResponseWriter, r *http.Request) {
 			"rooms":                   rooms,
 			"has_config":              true,
 			"has_rooms":               len(rooms) > 0,
-			"min_access_interval_sec": platformConfig.MinAccessIntervalSec,
+			"min_access_interval_sec": platformInfoIntervalSec(platformConfig),
+			"rate_limits":             platformConfig.RateLimits,
 			"interval":                platformConfig.Interval,
 			"effective_interval":      interval,
 			"actual_access_interval":  actualAccessInterval,
@@ -1662,7 +1692,8 @@ func getPlatformStats(writer http.ResponseWriter, r *http.Request) {
 			"rooms":                   []map[string]interface{}{},
 			"has_config":              true,
 			"has_rooms":               false,
-			"min_access_interval_sec": platformConfig.MinAccessIntervalSec,
+			"min_access_interval_sec": platformInfoIntervalSec(platformConfig),
+			"rate_limits":             platformConfig.RateLimits,
 			"interval":                platformConfig.Interval,
 			"out_put_path":            platformConfig.OutPutPath,
 			"ffmpeg_path":             platformConfig.FfmpegPath,
@@ -1811,6 +1842,9 @@ func (m *mockLiveForPreview) UpdateLiveOptionsbyConfig(ctx context.Context, room
 }
 func (m *mockLiveForPreview) GetLogger() *livelogger.LiveLogger { return nil }
 
+// errInvalidConfigUpdate 更新内容无法解析或不合法时返回，对应 400
+var errInvalidConfigUpdate = errors.New("无效的配置")
+
 // updateConfig 更新配置（支持部分更新）
 func updateConfig(writer http.ResponseWriter, r *http.Request) {
 	b, err := io.ReadAll(r.Body)
@@ -1836,13 +1870,20 @@ func updateConfig(writer http.ResponseWriter, r *http.Request) {
 		if err := applyConfigUpdates(c, updates); err != nil {
 			return err
 		}
+		if err := c.ValidateNotifyRules(); err != nil {
+			return fmt.Errorf("%w: %w", errInvalidConfigUpdate, err)
+		}
 		// 校验配置
 		return c.Verify()
 	}, 3, 10*time.Millisecond)
 
 	if err != nil {
-		writeJsonWithStatusCode(writer, http.StatusInternalServerError, commonResp{
-			ErrNo:  http.StatusInternalServerError,
+		code := http.StatusInternalServerError
+		if errors.Is(err, errInvalidConfigUpdate) {
+			code = http.StatusBadRequest
+		}
+		writeJsonWithStatusCode(writer, code, commonResp{
+			ErrNo:  code,
 			ErrMsg: "更新配置失败: " + err.Error(),
 		})
 		return
@@ -1853,6 +1894,15 @@ func updateConfig(writer http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// decodeConfigUpdate 将 JSON 解析得到的更新内容按字段合并到 target
+func decodeConfigUpdate(updates any, target any) error {
+	b, err := json.Marshal(updates)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, target)
+}
+
 // applyConfigUpdates 将更新应用到配置
 func applyConfigUpdates(c *configs.Config, updates map[string]interface{}) error {
 	// 处理 RPC 配置
@@ -1921,6 +1971,7 @@ func applyConfigUpdates(c *configs.Config, updates map[string]interface{}) error
 		if removeSymbolOther, ok := feature["remove_symbol_other_character"].(bool); ok {
 			c.Feature.RemoveSymbolOtherCharacter = removeSymbolOther
 		}
+		applyDownloaderFallbackUpdates(&c.Feature, feature)
 	}
 
 	// 处理视频分割策略
@@ -1988,6 +2039,42 @@ func applyConfigUpdates(c *configs.Config, updates map[string]interface{}) error
 				c.Notify.Email.RecipientEmail = recipientEmail
 			}
 		}
+		// 其余通知渠道的字段与 JSON 键一一对应，直接合并到现有配置上
+		channelTargets := map[string]any{
+			"bark":       &c.Notify.Bark,
+			"serverchan": &c.Notify.ServerChan,
+			"dingtalk":   &c.Notify.DingTalk,
+			"feishu":     &c.Notify.Feishu,
+			"wecom":      &c.Notify.WeCom,
+			"discord":    &c.Notify.Discord,
+			"gotify":     &c.Notify.Gotify,
+			"pushover":   &c.Notify.Pushover,
+		}
+		for channel, target := range channelTargets {
+			channelUpdates, ok := notify[channel].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if err := decodeConfigUpdate(channelUpdates, target); err != nil {
+				return fmt.Errorf("%w: 通知渠道 %s: %v", errInvalidConfigUpdate, channel, err)
+			}
+		}
+
+		// 通知规则整体替换，避免与旧配置快照共享 map
+		if rules, ok := notify["rules"].(map[string]interface{}); ok {
+			var newRules configs.NotifyRules
+			if err := decodeConfigUpdate(rules, &newRules); err != nil {
+				return fmt.Errorf("%w: 通知规则: %v", errInvalidConfigUpdate, err)
+			}
+			c.Notify.Rules = newRules
+		}
+		if tagRules, ok := notify["tag_rules"].(map[string]interface{}); ok {
+			var newTagRules map[string]configs.NotifyRules
+			if err := decodeConfigUpdate(tagRules, &newTagRules); err != nil {
+				return fmt.Errorf("%w: 标签通知规则: %v", errInvalidConfigUpdate, err)
+			}
+			c.Notify.TagRules = newTagRules
+		}
 	}
 
 	// 处理代理配置
@@ -2085,18 +2172,39 @@ func updatePlatformConfig(writer http.ResponseWriter, r *http.Request) {
 			pc.Name = name
 		}
 		if minInterval, ok := updates["min_access_interval_sec"].(float64); ok {
-			pc.MinAccessIntervalSec = int(minInterval)
+			// 兼容旧接口：设置开播状态检测的令牌补充间隔，保留已配置的容量
+			setPlatformRateLimit(&pc, ratelimit.EndpointInfo, minInterval, 0)
+		}
+		if limits, ok := updates["rate_limits"].(map[string]interface{}); ok {
+			for endpoint, v := range limits {
+				bucket, _ := v.(map[string]interface{})
+				interval, _ := bucket["interval_sec"].(float64)
+				burst, _ := bucket["burst"].(float64)
+				setPlatformRateLimit(&pc, ratelimit.Endpoint(endpoint), interval, int(burst))
+			}
 		}
 		// 使用助手函数更新可覆盖配置
-		applyOverridableConfigUpdates(&pc.OverridableConfig, updates)
+		if err := applyOverridableConfigUpdates(&pc.OverridableConfig, updates); err != nil {
+			return err
+		}
+		if err := applyProfileReferenceUpdates(c, &pc.Profiles, updates); err != nil {
+			return err
+		}
 
 		c.PlatformConfigs[platformKey] = pc
+		if err := c.ValidateNotifyRules(); err != nil {
+			return fmt.Errorf("%w: %w", errInvalidConfigUpdate, err)
+		}
 		return nil
 	}, 3, 10*time.Millisecond)
 
 	if err != nil {
-		writeJsonWithStatusCode(writer, http.StatusInternalServerError, commonResp{
-			ErrNo:  http.StatusInternalServerError,
+		code := http.StatusInternalServerError
+		if errors.Is(err, errInvalidConfigUpdate) {
+			code = http.StatusBadRequest
+		}
+		writeJsonWithStatusCode(writer, code, commonResp{
+			ErrNo:  code,
 			ErrMsg: "更新平台配置失败: " + err.Error(),
 		})
 		return
@@ -2155,7 +2263,8 @@ func updateRoomConfigById(writer http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = configs.UpdateWithRetry(func(c *configs.Config) error {
+	var roomUrlAfterUpdate string
+	newCfg, err := configs.UpdateWithRetry(func(c *configs.Config) error {
 		// 查找直播间
 		roomIdx := -1
 		for i, room := range c.LiveRooms {
@@ -2172,8 +2281,15 @@ func updateRoomConfigById(writer http.ResponseWriter, r *http.Request) {
 		room := &c.LiveRooms[roomIdx]
 
 		// 更新直播间特有字段
-		if url, ok := updates["url"].(string); ok {
-			room.Url = url
+		if roomUrl, ok := updates["url"].(string); ok {
+			u, err := url.Parse(roomUrl)
+			if err != nil {
+				return fmt.Errorf("%w: 无法解析直播间地址: %v", errInvalidConfigUpdate, err)
+			}
+			if err := checkRoomUrlAllowed(u); err != nil {
+				return fmt.Errorf("%w: %w", errInvalidConfigUpdate, err)
+			}
+			room.Url = roomUrl
 		}
 		if isListening, ok := updates["is_listening"].(bool); ok {
 			room.IsListening = isListening
@@ -2189,26 +2305,67 @@ func updateRoomConfigById(writer http.ResponseWriter, r *http.Request) {
 		}
 
 		// 更新可覆盖配置
-		applyOverridableConfigUpdates(&room.OverridableConfig, updates)
+		if err := applyOverridableConfigUpdates(&room.OverridableConfig, updates); err != nil {
+			return err
+		}
 
-		return nil
+		applyRoomTagUpdates(room, updates)
+		applyRoomAccountUpdates(room, updates)
+		roomUrlAfterUpdate = room.Url
+
+		if err := applyProfileReferenceUpdates(c, &room.Profiles, updates); err != nil {
+			return err
+		}
+		if err := c.ValidateNotifyRules(); err != nil {
+			return fmt.Errorf("%w: %w", errInvalidConfigUpdate, err)
+		}
+		return c.ValidateAccounts()
 	}, 3, 10*time.Millisecond)
 
 	if err != nil {
-		writeJsonWithStatusCode(writer, http.StatusInternalServerError, commonResp{
-			ErrNo:  http.StatusInternalServerError,
+		code := http.StatusInternalServerError
+		if errors.Is(err, errInvalidConfigUpdate) {
+			code = http.StatusBadRequest
+		}
+		writeJsonWithStatusCode(writer, code, commonResp{
+			ErrNo:  code,
 			ErrMsg: "更新直播间配置失败: " + err.Error(),
 		})
 		return
 	}
+	if _, ok := updates["account"]; ok {
+		if u, err := url.Parse(roomUrlAfterUpdate); err == nil {
+			cookiemonitor.ReloadLiveOptions(r.Context(), newCfg, u.Host)
+		}
+	}
 
 	writeJSON(writer, commonResp{
 		Data: "OK",
 	})
 }
 
+// applyDownloaderFallbackUpdates 应用下载器回退配置的更新，downloader_fallback 为 null 时清除备用下载器
+func applyDownloaderFallbackUpdates(f *configs.Feature, feature map[string]interface{}) {
+	if fallback, ok := feature["downloader_fallback"]; ok {
+		f.DownloaderFallback = nil
+		if list, isList := fallback.([]interface{}); isList {
+			for _, item := range list {
+				if name, isString := item.(string); isString {
+					f.DownloaderFallback = append(f.DownloaderFallback, configs.DownloaderType(name))
+				}
+			}
+		}
+	}
+	if failures, ok := feature["downloader_fallback_failures"].(float64); ok {
+		f.DownloaderFallbackFailures = int(failures)
+	}
+	if fastFailureSec, ok := feature["downloader_fast_failure_sec"].(float64); ok {
+		f.DownloaderFastFailureSec = int(fastFailureSec)
+	}
+}
+
 // applyOverridableConfigUpdates 统一处理可覆盖配置的更新
-func applyOverridableConfigUpdates(oc *configs.OverridableConfig, updates map[string]interface{}) {
+func applyOverridableConfigUpdates(oc *configs.OverridableConfig, updates map[string]interface{}) error {
 	if interval, ok := updates["interval"].(float64); ok {
 		val := int(interval)
 		oc.Interval = &val
@@ -2238,6 +2395,29 @@ func applyOverridableConfigUpdates(oc *configs.OverridableConfig, updates map[st
 		val := int(timeoutSec * 1000000)
 		oc.TimeoutInUs = &val
 	}
+	if priority, ok := updates["recording_priority"]; ok {
+		if val, isNum := priority.(float64); isNum {
+			p := int(val)
+			oc.RecordingPriority = &p
+		} else if priority == nil {
+			oc.RecordingPriority = nil
+		}
+	}
+	if limit, ok := updates["bandwidth_limit_mbps"]; ok {
+		if val, isNum := limit.(float64); isNum {
+			oc.BandwidthLimitMbps = &val
+		} else if limit == nil {
+			oc.BandwidthLimitMbps = nil
+		}
+	}
+	if weight, ok := updates["rate_limit_weight"]; ok {
+		if val, isNum := weight.(float64); isNum {
+			w := int(val)
+			oc.RateLimitWeight = &w
+		} else if weight == nil {
+			oc.RateLimitWeight = nil
+		}
+	}
 
 	// 处理 feature 配置（包括 downloader_type）
 	if feature, ok := updates["feature"].(map[string]interface{}); ok {
@@ -2253,6 +2433,7 @@ func applyOverridableConfigUpdates(oc *configs.OverridableConfig, updates map[st
 		if enableFlvProxySegment, ok := feature["enable_flv_proxy_segment"].(bool); ok {
 			oc.Feature.EnableFlvProxySegment = enableFlvProxySegment
 		}
+		applyDownloaderFallbackUpdates(oc.Feature, feature)
 	}
 
 	// 也支持直接在顶层设置 downloader_type（简化前端逻辑）
@@ -2302,6 +2483,39 @@ func applyOverridableConfigUpdates(oc *configs.OverridableConfig, updates map[st
 			oc.StreamPreference = nil
 		}
 	}
+
+	// 处理 notify_rules 配置，传入 null 表示清除
+	if rawRules, ok := updates["notify_rules"]; ok {
+		if rawRules == nil {
+			oc.NotifyRules = nil
+		} else {
+			var rules configs.NotifyRules
+			if err := decodeConfigUpdate(rawRules, &rules); err != nil {
+				return fmt.Errorf("%w: 通知规则: %v", errInvalidConfigUpdate, err)
+			}
+			oc.NotifyRules = &rules
+		}
+	}
+	return nil
+}
+
+// applyRoomTagUpdates 处理直播间标签更新，传入空数组表示清除
+func applyRoomTagUpdates(room *configs.LiveRoom, updates map[string]interface{}) {
+	raw, ok := updates["tags"].([]interface{})
+	if !ok {
+		return
+	}
+	tags := make([]string, 0, len(raw))
+	for _, v := range raw {
+		if tag, ok := v.(string); ok && tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		room.Tags = nil
+		return
+	}
+	room.Tags = tags
 }
 
 // updateRoomConfig 更新直播间配置
@@ -2337,7 +2551,8 @@ func updateRoomConfig(writer http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = configs.UpdateWithRetry(func(c *configs.Config) error {
+	var roomUrlAfterUpdate string
+	newCfg, err := configs.UpdateWithRetry(func(c *configs.Config) error {
 		room, err := c.GetLiveRoomByUrl(decodedUrl)
 		if err != nil {
 			return errors.New("找不到直播间: " + decodedUrl)
@@ -2372,16 +2587,35 @@ func updateRoomConfig(writer http.ResponseWriter, r *http.Request) {
 			}
 		}
 
-		return nil
+		applyRoomTagUpdates(room, updates)
+		applyRoomAccountUpdates(room, updates)
+		roomUrlAfterUpdate = room.Url
+
+		if err := applyProfileReferenceUpdates(c, &room.Profiles, updates); err != nil {
+			return err
+		}
+		if err := c.ValidateNotifyRules(); err != nil {
+			return fmt.Errorf("%w: %w", errInvalidConfigUpdate, err)
+		}
+		return c.ValidateAccounts()
 	}, 3, 10*time.Millisecond)
 
 	if err != nil {
-		writeJsonWithStatusCode(writer, http.StatusInternalServerError, commonResp{
-			ErrNo:  http.StatusInternalServerError,
+		code := http.StatusInternalServerError
+		if errors.Is(err, errInvalidConfigUpdate) {
+			code = http.StatusBadRequest
+		}
+		writeJsonWithStatusCode(writer, code, commonResp{
+			ErrNo:  code,
 			ErrMsg: "更新直播间配置失败: " + err.Error(),
 		})
 		return
 	}
+	if _, ok := updates["account"]; ok {
+		if u, err := url.Parse(roomUrlAfterUpdate); err == nil {
+			cookiemonitor.ReloadLiveOptions(r.Context(), newCfg, u.Host)
+		}
+	}
 
 	writeJSON(writer, commonResp{
 		Data: "OK",
@@ -2796,6 +3030,8 @@ func putLiveHostCookie(writer http.ResponseWriter, r *http.Request) {
 	if err := newCfg.Marshal(); err != nil {
 		applog.GetLogger().Error("failed to persistence config: " + err.Error())
 	}
+	// Cookie 更新后立即重新检查登录状态
+	cookiemonitor.GetMonitor().TriggerCheck()
 	writeJSON(writer, commonResp{
 		Data: "OK",
 	})
@@ -2887,10 +3123,24 @@ func getLiveNameHistory(writer http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// getRecordingQueue 获取同时录制数量限制和因达到上限排队的直播间
+func getRecordingQueue(writer http.ResponseWriter, r *http.Request) {
+	inst := instance.GetInstance(r.Context())
+	recorderMgr, ok := inst.RecorderManager.(recorders.Manager)
+	if !ok {
+		writeJsonWithStatusCode(writer, http.StatusServiceUnavailable, commonResp{
+			ErrNo:  http.StatusServiceUnavailable,
+			ErrMsg: "录制管理器未初始化",
+		})
+		return
+	}
+	writeJSON(writer, recorderMgr.GetRecordingQueue())
+}
+
 // HistoryEvent 统一的历史事件格式
 type HistoryEvent struct {
 	ID        int64     `json:"id"`
-	Type      string    `json:"type"`      // "session" 或 "name_change"
+	Type      string    `json:"type"`      // "session"、"name_change"、"preemption" 或 "downloader_switch"
 	Timestamp time.Time `json:"timestamp"` // 事件时间
 	Data      any       `json:"data"`      // 事件详情
 }
@@ -2958,6 +3208,8 @@ func getLiveHistory(writer http.ResponseWriter, r *http.Request) {
 	eventTypes := query["type"] // 支持多选: ?type=session&type=name_change
 	includeSession := len(eventTypes) == 0 || contains(eventTypes, "session")
 	includeNameChange := len(eventTypes) == 0 || contains(eventTypes, "name_change")
+	includePreemption := len(eventTypes) == 0 || contains(eventTypes, "preemption")
+	includeDownloaderSwitch := len(eventTypes) == 0 || contains(eventTypes, "downloader_switch")
 
 	// 收集所有事件
 	var events []HistoryEvent
@@ -3003,6 +3255,46 @@ func getLiveHistory(writer http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// 获取录制抢占历史
+	if includePreemption {
+		preemptions := manager.GetPreemptionHistory(liveID, 1000)
+		for _, p := range preemptions {
+			// 时间范围筛选
+			if !startTime.IsZero() && p.PreemptedAt.Before(startTime) {
+				continue
+			}
+			if !endTime.IsZero() && p.PreemptedAt.After(endTime) {
+				continue
+			}
+			events = append(events, HistoryEvent{
+				ID:        p.ID,
+				Type:      "preemption",
+				Timestamp: p.PreemptedAt,
+				Data:      p,
+			})
+		}
+	}
+
+	// 获取下载器切换历史
+	if includeDownloaderSwitch {
+		switches := manager.GetDownloaderSwitchHistory(liveID, 1000)
+		for _, sw := range switches {
+			// 时间范围筛选
+			if !startTime.IsZero() && sw.SwitchedAt.Before(startTime) {
+				continue
+			}
+			if !endTime.IsZero() && sw.SwitchedAt.After(endTime) {
+				continue
+			}
+			events = append(events, HistoryEvent{
+				ID:        sw.ID,
+				Type:      "downloader_switch",
+				Timestamp: sw.SwitchedAt,
+				Data:      sw,
+			})
+		}
+	}
+
 	// 按时间倒序排序
 	sort.Slice(events, func(i, j int) bool {
 		return events[i].Timestamp.After(events[j].Timestamp)
@@ -3239,8 +3531,9 @@ func pollBilibiliQRCode(writer http.ResponseWriter, r *http.Request) {
 	var result struct {
 		Code int `json:"code"`
 		Data struct {
-			Code int    `json:"code"`
-			Url  string `json:"url"`
+			Code         int    `json:"code"`
+			Url          string `json:"url"`
+			RefreshToken string `json:"refresh_token"`
 		} `json:"data"`
 	}
 
@@ -3272,6 +3565,16 @@ func pollBilibiliQRCode(writer http.ResponseWriter, r *http.Request) {
 					body = newBody
 				}
 			}
+			// 指定了 account 参数时，将登录结果直接保存为命名账号，否则保存为全局 Cookie
+			// 两种情况都会保存刷新令牌，用于 Cookie 自动刷新
+			u.RawQuery = q.Encode()
+			if account := strings.TrimSpace(r.URL.Query().Get("account")); account != "" {
+				if err := saveBilibiliLoginAccount(r.Context(), account, u, result.Data.RefreshToken); err != nil {
+					applog.GetLogger().Error("保存扫码登录账号失败: " + err.Error())
+				}
+			} else if err := saveBilibiliLoginCookie(r.Context(), u, result.Data.RefreshToken); err != nil {
+				applog.GetLogger().Error("保存扫码登录 Cookie 失败: " + err.Error())
+			}
 		}
 	}
 
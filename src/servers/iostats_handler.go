@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/bililive-go/bililive-go/src/instance"
+	"github.com/bililive-go/bililive-go/src/pkg/bandwidth"
 	"github.com/bililive-go/bililive-go/src/pkg/iostats"
 )
 
@@ -226,6 +227,13 @@ func getDiskIOStats(writer http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// getBandwidthStats 获取带宽上限和各使用方的实时带宽，不依赖 IO 统计模块
+func getBandwidthStats(writer http.ResponseWriter, r *http.Request) {
+	writeJSON(writer, commonResp{
+		Data: bandwidth.GetManager().Snapshot(),
+	})
+}
+
 // getDiskDevices 获取可用的磁盘设备列表
 func getDiskDevices(writer http.ResponseWriter, r *http.Request) {
 	inst := instance.GetInstance(r.Context())
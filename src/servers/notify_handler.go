@@ -0,0 +1,66 @@
+package servers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/bililive-go/bililive-go/src/notify"
+)
+
+// notifyTestResult 单个通知渠道的测试结果
+type notifyTestResult struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// getNotifyChannels 获取所有已注册的通知渠道
+func getNotifyChannels(writer http.ResponseWriter, r *http.Request) {
+	writeJSON(writer, notify.Channels())
+}
+
+// sendTestNotification 发送测试通知
+// 请求体可选 {"channel": "bark"}，不指定渠道时发送到所有已启用的渠道
+func sendTestNotification(writer http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Channel string `json:"channel"`
+	}
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJsonWithStatusCode(writer, http.StatusBadRequest, commonResp{
+			ErrNo:  http.StatusBadRequest,
+			ErrMsg: err.Error(),
+		})
+		return
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &req); err != nil {
+			writeJsonWithStatusCode(writer, http.StatusBadRequest, commonResp{
+				ErrNo:  http.StatusBadRequest,
+				ErrMsg: "无效的JSON格式: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	results, err := notify.SendTest(r.Context(), req.Channel)
+	if err != nil {
+		writeJsonWithStatusCode(writer, http.StatusBadRequest, commonResp{
+			ErrNo:  http.StatusBadRequest,
+			ErrMsg: "发送测试通知失败: " + err.Error(),
+		})
+		return
+	}
+
+	data := make(map[string]notifyTestResult, len(results))
+	for channel, sendErr := range results {
+		result := notifyTestResult{Success: sendErr == nil}
+		if sendErr != nil {
+			result.Error = sendErr.Error()
+		}
+		data[channel] = result
+	}
+	writeJSON(writer, commonResp{
+		Data: data,
+	})
+}
@@ -0,0 +1,159 @@
+package servers
+
+import (
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/pkg/remux"
+	"github.com/bililive-go/bililive-go/src/pkg/utils"
+)
+
+var (
+	remuxCache     *remux.Cache
+	remuxCacheOnce sync.Once
+)
+
+// getRemuxCache 关键帧索引缓存在 .appdata/remux-index/ 下
+func getRemuxCache() *remux.Cache {
+	remuxCacheOnce.Do(func() {
+		appDataPath := ".appdata"
+		if cfg := configs.GetCurrentConfig(); cfg != nil && cfg.AppDataPath != "" {
+			appDataPath = cfg.AppDataPath
+		}
+		remuxCache = remux.NewCache(filepath.Join(appDataPath, "remux-index"))
+	})
+	return remuxCache
+}
+
+// playInfo 在线播放信息
+type playInfo struct {
+	Remux     bool    `json:"remux"`               // 是否需要转封装（否则直接返回原文件）
+	MimeType  string  `json:"mime_type,omitempty"` // 可用于 MediaSource.isTypeSupported
+	Duration  float64 `json:"duration,omitempty"`  // 时长（秒），FLV 以外的格式为 0
+	Width     int     `json:"width,omitempty"`
+	Height    int     `json:"height,omitempty"`
+	Keyframes int     `json:"keyframes,omitempty"`
+	PlayUrl   string  `json:"play_url"`
+}
+
+// resolvePlayFile 解析请求中的视频路径
+func resolvePlayFile(writer http.ResponseWriter, r *http.Request) (string, bool) {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		http.Error(writer, "配置未加载", http.StatusInternalServerError)
+		return "", false
+	}
+	absPath, ok := resolveOutputFile(cfg, mux.Vars(r)["path"])
+	if !ok {
+		http.Error(writer, "非法路径", http.StatusForbidden)
+		return "", false
+	}
+	if stat, err := os.Stat(absPath); err != nil || stat.IsDir() {
+		http.Error(writer, "文件不存在", http.StatusNotFound)
+		return "", false
+	}
+	return absPath, true
+}
+
+// getPlayInfo 返回在线播放所需的信息
+// 路由：GET /api/play-info/{path:.*}
+func getPlayInfo(writer http.ResponseWriter, r *http.Request) {
+	absPath, ok := resolvePlayFile(writer, r)
+	if !ok {
+		return
+	}
+	info := playInfo{PlayUrl: "/api/play/" + escapeRelPath(mux.Vars(r)["path"])}
+	switch strings.ToLower(filepath.Ext(absPath)) {
+	case ".mp4", ".m4a":
+		// 浏览器可直接播放
+	case ".flv":
+		idx, err := getRemuxCache().Get(absPath)
+		if err != nil {
+			writeJsonWithStatusCode(writer, http.StatusUnprocessableEntity, commonResp{ErrNo: http.StatusUnprocessableEntity, ErrMsg: err.Error()})
+			return
+		}
+		info.Remux = true
+		info.MimeType = idx.MimeType()
+		info.Duration = float64(idx.Duration()) / 1000
+		info.Width, info.Height = idx.Width, idx.Height
+		info.Keyframes = len(idx.Keyframes)
+	default:
+		info.Remux = true
+		info.MimeType = "video/mp4"
+	}
+	writeJSON(writer, info)
+}
+
+// playVideo 以分段 MP4 流式返回录制文件，供浏览器直接播放
+// FLV 使用内置的转封装并通过关键帧索引跳转；其他格式使用 ffmpeg -c copy；MP4 直接返回原文件
+// start 为起始秒数，实际起点（之前最近的关键帧）通过 X-Start-Time 响应头返回，输出的时间轴从 0 开始
+// 路由：GET /api/play/{path:.*}?start=<sec>
+func playVideo(writer http.ResponseWriter, r *http.Request) {
+	absPath, ok := resolvePlayFile(writer, r)
+	if !ok {
+		return
+	}
+	ext := strings.ToLower(filepath.Ext(absPath))
+	if ext == ".mp4" || ext == ".m4a" {
+		http.ServeFile(writer, r, absPath)
+		return
+	}
+
+	start := 0.0
+	if s := r.URL.Query().Get("start"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			http.Error(writer, "start 参数无效", http.StatusBadRequest)
+			return
+		}
+		start = v
+	}
+
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if ext != ".flv" {
+		ffmpegPath, err := utils.GetFFmpegPath(r.Context())
+		if err != nil {
+			http.Error(writer, "ffmpeg 未安装或未配置", http.StatusServiceUnavailable)
+			return
+		}
+		writer.Header().Set("Content-Type", "video/mp4")
+		writer.Header().Set("X-Start-Time", strconv.FormatFloat(start, 'f', 3, 64))
+		_ = remux.FFmpeg(r.Context(), ffmpegPath, absPath, start, writer)
+		return
+	}
+
+	idx, err := getRemuxCache().Get(absPath)
+	if err != nil {
+		status := http.StatusUnprocessableEntity
+		if os.IsNotExist(err) {
+			status = http.StatusNotFound
+		}
+		http.Error(writer, "无法解析视频: "+err.Error(), status)
+		return
+	}
+	f, err := os.Open(absPath)
+	if err != nil {
+		http.Error(writer, "打开文件失败", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	kf := idx.KeyframeAt(int64(start * 1000))
+	mimeType := "video/mp4"
+	if idx.Video == nil {
+		mimeType = "audio/mp4"
+	}
+	writer.Header().Set("Content-Type", mimeType)
+	writer.Header().Set("X-Start-Time", strconv.FormatFloat(float64(kf.Time-idx.Start)/1000, 'f', 3, 64))
+	_ = remux.Remux(r.Context(), writer, f, idx, kf)
+}
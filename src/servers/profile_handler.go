@@ -0,0 +1,206 @@
+package servers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+)
+
+// errProfileInUse 删除仍被引用的配置模板时返回
+var errProfileInUse = errors.New("配置模板仍被引用")
+
+// errInvalidProfile 更新后的配置模板未通过校验时返回
+var errInvalidProfile = errors.New("配置模板无效")
+
+// profileInfo 配置模板及其引用情况
+type profileInfo struct {
+	configs.ConfigProfile
+	Usage configs.ProfileUsage `json:"usage"`
+}
+
+// getProfiles 获取所有配置模板及其引用情况
+func getProfiles(writer http.ResponseWriter, r *http.Request) {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		writeJsonWithStatusCode(writer, http.StatusInternalServerError, commonResp{
+			ErrNo:  http.StatusInternalServerError,
+			ErrMsg: "配置未初始化",
+		})
+		return
+	}
+
+	profiles := make(map[string]profileInfo, len(cfg.Profiles))
+	for name, profile := range cfg.Profiles {
+		profiles[name] = profileInfo{
+			ConfigProfile: profile,
+			Usage:         cfg.GetProfileUsage(name),
+		}
+	}
+	writeJSON(writer, profiles)
+}
+
+// updateProfile 创建或更新配置模板
+// 由于房间配置在使用时实时解析，修改模板后所有引用它的直播间立即生效，无需重启
+func updateProfile(writer http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(mux.Vars(r)["name"])
+	if name == "" {
+		writeJsonWithStatusCode(writer, http.StatusBadRequest, commonResp{
+			ErrNo:  http.StatusBadRequest,
+			ErrMsg: "配置模板名称不能为空",
+		})
+		return
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJsonWithStatusCode(writer, http.StatusBadRequest, commonResp{
+			ErrNo:  http.StatusBadRequest,
+			ErrMsg: err.Error(),
+		})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := json.Unmarshal(b, &updates); err != nil {
+		writeJsonWithStatusCode(writer, http.StatusBadRequest, commonResp{
+			ErrNo:  http.StatusBadRequest,
+			ErrMsg: "无效的JSON格式: " + err.Error(),
+		})
+		return
+	}
+
+	_, err = configs.UpdateWithRetry(func(c *configs.Config) error {
+		if c.Profiles == nil {
+			c.Profiles = make(map[string]configs.ConfigProfile)
+		}
+
+		profile := c.Profiles[name]
+		if description, ok := updates["description"].(string); ok {
+			profile.Description = description
+		}
+		if err := applyOverridableConfigUpdates(&profile.OverridableConfig, updates); err != nil {
+			return err
+		}
+
+		c.Profiles[name] = profile
+		if err := c.ValidateProfiles(); err != nil {
+			return fmt.Errorf("%w: %w", errInvalidProfile, err)
+		}
+		if err := c.ValidateNotifyRules(); err != nil {
+			return fmt.Errorf("%w: %w", errInvalidProfile, err)
+		}
+		return nil
+	}, 3, 10*time.Millisecond)
+
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, errInvalidProfile) || errors.Is(err, errInvalidConfigUpdate) {
+			code = http.StatusBadRequest
+		}
+		writeJsonWithStatusCode(writer, code, commonResp{
+			ErrNo:  code,
+			ErrMsg: "更新配置模板失败: " + err.Error(),
+		})
+		return
+	}
+
+	writeJSON(writer, commonResp{
+		Data: "OK",
+	})
+}
+
+// deleteProfile 删除配置模板
+// 模板仍被引用时默认拒绝删除，传入 force=true 时会同时移除所有引用
+func deleteProfile(writer http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	force := r.URL.Query().Get("force") == "true"
+
+	_, err := configs.UpdateWithRetry(func(c *configs.Config) error {
+		if _, ok := c.Profiles[name]; !ok {
+			return nil
+		}
+		usage := c.GetProfileUsage(name)
+		if len(usage.Platforms)+len(usage.Rooms) > 0 {
+			if !force {
+				return fmt.Errorf("%w: %d 个平台, %d 个直播间", errProfileInUse, len(usage.Platforms), len(usage.Rooms))
+			}
+			for _, platformKey := range usage.Platforms {
+				pc := c.PlatformConfigs[platformKey]
+				pc.Profiles = removeProfileName(pc.Profiles, name)
+				c.PlatformConfigs[platformKey] = pc
+			}
+			for i := range c.LiveRooms {
+				c.LiveRooms[i].Profiles = removeProfileName(c.LiveRooms[i].Profiles, name)
+			}
+		}
+		delete(c.Profiles, name)
+		return nil
+	}, 3, 10*time.Millisecond)
+
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, errProfileInUse) {
+			code = http.StatusConflict
+		}
+		writeJsonWithStatusCode(writer, code, commonResp{
+			ErrNo:  code,
+			ErrMsg: "删除配置模板失败: " + err.Error(),
+		})
+		return
+	}
+
+	writeJSON(writer, commonResp{
+		Data: "OK",
+	})
+}
+
+// applyProfileReferenceUpdates 处理平台或直播间对配置模板的引用更新
+// 传入空数组表示清除所有引用
+func applyProfileReferenceUpdates(c *configs.Config, target *[]string, updates map[string]interface{}) error {
+	raw, ok := updates["profiles"].([]interface{})
+	if !ok {
+		return nil
+	}
+	names := make([]string, 0, len(raw))
+	for _, v := range raw {
+		name, ok := v.(string)
+		if !ok || name == "" {
+			continue
+		}
+		if _, exists := c.Profiles[name]; !exists {
+			return fmt.Errorf("配置模板 '%s' 不存在", name)
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		*target = nil
+		return nil
+	}
+	// 使用新切片，避免与旧配置快照共享底层数组
+	*target = names
+	return nil
+}
+
+func removeProfileName(names []string, name string) []string {
+	if len(names) == 0 {
+		return names
+	}
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		if n != name {
+			out = append(out, n)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,19 @@
+package servers
+
+import (
+	"net/http"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/pkg/pushdetect"
+)
+
+// getPushDetection 获取推送通道的连接状态
+// B站每个连接只能订阅一个直播间，直播间数超过 max_connections 时 unassigned 为继续轮询检测的直播间数
+func getPushDetection(writer http.ResponseWriter, r *http.Request) {
+	cfg := configs.GetCurrentConfig()
+	writeJSON(writer, map[string]interface{}{
+		"enable":          cfg != nil && cfg.PushDetection.Enable,
+		"max_connections": pushdetect.GetHub().MaxConns(),
+		"stats":           pushdetect.GetHub().Stats(),
+	})
+}
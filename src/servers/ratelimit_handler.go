@@ -0,0 +1,81 @@
+package servers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/pkg/ratelimit"
+)
+
+// getRateLimits 获取各平台各接口类别的令牌桶状态和风控冷却状态
+func getRateLimits(writer http.ResponseWriter, r *http.Request) {
+	limiter := ratelimit.GetGlobalRateLimiter()
+	buckets := limiter.GetBucketStates()
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Platform != buckets[j].Platform {
+			return buckets[i].Platform < buckets[j].Platform
+		}
+		return buckets[i].Endpoint < buckets[j].Endpoint
+	})
+	cooldowns := limiter.GetAllCooldowns()
+	if cooldowns == nil {
+		cooldowns = []ratelimit.CooldownState{}
+	}
+	writeJSON(writer, map[string]interface{}{
+		"buckets":   buckets,
+		"cooldowns": cooldowns,
+	})
+}
+
+// rateLimitInfo 平台某类接口的等待状态，用于接口返回和 SSE 推送
+func rateLimitInfo(waitInfo ratelimit.WaitInfo) map[string]interface{} {
+	return map[string]interface{}{
+		"waited_seconds":      waitInfo.WaitedSeconds,
+		"next_request_in_sec": waitInfo.NextRequestInSec,
+		"min_interval_sec":    waitInfo.MinIntervalSec,
+		"tokens":              waitInfo.Tokens,
+		"burst":               waitInfo.Burst,
+		"waiting":             waitInfo.Waiting,
+		"cooldown_level":      waitInfo.CooldownLevel,
+		"cooldown_until":      waitInfo.CooldownUntil,
+	}
+}
+
+// rateLimitBuckets 平台各接口类别的等待状态
+func rateLimitBuckets(platformKey string) map[ratelimit.Endpoint]interface{} {
+	buckets := make(map[ratelimit.Endpoint]interface{}, len(ratelimit.Endpoints))
+	for _, endpoint := range ratelimit.Endpoints {
+		buckets[endpoint] = rateLimitInfo(ratelimit.GetGlobalRateLimiter().GetWaitInfo(platformKey, endpoint))
+	}
+	return buckets
+}
+
+// platformInfoIntervalSec 平台配置的开播状态检测平均请求间隔（秒），未配置时为 0
+func platformInfoIntervalSec(pc configs.PlatformConfig) float64 {
+	return pc.RateLimits[string(ratelimit.EndpointInfo)].IntervalSec
+}
+
+// setPlatformRateLimit 设置平台某类接口的令牌桶，intervalSec 为 0 时移除该接口类别的配置，burst 为 0 时保留原有容量
+// 总是复制 map，避免修改当前配置快照
+func setPlatformRateLimit(pc *configs.PlatformConfig, endpoint ratelimit.Endpoint, intervalSec float64, burst int) {
+	limits := make(map[string]configs.RateLimitBucket, len(pc.RateLimits)+1)
+	for k, v := range pc.RateLimits {
+		limits[k] = v
+	}
+	if intervalSec <= 0 {
+		delete(limits, string(endpoint))
+	} else {
+		bucket := limits[string(endpoint)]
+		bucket.IntervalSec = intervalSec
+		if burst > 0 {
+			bucket.Burst = burst
+		}
+		bucket.Burst = max(bucket.Burst, 1)
+		limits[string(endpoint)] = bucket
+	}
+	if len(limits) == 0 {
+		limits = nil
+	}
+	pc.RateLimits = limits
+}
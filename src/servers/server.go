@@ -77,6 +77,9 @@ func initMux(ctx context.Context) *mux.Router {
 	apiRoute.HandleFunc("/config/platforms", getPlatformStats).Methods("GET")   // 新增：获取平台统计
 	apiRoute.HandleFunc("/config/platforms/{platform}", updatePlatformConfig).Methods("PUT", "PATCH")
 	apiRoute.HandleFunc("/config/platforms/{platform}", deletePlatformConfig).Methods("DELETE")
+	apiRoute.HandleFunc("/config/profiles", getProfiles).Methods("GET") // 配置模板
+	apiRoute.HandleFunc("/config/profiles/{name}", updateProfile).Methods("PUT", "PATCH")
+	apiRoute.HandleFunc("/config/profiles/{name}", deleteProfile).Methods("DELETE")
 	apiRoute.HandleFunc("/config/rooms/id/{id}", updateRoomConfigById).Methods("PUT", "PATCH") // 更具体的路由必须在通配符之前
 	apiRoute.HandleFunc("/config/rooms/{url:.*}", updateRoomConfig).Methods("PUT", "PATCH")
 	apiRoute.HandleFunc("/config/preview-template", previewOutputTmpl).Methods("POST") // 新增：模板预览
@@ -90,8 +93,12 @@ func initMux(ctx context.Context) *mux.Router {
 	apiRoute.HandleFunc("/lives/{id}/sessions", getLiveSessionHistory).Methods("GET")    // 获取直播会话历史
 	apiRoute.HandleFunc("/lives/{id}/name-history", getLiveNameHistory).Methods("GET")   // 获取名称变更历史
 	apiRoute.HandleFunc("/lives/{id}/history", getLiveHistory).Methods("GET")            // 获取统一历史事件（支持分页筛选）
+	apiRoute.HandleFunc("/lives/{id}/podcast.xml", getLivePodcast).Methods("GET")        // 播客 RSS 订阅源
 	apiRoute.HandleFunc("/lives/{id}/switchStream", switchStreamHandler).Methods("POST") // 切换流设置（需要请求体，必须在通配符之前）
 	apiRoute.HandleFunc("/lives/{id}/{action}", parseLiveAction).Methods("GET")          // 通配符路由必须放在最后
+	apiRoute.HandleFunc("/feed/recordings.atom", getRecordingsFeed).Methods("GET")       // 全部直播间新录制的 Atom 订阅源
+	apiRoute.HandleFunc("/feed/files/{id:[0-9]+}/{name}", getFeedFile).Methods("GET")    // 订阅源中不在输出根目录下的录制文件
+	apiRoute.HandleFunc("/recordings/queue", getRecordingQueue).Methods("GET")           // 同时录制上限和排队状态
 	apiRoute.HandleFunc("/file/{path:.*}", getFileInfo).Methods("GET")
 	apiRoute.HandleFunc("/file/{path:.*}", renameFile).Methods("PUT")
 	apiRoute.HandleFunc("/file/{path:.*}", deleteFile).Methods("DELETE")
@@ -99,6 +106,18 @@ func initMux(ctx context.Context) *mux.Router {
 	apiRoute.HandleFunc("/batch/file/delete", batchDeleteFiles).Methods("POST")
 	apiRoute.HandleFunc("/cookies", getLiveHostCookie).Methods("GET")
 	apiRoute.HandleFunc("/cookies", putLiveHostCookie).Methods("PUT")
+	apiRoute.HandleFunc("/cookies/status", getCookieStatus).Methods("GET")
+	apiRoute.HandleFunc("/cookies/status/check", checkCookieStatus).Methods("POST")
+	apiRoute.HandleFunc("/cookies/refresh_token", putCookieRefreshToken).Methods("PUT")
+	apiRoute.HandleFunc("/ratelimits", getRateLimits).Methods("GET")        // 各平台令牌桶和风控冷却状态
+	apiRoute.HandleFunc("/push-detection", getPushDetection).Methods("GET") // 推送通道连接数和未分配连接的直播间数
+	apiRoute.HandleFunc("/accounts", getAccounts).Methods("GET")            // 命名账号
+	apiRoute.HandleFunc("/accounts/risk-status", getAccountRiskStatus).Methods("GET")
+	apiRoute.HandleFunc("/accounts/{name}", updateAccount).Methods("PUT", "PATCH")
+	apiRoute.HandleFunc("/accounts/{name}", deleteAccount).Methods("DELETE")
+	apiRoute.HandleFunc("/accounts/{name}/check", checkAccount).Methods("POST")
+	apiRoute.HandleFunc("/notify/channels", getNotifyChannels).Methods("GET")
+	apiRoute.HandleFunc("/notify/test", sendTestNotification).Methods("POST") // 发送测试通知
 
 	// Bilibili Login
 	apiRoute.HandleFunc("/bilibili/qrcode", getBilibiliQRCode).Methods("GET")
@@ -111,6 +130,12 @@ func initMux(ctx context.Context) *mux.Router {
 	apiRoute.HandleFunc("/video-library", getVideoLibrary).Methods("GET")
 	apiRoute.HandleFunc("/thumbnail/{path:.*}", getThumbnail).Methods("GET")
 	apiRoute.HandleFunc("/video-files/{path:.*}", getVideoFiles).Methods("GET")
+	apiRoute.HandleFunc("/play-info/{path:.*}", getPlayInfo).Methods("GET")
+	apiRoute.HandleFunc("/play/{path:.*}", playVideo).Methods("GET")           // FLV 等格式转封装为分段 MP4 在线播放
+	apiRoute.HandleFunc("/storyboard/{path:.*}", getStoryboard).Methods("GET") // 故事板（雪碧图 + WebVTT + 波形）
+	apiRoute.HandleFunc("/storyboard-vtt/{path:.*}", getStoryboardVTT).Methods("GET")
+	apiRoute.HandleFunc("/storyboard-sprite/{index:[0-9]+}/{path:.*}", getStoryboardSprite).Methods("GET")
+	apiRoute.HandleFunc("/waveform/{path:.*}", getWaveform).Methods("GET")
 	// 远程 WebUI 路由
 	apiRoute.HandleFunc("/webui/remote/status", getRemoteWebuiStatus).Methods("GET")  // 获取远程 WebUI 状态
 	apiRoute.HandleFunc("/webui/remote/check", checkRemoteWebuiUpdate).Methods("GET") // 检查远程 WebUI 更新
@@ -134,6 +159,7 @@ func initMux(ctx context.Context) *mux.Router {
 	apiRoute.HandleFunc("/iostats/filters", getIOStatsFilters).Methods("GET")
 	apiRoute.HandleFunc("/iostats/disk", getDiskIOStats).Methods("GET")                   // 系统磁盘 I/O 统计
 	apiRoute.HandleFunc("/iostats/devices", getDiskDevices).Methods("GET")                // 可用磁盘设备列表
+	apiRoute.HandleFunc("/iostats/bandwidth", getBandwidthStats).Methods("GET")           // 各使用方的实时带宽
 	apiRoute.HandleFunc("/iostats/memory", getMemoryStatsHistory).Methods("GET")          // 内存统计历史数据
 	apiRoute.HandleFunc("/iostats/memory/categories", getMemoryCategories).Methods("GET") // 可用内存类别列表
 
@@ -294,6 +320,11 @@ func setupRecorderStatusBroadcast() {
 		GetSSEHub().BroadcastRecorderStatus(liveId, status)
 	})
 
+	// 设置录制排队状态广播回调
+	recorders.SetBroadcastRecordingQueueFunc(func(status recorders.RecordingQueueStatus) {
+		GetSSEHub().BroadcastRecordingQueue(status)
+	})
+
 	// 设置录制结束回调，用于触发优雅更新检查
 	recorders.SetOnRecordingEndFunc(func(ctx context.Context) {
 		// 延迟一小段时间，确保录制器已完全关闭
@@ -40,6 +40,10 @@ const (
 	SSEEventUpdateReady SSEEventType = "update_ready"
 	// SSEEventUpdateError 更新过程中出错
 	SSEEventUpdateError SSEEventType = "update_error"
+	// SSEEventRiskControl 平台风控冷却状态变化
+	SSEEventRiskControl SSEEventType = "risk_control"
+	// SSEEventRecordingQueue 同时录制排队状态变化
+	SSEEventRecordingQueue SSEEventType = "recording_queue"
 )
 
 // SSEMessage SSE 消息结构
@@ -207,6 +211,24 @@ func (h *SSEHub) BroadcastUpdateError(err error) {
 	})
 }
 
+// BroadcastRiskControl 广播平台风控冷却状态变化
+func (h *SSEHub) BroadcastRiskControl(data interface{}) {
+	h.Broadcast(SSEMessage{
+		Type:   SSEEventRiskControl,
+		RoomID: "",
+		Data:   data,
+	})
+}
+
+// BroadcastRecordingQueue 广播同时录制排队状态变化
+func (h *SSEHub) BroadcastRecordingQueue(data interface{}) {
+	h.Broadcast(SSEMessage{
+		Type:   SSEEventRecordingQueue,
+		RoomID: "",
+		Data:   data,
+	})
+}
+
 // ClientCount 获取当前连接的客户端数量
 func (h *SSEHub) ClientCount() int {
 	h.mu.RLock()
@@ -357,10 +379,14 @@ func RegisterSSEEventListeners(inst *instance.Instance) {
 		"ListenStop",
 		"LiveStart",
 		"LiveEnd",
+		"LiveInterrupted", // 直播中断，进入宽限期
+		"LiveResumed",     // 宽限期内直播恢复
 		"RoomNameChanged",
 		"RoomInitializingFinished",
-		"RecorderStart", // 录制开始
-		"RecorderStop",  // 录制结束
+		"RecorderStart",              // 录制开始
+		"RecorderStop",               // 录制结束
+		"RecorderPreempted",          // 录制被优先级更高的直播间抢占
+		"RecorderDownloaderSwitched", // 下载器连续快速失败后切换
 	}
 
 	for _, eventType := range eventTypes {
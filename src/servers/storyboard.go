@@ -0,0 +1,186 @@
+package servers
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/bililive-go/bililive-go/src/configs"
+	"github.com/bililive-go/bililive-go/src/pkg/storyboard"
+	"github.com/bililive-go/bililive-go/src/pkg/utils"
+)
+
+// storyboardRecordingWindow 视频在该时长内有写入时视为仍在录制，不重新生成故事板
+const storyboardRecordingWindow = time.Minute
+
+// storyboardResp 故事板描述，附带雪碧图、WebVTT 和波形的访问地址
+type storyboardResp struct {
+	*storyboard.Manifest
+	VttUrl      string   `json:"vtt_url,omitempty"`
+	SpriteUrls  []string `json:"sprite_urls"`
+	WaveformUrl string   `json:"waveform_url,omitempty"`
+}
+
+// resolveOutputFile 将相对 output_path 的路径转换为绝对路径，并防止路径穿越
+func resolveOutputFile(cfg *configs.Config, relPath string) (string, bool) {
+	rootPath := cfg.OutPutPath
+	if rootPath == "" {
+		rootPath = "./"
+	}
+	absRoot, _ := filepath.Abs(rootPath)
+	absPath, _ := filepath.Abs(filepath.Join(rootPath, filepath.FromSlash(relPath)))
+	if relPath == "" || !strings.HasPrefix(filepath.Clean(absPath)+string(filepath.Separator), filepath.Clean(absRoot)+string(filepath.Separator)) {
+		return "", false
+	}
+	return absPath, true
+}
+
+// appDataStoryboardDir 按需生成的故事板缓存在 .appdata/storyboards/ 下，与缩略图一致用相对路径作为目录名
+func appDataStoryboardDir(cfg *configs.Config, relPath string) string {
+	appDataPath := cfg.AppDataPath
+	if appDataPath == "" {
+		appDataPath = ".appdata"
+	}
+	return filepath.Join(appDataPath, "storyboards", strings.ReplaceAll(filepath.ToSlash(relPath), "/", "_"))
+}
+
+// cachedStoryboardDir 返回已有故事板的目录，优先使用后处理阶段生成在视频旁边的故事板
+func cachedStoryboardDir(cfg *configs.Config, relPath, absPath string) string {
+	for _, dir := range []string{storyboard.SiblingDir(absPath), appDataStoryboardDir(cfg, relPath)} {
+		if _, err := os.Stat(filepath.Join(dir, storyboard.ManifestFile)); err == nil {
+			return dir
+		}
+	}
+	return ""
+}
+
+// escapeRelPath 按路径段转义相对路径，用于拼接 URL
+func escapeRelPath(relPath string) string {
+	segments := strings.Split(filepath.ToSlash(relPath), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// loadStoryboard 读取故事板，不存在或视频在生成之后又有写入时重新生成到 app data
+// 仍在录制的视频使用已有的故事板，没有时返回 409，避免每次请求都重新生成
+func loadStoryboard(writer http.ResponseWriter, r *http.Request) (*storyboard.Manifest, string, bool) {
+	cfg := configs.GetCurrentConfig()
+	if cfg == nil {
+		http.Error(writer, "配置未加载", http.StatusInternalServerError)
+		return nil, "", false
+	}
+	relPath := mux.Vars(r)["path"]
+	absPath, ok := resolveOutputFile(cfg, relPath)
+	if !ok {
+		http.Error(writer, "非法路径", http.StatusForbidden)
+		return nil, "", false
+	}
+	stat, err := os.Stat(absPath)
+	if err != nil || stat.IsDir() {
+		http.Error(writer, "文件不存在", http.StatusNotFound)
+		return nil, "", false
+	}
+
+	recording := time.Since(stat.ModTime()) < storyboardRecordingWindow
+	if dir := cachedStoryboardDir(cfg, relPath, absPath); dir != "" {
+		if m, err := storyboard.Load(dir); err == nil && (recording || m.IsFresh(stat.ModTime())) {
+			return m, dir, true
+		}
+	}
+	if recording {
+		http.Error(writer, "文件仍在录制中，录制完成后再生成故事板", http.StatusConflict)
+		return nil, "", false
+	}
+
+	ffmpegPath, err := utils.GetFFmpegPath(r.Context())
+	if err != nil {
+		http.Error(writer, "ffmpeg 未安装或未配置", http.StatusServiceUnavailable)
+		return nil, "", false
+	}
+	dir := appDataStoryboardDir(cfg, relPath)
+	m, err := storyboard.Ensure(r.Context(), ffmpegPath, absPath, dir, storyboard.Options{})
+	if err != nil {
+		http.Error(writer, "生成故事板失败: "+err.Error(), http.StatusInternalServerError)
+		return nil, "", false
+	}
+	return m, dir, true
+}
+
+// getStoryboard 返回视频的故事板描述，没有缓存时按需生成
+// 路由：GET /api/storyboard/{path:.*}
+func getStoryboard(writer http.ResponseWriter, r *http.Request) {
+	m, _, ok := loadStoryboard(writer, r)
+	if !ok {
+		return
+	}
+	escaped := escapeRelPath(mux.Vars(r)["path"])
+	resp := storyboardResp{Manifest: m, SpriteUrls: make([]string, 0, len(m.Sprites))}
+	for i := range m.Sprites {
+		resp.SpriteUrls = append(resp.SpriteUrls, "/api/storyboard-sprite/"+strconv.Itoa(i)+"/"+escaped)
+	}
+	if len(m.Sprites) > 0 {
+		resp.VttUrl = "/api/storyboard-vtt/" + escaped
+	}
+	if m.Waveform != "" {
+		resp.WaveformUrl = "/api/waveform/" + escaped
+	}
+	writeJSON(writer, resp)
+}
+
+// getStoryboardVTT 返回 WebVTT 缩略图轨道，雪碧图使用 API 地址
+// 路由：GET /api/storyboard-vtt/{path:.*}
+func getStoryboardVTT(writer http.ResponseWriter, r *http.Request) {
+	m, _, ok := loadStoryboard(writer, r)
+	if !ok {
+		return
+	}
+	if len(m.Sprites) == 0 {
+		http.Error(writer, "没有视频画面", http.StatusNotFound)
+		return
+	}
+	escaped := escapeRelPath(mux.Vars(r)["path"])
+	writer.Header().Set("Content-Type", "text/vtt; charset=utf-8")
+	_ = m.WriteVTT(writer, func(sheet int) string {
+		return "/api/storyboard-sprite/" + strconv.Itoa(sheet) + "/" + escaped
+	})
+}
+
+// getStoryboardSprite 返回第 index 张雪碧图
+// 路由：GET /api/storyboard-sprite/{index}/{path:.*}
+func getStoryboardSprite(writer http.ResponseWriter, r *http.Request) {
+	m, dir, ok := loadStoryboard(writer, r)
+	if !ok {
+		return
+	}
+	index, err := strconv.Atoi(mux.Vars(r)["index"])
+	if err != nil || index < 0 || index >= len(m.Sprites) {
+		http.Error(writer, "雪碧图不存在", http.StatusNotFound)
+		return
+	}
+	writer.Header().Set("Content-Type", "image/jpeg")
+	writer.Header().Set("Cache-Control", "public, max-age=86400")
+	http.ServeFile(writer, r, filepath.Join(dir, filepath.Base(m.Sprites[index])))
+}
+
+// getWaveform 返回音频波形
+// 路由：GET /api/waveform/{path:.*}
+func getWaveform(writer http.ResponseWriter, r *http.Request) {
+	m, dir, ok := loadStoryboard(writer, r)
+	if !ok {
+		return
+	}
+	if m.Waveform == "" {
+		http.Error(writer, "没有音频", http.StatusNotFound)
+		return
+	}
+	writer.Header().Set(contentType, contentTypeJSON)
+	http.ServeFile(writer, r, filepath.Join(dir, filepath.Base(m.Waveform)))
+}